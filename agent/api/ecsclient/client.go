@@ -69,7 +69,11 @@ func NewECSClient(
 	}
 	standardClient := ecs.New(session.New(&ecsConfig))
 	submitStateChangeClient := newSubmitStateChangeClient(&ecsConfig)
-	pollEndpoinCache := async.NewLRUCache(pollEndpointCacheSize, pollEndpointCacheTTL)
+	pollEndpointTTL := pollEndpointCacheTTL
+	if config.PollEndpointCacheTTL > 0 {
+		pollEndpointTTL = config.PollEndpointCacheTTL
+	}
+	pollEndpoinCache := async.NewLRUCache(pollEndpointCacheSize, pollEndpointTTL)
 	return &APIECSClient{
 		credentialProvider:      credentialProvider,
 		config:                  config,
@@ -365,6 +369,9 @@ func (client *APIECSClient) SubmitTaskStateChange(change api.TaskStateChange) er
 		PullStoppedAt:      change.PullStoppedAt,
 		ExecutionStoppedAt: change.ExecutionStoppedAt,
 	}
+	if err := req.TruncateReason(); err != nil {
+		seelog.Warnf("Truncating the reason of task state change [%s]: %v", change.TaskARN, err)
+	}
 
 	containerEvents := make([]*ecs.ContainerStateChange, len(change.Containers))
 	for i, containerEvent := range change.Containers {
@@ -611,7 +611,7 @@ func TestRegisterBlankCluster(t *testing.T) {
 	gomock.InOrder(
 		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return("instanceIdentityDocument", nil),
 		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource).Return("signature", nil),
-		mc.EXPECT().RegisterContainerInstance(gomock.Any()).Return(nil, awserr.New("ClientException", "No such cluster", errors.New("No such cluster"))),
+		mc.EXPECT().RegisterContainerInstance(gomock.Any()).Return(nil, awserr.New(ecs.ErrCodeClientException, "No such cluster", errors.New("No such cluster"))),
 		mc.EXPECT().CreateCluster(&ecs.CreateClusterInput{ClusterName: &defaultCluster}).Return(&ecs.CreateClusterOutput{Cluster: &ecs.Cluster{ClusterName: &defaultCluster}}, nil),
 		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentResource).Return("instanceIdentityDocument", nil),
 		mockEC2Metadata.EXPECT().GetDynamicData(ec2.InstanceIdentityDocumentSignatureResource).Return("signature", nil),
@@ -839,6 +839,28 @@ func TestSubmitTaskStateChangeWithoutAttachments(t *testing.T) {
 	assert.NoError(t, err, "Unable to submit task state change with no attachments")
 }
 
+func TestSubmitTaskStateChangeLongReason(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client, _, mockSubmitStateClient := NewMockClient(mockCtrl, ec2.NewBlackholeEC2MetadataClient(), nil)
+	mockSubmitStateClient.EXPECT().SubmitTaskStateChange(&taskSubmitInputMatcher{
+		ecs.SubmitTaskStateChangeInput{
+			Cluster: aws.String(configuredCluster),
+			Task:    aws.String("task_arn"),
+			Reason:  aws.String(strings.Repeat("a", ecsMaxReasonLength)),
+			Status:  aws.String("STOPPED"),
+		},
+	})
+
+	err := client.SubmitTaskStateChange(api.TaskStateChange{
+		TaskARN: "task_arn",
+		Status:  apitaskstatus.TaskStopped,
+		Reason:  strings.Repeat("a", ecsMaxReasonLength+1),
+	})
+	assert.NoError(t, err, "Unable to submit task state change with a long reason")
+}
+
 // TestSubmitContainerStateChangeWhileTaskInPending tests the container state change was submitted
 // when the task is still in pending state
 func TestSubmitContainerStateChangeWhileTaskInPending(t *testing.T) {
@@ -994,7 +994,7 @@ func TestRegisterContainerInstanceInvalidParameterTerminalError(t *testing.T) {
 		dockerClient.EXPECT().ListPluginsWithFilters(gomock.Any(), gomock.Any(), gomock.Any(),
 			gomock.Any()).AnyTimes().Return([]string{}, nil),
 		client.EXPECT().RegisterContainerInstance(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(
-			"", "", awserr.New("InvalidParameterException", "", nil)),
+			"", "", awserr.New(ecs.ErrCodeInvalidParameterException, "", nil)),
 	)
 
 	cfg := getTestConfig()
@@ -466,6 +466,7 @@ func environmentConfig() (Config, error) {
 		DockerStopTimeout:                  parseDockerStopTimeout(),
 		ContainerStartTimeout:              parseContainerStartTimeout(),
 		ImagePullInactivityTimeout:         parseImagePullInactivityTimeout(),
+		PollEndpointCacheTTL:               parseEnvVariableDuration("ECS_POLL_ENDPOINT_CACHE_TTL"),
 		CredentialsAuditLogFile:            os.Getenv("ECS_AUDIT_LOGFILE"),
 		CredentialsAuditLogDisabled:        utils.ParseBool(os.Getenv("ECS_AUDIT_LOGFILE_DISABLED"), false),
 		TaskIAMRoleEnabledForNetworkHost:   utils.ParseBool(os.Getenv("ECS_ENABLE_TASK_IAM_ROLE_NETWORK_HOST"), false),
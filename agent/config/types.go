@@ -103,6 +103,10 @@ type Config struct {
 	// ImagePullInactivityTimeout is here to override the amount of time to wait when pulling and extracting a container
 	ImagePullInactivityTimeout time.Duration
 
+	// PollEndpointCacheTTL specifies how long the endpoints returned by
+	// DiscoverPollEndpoint are cached before they are discovered again
+	PollEndpointCacheTTL time.Duration
+
 	// AvailableLoggingDrivers specifies the logging drivers available for use
 	// with Docker.  If not set, it defaults to ["json-file","none"].
 	AvailableLoggingDrivers []dockerclient.LoggingDriver
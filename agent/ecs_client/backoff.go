@@ -0,0 +1,68 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"math/rand"
+	"time"
+)
+
+// BackoffFunc returns the delay to wait before retrying a call, given the
+// number of retries already made. The first retry is attempt 0.
+type BackoffFunc func(attempt int) time.Duration
+
+// NewLinearBackoff returns a BackoffFunc whose delay grows by base with each
+// attempt: base, 2*base, 3*base and so on.
+func NewLinearBackoff(base time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		return base * time.Duration(attempt+1)
+	}
+}
+
+// NewExponentialBackoff returns a BackoffFunc whose delay doubles with each
+// attempt, starting from base and capped at maxDelay.
+func NewExponentialBackoff(base time.Duration, maxDelay time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		return exponentialDelay(base, maxDelay, attempt)
+	}
+}
+
+// NewFullJitterBackoff returns a BackoffFunc whose delay is picked at random
+// between 0 and the exponential delay for the attempt, capped at max. Spreading
+// the retries of many callers this way keeps them from retrying in lockstep.
+func NewFullJitterBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		delay := exponentialDelay(base, max, attempt)
+		if delay <= 0 {
+			return 0
+		}
+		return time.Duration(rand.Int63n(int64(delay)))
+	}
+}
+
+// exponentialDelay returns base*2^attempt, capped at max
+func exponentialDelay(base, max time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 0; i < attempt; i++ {
+		// Stop doubling once the cap is reached, which also prevents overflow
+		if delay >= max/2 {
+			return max
+		}
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,74 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearBackoff(t *testing.T) {
+	backoff := NewLinearBackoff(100 * time.Millisecond)
+
+	assert.Equal(t, 100*time.Millisecond, backoff(0))
+	assert.Equal(t, 200*time.Millisecond, backoff(1))
+	assert.Equal(t, 1100*time.Millisecond, backoff(10))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := NewExponentialBackoff(100*time.Millisecond, 30*time.Second)
+
+	assert.Equal(t, 100*time.Millisecond, backoff(0))
+	assert.Equal(t, 200*time.Millisecond, backoff(1))
+	assert.Equal(t, 30*time.Second, backoff(10), "102.4s should be capped at 30s")
+}
+
+func TestExponentialBackoffBelowCap(t *testing.T) {
+	backoff := NewExponentialBackoff(time.Millisecond, time.Minute)
+
+	assert.Equal(t, 1024*time.Millisecond, backoff(10))
+}
+
+func TestExponentialBackoffDoesNotOverflow(t *testing.T) {
+	backoff := NewExponentialBackoff(time.Second, time.Hour)
+
+	assert.Equal(t, time.Hour, backoff(1000))
+}
+
+func TestFullJitterBackoff(t *testing.T) {
+	backoff := NewFullJitterBackoff(100*time.Millisecond, 30*time.Second)
+
+	for _, tc := range []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{10, 30 * time.Second},
+	} {
+		for i := 0; i < 100; i++ {
+			delay := backoff(tc.attempt)
+			assert.True(t, delay >= 0 && delay < tc.max,
+				"attempt %d: delay %v should be in [0, %v)", tc.attempt, delay, tc.max)
+		}
+	}
+}
+
+func TestFullJitterBackoffZeroBase(t *testing.T) {
+	assert.Equal(t, time.Duration(0), NewFullJitterBackoff(0, time.Second)(3))
+}
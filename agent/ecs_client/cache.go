@@ -0,0 +1,138 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/async"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+)
+
+const (
+	// familyLookupTTL is how long the revision a bare family resolved to is
+	// kept, so that new revisions of the family are seen soon after they are
+	// registered
+	familyLookupTTL = 30 * time.Second
+	// neverExpire is the TTL of the cached revisions, which never change once
+	// registered
+	neverExpire = time.Duration(math.MaxInt64)
+)
+
+// ContentAddressedTaskDefinitionCache caches the task definitions described
+// through a client. A revision, given as an ARN or as family:revision, never
+// changes once registered, so it is kept under its revision ARN and never
+// described again while cached. A bare family resolves to its latest ACTIVE
+// revision, which changes as revisions are registered; its lookup is kept for
+// 30 seconds, or until Invalidate is called or it is evicted. Both hold at
+// most maxSize entries, evicting the least recently used first. It is safe
+// for concurrent use.
+type ContentAddressedTaskDefinitionCache struct {
+	client ecs.ECSClientInterface
+
+	// revisions holds task definitions keyed by revision ARN
+	revisions async.Cache
+	// aliases maps family:revision to the revision ARN
+	aliases async.Cache
+	// families maps a family to the revision ARN it last resolved to
+	families async.Cache
+}
+
+// NewContentAddressedTaskDefinitionCache creates a cache of the task
+// definitions described through client, holding at most maxSize revisions
+// and maxSize family lookups
+func NewContentAddressedTaskDefinitionCache(client ecs.ECSClientInterface, maxSize int) *ContentAddressedTaskDefinitionCache {
+	return newContentAddressedTaskDefinitionCache(client, maxSize, familyLookupTTL)
+}
+
+func newContentAddressedTaskDefinitionCache(client ecs.ECSClientInterface, maxSize int, familyTTL time.Duration) *ContentAddressedTaskDefinitionCache {
+	return &ContentAddressedTaskDefinitionCache{
+		client:    client,
+		revisions: async.NewLRUCache(maxSize, neverExpire),
+		aliases:   async.NewLRUCache(maxSize, neverExpire),
+		families:  async.NewLRUCache(maxSize, familyTTL),
+	}
+}
+
+// DescribeTaskDefinition returns the task definition given as a family, a
+// family:revision pair or an ARN, describing it only if it is not cached.
+// The task definition returned is a copy that the caller may modify.
+func (c *ContentAddressedTaskDefinitionCache) DescribeTaskDefinition(ctx aws.Context, taskDefinition string) (*ecs.TaskDefinition, error) {
+	if cached, ok := c.get(taskDefinition); ok {
+		return awsutil.CopyOf(cached).(*ecs.TaskDefinition), nil
+	}
+
+	output, err := c.client.DescribeTaskDefinitionWithContext(ctx, &ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(taskDefinition),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if output.TaskDefinition == nil {
+		return nil, fmt.Errorf("ecs client: no task definition returned for %s", taskDefinition)
+	}
+	c.add(taskDefinition, output.TaskDefinition)
+	return awsutil.CopyOf(output.TaskDefinition).(*ecs.TaskDefinition), nil
+}
+
+// Invalidate drops the lookup of a family, so that its latest revision is
+// described again, for example after a new revision is registered
+func (c *ContentAddressedTaskDefinitionCache) Invalidate(family string) {
+	c.families.Delete(family)
+}
+
+// get returns the cached task definition of an identifier
+func (c *ContentAddressedTaskDefinitionCache) get(identifier string) (*ecs.TaskDefinition, bool) {
+	arn := identifier
+	if isFamily(identifier) {
+		value, ok := c.families.Get(identifier)
+		if !ok {
+			return nil, false
+		}
+		arn = value.(string)
+	} else if alias, ok := c.aliases.Get(identifier); ok {
+		arn = alias.(string)
+	}
+	value, ok := c.revisions.Get(arn)
+	if !ok {
+		return nil, false
+	}
+	return value.(*ecs.TaskDefinition), true
+}
+
+// add caches a task definition described with identifier
+func (c *ContentAddressedTaskDefinitionCache) add(identifier string, taskDefinition *ecs.TaskDefinition) {
+	arn := aws.StringValue(taskDefinition.TaskDefinitionArn)
+	c.revisions.Set(arn, awsutil.CopyOf(taskDefinition))
+	c.aliases.Set(familyRevision(taskDefinition), arn)
+	if isFamily(identifier) {
+		c.families.Set(identifier, arn)
+	}
+}
+
+// isFamily returns true if a task definition identifier is a bare family,
+// rather than a revision
+func isFamily(identifier string) bool {
+	return !strings.Contains(identifier, ":")
+}
+
+// familyRevision returns the family:revision identifier of a task definition
+func familyRevision(taskDefinition *ecs.TaskDefinition) string {
+	return fmt.Sprintf("%s:%d", aws.StringValue(taskDefinition.Family), aws.Int64Value(taskDefinition.Revision))
+}
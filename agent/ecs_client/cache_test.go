@@ -0,0 +1,208 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/ecsfake"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// describeCounter counts the DescribeTaskDefinition calls made to the
+// client it wraps
+type describeCounter struct {
+	ecs.ECSClientInterface
+	calls int64
+}
+
+func (d *describeCounter) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	atomic.AddInt64(&d.calls, 1)
+	return d.ECSClientInterface.DescribeTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+func (d *describeCounter) count() int {
+	return int(atomic.LoadInt64(&d.calls))
+}
+
+// registerTestTaskDefinitions registers the first n revisions of each family
+func registerTestTaskDefinitions(t *testing.T, client ecs.ECSClientInterface, n int, families ...string) {
+	for _, family := range families {
+		for i := 0; i < n; i++ {
+			_, err := client.RegisterTaskDefinitionWithContext(context.Background(), &ecs.RegisterTaskDefinitionInput{
+				Family: aws.String(family),
+				ContainerDefinitions: []*ecs.ContainerDefinition{{
+					Name:  aws.String("container"),
+					Image: aws.String("busybox"),
+				}},
+			})
+			require.NoError(t, err)
+		}
+	}
+}
+
+func TestTaskDefinitionCacheRevision(t *testing.T) {
+	client := &describeCounter{ECSClientInterface: ecsfake.NewServer()}
+	registerTestTaskDefinitions(t, client, 1, "web")
+	cache := NewContentAddressedTaskDefinitionCache(client, 10)
+
+	taskDefinition, err := cache.DescribeTaskDefinition(context.Background(), "web:1")
+	require.NoError(t, err)
+	assert.Equal(t, "web", aws.StringValue(taskDefinition.Family))
+	assert.Equal(t, 1, client.count())
+
+	// The revision is found again by family:revision and by its ARN
+	for _, identifier := range []string{"web:1", aws.StringValue(taskDefinition.TaskDefinitionArn)} {
+		cached, err := cache.DescribeTaskDefinition(context.Background(), identifier)
+		require.NoError(t, err)
+		assert.Equal(t, taskDefinition, cached)
+	}
+	assert.Equal(t, 1, client.count())
+
+	// Changing the task definition returned leaves the cached one alone
+	taskDefinition.Family = aws.String("changed")
+	cached, err := cache.DescribeTaskDefinition(context.Background(), "web:1")
+	require.NoError(t, err)
+	assert.Equal(t, "web", aws.StringValue(cached.Family))
+}
+
+func TestTaskDefinitionCacheFamily(t *testing.T) {
+	client := &describeCounter{ECSClientInterface: ecsfake.NewServer()}
+	registerTestTaskDefinitions(t, client, 1, "web")
+	cache := NewContentAddressedTaskDefinitionCache(client, 10)
+
+	taskDefinition, err := cache.DescribeTaskDefinition(context.Background(), "web")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), aws.Int64Value(taskDefinition.Revision))
+
+	// A new revision is only seen once the family lookup is invalidated
+	registerTestTaskDefinitions(t, client, 1, "web")
+	taskDefinition, err = cache.DescribeTaskDefinition(context.Background(), "web")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), aws.Int64Value(taskDefinition.Revision))
+	assert.Equal(t, 1, client.count())
+
+	cache.Invalidate("web")
+	taskDefinition, err = cache.DescribeTaskDefinition(context.Background(), "web")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), aws.Int64Value(taskDefinition.Revision))
+	assert.Equal(t, 2, client.count())
+
+	// The revision the family resolved to is cached too
+	_, err = cache.DescribeTaskDefinition(context.Background(), "web:2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.count())
+}
+
+func TestTaskDefinitionCacheFamilyExpires(t *testing.T) {
+	client := &describeCounter{ECSClientInterface: ecsfake.NewServer()}
+	registerTestTaskDefinitions(t, client, 1, "web")
+	cache := newContentAddressedTaskDefinitionCache(client, 10, 10*time.Millisecond)
+
+	taskDefinition, err := cache.DescribeTaskDefinition(context.Background(), "web")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), aws.Int64Value(taskDefinition.Revision))
+
+	// Once the family lookup expires, the new revision is seen without
+	// invalidating the family
+	registerTestTaskDefinitions(t, client, 1, "web")
+	time.Sleep(20 * time.Millisecond)
+	taskDefinition, err = cache.DescribeTaskDefinition(context.Background(), "web")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), aws.Int64Value(taskDefinition.Revision))
+	assert.Equal(t, 2, client.count())
+
+	// The revisions themselves do not expire
+	_, err = cache.DescribeTaskDefinition(context.Background(), "web:1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.count())
+}
+
+func TestTaskDefinitionCacheEviction(t *testing.T) {
+	client := &describeCounter{ECSClientInterface: ecsfake.NewServer()}
+	registerTestTaskDefinitions(t, client, 1, "a", "b", "c")
+	cache := NewContentAddressedTaskDefinitionCache(client, 2)
+
+	for _, family := range []string{"a", "b", "a", "c"} {
+		_, err := cache.DescribeTaskDefinition(context.Background(), family)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, client.count())
+
+	// b was the least recently used when c was added, so only b is described
+	// again
+	for _, family := range []string{"a", "c"} {
+		_, err := cache.DescribeTaskDefinition(context.Background(), family)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 3, client.count())
+	_, err := cache.DescribeTaskDefinition(context.Background(), "b:1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, client.count())
+}
+
+func TestTaskDefinitionCacheError(t *testing.T) {
+	cache := NewContentAddressedTaskDefinitionCache(ecsfake.NewServer(), 10)
+
+	_, err := cache.DescribeTaskDefinition(context.Background(), "missing")
+	assert.Error(t, err)
+	_, ok := cache.families.Get("missing")
+	assert.False(t, ok, "a failed lookup should not be cached")
+}
+
+func TestTaskDefinitionCacheConcurrentReads(t *testing.T) {
+	const families = 5
+	client := &describeCounter{ECSClientInterface: ecsfake.NewServer()}
+	var names []string
+	for i := 0; i < families; i++ {
+		names = append(names, fmt.Sprintf("family-%d", i))
+	}
+	registerTestTaskDefinitions(t, client, 2, names...)
+	// The cache is smaller than the working set, so reads race with evictions
+	cache := NewContentAddressedTaskDefinitionCache(client, families)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				family := names[(i+j)%families]
+				identifier := family
+				if j%2 == 1 {
+					identifier = family + ":1"
+				}
+				taskDefinition, err := cache.DescribeTaskDefinition(context.Background(), identifier)
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, family, aws.StringValue(taskDefinition.Family))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.True(t, client.count() < 20*50, "some reads should be served from the cache")
+}
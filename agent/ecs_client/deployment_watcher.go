@@ -0,0 +1,233 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// DeploymentEvent is a change of a deployment of a service seen between two
+// polls
+type DeploymentEvent struct {
+	// Timestamp is when the poll that saw the change was made
+	Timestamp time.Time
+	// OldState is the deployment as of the previous poll, or nil if the
+	// deployment is new
+	OldState *ecs.Deployment
+	// NewState is the deployment as of this poll, or nil if the deployment
+	// is gone
+	NewState *ecs.Deployment
+	// Err is the error of the poll if it failed, in which case OldState and
+	// NewState are nil
+	Err error
+}
+
+// serviceStatusInactive is the status of a service once it is deleted
+const serviceStatusInactive = "INACTIVE"
+
+// ServiceMissingError is the error of a poll that found no service to
+// describe, with the reason of the failure returned by DescribeServices, or
+// found it INACTIVE because it was deleted
+type ServiceMissingError struct {
+	Service string
+	Reason  string
+}
+
+func (err *ServiceMissingError) Error() string {
+	return fmt.Sprintf("unable to describe service %s: %s", err.Service, err.Reason)
+}
+
+// DeploymentWatcher polls DescribeServices for a service and emits an event
+// each time one of its deployments is added, changed or removed. The
+// deployments seen by the first poll are all emitted as added. A poll that
+// fails is emitted as an event with its error, and the next one is diffed
+// against the last poll that succeeded. Polling stops once the service or its
+// cluster is found missing, or the service INACTIVE, as retrying would not
+// help; Err returns the error it stopped on.
+type DeploymentWatcher struct {
+	client   ecs.ECSClientInterface
+	cluster  string
+	service  string
+	interval time.Duration
+
+	lock   sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	// err is the error polling stopped on
+	err error
+}
+
+// NewDeploymentWatcher creates a DeploymentWatcher that polls a service of a
+// cluster every interval
+func NewDeploymentWatcher(client ecs.ECSClientInterface, cluster, service string, interval time.Duration) *DeploymentWatcher {
+	return &DeploymentWatcher{
+		client:   client,
+		cluster:  cluster,
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Start starts polling and returns the channel the events are sent on. The
+// channel is closed once polling stops, when ctx is done or Stop is called.
+// Start must only be called once.
+func (w *DeploymentWatcher) Start(ctx aws.Context) <-chan DeploymentEvent {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	events := make(chan DeploymentEvent)
+	go w.watch(ctx, events)
+	return events
+}
+
+// Stop stops polling and waits for the events channel to be closed
+func (w *DeploymentWatcher) Stop() {
+	w.lock.Lock()
+	cancel, done := w.cancel, w.done
+	w.lock.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Err returns the error polling stopped on because the service or its
+// cluster is missing, or nil if polling has not stopped or stopped otherwise
+func (w *DeploymentWatcher) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.err
+}
+
+// watch polls the service until ctx is done or the service is missing,
+// sending the changes seen and the errors of the polls on events
+func (w *DeploymentWatcher) watch(ctx aws.Context, events chan<- DeploymentEvent) {
+	defer close(w.done)
+	defer close(events)
+
+	var last []*ecs.Deployment
+	for {
+		deployments, err := w.poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case events <- DeploymentEvent{Timestamp: time.Now(), Err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if isServiceMissing(err) {
+				w.lock.Lock()
+				w.err = err
+				w.lock.Unlock()
+				return
+			}
+		} else {
+			for _, event := range diffDeployments(last, deployments, time.Now()) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			last = deployments
+		}
+
+		timer := time.NewTimer(w.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// poll returns the current deployments of the service
+func (w *DeploymentWatcher) poll(ctx aws.Context) ([]*ecs.Deployment, error) {
+	output, err := w.client.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String(w.cluster),
+		Services: aws.StringSlice([]string{w.service}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Services) == 0 {
+		reason := "MISSING"
+		if len(output.Failures) > 0 {
+			reason = aws.StringValue(output.Failures[0].Reason)
+		}
+		return nil, &ServiceMissingError{Service: w.service, Reason: reason}
+	}
+	// A deleted service is still described for a while, as INACTIVE
+	if aws.StringValue(output.Services[0].Status) == serviceStatusInactive {
+		return nil, &ServiceMissingError{Service: w.service, Reason: serviceStatusInactive}
+	}
+	return output.Services[0].Deployments, nil
+}
+
+// isServiceMissing returns true if a poll failed because the service or its
+// cluster does not exist
+func isServiceMissing(err error) bool {
+	if _, ok := err.(*ServiceMissingError); ok {
+		return true
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case ecs.ErrCodeClusterNotFoundException, ecs.ErrCodeServiceNotFoundException:
+			return true
+		}
+	}
+	return false
+}
+
+// diffDeployments returns the events turning the previous deployments into
+// the current ones: the deployments that are new or changed, in the order of
+// the current deployments, followed by those that are gone
+func diffDeployments(previous, current []*ecs.Deployment, timestamp time.Time) []DeploymentEvent {
+	previousByID := make(map[string]*ecs.Deployment, len(previous))
+	for _, deployment := range previous {
+		previousByID[aws.StringValue(deployment.Id)] = deployment
+	}
+	currentByID := make(map[string]*ecs.Deployment, len(current))
+	for _, deployment := range current {
+		currentByID[aws.StringValue(deployment.Id)] = deployment
+	}
+
+	var events []DeploymentEvent
+	for _, deployment := range current {
+		old := previousByID[aws.StringValue(deployment.Id)]
+		if old == nil || !reflect.DeepEqual(old, deployment) {
+			events = append(events, DeploymentEvent{Timestamp: timestamp, OldState: old, NewState: deployment})
+		}
+	}
+	for _, deployment := range previous {
+		if _, ok := currentByID[aws.StringValue(deployment.Id)]; !ok {
+			events = append(events, DeploymentEvent{Timestamp: timestamp, OldState: deployment})
+		}
+	}
+	return events
+}
@@ -0,0 +1,235 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/ecsfake"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWatchInterval = 10 * time.Millisecond
+
+// setupWatchedService creates a service running the first revision of the
+// "web" task definition, which has two revisions
+func setupWatchedService(t *testing.T, client ecs.ECSClientInterface) {
+	_, err := client.CreateClusterWithContext(context.Background(), &ecs.CreateClusterInput{
+		ClusterName: aws.String("cluster"),
+	})
+	require.NoError(t, err)
+	registerTestTaskDefinitions(t, client, 2, "web")
+	_, err = client.CreateServiceWithContext(context.Background(), &ecs.CreateServiceInput{
+		Cluster:        aws.String("cluster"),
+		ServiceName:    aws.String("service"),
+		TaskDefinition: aws.String("web:1"),
+		DesiredCount:   aws.Int64(1),
+	})
+	require.NoError(t, err)
+}
+
+// nextDeploymentEvent returns the next event sent on events, failing the test
+// if there is none within a second
+func nextDeploymentEvent(t *testing.T, events <-chan DeploymentEvent) DeploymentEvent {
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "the events channel should not be closed")
+		return event
+	case <-time.After(time.Second):
+		require.FailNow(t, "no deployment event")
+	}
+	return DeploymentEvent{}
+}
+
+func TestDeploymentWatcher(t *testing.T) {
+	client := ecsfake.NewServer()
+	setupWatchedService(t, client)
+
+	watcher := NewDeploymentWatcher(client, "cluster", "service", testWatchInterval)
+	events := watcher.Start(context.Background())
+	defer watcher.Stop()
+
+	// The first poll reports the existing deployment as added
+	event := nextDeploymentEvent(t, events)
+	assert.Nil(t, event.OldState)
+	require.NotNil(t, event.NewState)
+	assert.Equal(t, "PRIMARY", aws.StringValue(event.NewState.Status))
+	assert.False(t, event.Timestamp.IsZero())
+	original := aws.StringValue(event.NewState.Id)
+
+	// Nothing is emitted while the service does not change
+	select {
+	case event := <-events:
+		assert.Fail(t, "unexpected deployment event", "%v", event)
+	case <-time.After(5 * testWatchInterval):
+	}
+
+	_, err := client.UpdateServiceWithContext(context.Background(), &ecs.UpdateServiceInput{
+		Cluster:        aws.String("cluster"),
+		Service:        aws.String("service"),
+		TaskDefinition: aws.String("web:2"),
+	})
+	require.NoError(t, err)
+
+	// The new deployment is added and the original one becomes ACTIVE
+	event = nextDeploymentEvent(t, events)
+	assert.Nil(t, event.OldState)
+	require.NotNil(t, event.NewState)
+	assert.Equal(t, "PRIMARY", aws.StringValue(event.NewState.Status))
+	assert.NotEqual(t, original, aws.StringValue(event.NewState.Id))
+
+	event = nextDeploymentEvent(t, events)
+	require.NotNil(t, event.OldState)
+	require.NotNil(t, event.NewState)
+	assert.Equal(t, original, aws.StringValue(event.NewState.Id))
+	assert.Equal(t, "PRIMARY", aws.StringValue(event.OldState.Status))
+	assert.Equal(t, "ACTIVE", aws.StringValue(event.NewState.Status))
+}
+
+func TestDeploymentWatcherStop(t *testing.T) {
+	client := ecsfake.NewServer()
+	setupWatchedService(t, client)
+
+	watcher := NewDeploymentWatcher(client, "cluster", "service", testWatchInterval)
+	events := watcher.Start(context.Background())
+	nextDeploymentEvent(t, events)
+
+	watcher.Stop()
+	_, ok := <-events
+	assert.False(t, ok, "the events channel should be closed once stopped")
+	// Stopping again is a no-op
+	watcher.Stop()
+}
+
+func TestDeploymentWatcherContextDone(t *testing.T) {
+	client := ecsfake.NewServer()
+	setupWatchedService(t, client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher := NewDeploymentWatcher(client, "cluster", "service", testWatchInterval)
+	events := watcher.Start(ctx)
+	cancel()
+
+	// Unread events are dropped once the context is done
+	for range events {
+	}
+	watcher.Stop()
+}
+
+func TestDeploymentWatcherFailedPolls(t *testing.T) {
+	client := ecsfake.NewServer(ecsfake.DeterministicFailures("DescribeServices", 2))
+	setupWatchedService(t, client)
+
+	watcher := NewDeploymentWatcher(client, "cluster", "service", testWatchInterval)
+	events := watcher.Start(context.Background())
+	defer watcher.Stop()
+
+	event := nextDeploymentEvent(t, events)
+	assert.NoError(t, event.Err)
+	require.NotNil(t, event.NewState)
+	// Every other poll fails; the failures are reported, but do not report
+	// the deployment as gone nor stop polling
+	for i := 0; i < 3; i++ {
+		event = nextDeploymentEvent(t, events)
+		assert.Error(t, event.Err)
+		assert.Nil(t, event.OldState)
+		assert.Nil(t, event.NewState)
+	}
+	assert.NoError(t, watcher.Err())
+}
+
+func TestDeploymentWatcherServiceMissing(t *testing.T) {
+	client := ecsfake.NewServer()
+	setupWatchedService(t, client)
+
+	watcher := NewDeploymentWatcher(client, "cluster", "missing", testWatchInterval)
+	events := watcher.Start(context.Background())
+	defer watcher.Stop()
+
+	event := nextDeploymentEvent(t, events)
+	require.Error(t, event.Err)
+	missing, ok := event.Err.(*ServiceMissingError)
+	require.True(t, ok, "unexpected error %v", event.Err)
+	assert.Equal(t, "missing", missing.Service)
+	assert.Equal(t, "MISSING", missing.Reason)
+
+	// Polling stops, as the service will not appear by retrying
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "the events channel should be closed once the service is missing")
+	case <-time.After(time.Second):
+		require.FailNow(t, "the events channel was not closed")
+	}
+	assert.Equal(t, event.Err, watcher.Err())
+}
+
+// inactiveServiceClient describes the service as deleted, which is how
+// DescribeServices reports it for a while after DeleteService
+type inactiveServiceClient struct {
+	ecs.ECSClientInterface
+}
+
+func (c *inactiveServiceClient) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	return &ecs.DescribeServicesOutput{
+		Services: []*ecs.Service{{
+			ServiceName: input.Services[0],
+			Status:      aws.String("INACTIVE"),
+		}},
+	}, nil
+}
+
+func TestDeploymentWatcherServiceInactive(t *testing.T) {
+	watcher := NewDeploymentWatcher(&inactiveServiceClient{}, "cluster", "service", testWatchInterval)
+	events := watcher.Start(context.Background())
+	defer watcher.Stop()
+
+	event := nextDeploymentEvent(t, events)
+	missing, ok := event.Err.(*ServiceMissingError)
+	require.True(t, ok, "unexpected error %v", event.Err)
+	assert.Equal(t, "service", missing.Service)
+	assert.Equal(t, "INACTIVE", missing.Reason)
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "the events channel should be closed once the service is inactive")
+	case <-time.After(time.Second):
+		require.FailNow(t, "the events channel was not closed")
+	}
+	assert.Equal(t, event.Err, watcher.Err())
+}
+
+func TestDiffDeployments(t *testing.T) {
+	now := time.Now()
+	primary := &ecs.Deployment{Id: aws.String("primary"), Status: aws.String("PRIMARY"), RunningCount: aws.Int64(0)}
+	running := &ecs.Deployment{Id: aws.String("primary"), Status: aws.String("PRIMARY"), RunningCount: aws.Int64(1)}
+	active := &ecs.Deployment{Id: aws.String("active"), Status: aws.String("ACTIVE")}
+
+	assert.Empty(t, diffDeployments(nil, nil, now))
+	assert.Empty(t, diffDeployments([]*ecs.Deployment{primary, active}, []*ecs.Deployment{primary, active}, now))
+	assert.Equal(t, []DeploymentEvent{
+		{Timestamp: now, OldState: primary, NewState: running},
+		{Timestamp: now, OldState: active},
+	}, diffDeployments([]*ecs.Deployment{primary, active}, []*ecs.Deployment{running}, now))
+	assert.Equal(t, []DeploymentEvent{
+		{Timestamp: now, NewState: active},
+	}, diffDeployments([]*ecs.Deployment{primary}, []*ecs.Deployment{primary, active}, now))
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	settingEnabled  = "enabled"
+	settingDisabled = "disabled"
+
+	listAccountSettingsDefaultMaxResults = 10
+)
+
+// settingNames are the names of the settings the fake supports
+var settingNames = []string{
+	ecs.SettingNameServiceLongArnFormat,
+	ecs.SettingNameTaskLongArnFormat,
+	ecs.SettingNameContainerInstanceLongArnFormat,
+}
+
+// PutAccountSettingWithContext sets an account setting for a principal. The
+// root user of the fake account is used when no principal is given.
+func (s *Server) PutAccountSettingWithContext(ctx aws.Context, input *ecs.PutAccountSettingInput, opts ...request.Option) (*ecs.PutAccountSettingOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("PutAccountSetting"); err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.Name)
+	if err := validateSettingName(name); err != nil {
+		return nil, err
+	}
+	value := aws.StringValue(input.Value)
+	if value != settingEnabled && value != settingDisabled {
+		return nil, invalidParameterException("Invalid value for setting %s: %s", name, value)
+	}
+	principal := s.principalArn(input.PrincipalArn)
+	if _, ok := s.accountSettings[principal]; !ok {
+		s.accountSettings[principal] = make(map[string]*ecs.Setting)
+	}
+	setting := &ecs.Setting{
+		Name:         aws.String(name),
+		Value:        aws.String(value),
+		PrincipalArn: aws.String(principal),
+	}
+	s.accountSettings[principal][name] = setting
+	return &ecs.PutAccountSettingOutput{
+		Setting: awsutil.CopyOf(setting).(*ecs.Setting),
+	}, nil
+}
+
+// DeleteAccountSettingWithContext removes an account setting of a principal
+func (s *Server) DeleteAccountSettingWithContext(ctx aws.Context, input *ecs.DeleteAccountSettingInput, opts ...request.Option) (*ecs.DeleteAccountSettingOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DeleteAccountSetting"); err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.Name)
+	if err := validateSettingName(name); err != nil {
+		return nil, err
+	}
+	principal := s.principalArn(input.PrincipalArn)
+	setting, ok := s.accountSettings[principal][name]
+	if !ok {
+		setting = &ecs.Setting{
+			Name:         aws.String(name),
+			PrincipalArn: aws.String(principal),
+		}
+	}
+	delete(s.accountSettings[principal], name)
+	return &ecs.DeleteAccountSettingOutput{
+		Setting: awsutil.CopyOf(setting).(*ecs.Setting),
+	}, nil
+}
+
+// ListAccountSettingsWithContext lists the account settings of a principal.
+// With EffectiveSettings, every setting is listed with the value that applies
+// to the principal: its own, the account default set on the root user, or
+// enabled.
+func (s *Server) ListAccountSettingsWithContext(ctx aws.Context, input *ecs.ListAccountSettingsInput, opts ...request.Option) (*ecs.ListAccountSettingsOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListAccountSettings"); err != nil {
+		return nil, err
+	}
+
+	names := settingNames
+	if input.Name != nil {
+		name := aws.StringValue(input.Name)
+		if err := validateSettingName(name); err != nil {
+			return nil, err
+		}
+		names = []string{name}
+	}
+	principal := s.principalArn(input.PrincipalArn)
+	root := s.principalArn(nil)
+
+	var settings []*ecs.Setting
+	for _, name := range names {
+		setting, ok := s.accountSettings[principal][name]
+		if !ok && aws.BoolValue(input.EffectiveSettings) {
+			setting, ok = s.accountSettings[root][name]
+		}
+		if !ok && aws.BoolValue(input.EffectiveSettings) {
+			setting = &ecs.Setting{
+				Name:         aws.String(name),
+				Value:        aws.String(settingEnabled),
+				PrincipalArn: aws.String(root),
+			}
+			ok = true
+		}
+		if !ok {
+			continue
+		}
+		if input.Value != nil && aws.StringValue(setting.Value) != aws.StringValue(input.Value) {
+			continue
+		}
+		settings = append(settings, awsutil.CopyOf(setting).(*ecs.Setting))
+	}
+	start, end, nextToken, err := page(len(settings), input.MaxResults, input.NextToken, listAccountSettingsDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListAccountSettingsOutput{
+		Settings:  settings[start:end],
+		NextToken: nextToken,
+	}, nil
+}
+
+// principalArn returns the given principal, or the root user of the fake
+// account
+func (s *Server) principalArn(principal *string) string {
+	if principal != nil {
+		return aws.StringValue(principal)
+	}
+	return fmt.Sprintf("arn:aws:iam::%s:root", s.accountID)
+}
+
+func validateSettingName(name string) error {
+	for _, supported := range settingNames {
+		if name == supported {
+			return nil
+		}
+	}
+	return invalidParameterException("Invalid setting name: %s", name)
+}
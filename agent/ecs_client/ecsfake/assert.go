@@ -0,0 +1,62 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// AssertNoOrphanedAttributes fails the test if fake still holds attributes
+// of the container instance targetArn, in any of its clusters. The
+// attributes are looked up with ListAttributes. Calling it at the end of a
+// test that deletes attributes catches the ones left behind, which would
+// otherwise leak into the assertions of later tests sharing the fake.
+func AssertNoOrphanedAttributes(t *testing.T, fake *Server, targetArn string) {
+	ctx := aws.BackgroundContext()
+	fake.lock.Lock()
+	var clusters []string
+	for name := range fake.clusters {
+		clusters = append(clusters, name)
+	}
+	fake.lock.Unlock()
+	sort.Strings(clusters)
+
+	for _, cluster := range clusters {
+		input := &ecs.ListAttributesInput{
+			Cluster:    aws.String(cluster),
+			TargetType: aws.String(ecs.TargetTypeContainerInstance),
+		}
+		for {
+			output, err := fake.ListAttributesWithContext(ctx, input)
+			if err != nil {
+				t.Errorf("unable to list the attributes of cluster %s: %v", cluster, err)
+				break
+			}
+			for _, attribute := range output.Attributes {
+				if aws.StringValue(attribute.TargetId) == targetArn {
+					t.Errorf("attribute %s of %s was not deleted from cluster %s",
+						aws.StringValue(attribute.Name), targetArn, cluster)
+				}
+			}
+			if output.NextToken == nil {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+	}
+}
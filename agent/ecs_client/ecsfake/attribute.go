@@ -0,0 +1,222 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	// maxAttributesPerCall is the number of attributes that can be put in a
+	// single PutAttributes call
+	maxAttributesPerCall = 10
+	// maxCustomAttributesPerResource is the number of custom attributes a
+	// single resource can carry. Attributes in the ecs. namespace are not
+	// counted.
+	maxCustomAttributesPerResource = 10
+	ecsAttributePrefix             = "ecs."
+
+	listAttributesDefaultMaxResults = 100
+)
+
+// PutAttributesWithContext creates or updates attributes on container
+// instances
+func (s *Server) PutAttributesWithContext(ctx aws.Context, input *ecs.PutAttributesInput, opts ...request.Option) (*ecs.PutAttributesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("PutAttributes"); err != nil {
+		return nil, err
+	}
+
+	if len(input.Attributes) > maxAttributesPerCall {
+		return nil, invalidParameterException("Attributes cannot contain more than %d elements.", maxAttributesPerCall)
+	}
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+
+	targets := make(map[string][]*ecs.Attribute)
+	var order []string
+	for _, attribute := range input.Attributes {
+		instance, ok := s.containerInstances[clusterName][resourceName(aws.StringValue(attribute.TargetId))]
+		if !ok {
+			return nil, awserr.New(ecs.ErrCodeTargetNotFoundException,
+				"Could not find target "+aws.StringValue(attribute.TargetId), nil)
+		}
+		arn := aws.StringValue(instance.ContainerInstanceArn)
+		if _, ok := targets[arn]; !ok {
+			order = append(order, arn)
+		}
+		targets[arn] = append(targets[arn], attribute)
+	}
+	for _, arn := range order {
+		if s.countCustomAttributes(clusterName, arn, targets[arn]) > maxCustomAttributesPerResource {
+			return nil, awserr.New(ecs.ErrCodeAttributeLimitExceededException,
+				"Attribute limit exceeded for "+arn, nil)
+		}
+	}
+
+	output := &ecs.PutAttributesOutput{}
+	for _, arn := range order {
+		output.Attributes = append(output.Attributes, s.putAttributes(clusterName, arn, targets[arn])...)
+	}
+	return output, nil
+}
+
+// DeleteAttributesWithContext deletes attributes from container instances
+func (s *Server) DeleteAttributesWithContext(ctx aws.Context, input *ecs.DeleteAttributesInput, opts ...request.Option) (*ecs.DeleteAttributesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DeleteAttributes"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+
+	output := &ecs.DeleteAttributesOutput{}
+	for _, attribute := range input.Attributes {
+		instance, ok := s.containerInstances[clusterName][resourceName(aws.StringValue(attribute.TargetId))]
+		if !ok {
+			return nil, awserr.New(ecs.ErrCodeTargetNotFoundException,
+				"Could not find target "+aws.StringValue(attribute.TargetId), nil)
+		}
+		arn := aws.StringValue(instance.ContainerInstanceArn)
+		remaining := s.attributes[clusterName][:0]
+		for _, existing := range s.attributes[clusterName] {
+			if aws.StringValue(existing.TargetId) == arn && aws.StringValue(existing.Name) == aws.StringValue(attribute.Name) {
+				output.Attributes = append(output.Attributes, awsutil.CopyOf(existing).(*ecs.Attribute))
+				continue
+			}
+			remaining = append(remaining, existing)
+		}
+		s.attributes[clusterName] = remaining
+	}
+	return output, nil
+}
+
+// ListAttributesWithContext lists the attributes of the given target type,
+// optionally filtered by name and value
+func (s *Server) ListAttributesWithContext(ctx aws.Context, input *ecs.ListAttributesInput, opts ...request.Option) (*ecs.ListAttributesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListAttributes"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if input.AttributeValue != nil && input.AttributeName == nil {
+		return nil, invalidParameterException("AttributeName must be specified when AttributeValue is specified.")
+	}
+
+	var attributes []*ecs.Attribute
+	for _, attribute := range s.attributes[aws.StringValue(cluster.ClusterName)] {
+		if aws.StringValue(attribute.TargetType) != aws.StringValue(input.TargetType) {
+			continue
+		}
+		if input.AttributeName != nil && aws.StringValue(attribute.Name) != aws.StringValue(input.AttributeName) {
+			continue
+		}
+		if input.AttributeValue != nil && aws.StringValue(attribute.Value) != aws.StringValue(input.AttributeValue) {
+			continue
+		}
+		attributes = append(attributes, awsutil.CopyOf(attribute).(*ecs.Attribute))
+	}
+	start, end, nextToken, err := page(len(attributes), input.MaxResults, input.NextToken, listAttributesDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListAttributesOutput{
+		Attributes: attributes[start:end],
+		NextToken:  nextToken,
+	}, nil
+}
+
+// putAttributes creates or updates the attributes of a container instance and
+// returns the stored attributes. The lock must be held by the caller.
+func (s *Server) putAttributes(clusterName, targetArn string, attributes []*ecs.Attribute) []*ecs.Attribute {
+	var stored []*ecs.Attribute
+	for _, attribute := range attributes {
+		updated := &ecs.Attribute{
+			Name:       attribute.Name,
+			Value:      attribute.Value,
+			TargetId:   aws.String(targetArn),
+			TargetType: aws.String(ecs.TargetTypeContainerInstance),
+		}
+		replaced := false
+		for i, existing := range s.attributes[clusterName] {
+			if aws.StringValue(existing.TargetId) == targetArn && aws.StringValue(existing.Name) == aws.StringValue(attribute.Name) {
+				s.attributes[clusterName][i] = updated
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.attributes[clusterName] = append(s.attributes[clusterName], updated)
+		}
+		stored = append(stored, awsutil.CopyOf(updated).(*ecs.Attribute))
+	}
+	return stored
+}
+
+// countCustomAttributes returns the number of custom attributes the target
+// would have once the given attributes are put. The lock must be held by the
+// caller.
+func (s *Server) countCustomAttributes(clusterName, targetArn string, attributes []*ecs.Attribute) int {
+	names := make(map[string]struct{})
+	for _, existing := range s.attributes[clusterName] {
+		if aws.StringValue(existing.TargetId) == targetArn {
+			names[aws.StringValue(existing.Name)] = struct{}{}
+		}
+	}
+	for _, attribute := range attributes {
+		names[aws.StringValue(attribute.Name)] = struct{}{}
+	}
+	count := 0
+	for name := range names {
+		if !strings.HasPrefix(name, ecsAttributePrefix) {
+			count++
+		}
+	}
+	return count
+}
+
+// deleteAttributesOfTarget removes all the attributes of a container
+// instance. The lock must be held by the caller.
+func (s *Server) deleteAttributesOfTarget(clusterName, targetArn string) {
+	remaining := s.attributes[clusterName][:0]
+	for _, existing := range s.attributes[clusterName] {
+		if aws.StringValue(existing.TargetId) != targetArn {
+			remaining = append(remaining, existing)
+		}
+	}
+	s.attributes[clusterName] = remaining
+}
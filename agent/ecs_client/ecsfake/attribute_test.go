@@ -0,0 +1,64 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAttributesLeavesNoOrphans(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+
+	instances, err := client.ListContainerInstances(&ecs.ListContainerInstancesInput{
+		Cluster: aws.String(testCluster),
+	})
+	require.NoError(t, err)
+	require.Len(t, instances.ContainerInstanceArns, 1)
+	targetArn := aws.StringValue(instances.ContainerInstanceArns[0])
+
+	attributes := []*ecs.Attribute{
+		{Name: aws.String("stack"), Value: aws.String("prod"), TargetId: aws.String(targetArn)},
+		{Name: aws.String("gpu"), TargetId: aws.String(targetArn)},
+	}
+	_, err = client.PutAttributes(&ecs.PutAttributesInput{
+		Cluster:    aws.String(testCluster),
+		Attributes: attributes,
+	})
+	require.NoError(t, err)
+
+	listed, err := client.ListAttributes(&ecs.ListAttributesInput{
+		Cluster:    aws.String(testCluster),
+		TargetType: aws.String(ecs.TargetTypeContainerInstance),
+	})
+	require.NoError(t, err)
+	require.Len(t, listed.Attributes, 2)
+
+	for _, attribute := range attributes {
+		_, err = client.DeleteAttributes(&ecs.DeleteAttributesInput{
+			Cluster:    aws.String(testCluster),
+			Attributes: []*ecs.Attribute{attribute},
+		})
+		require.NoError(t, err)
+	}
+	AssertNoOrphanedAttributes(t, server, targetArn)
+}
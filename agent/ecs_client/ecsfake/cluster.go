@@ -0,0 +1,210 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"sort"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	statusActive   = "ACTIVE"
+	statusInactive = "INACTIVE"
+
+	listClustersDefaultMaxResults = 100
+)
+
+// CreateClusterWithContext creates a cluster, or returns the existing one if a
+// cluster with the same name was already created
+func (s *Server) CreateClusterWithContext(ctx aws.Context, input *ecs.CreateClusterInput, opts ...request.Option) (*ecs.CreateClusterOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("CreateCluster"); err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.ClusterName)
+	if name == "" {
+		name = defaultClusterName
+	}
+	if _, ok := s.clusters[name]; !ok {
+		cluster := &ecs.Cluster{
+			ClusterArn:  aws.String(s.arn("cluster/" + name)),
+			ClusterName: aws.String(name),
+			Status:      aws.String(statusActive),
+		}
+		s.clusters[name] = cluster
+		if len(input.Tags) > 0 {
+			s.tags[aws.StringValue(cluster.ClusterArn)] = input.Tags
+		}
+	}
+	return &ecs.CreateClusterOutput{
+		Cluster: s.describeCluster(name, true),
+	}, nil
+}
+
+// DescribeClustersWithContext describes the requested clusters, or the default
+// cluster if none are requested. Tags are only included when requested.
+func (s *Server) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput, opts ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DescribeClusters"); err != nil {
+		return nil, err
+	}
+
+	identifiers := aws.StringValueSlice(input.Clusters)
+	if len(identifiers) == 0 {
+		identifiers = []string{defaultClusterName}
+	}
+	includeTags := false
+	for _, include := range aws.StringValueSlice(input.Include) {
+		if include == ecs.ClusterFieldTags {
+			includeTags = true
+		}
+	}
+
+	output := &ecs.DescribeClustersOutput{}
+	for _, identifier := range identifiers {
+		name := resourceName(identifier)
+		if _, ok := s.clusters[name]; !ok {
+			output.Failures = append(output.Failures, missingFailure(identifier))
+			continue
+		}
+		output.Clusters = append(output.Clusters, s.describeCluster(name, includeTags))
+	}
+	return output, nil
+}
+
+// ListClustersWithContext lists the ARNs of all clusters, sorted by ARN
+func (s *Server) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListClusters"); err != nil {
+		return nil, err
+	}
+
+	var arns []string
+	for _, cluster := range s.clusters {
+		arns = append(arns, aws.StringValue(cluster.ClusterArn))
+	}
+	sort.Strings(arns)
+	clusterArns, nextToken, err := pageStrings(arns, input.MaxResults, input.NextToken, listClustersDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListClustersOutput{
+		ClusterArns: clusterArns,
+		NextToken:   nextToken,
+	}, nil
+}
+
+// DeleteClusterWithContext deletes a cluster that has no container instances,
+// services or tasks
+func (s *Server) DeleteClusterWithContext(ctx aws.Context, input *ecs.DeleteClusterInput, opts ...request.Option) (*ecs.DeleteClusterOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DeleteCluster"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	name := aws.StringValue(cluster.ClusterName)
+	described := s.describeCluster(name, false)
+	switch {
+	case aws.Int64Value(described.RegisteredContainerInstancesCount) > 0:
+		return nil, awserr.New(ecs.ErrCodeClusterContainsContainerInstancesException,
+			"The Cluster cannot be deleted while Container Instances are active or draining.", nil)
+	case aws.Int64Value(described.ActiveServicesCount) > 0:
+		return nil, awserr.New(ecs.ErrCodeClusterContainsServicesException,
+			"The Cluster cannot be deleted while Services are active.", nil)
+	case aws.Int64Value(described.RunningTasksCount)+aws.Int64Value(described.PendingTasksCount) > 0:
+		return nil, awserr.New(ecs.ErrCodeClusterContainsTasksException,
+			"The Cluster cannot be deleted while Tasks are active.", nil)
+	}
+
+	delete(s.clusters, name)
+	delete(s.containerInstances, name)
+	delete(s.services, name)
+	delete(s.tasks, name)
+	delete(s.attributes, name)
+	delete(s.tags, aws.StringValue(cluster.ClusterArn))
+	described.Status = aws.String(statusInactive)
+	return &ecs.DeleteClusterOutput{Cluster: described}, nil
+}
+
+// describeCluster returns a copy of the named cluster with up to date
+// counts. The lock must be held by the caller.
+func (s *Server) describeCluster(name string, includeTags bool) *ecs.Cluster {
+	cluster := awsutil.CopyOf(s.clusters[name]).(*ecs.Cluster)
+	var instances, services, running, pending int64
+	for _, instance := range s.containerInstances[name] {
+		if aws.StringValue(instance.Status) != statusInactive {
+			instances++
+		}
+	}
+	for _, service := range s.services[name] {
+		if aws.StringValue(service.Status) == statusActive {
+			services++
+		}
+	}
+	for _, task := range s.tasks[name] {
+		switch aws.StringValue(task.LastStatus) {
+		case taskStatusRunning:
+			running++
+		case taskStatusPending:
+			pending++
+		}
+	}
+	cluster.RegisteredContainerInstancesCount = aws.Int64(instances)
+	cluster.ActiveServicesCount = aws.Int64(services)
+	cluster.RunningTasksCount = aws.Int64(running)
+	cluster.PendingTasksCount = aws.Int64(pending)
+	if includeTags {
+		cluster.Tags = s.copyTags(aws.StringValue(cluster.ClusterArn))
+	}
+	return cluster
+}
+
+// getCluster looks up a cluster by name or ARN, defaulting to the default
+// cluster. The lock must be held by the caller.
+func (s *Server) getCluster(identifier *string) (*ecs.Cluster, error) {
+	name := resourceName(aws.StringValue(identifier))
+	if name == "" {
+		name = defaultClusterName
+	}
+	cluster, ok := s.clusters[name]
+	if !ok {
+		return nil, awserr.New(ecs.ErrCodeClusterNotFoundException, "Cluster not found.", nil)
+	}
+	return cluster, nil
+}
+
+func missingFailure(arn string) *ecs.Failure {
+	return &ecs.Failure{
+		Arn:    aws.String(arn),
+		Reason: aws.String("MISSING"),
+	}
+}
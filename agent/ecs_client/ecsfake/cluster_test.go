@@ -0,0 +1,165 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTags(n int) []*ecs.Tag {
+	tags := make([]*ecs.Tag, n)
+	for i := range tags {
+		tags[i] = &ecs.Tag{
+			Key:   aws.String(fmt.Sprintf("key%d", i)),
+			Value: aws.String(fmt.Sprintf("value%d", i)),
+		}
+	}
+	return tags
+}
+
+func TestCreateClusterWithTags(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	output, err := client.CreateCluster(&ecs.CreateClusterInput{
+		ClusterName: aws.String("tagged"),
+		Tags:        testTags(2),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:cluster/tagged", aws.StringValue(output.Cluster.ClusterArn))
+	assert.Equal(t, testTags(2), output.Cluster.Tags)
+}
+
+func TestCreateClusterTagLimit(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{
+		ClusterName: aws.String("tagged"),
+		Tags:        testTags(50),
+	})
+	assert.NoError(t, err)
+
+	_, err = client.CreateCluster(&ecs.CreateClusterInput{
+		ClusterName: aws.String("overtagged"),
+		Tags:        testTags(51),
+	})
+	require.Error(t, err)
+	assert.Equal(t, request.InvalidParameterErrCode, err.(awserr.Error).Code())
+
+	invalidParams, ok := err.(request.ErrInvalidParams)
+	require.True(t, ok)
+	require.Equal(t, 1, invalidParams.Len())
+	assert.Equal(t, ecs.ParamMaxLenErrCode, invalidParams.OrigErrs()[0].(awserr.Error).Code())
+}
+
+func TestDescribeClustersIncludeTags(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{
+		ClusterName: aws.String("tagged"),
+		Tags:        testTags(1),
+	})
+	require.NoError(t, err)
+
+	output, err := client.DescribeClusters(&ecs.DescribeClustersInput{
+		Clusters: aws.StringSlice([]string{"tagged"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Clusters, 1)
+	assert.Nil(t, output.Clusters[0].Tags, "tags should only be returned when requested")
+
+	output, err = client.DescribeClusters(&ecs.DescribeClustersInput{
+		Clusters: aws.StringSlice([]string{"arn:aws:ecs:us-west-2:123456789012:cluster/tagged"}),
+		Include:  aws.StringSlice([]string{ecs.ClusterFieldTags}),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Clusters, 1)
+	assert.Equal(t, testTags(1), output.Clusters[0].Tags)
+}
+
+func TestDescribeClustersMissing(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	output, err := server.Client().DescribeClusters(&ecs.DescribeClustersInput{
+		Clusters: aws.StringSlice([]string{"missing"}),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, output.Clusters)
+	require.Len(t, output.Failures, 1)
+	assert.Equal(t, "MISSING", aws.StringValue(output.Failures[0].Reason))
+}
+
+func TestDeleteClusterNotFound(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	_, err := server.Client().DeleteCluster(&ecs.DeleteClusterInput{
+		Cluster: aws.String("missing"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeClusterNotFoundException, err.(awserr.Error).Code())
+}
+
+func TestListClustersNamePrefix(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	for _, name := range []string{"prod", "prod-batch", "staging-prod"} {
+		_, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(name)})
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name       string
+		namePrefix *string
+		expected   []string
+	}{
+		{"unset", nil, []string{"prod", "prod-batch", "staging-prod"}},
+		{"exact match", aws.String("prod-batch"), []string{"prod-batch"}},
+		{"partial prefix", aws.String("pro"), []string{"prod", "prod-batch"}},
+		{"no match", aws.String("dev"), nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := client.ListClusters(&ecs.ListClustersInput{})
+			require.NoError(t, err)
+			arns := aws.StringValueSlice(output.ClusterArns)
+			if tc.namePrefix != nil {
+				arns = ecs.FilterClustersByPrefix(arns, aws.StringValue(tc.namePrefix))
+			}
+			var names []string
+			for _, arn := range arns {
+				names = append(names, resourceName(arn))
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
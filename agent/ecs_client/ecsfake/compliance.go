@@ -0,0 +1,397 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+const complianceName = "compliance"
+
+// expectedErrorCodes are the error codes an implementation may return from a
+// call made by TestInterfaceCompliance
+var expectedErrorCodes = map[string]struct{}{
+	ecs.ErrCodeAccessDeniedException:                          {},
+	ecs.ErrCodeAttributeLimitExceededException:                {},
+	ecs.ErrCodeBlockedException:                               {},
+	ecs.ErrCodeClientException:                                {},
+	ecs.ErrCodeClusterContainsContainerInstancesException:     {},
+	ecs.ErrCodeClusterContainsServicesException:               {},
+	ecs.ErrCodeClusterContainsTasksException:                  {},
+	ecs.ErrCodeClusterNotFoundException:                       {},
+	ecs.ErrCodeInvalidParameterException:                      {},
+	ecs.ErrCodeMissingVersionException:                        {},
+	ecs.ErrCodeNoUpdateAvailableException:                     {},
+	ecs.ErrCodePlatformTaskDefinitionIncompatibilityException: {},
+	ecs.ErrCodePlatformUnknownException:                       {},
+	ecs.ErrCodeServerException:                                {},
+	ecs.ErrCodeServiceNotActiveException:                      {},
+	ecs.ErrCodeServiceNotFoundException:                       {},
+	ecs.ErrCodeTargetNotFoundException:                        {},
+	ecs.ErrCodeUnsupportedFeatureException:                    {},
+	ecs.ErrCodeUpdateInProgressException:                      {},
+}
+
+// complianceStep calls a single method of ecs.ECSClientInterface
+type complianceStep struct {
+	method string
+	call   func(ecs.ECSClientInterface) error
+}
+
+// TestInterfaceCompliance calls every method of ecs.ECSClientInterface with
+// valid but trivial inputs and checks that each call either succeeds or
+// fails with one of the typed ECS errors. The calls build on each other: a
+// cluster is created first, then a container instance, a task definition,
+// tasks and a service, and everything is torn down again at the end. The
+// test fails if a method of the interface is not exercised, so that new
+// methods are not left out when the interface grows.
+func TestInterfaceCompliance(t *testing.T, impl ecs.ECSClientInterface) {
+	ctx := aws.BackgroundContext()
+	var (
+		clusterArn           *string
+		containerInstanceArn *string
+		taskDefinitionArn    *string
+		taskArns             []*string
+	)
+
+	steps := []complianceStep{
+		{"CreateClusterWithContext", func(impl ecs.ECSClientInterface) error {
+			output, err := impl.CreateClusterWithContext(ctx, &ecs.CreateClusterInput{
+				ClusterName: aws.String(complianceName),
+			})
+			if err == nil {
+				clusterArn = output.Cluster.ClusterArn
+			}
+			return err
+		}},
+		{"DescribeClustersWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DescribeClustersWithContext(ctx, &ecs.DescribeClustersInput{
+				Clusters: aws.StringSlice([]string{complianceName}),
+			})
+			return err
+		}},
+		{"ListClustersWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+			return err
+		}},
+		{"RegisterContainerInstanceWithContext", func(impl ecs.ECSClientInterface) error {
+			output, err := impl.RegisterContainerInstanceWithContext(ctx, &ecs.RegisterContainerInstanceInput{
+				Cluster: aws.String(complianceName),
+				TotalResources: []*ecs.Resource{
+					{Name: aws.String("CPU"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(1024)},
+					{Name: aws.String("MEMORY"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(1024)},
+				},
+			})
+			if err == nil {
+				containerInstanceArn = output.ContainerInstance.ContainerInstanceArn
+			}
+			return err
+		}},
+		{"DescribeContainerInstancesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DescribeContainerInstancesWithContext(ctx, &ecs.DescribeContainerInstancesInput{
+				Cluster:            aws.String(complianceName),
+				ContainerInstances: []*string{containerInstanceArn},
+			})
+			return err
+		}},
+		{"ListContainerInstancesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListContainerInstancesWithContext(ctx, &ecs.ListContainerInstancesInput{
+				Cluster: aws.String(complianceName),
+			})
+			return err
+		}},
+		{"UpdateContainerInstancesStateWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.UpdateContainerInstancesStateWithContext(ctx, &ecs.UpdateContainerInstancesStateInput{
+				Cluster:            aws.String(complianceName),
+				ContainerInstances: []*string{containerInstanceArn},
+				Status:             aws.String(ecs.ContainerInstanceStatusActive),
+			})
+			return err
+		}},
+		{"UpdateContainerAgentWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.UpdateContainerAgentWithContext(ctx, &ecs.UpdateContainerAgentInput{
+				Cluster:           aws.String(complianceName),
+				ContainerInstance: containerInstanceArn,
+			})
+			return err
+		}},
+		{"DiscoverPollEndpointWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DiscoverPollEndpointWithContext(ctx, &ecs.DiscoverPollEndpointInput{
+				Cluster:           aws.String(complianceName),
+				ContainerInstance: containerInstanceArn,
+			})
+			return err
+		}},
+		{"PutAttributesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.PutAttributesWithContext(ctx, &ecs.PutAttributesInput{
+				Cluster:    aws.String(complianceName),
+				Attributes: []*ecs.Attribute{complianceAttribute(containerInstanceArn)},
+			})
+			return err
+		}},
+		{"ListAttributesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListAttributesWithContext(ctx, &ecs.ListAttributesInput{
+				Cluster:    aws.String(complianceName),
+				TargetType: aws.String(ecs.TargetTypeContainerInstance),
+			})
+			return err
+		}},
+		{"DeleteAttributesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DeleteAttributesWithContext(ctx, &ecs.DeleteAttributesInput{
+				Cluster:    aws.String(complianceName),
+				Attributes: []*ecs.Attribute{complianceAttribute(containerInstanceArn)},
+			})
+			return err
+		}},
+		{"RegisterTaskDefinitionWithContext", func(impl ecs.ECSClientInterface) error {
+			output, err := impl.RegisterTaskDefinitionWithContext(ctx, &ecs.RegisterTaskDefinitionInput{
+				Family: aws.String(complianceName),
+				ContainerDefinitions: []*ecs.ContainerDefinition{{
+					Name:   aws.String(complianceName),
+					Image:  aws.String("busybox"),
+					Memory: aws.Int64(128),
+				}},
+			})
+			if err == nil {
+				taskDefinitionArn = output.TaskDefinition.TaskDefinitionArn
+			}
+			return err
+		}},
+		{"DescribeTaskDefinitionWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DescribeTaskDefinitionWithContext(ctx, &ecs.DescribeTaskDefinitionInput{
+				TaskDefinition: aws.String(complianceName),
+			})
+			return err
+		}},
+		{"ListTaskDefinitionsWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListTaskDefinitionsWithContext(ctx, &ecs.ListTaskDefinitionsInput{
+				FamilyPrefix: aws.String(complianceName),
+			})
+			return err
+		}},
+		{"ListTaskDefinitionFamiliesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListTaskDefinitionFamiliesWithContext(ctx, &ecs.ListTaskDefinitionFamiliesInput{})
+			return err
+		}},
+		{"RunTaskWithContext", func(impl ecs.ECSClientInterface) error {
+			output, err := impl.RunTaskWithContext(ctx, &ecs.RunTaskInput{
+				Cluster:        aws.String(complianceName),
+				TaskDefinition: taskDefinitionArn,
+			})
+			if err == nil {
+				for _, task := range output.Tasks {
+					taskArns = append(taskArns, task.TaskArn)
+				}
+			}
+			return err
+		}},
+		{"RunTaskBatch", func(impl ecs.ECSClientInterface) error {
+			tasks, _, err := impl.RunTaskBatch(ctx, &ecs.RunTaskInput{
+				Cluster:        aws.String(complianceName),
+				TaskDefinition: taskDefinitionArn,
+			}, 1)
+			for _, task := range tasks {
+				taskArns = append(taskArns, task.TaskArn)
+			}
+			if batchErr, ok := err.(*ecs.RunTaskBatchError); ok {
+				return batchErr.Errors[0]
+			}
+			return err
+		}},
+		{"StartTaskWithContext", func(impl ecs.ECSClientInterface) error {
+			output, err := impl.StartTaskWithContext(ctx, &ecs.StartTaskInput{
+				Cluster:            aws.String(complianceName),
+				TaskDefinition:     taskDefinitionArn,
+				ContainerInstances: []*string{containerInstanceArn},
+			})
+			if err == nil {
+				for _, task := range output.Tasks {
+					taskArns = append(taskArns, task.TaskArn)
+				}
+			}
+			return err
+		}},
+		{"DescribeTasksWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DescribeTasksWithContext(ctx, &ecs.DescribeTasksInput{
+				Cluster: aws.String(complianceName),
+				Tasks:   taskArns,
+			})
+			return err
+		}},
+		{"ListTasksWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListTasksWithContext(ctx, &ecs.ListTasksInput{
+				Cluster: aws.String(complianceName),
+			})
+			return err
+		}},
+		{"SubmitTaskStateChangeWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.SubmitTaskStateChangeWithContext(ctx, &ecs.SubmitTaskStateChangeInput{
+				Cluster: aws.String(complianceName),
+				Task:    lastTask(taskArns),
+				Status:  aws.String(ecs.DesiredStatusRunning),
+			})
+			return err
+		}},
+		{"SubmitContainerStateChangeWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.SubmitContainerStateChangeWithContext(ctx, &ecs.SubmitContainerStateChangeInput{
+				Cluster:       aws.String(complianceName),
+				Task:          lastTask(taskArns),
+				ContainerName: aws.String(complianceName),
+				Status:        aws.String(ecs.DesiredStatusRunning),
+			})
+			return err
+		}},
+		{"StopTaskWithContext", func(impl ecs.ECSClientInterface) error {
+			for _, taskArn := range taskArns {
+				_, err := impl.StopTaskWithContext(ctx, &ecs.StopTaskInput{
+					Cluster: aws.String(complianceName),
+					Task:    taskArn,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{"CreateServiceWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.CreateServiceWithContext(ctx, &ecs.CreateServiceInput{
+				Cluster:        aws.String(complianceName),
+				ServiceName:    aws.String(complianceName),
+				TaskDefinition: taskDefinitionArn,
+				DesiredCount:   aws.Int64(0),
+			})
+			return err
+		}},
+		{"DescribeServicesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+				Cluster:  aws.String(complianceName),
+				Services: aws.StringSlice([]string{complianceName}),
+			})
+			return err
+		}},
+		{"ListServicesWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListServicesWithContext(ctx, &ecs.ListServicesInput{
+				Cluster: aws.String(complianceName),
+			})
+			return err
+		}},
+		{"UpdateServiceWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.UpdateServiceWithContext(ctx, &ecs.UpdateServiceInput{
+				Cluster:      aws.String(complianceName),
+				Service:      aws.String(complianceName),
+				DesiredCount: aws.Int64(0),
+			})
+			return err
+		}},
+		{"DeleteServiceWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DeleteServiceWithContext(ctx, &ecs.DeleteServiceInput{
+				Cluster: aws.String(complianceName),
+				Service: aws.String(complianceName),
+			})
+			return err
+		}},
+		{"ListTagsForResourceWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListTagsForResourceWithContext(ctx, &ecs.ListTagsForResourceInput{
+				ResourceArn: clusterArn,
+			})
+			return err
+		}},
+		{"PutAccountSettingWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.PutAccountSettingWithContext(ctx, &ecs.PutAccountSettingInput{
+				Name:  aws.String(ecs.SettingNameTaskLongArnFormat),
+				Value: aws.String(settingEnabled),
+			})
+			return err
+		}},
+		{"ListAccountSettingsWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.ListAccountSettingsWithContext(ctx, &ecs.ListAccountSettingsInput{
+				EffectiveSettings: aws.Bool(true),
+			})
+			return err
+		}},
+		{"DeleteAccountSettingWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DeleteAccountSettingWithContext(ctx, &ecs.DeleteAccountSettingInput{
+				Name: aws.String(ecs.SettingNameTaskLongArnFormat),
+			})
+			return err
+		}},
+		{"DeregisterTaskDefinitionWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DeregisterTaskDefinitionWithContext(ctx, &ecs.DeregisterTaskDefinitionInput{
+				TaskDefinition: taskDefinitionArn,
+			})
+			return err
+		}},
+		{"DeregisterContainerInstanceWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DeregisterContainerInstanceWithContext(ctx, &ecs.DeregisterContainerInstanceInput{
+				Cluster:           aws.String(complianceName),
+				ContainerInstance: containerInstanceArn,
+				Force:             aws.Bool(true),
+			})
+			return err
+		}},
+		{"DeleteClusterWithContext", func(impl ecs.ECSClientInterface) error {
+			_, err := impl.DeleteClusterWithContext(ctx, &ecs.DeleteClusterInput{
+				Cluster: aws.String(complianceName),
+			})
+			return err
+		}},
+	}
+
+	exercised := make(map[string]bool)
+	for _, step := range steps {
+		exercised[step.method] = true
+		if err := step.call(impl); err != nil && !isExpectedError(err) {
+			t.Errorf("%s returned an unexpected error: %v", step.method, err)
+		}
+	}
+
+	iface := reflect.TypeOf((*ecs.ECSClientInterface)(nil)).Elem()
+	for i := 0; i < iface.NumMethod(); i++ {
+		if name := iface.Method(i).Name; !exercised[name] {
+			t.Errorf("%s is not exercised by TestInterfaceCompliance", name)
+		}
+	}
+}
+
+func lastTask(taskArns []*string) *string {
+	if len(taskArns) == 0 {
+		return nil
+	}
+	return taskArns[len(taskArns)-1]
+}
+
+func complianceAttribute(targetID *string) *ecs.Attribute {
+	return &ecs.Attribute{
+		Name:       aws.String(complianceName),
+		Value:      aws.String(complianceName),
+		TargetId:   targetID,
+		TargetType: aws.String(ecs.TargetTypeContainerInstance),
+	}
+}
+
+// isExpectedError reports whether err is one of the typed errors of the ECS
+// API
+func isExpectedError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	_, ok = expectedErrorCodes[awsErr.Code()]
+	return ok
+}
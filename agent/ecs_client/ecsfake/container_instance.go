@@ -0,0 +1,363 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	listContainerInstancesDefaultMaxResults = 100
+	// maxDescribeContainerInstances is the number of container instances
+	// that can be described by a single DescribeContainerInstances call
+	maxDescribeContainerInstances = 100
+
+	// PollEndpoint is the endpoint returned by DiscoverPollEndpoint
+	PollEndpoint = "https://ecs-a-1.us-west-2.amazonaws.com/"
+	// TelemetryEndpoint is the telemetry endpoint returned by
+	// DiscoverPollEndpoint
+	TelemetryEndpoint = "https://ecs-t-1.us-west-2.amazonaws.com/"
+)
+
+// RegisterContainerInstanceWithContext registers a new container instance, or
+// updates the resources of an existing one when its ARN is supplied. The EC2
+// instance id is read from the instance identity document, if any.
+func (s *Server) RegisterContainerInstanceWithContext(ctx aws.Context, input *ecs.RegisterContainerInstanceInput, opts ...request.Option) (*ecs.RegisterContainerInstanceOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("RegisterContainerInstance"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+
+	if input.ContainerInstanceArn != nil {
+		instance, err := s.getContainerInstance(clusterName, input.ContainerInstanceArn)
+		if err != nil {
+			return nil, err
+		}
+		instance.RegisteredResources = input.TotalResources
+		instance.RemainingResources = copyResources(input.TotalResources)
+		instance.VersionInfo = input.VersionInfo
+		instance.Version = aws.Int64(aws.Int64Value(instance.Version) + 1)
+		s.putAttributes(clusterName, aws.StringValue(instance.ContainerInstanceArn), input.Attributes)
+		return &ecs.RegisterContainerInstanceOutput{
+			ContainerInstance: s.describeContainerInstance(clusterName, instance),
+		}, nil
+	}
+
+	id := s.nextID()
+	instance := &ecs.ContainerInstance{
+		ContainerInstanceArn: aws.String(s.arn("container-instance/" + clusterName + "/" + id)),
+		Ec2InstanceId:        ec2InstanceID(aws.StringValue(input.InstanceIdentityDocument)),
+		Status:               aws.String(ecs.ContainerInstanceStatusActive),
+		AgentConnected:       aws.Bool(true),
+		RegisteredAt:         aws.Time(time.Now()),
+		RegisteredResources:  input.TotalResources,
+		RemainingResources:   copyResources(input.TotalResources),
+		VersionInfo:          input.VersionInfo,
+		ClientToken:          input.ClientToken,
+		Version:              aws.Int64(1),
+	}
+	if s.containerInstances[clusterName] == nil {
+		s.containerInstances[clusterName] = make(map[string]*ecs.ContainerInstance)
+	}
+	s.containerInstances[clusterName][id] = instance
+	s.putAttributes(clusterName, aws.StringValue(instance.ContainerInstanceArn), input.Attributes)
+	if len(input.Tags) > 0 {
+		s.tags[aws.StringValue(instance.ContainerInstanceArn)] = input.Tags
+	}
+	return &ecs.RegisterContainerInstanceOutput{
+		ContainerInstance: s.describeContainerInstance(clusterName, instance),
+	}, nil
+}
+
+// DeregisterContainerInstanceWithContext deregisters a container instance.
+// Instances with running tasks can only be deregistered when Force is set.
+func (s *Server) DeregisterContainerInstanceWithContext(ctx aws.Context, input *ecs.DeregisterContainerInstanceInput, opts ...request.Option) (*ecs.DeregisterContainerInstanceOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DeregisterContainerInstance"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+	instance, err := s.getContainerInstance(clusterName, input.ContainerInstance)
+	if err != nil {
+		return nil, err
+	}
+	described := s.describeContainerInstance(clusterName, instance)
+	if aws.Int64Value(described.RunningTasksCount) > 0 && !aws.BoolValue(input.Force) {
+		return nil, invalidParameterException(
+			"Found running tasks on the instance. Use force to deregister the instance.")
+	}
+
+	arn := aws.StringValue(instance.ContainerInstanceArn)
+	delete(s.containerInstances[clusterName], resourceName(arn))
+	s.deleteAttributesOfTarget(clusterName, arn)
+	delete(s.tags, arn)
+	described.Status = aws.String(statusInactive)
+	described.AgentConnected = aws.Bool(false)
+	return &ecs.DeregisterContainerInstanceOutput{ContainerInstance: described}, nil
+}
+
+// DescribeContainerInstancesWithContext describes the requested container
+// instances, up to 100. Tags are only included when requested.
+func (s *Server) DescribeContainerInstancesWithContext(ctx aws.Context, input *ecs.DescribeContainerInstancesInput, opts ...request.Option) (*ecs.DescribeContainerInstancesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DescribeContainerInstances"); err != nil {
+		return nil, err
+	}
+	if len(input.ContainerInstances) > maxDescribeContainerInstances {
+		return nil, invalidParameterException("ContainerInstances cannot contain more than %d elements.", maxDescribeContainerInstances)
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+	includeTags := false
+	for _, include := range aws.StringValueSlice(input.Include) {
+		if include == ecs.ContainerInstanceFieldTags {
+			includeTags = true
+		}
+	}
+
+	output := &ecs.DescribeContainerInstancesOutput{}
+	for _, identifier := range input.ContainerInstances {
+		instance, ok := s.containerInstances[clusterName][resourceName(aws.StringValue(identifier))]
+		if !ok {
+			output.Failures = append(output.Failures, missingFailure(aws.StringValue(identifier)))
+			continue
+		}
+		described := s.describeContainerInstance(clusterName, instance)
+		if includeTags {
+			described.Tags = s.copyTags(aws.StringValue(instance.ContainerInstanceArn))
+		}
+		output.ContainerInstances = append(output.ContainerInstances, described)
+	}
+	return output, nil
+}
+
+// ListContainerInstancesWithContext lists the ARNs of the container instances
+// in a cluster, optionally filtered by status. The cluster query language
+// Filter is not supported by the fake and is ignored.
+func (s *Server) ListContainerInstancesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput, opts ...request.Option) (*ecs.ListContainerInstancesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListContainerInstances"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var arns []string
+	for _, instance := range s.containerInstances[aws.StringValue(cluster.ClusterName)] {
+		if input.Status != nil && aws.StringValue(instance.Status) != aws.StringValue(input.Status) {
+			continue
+		}
+		arns = append(arns, aws.StringValue(instance.ContainerInstanceArn))
+	}
+	sort.Strings(arns)
+	instanceArns, nextToken, err := pageStrings(arns, input.MaxResults, input.NextToken, listContainerInstancesDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListContainerInstancesOutput{
+		ContainerInstanceArns: instanceArns,
+		NextToken:             nextToken,
+	}, nil
+}
+
+// UpdateContainerInstancesStateWithContext sets the status of the requested
+// container instances
+func (s *Server) UpdateContainerInstancesStateWithContext(ctx aws.Context, input *ecs.UpdateContainerInstancesStateInput, opts ...request.Option) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("UpdateContainerInstancesState"); err != nil {
+		return nil, err
+	}
+
+	status := aws.StringValue(input.Status)
+	if status != ecs.ContainerInstanceStatusActive && status != ecs.ContainerInstanceStatusDraining {
+		return nil, invalidParameterException("Container instance status should be one of [ACTIVE,DRAINING]")
+	}
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+
+	output := &ecs.UpdateContainerInstancesStateOutput{}
+	for _, identifier := range input.ContainerInstances {
+		instance, ok := s.containerInstances[clusterName][resourceName(aws.StringValue(identifier))]
+		if !ok {
+			output.Failures = append(output.Failures, missingFailure(aws.StringValue(identifier)))
+			continue
+		}
+		instance.Status = aws.String(status)
+		output.ContainerInstances = append(output.ContainerInstances,
+			s.describeContainerInstance(clusterName, instance))
+	}
+	return output, nil
+}
+
+// UpdateContainerAgentWithContext marks an agent update as pending on the
+// container instance
+func (s *Server) UpdateContainerAgentWithContext(ctx aws.Context, input *ecs.UpdateContainerAgentInput, opts ...request.Option) (*ecs.UpdateContainerAgentOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("UpdateContainerAgent"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+	instance, err := s.getContainerInstance(clusterName, input.ContainerInstance)
+	if err != nil {
+		return nil, err
+	}
+	if aws.StringValue(instance.AgentUpdateStatus) == ecs.AgentUpdateStatusPending {
+		return nil, awserr.New(ecs.ErrCodeUpdateInProgressException,
+			"An agent update is already in progress.", nil)
+	}
+	instance.AgentUpdateStatus = aws.String(ecs.AgentUpdateStatusPending)
+	return &ecs.UpdateContainerAgentOutput{
+		ContainerInstance: s.describeContainerInstance(clusterName, instance),
+	}, nil
+}
+
+// DiscoverPollEndpointWithContext returns the fixed PollEndpoint and
+// TelemetryEndpoint
+func (s *Server) DiscoverPollEndpointWithContext(ctx aws.Context, input *ecs.DiscoverPollEndpointInput, opts ...request.Option) (*ecs.DiscoverPollEndpointOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DiscoverPollEndpoint"); err != nil {
+		return nil, err
+	}
+	return &ecs.DiscoverPollEndpointOutput{
+		Endpoint:          aws.String(PollEndpoint),
+		TelemetryEndpoint: aws.String(TelemetryEndpoint),
+	}, nil
+}
+
+// getContainerInstance looks up a container instance by id or ARN. The lock
+// must be held by the caller.
+func (s *Server) getContainerInstance(clusterName string, identifier *string) (*ecs.ContainerInstance, error) {
+	instance, ok := s.containerInstances[clusterName][resourceName(aws.StringValue(identifier))]
+	if !ok {
+		return nil, invalidParameterException("Container instance not found: %s", aws.StringValue(identifier))
+	}
+	return instance, nil
+}
+
+// SetAgentConnected changes whether the agent of a container instance is
+// connected, as it would when the agent loses its connection to ECS
+func (s *Server) SetAgentConnected(cluster, containerInstance string, connected bool) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	described, err := s.getCluster(aws.String(cluster))
+	if err != nil {
+		return err
+	}
+	instance, err := s.getContainerInstance(aws.StringValue(described.ClusterName), aws.String(containerInstance))
+	if err != nil {
+		return err
+	}
+	instance.AgentConnected = aws.Bool(connected)
+	return nil
+}
+
+// describeContainerInstance returns a copy of the container instance with its
+// attributes and task counts filled in. The lock must be held by the caller.
+func (s *Server) describeContainerInstance(clusterName string, instance *ecs.ContainerInstance) *ecs.ContainerInstance {
+	described := awsutil.CopyOf(instance).(*ecs.ContainerInstance)
+	arn := aws.StringValue(instance.ContainerInstanceArn)
+	var running, pending int64
+	for _, task := range s.tasks[clusterName] {
+		if aws.StringValue(task.ContainerInstanceArn) != arn {
+			continue
+		}
+		switch aws.StringValue(task.LastStatus) {
+		case taskStatusRunning:
+			running++
+		case taskStatusPending:
+			pending++
+		}
+	}
+	described.RunningTasksCount = aws.Int64(running)
+	described.PendingTasksCount = aws.Int64(pending)
+	described.Attributes = nil
+	for _, attribute := range s.attributes[clusterName] {
+		if aws.StringValue(attribute.TargetId) == arn {
+			described.Attributes = append(described.Attributes, &ecs.Attribute{
+				Name:  attribute.Name,
+				Value: attribute.Value,
+			})
+		}
+	}
+	return described
+}
+
+// ec2InstanceID extracts the instance id from an instance identity document
+func ec2InstanceID(document string) *string {
+	var identity struct {
+		InstanceID string `json:"instanceId"`
+	}
+	if err := json.Unmarshal([]byte(document), &identity); err != nil || identity.InstanceID == "" {
+		return nil
+	}
+	return aws.String(identity.InstanceID)
+}
+
+// copyResources returns a deep copy of a list of resources
+func copyResources(resources []*ecs.Resource) []*ecs.Resource {
+	var copied []*ecs.Resource
+	for _, resource := range resources {
+		copied = append(copied, awsutil.CopyOf(resource).(*ecs.Resource))
+	}
+	return copied
+}
@@ -0,0 +1,140 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupFilteredInstances registers three container instances: one ACTIVE, one
+// DRAINING and one ACTIVE whose agent is disconnected
+func setupFilteredInstances(t *testing.T, server *Server) (active, draining, disconnected string) {
+	client := server.Client()
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+	var arns []string
+	for i := 0; i < 3; i++ {
+		output, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+			Cluster: aws.String(testCluster),
+		})
+		require.NoError(t, err)
+		arns = append(arns, aws.StringValue(output.ContainerInstance.ContainerInstanceArn))
+	}
+	_, err = client.UpdateContainerInstancesState(&ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns[1:2]),
+		Status:             aws.String(ecs.ContainerInstanceStatusDraining),
+	})
+	require.NoError(t, err)
+	require.NoError(t, server.SetAgentConnected(testCluster, arns[2], false))
+	return arns[0], arns[1], arns[2]
+}
+
+func TestDescribeContainerInstancesFilters(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	active, draining, disconnected := setupFilteredInstances(t, server)
+
+	testCases := []struct {
+		name     string
+		filters  []*ecs.Filter
+		expected []string
+	}{
+		{"no filter", nil, []string{active, draining, disconnected}},
+		{"status ACTIVE", []*ecs.Filter{{
+			Name:   aws.String(ecs.FilterNameStatus),
+			Values: aws.StringSlice([]string{ecs.ContainerInstanceStatusActive}),
+		}}, []string{active, disconnected}},
+		{"status DRAINING", []*ecs.Filter{{
+			Name:   aws.String(ecs.FilterNameStatus),
+			Values: aws.StringSlice([]string{ecs.ContainerInstanceStatusDraining}),
+		}}, []string{draining}},
+		{"agentConnected true", []*ecs.Filter{{
+			Name:   aws.String(ecs.FilterNameAgentConnected),
+			Values: aws.StringSlice([]string{"true"}),
+		}}, []string{active, draining}},
+		{"status ACTIVE and agentConnected true", []*ecs.Filter{
+			{Name: aws.String(ecs.FilterNameStatus), Values: aws.StringSlice([]string{ecs.ContainerInstanceStatusActive})},
+			{Name: aws.String(ecs.FilterNameAgentConnected), Values: aws.StringSlice([]string{"true"})},
+		}, []string{active}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+				Cluster:            aws.String(testCluster),
+				ContainerInstances: aws.StringSlice([]string{active, draining, disconnected}),
+			})
+			require.NoError(t, err)
+			assert.Empty(t, output.Failures)
+			instances, err := ecs.FilterContainerInstances(output.ContainerInstances, tc.filters)
+			require.NoError(t, err)
+			var arns []string
+			for _, instance := range instances {
+				arns = append(arns, aws.StringValue(instance.ContainerInstanceArn))
+			}
+			assert.Equal(t, tc.expected, arns)
+		})
+	}
+}
+
+func TestDescribeContainerInstancesIncludeTags(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+	registered, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+		Cluster: aws.String(testCluster),
+		Tags:    testTags(2),
+	})
+	require.NoError(t, err)
+	arns := []*string{registered.ContainerInstance.ContainerInstanceArn}
+
+	described, err := client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: arns,
+	})
+	require.NoError(t, err)
+	require.Len(t, described.ContainerInstances, 1)
+	assert.Nil(t, described.ContainerInstances[0].Tags)
+
+	described, err = client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: arns,
+		Include:            aws.StringSlice([]string{ecs.ContainerInstanceFieldTags}),
+	})
+	require.NoError(t, err)
+	require.Len(t, described.ContainerInstances, 1)
+	assert.Equal(t, testTags(2), described.ContainerInstances[0].Tags)
+
+	_, err = client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: arns,
+		Include:            aws.StringSlice([]string{ecs.ContainerInstanceFieldTags, "STATISTICS"}),
+	})
+	require.Error(t, err)
+	assert.Equal(t, request.InvalidParameterErrCode, err.(awserr.Error).Code())
+	assert.Contains(t, err.Error(), "Include[1]")
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Option configures a Server created by NewServer
+type Option func(*Server)
+
+// faults holds the configuration of the failures injected into the calls
+// made to a Server, along with the state needed to inject them
+type faults struct {
+	// failureRate is the fraction of all calls that fail at random
+	failureRate float64
+	random      *rand.Rand
+	// failEvery is the period of the failures of an operation, keyed by
+	// operation name
+	failEvery map[string]int
+	// calls counts the calls made to each operation, keyed by operation name
+	calls map[string]int
+}
+
+// ChaoticOption makes the server fail a random fraction of all calls with a
+// ServerException. A failureRate of 0 never fails and a failureRate of 1
+// fails every call.
+func ChaoticOption(failureRate float64) Option {
+	return func(s *Server) {
+		s.faults.failureRate = failureRate
+		s.faults.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
+// DeterministicFailures makes the server fail every nth call to operation,
+// such as "DescribeTasks", with a ServerException. It can be given once per
+// operation.
+func DeterministicFailures(operation string, n int) Option {
+	return func(s *Server) {
+		if n <= 0 {
+			return
+		}
+		if s.faults.failEvery == nil {
+			s.faults.failEvery = make(map[string]int)
+		}
+		s.faults.failEvery[operation] = n
+	}
+}
+
+// injectFault counts a call to operation and returns the ServerException it
+// should fail with, if any. The lock must be held by the caller.
+func (s *Server) injectFault(operation string) error {
+	if s.faults.calls == nil {
+		s.faults.calls = make(map[string]int)
+	}
+	s.faults.calls[operation]++
+
+	if n, ok := s.faults.failEvery[operation]; ok && s.faults.calls[operation]%n == 0 {
+		return serverException("Injected failure of call %d to %s", s.faults.calls[operation], operation)
+	}
+	if s.faults.random != nil && s.faults.random.Float64() < s.faults.failureRate {
+		return serverException("Injected random failure of %s", operation)
+	}
+	return nil
+}
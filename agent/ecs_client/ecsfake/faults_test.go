@@ -0,0 +1,76 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicFailures(t *testing.T) {
+	server := NewServer(DeterministicFailures("ListClusters", 3))
+	ctx := aws.BackgroundContext()
+
+	for call := 1; call <= 6; call++ {
+		_, err := server.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+		if call%3 != 0 {
+			assert.NoError(t, err, "call %d", call)
+			continue
+		}
+		require.Error(t, err, "call %d", call)
+		assert.Equal(t, ecs.ErrCodeServerException, err.(awserr.Error).Code())
+	}
+
+	// Other operations are not affected
+	for call := 1; call <= 3; call++ {
+		_, err := server.ListTaskDefinitionsWithContext(ctx, &ecs.ListTaskDefinitionsInput{})
+		assert.NoError(t, err, "call %d", call)
+	}
+}
+
+func TestDeterministicFailuresOverHTTP(t *testing.T) {
+	server := NewServer(DeterministicFailures("ListClusters", 2))
+	defer server.Close()
+	client := server.Client()
+
+	_, err := client.ListClusters(&ecs.ListClustersInput{})
+	assert.NoError(t, err)
+	_, err = client.ListClusters(&ecs.ListClustersInput{})
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeServerException, err.(awserr.Error).Code())
+}
+
+func TestChaoticOption(t *testing.T) {
+	ctx := aws.BackgroundContext()
+
+	server := NewServer(ChaoticOption(1))
+	for call := 0; call < 10; call++ {
+		_, err := server.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+		require.Error(t, err)
+		assert.Equal(t, ecs.ErrCodeServerException, err.(awserr.Error).Code())
+	}
+
+	server = NewServer(ChaoticOption(0))
+	for call := 0; call < 10; call++ {
+		_, err := server.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package ecsfake provides an in-memory fake of the ECS API for use in tests.
+package ecsfake
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+)
+
+const (
+	// DefaultRegion is the region used in the ARNs generated by the fake
+	DefaultRegion = "us-west-2"
+	// DefaultAccountID is the account id used in the ARNs generated by the fake
+	DefaultAccountID = "123456789012"
+
+	defaultClusterName = "default"
+	targetPrefix       = "AmazonEC2ContainerServiceV20141113."
+	jsonContentType    = "application/x-amz-json-1.1"
+)
+
+// Server is an in-memory fake of the ECS API. Its methods mirror the
+// *WithContext methods of *ecs.ECS and can be called directly. The same
+// operations are also served over the ECS JSON protocol, so that a real
+// *ecs.ECS client returned by Client can be used against the fake.
+type Server struct {
+	region    string
+	accountID string
+
+	lock sync.Mutex
+	// clusters are keyed by cluster name
+	clusters map[string]*ecs.Cluster
+	// containerInstances are keyed by cluster name, then by id
+	containerInstances map[string]map[string]*ecs.ContainerInstance
+	// services are keyed by cluster name, then by service name
+	services map[string]map[string]*ecs.Service
+	// tasks are keyed by cluster name, then by id
+	tasks map[string]map[string]*ecs.Task
+	// taskDefinitions are keyed by family, ordered by revision
+	taskDefinitions map[string][]*ecs.TaskDefinition
+	// attributes are keyed by cluster name
+	attributes map[string][]*ecs.Attribute
+	// tags are keyed by resource ARN
+	tags map[string][]*ecs.Tag
+	// accountSettings are keyed by principal ARN, then by setting name
+	accountSettings map[string]map[string]*ecs.Setting
+	// idSequence is used to generate unique resource ids
+	idSequence int
+	// faults are the failures injected into the calls made to the fake
+	faults faults
+
+	httpServerOnce sync.Once
+	httpServer     *httptest.Server
+}
+
+var _ ecs.ECSClientInterface = (*Server)(nil)
+
+// NewServer creates a new fake ECS server with no resources. Options can be
+// given to inject failures into the calls made to it.
+func NewServer(options ...Option) *Server {
+	s := &Server{
+		region:    DefaultRegion,
+		accountID: DefaultAccountID,
+		clusters:  make(map[string]*ecs.Cluster),
+
+		containerInstances: make(map[string]map[string]*ecs.ContainerInstance),
+		services:           make(map[string]map[string]*ecs.Service),
+		tasks:              make(map[string]map[string]*ecs.Task),
+		taskDefinitions:    make(map[string][]*ecs.TaskDefinition),
+		attributes:         make(map[string][]*ecs.Attribute),
+		tags:               make(map[string][]*ecs.Tag),
+		accountSettings:    make(map[string]map[string]*ecs.Setting),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Client returns an ECS client that sends its requests to the fake. The
+// client does not retry failed requests.
+func (s *Server) Client() *ecs.ECS {
+	s.httpServerOnce.Do(func() {
+		s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	})
+	return ecs.New(session.New(&aws.Config{
+		Region:      aws.String(s.region),
+		Endpoint:    aws.String(s.httpServer.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+		MaxRetries:  aws.Int(0),
+	}))
+}
+
+// Close shuts down the HTTP endpoint of the fake, if it was started
+func (s *Server) Close() {
+	if s.httpServer != nil {
+		s.httpServer.Close()
+	}
+}
+
+// serveHTTP dispatches a JSON protocol request to the method of the fake
+// named after the operation in the X-Amz-Target header
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), targetPrefix)
+	method := reflect.ValueOf(s).MethodByName(operation + "WithContext")
+	if operation == "" || !method.IsValid() {
+		writeError(w, awserr.New("UnknownOperationException",
+			fmt.Sprintf("unknown operation: %s", operation), nil))
+		return
+	}
+
+	input := reflect.New(method.Type().In(1).Elem())
+	if err := jsonutil.UnmarshalJSON(input.Interface(), r.Body); err != nil {
+		writeError(w, awserr.New("SerializationException", err.Error(), nil))
+		return
+	}
+
+	results := method.Call([]reflect.Value{reflect.ValueOf(r.Context()), input})
+	if err, ok := results[1].Interface().(error); ok && err != nil {
+		writeError(w, err)
+		return
+	}
+	body, err := jsonutil.BuildJSON(results[0].Interface())
+	if err != nil {
+		writeError(w, awserr.New(ecs.ErrCodeServerException, err.Error(), nil))
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := ecs.ErrCodeServerException
+	message := err.Error()
+	if awsErr, ok := err.(awserr.Error); ok {
+		code = awsErr.Code()
+		message = awsErr.Message()
+	}
+	body, _ := jsonutil.BuildJSON(&struct {
+		Code    *string `locationName:"__type" type:"string"`
+		Message *string `locationName:"message" type:"string"`
+	}{
+		Code:    aws.String(code),
+		Message: aws.String(message),
+	})
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(body)
+}
+
+// arn builds the ARN of an ECS resource owned by the fake
+func (s *Server) arn(resource string) string {
+	return fmt.Sprintf("arn:aws:ecs:%s:%s:%s", s.region, s.accountID, resource)
+}
+
+// resourceName returns the name or id from either a short identifier or a
+// full ARN
+func resourceName(identifier string) string {
+	if !strings.HasPrefix(identifier, "arn:") {
+		return identifier
+	}
+	return identifier[strings.LastIndex(identifier, "/")+1:]
+}
+
+func clientException(format string, args ...interface{}) error {
+	return awserr.New(ecs.ErrCodeClientException, fmt.Sprintf(format, args...), nil)
+}
+
+func serverException(format string, args ...interface{}) error {
+	return awserr.New(ecs.ErrCodeServerException, fmt.Sprintf(format, args...), nil)
+}
+
+func invalidParameterException(format string, args ...interface{}) error {
+	return awserr.New(ecs.ErrCodeInvalidParameterException, fmt.Sprintf(format, args...), nil)
+}
+
+// nextID returns a new unique resource id. The lock must be held by the
+// caller.
+func (s *Server) nextID() string {
+	s.idSequence++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", s.idSequence)
+}
+
+// page returns the bounds of the page of n items selected by maxResults and
+// nextToken, along with the token for the following page, if any. The tokens
+// used by the fake are the string offsets of the first item of the page.
+func page(n int, maxResults *int64, nextToken *string, defaultMaxResults int) (int, int, *string, error) {
+	start := 0
+	if token := aws.StringValue(nextToken); token != "" {
+		var err error
+		start, err = strconv.Atoi(token)
+		if err != nil || start < 0 || start > n {
+			return 0, 0, nil, invalidParameterException("Invalid nextToken: %s", token)
+		}
+	}
+	size := defaultMaxResults
+	if maxResults != nil && aws.Int64Value(maxResults) > 0 {
+		size = int(aws.Int64Value(maxResults))
+	}
+	end := start + size
+	if end >= n {
+		return start, n, nil, nil
+	}
+	return start, end, aws.String(strconv.Itoa(end)), nil
+}
+
+// pageStrings returns the page of values selected by maxResults and nextToken
+func pageStrings(values []string, maxResults *int64, nextToken *string, defaultMaxResults int) ([]*string, *string, error) {
+	start, end, next, err := page(len(values), maxResults, nextToken, defaultMaxResults)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aws.StringSlice(values[start:end]), next, nil
+}
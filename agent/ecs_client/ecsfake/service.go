@@ -0,0 +1,302 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	serviceStatusDraining = "DRAINING"
+
+	deploymentStatusPrimary = "PRIMARY"
+	deploymentStatusActive  = "ACTIVE"
+
+	// serviceGroupPrefix is the prefix of the group of the tasks started by a
+	// service
+	serviceGroupPrefix = "service:"
+
+	listServicesDefaultMaxResults = 10
+)
+
+// CreateServiceWithContext creates a service with a single PRIMARY
+// deployment. The fake does not start the tasks of the service.
+func (s *Server) CreateServiceWithContext(ctx aws.Context, input *ecs.CreateServiceInput, opts ...request.Option) (*ecs.CreateServiceOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("CreateService"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+	name := aws.StringValue(input.ServiceName)
+	if existing, ok := s.services[clusterName][name]; ok && aws.StringValue(existing.Status) == statusActive {
+		return nil, invalidParameterException("Creation of service was not idempotent.")
+	}
+	taskDefinition, err := s.getTaskDefinition(aws.StringValue(input.TaskDefinition))
+	if err != nil {
+		return nil, err
+	}
+	launchType := aws.StringValue(input.LaunchType)
+	if launchType == "" {
+		launchType = ecs.LaunchTypeEc2
+	}
+	schedulingStrategy := aws.StringValue(input.SchedulingStrategy)
+	if schedulingStrategy == "" {
+		schedulingStrategy = ecs.SchedulingStrategyReplica
+	}
+
+	now := time.Now()
+	service := &ecs.Service{
+		ServiceArn:                    aws.String(s.arn("service/" + clusterName + "/" + name)),
+		ServiceName:                   aws.String(name),
+		ClusterArn:                    cluster.ClusterArn,
+		Status:                        aws.String(statusActive),
+		TaskDefinition:                taskDefinition.TaskDefinitionArn,
+		DesiredCount:                  aws.Int64(aws.Int64Value(input.DesiredCount)),
+		RunningCount:                  aws.Int64(0),
+		PendingCount:                  aws.Int64(0),
+		LaunchType:                    aws.String(launchType),
+		SchedulingStrategy:            aws.String(schedulingStrategy),
+		DeploymentConfiguration:       input.DeploymentConfiguration,
+		EnableECSManagedTags:          input.EnableECSManagedTags,
+		HealthCheckGracePeriodSeconds: input.HealthCheckGracePeriodSeconds,
+		LoadBalancers:                 input.LoadBalancers,
+		NetworkConfiguration:          input.NetworkConfiguration,
+		PlacementConstraints:          input.PlacementConstraints,
+		PlacementStrategy:             input.PlacementStrategy,
+		PlatformVersion:               input.PlatformVersion,
+		PropagateTags:                 input.PropagateTags,
+		RoleArn:                       input.Role,
+		ServiceRegistries:             input.ServiceRegistries,
+		CreatedAt:                     aws.Time(now),
+	}
+	service.Deployments = []*ecs.Deployment{s.newDeployment(service, now)}
+	if s.services[clusterName] == nil {
+		s.services[clusterName] = make(map[string]*ecs.Service)
+	}
+	s.services[clusterName][name] = service
+	return &ecs.CreateServiceOutput{
+		Service: awsutil.CopyOf(service).(*ecs.Service),
+	}, nil
+}
+
+// UpdateServiceWithContext updates a service. Changing the task definition or
+// the network configuration, or forcing a new deployment, makes the current
+// PRIMARY deployment ACTIVE and adds a new PRIMARY deployment.
+func (s *Server) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput, opts ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("UpdateService"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	service, err := s.getService(aws.StringValue(cluster.ClusterName), input.Service)
+	if err != nil {
+		return nil, err
+	}
+	if aws.StringValue(service.Status) != statusActive {
+		return nil, awserr.New(ecs.ErrCodeServiceNotActiveException, "Service was not ACTIVE.", nil)
+	}
+
+	deploy := aws.BoolValue(input.ForceNewDeployment)
+	if input.TaskDefinition != nil {
+		taskDefinition, err := s.getTaskDefinition(aws.StringValue(input.TaskDefinition))
+		if err != nil {
+			return nil, err
+		}
+		if aws.StringValue(taskDefinition.TaskDefinitionArn) != aws.StringValue(service.TaskDefinition) {
+			service.TaskDefinition = taskDefinition.TaskDefinitionArn
+			deploy = true
+		}
+	}
+	if input.NetworkConfiguration != nil {
+		service.NetworkConfiguration = input.NetworkConfiguration
+		deploy = true
+	}
+	if input.PlatformVersion != nil {
+		service.PlatformVersion = input.PlatformVersion
+		deploy = true
+	}
+	if input.DesiredCount != nil {
+		service.DesiredCount = input.DesiredCount
+	}
+	if input.DeploymentConfiguration != nil {
+		service.DeploymentConfiguration = input.DeploymentConfiguration
+	}
+	if input.HealthCheckGracePeriodSeconds != nil {
+		service.HealthCheckGracePeriodSeconds = input.HealthCheckGracePeriodSeconds
+	}
+
+	now := time.Now()
+	if deploy {
+		for _, deployment := range service.Deployments {
+			deployment.Status = aws.String(deploymentStatusActive)
+			deployment.UpdatedAt = aws.Time(now)
+		}
+		service.Deployments = append([]*ecs.Deployment{s.newDeployment(service, now)}, service.Deployments...)
+	} else {
+		service.Deployments[0].DesiredCount = service.DesiredCount
+		service.Deployments[0].UpdatedAt = aws.Time(now)
+	}
+	return &ecs.UpdateServiceOutput{
+		Service: awsutil.CopyOf(service).(*ecs.Service),
+	}, nil
+}
+
+// DeleteServiceWithContext deletes a service. A service with a non zero
+// desired count can only be deleted when Force is set.
+func (s *Server) DeleteServiceWithContext(ctx aws.Context, input *ecs.DeleteServiceInput, opts ...request.Option) (*ecs.DeleteServiceOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DeleteService"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+	service, err := s.getService(clusterName, input.Service)
+	if err != nil {
+		return nil, err
+	}
+	if aws.StringValue(service.Status) != statusActive {
+		return nil, awserr.New(ecs.ErrCodeServiceNotActiveException, "Service was not ACTIVE.", nil)
+	}
+	if aws.Int64Value(service.DesiredCount) > 0 && !aws.BoolValue(input.Force) &&
+		aws.StringValue(service.SchedulingStrategy) != ecs.SchedulingStrategyDaemon {
+		return nil, invalidParameterException(
+			"The service cannot be stopped while it is scaled above 0.")
+	}
+	service.Status = aws.String(serviceStatusDraining)
+	service.DesiredCount = aws.Int64(0)
+	delete(s.tags, aws.StringValue(service.ServiceArn))
+	return &ecs.DeleteServiceOutput{
+		Service: awsutil.CopyOf(service).(*ecs.Service),
+	}, nil
+}
+
+// DescribeServicesWithContext describes the requested services
+func (s *Server) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DescribeServices"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+
+	output := &ecs.DescribeServicesOutput{}
+	for _, identifier := range input.Services {
+		service, ok := s.services[clusterName][resourceName(aws.StringValue(identifier))]
+		if !ok {
+			output.Failures = append(output.Failures, missingFailure(aws.StringValue(identifier)))
+			continue
+		}
+		output.Services = append(output.Services, awsutil.CopyOf(service).(*ecs.Service))
+	}
+	return output, nil
+}
+
+// ListServicesWithContext lists the ARNs of the ACTIVE services of a cluster,
+// optionally filtered by launch type and scheduling strategy
+func (s *Server) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput, opts ...request.Option) (*ecs.ListServicesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListServices"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var arns []string
+	for _, service := range s.services[aws.StringValue(cluster.ClusterName)] {
+		switch {
+		case aws.StringValue(service.Status) != statusActive:
+			continue
+		case input.LaunchType != nil && aws.StringValue(service.LaunchType) != aws.StringValue(input.LaunchType):
+			continue
+		case input.SchedulingStrategy != nil && aws.StringValue(service.SchedulingStrategy) != aws.StringValue(input.SchedulingStrategy):
+			continue
+		}
+		arns = append(arns, aws.StringValue(service.ServiceArn))
+	}
+	sort.Strings(arns)
+	serviceArns, nextToken, err := pageStrings(arns, input.MaxResults, input.NextToken, listServicesDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListServicesOutput{
+		ServiceArns: serviceArns,
+		NextToken:   nextToken,
+	}, nil
+}
+
+// newDeployment returns a PRIMARY deployment of the current configuration of
+// a service. The lock must be held by the caller.
+func (s *Server) newDeployment(service *ecs.Service, now time.Time) *ecs.Deployment {
+	return &ecs.Deployment{
+		Id:                   aws.String("ecs-svc/" + s.nextID()),
+		Status:               aws.String(deploymentStatusPrimary),
+		RolloutState:         aws.String(ecs.DeploymentRolloutStateInProgress),
+		TaskDefinition:       service.TaskDefinition,
+		DesiredCount:         service.DesiredCount,
+		RunningCount:         aws.Int64(0),
+		PendingCount:         aws.Int64(0),
+		LaunchType:           service.LaunchType,
+		PlatformVersion:      service.PlatformVersion,
+		NetworkConfiguration: service.NetworkConfiguration,
+		CreatedAt:            aws.Time(now),
+		UpdatedAt:            aws.Time(now),
+	}
+}
+
+// getService looks up a service by name or ARN. The lock must be held by the
+// caller.
+func (s *Server) getService(clusterName string, identifier *string) (*ecs.Service, error) {
+	service, ok := s.services[clusterName][resourceName(aws.StringValue(identifier))]
+	if !ok {
+		return nil, awserr.New(ecs.ErrCodeServiceNotFoundException, "Service not found.", nil)
+	}
+	return service, nil
+}
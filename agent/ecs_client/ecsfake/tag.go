@@ -0,0 +1,103 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// ListTagsForResourceWithContext lists the tags of a resource owned by the
+// fake
+func (s *Server) ListTagsForResourceWithContext(ctx aws.Context, input *ecs.ListTagsForResourceInput, opts ...request.Option) (*ecs.ListTagsForResourceOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListTagsForResource"); err != nil {
+		return nil, err
+	}
+
+	arn := aws.StringValue(input.ResourceArn)
+	if !s.resourceExists(arn) {
+		return nil, invalidParameterException("The specified resource could not be found: %s", arn)
+	}
+	return &ecs.ListTagsForResourceOutput{
+		Tags: s.copyTags(arn),
+	}, nil
+}
+
+// SetTags replaces the tags of a resource owned by the fake. It can be used
+// to tag resources whose API calls do not accept tags.
+func (s *Server) SetTags(resourceArn string, tags []*ecs.Tag) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.tags[resourceArn] = copyTagList(tags)
+}
+
+// copyTags returns a copy of the tags of a resource. The lock must be held by
+// the caller.
+func (s *Server) copyTags(arn string) []*ecs.Tag {
+	return copyTagList(s.tags[arn])
+}
+
+// resourceExists reports whether a taggable resource with the given ARN is
+// known to the fake. The lock must be held by the caller.
+func (s *Server) resourceExists(arn string) bool {
+	for _, cluster := range s.clusters {
+		if aws.StringValue(cluster.ClusterArn) == arn {
+			return true
+		}
+	}
+	for _, revisions := range s.taskDefinitions {
+		for _, taskDefinition := range revisions {
+			if aws.StringValue(taskDefinition.TaskDefinitionArn) == arn {
+				return true
+			}
+		}
+	}
+	for _, services := range s.services {
+		for _, service := range services {
+			if aws.StringValue(service.ServiceArn) == arn {
+				return true
+			}
+		}
+	}
+	for _, tasks := range s.tasks {
+		for _, task := range tasks {
+			if aws.StringValue(task.TaskArn) == arn {
+				return true
+			}
+		}
+	}
+	for _, instances := range s.containerInstances {
+		for _, instance := range instances {
+			if aws.StringValue(instance.ContainerInstanceArn) == arn {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copyTagList returns a deep copy of a list of tags
+func copyTagList(tags []*ecs.Tag) []*ecs.Tag {
+	var copied []*ecs.Tag
+	for _, tag := range tags {
+		copied = append(copied, awsutil.CopyOf(tag).(*ecs.Tag))
+	}
+	return copied
+}
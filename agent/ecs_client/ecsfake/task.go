@@ -0,0 +1,459 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	taskStatusPending = ecs.DesiredStatusPending
+	taskStatusRunning = ecs.DesiredStatusRunning
+	taskStatusStopped = ecs.DesiredStatusStopped
+
+	// maxRunTaskCount is the number of tasks that can be started by a single
+	// RunTask call
+	maxRunTaskCount = 10
+
+	listTasksDefaultMaxResults = 100
+
+	acknowledgment = "ACK"
+)
+
+// RunTaskWithContext starts Count tasks. Tasks using the EC2 launch type are
+// placed on the ACTIVE container instances of the cluster in turn; a failure
+// is returned for each task that cannot be placed.
+func (s *Server) RunTaskWithContext(ctx aws.Context, input *ecs.RunTaskInput, opts ...request.Option) (*ecs.RunTaskOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("RunTask"); err != nil {
+		return nil, err
+	}
+
+	count := aws.Int64Value(input.Count)
+	if count == 0 {
+		count = 1
+	}
+	if count > maxRunTaskCount {
+		return nil, invalidParameterException("Count must be between 1 and %d.", maxRunTaskCount)
+	}
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+	taskDefinition, err := s.getTaskDefinition(aws.StringValue(input.TaskDefinition))
+	if err != nil {
+		return nil, err
+	}
+	launchType := aws.StringValue(input.LaunchType)
+	if launchType == "" {
+		launchType = ecs.LaunchTypeEc2
+	}
+
+	output := &ecs.RunTaskOutput{}
+	if launchType == ecs.LaunchTypeFargate {
+		for i := int64(0); i < count; i++ {
+			task := s.startTask(clusterName, taskDefinition, &taskLaunch{
+				launchType: launchType,
+				startedBy:  input.StartedBy,
+				group:      input.Group,
+				overrides:  input.Overrides,
+				tags:       input.Tags,
+			})
+			output.Tasks = append(output.Tasks, task)
+		}
+		return output, nil
+	}
+
+	instances := s.activeContainerInstances(clusterName)
+	if len(instances) == 0 {
+		return nil, invalidParameterException("No Container Instances were found in your cluster.")
+	}
+	for i := int64(0); i < count; i++ {
+		instance := instances[int(i)%len(instances)]
+		task := s.startTask(clusterName, taskDefinition, &taskLaunch{
+			containerInstanceArn: instance.ContainerInstanceArn,
+			launchType:           launchType,
+			startedBy:            input.StartedBy,
+			group:                input.Group,
+			overrides:            input.Overrides,
+			tags:                 input.Tags,
+		})
+		output.Tasks = append(output.Tasks, task)
+	}
+	return output, nil
+}
+
+// RunTaskBatch starts count tasks with as many RunTask calls as needed
+func (s *Server) RunTaskBatch(ctx aws.Context, input *ecs.RunTaskInput, count int) ([]*ecs.Task, []*ecs.Failure, error) {
+	return ecs.RunTaskInChunks(ctx, s, input, count)
+}
+
+// StartTaskWithContext starts a task on each of the requested container
+// instances
+func (s *Server) StartTaskWithContext(ctx aws.Context, input *ecs.StartTaskInput, opts ...request.Option) (*ecs.StartTaskOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("StartTask"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+	taskDefinition, err := s.getTaskDefinition(aws.StringValue(input.TaskDefinition))
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ecs.StartTaskOutput{}
+	for _, identifier := range input.ContainerInstances {
+		instance, ok := s.containerInstances[clusterName][resourceName(aws.StringValue(identifier))]
+		if !ok {
+			output.Failures = append(output.Failures, missingFailure(aws.StringValue(identifier)))
+			continue
+		}
+		task := s.startTask(clusterName, taskDefinition, &taskLaunch{
+			containerInstanceArn: instance.ContainerInstanceArn,
+			launchType:           ecs.LaunchTypeEc2,
+			startedBy:            input.StartedBy,
+			group:                input.Group,
+			overrides:            input.Overrides,
+			tags:                 input.Tags,
+		})
+		output.Tasks = append(output.Tasks, task)
+	}
+	return output, nil
+}
+
+// StopTaskWithContext stops a task. The fake stops the task immediately.
+func (s *Server) StopTaskWithContext(ctx aws.Context, input *ecs.StopTaskInput, opts ...request.Option) (*ecs.StopTaskOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("StopTask"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.getTask(aws.StringValue(cluster.ClusterName), input.Task)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	task.DesiredStatus = aws.String(taskStatusStopped)
+	task.LastStatus = aws.String(taskStatusStopped)
+	task.StoppedReason = input.Reason
+	task.StoppingAt = aws.Time(now)
+	task.StoppedAt = aws.Time(now)
+	task.Version = aws.Int64(aws.Int64Value(task.Version) + 1)
+	for _, container := range task.Containers {
+		container.LastStatus = aws.String(taskStatusStopped)
+	}
+	return &ecs.StopTaskOutput{
+		Task: s.describeTask(task),
+	}, nil
+}
+
+// DescribeTasksWithContext describes the requested tasks
+func (s *Server) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DescribeTasks"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+
+	output := &ecs.DescribeTasksOutput{}
+	for _, identifier := range input.Tasks {
+		task, ok := s.tasks[clusterName][resourceName(aws.StringValue(identifier))]
+		if !ok {
+			output.Failures = append(output.Failures, missingFailure(aws.StringValue(identifier)))
+			continue
+		}
+		output.Tasks = append(output.Tasks, s.describeTask(task))
+	}
+	return output, nil
+}
+
+// ListTasksWithContext lists the ARNs of the tasks in a cluster that match
+// the given filters. Only tasks with a desired status of RUNNING are listed
+// unless another DesiredStatus is requested.
+func (s *Server) ListTasksWithContext(ctx aws.Context, input *ecs.ListTasksInput, opts ...request.Option) (*ecs.ListTasksOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListTasks"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	clusterName := aws.StringValue(cluster.ClusterName)
+
+	var instanceArn string
+	if input.ContainerInstance != nil {
+		instance, err := s.getContainerInstance(clusterName, input.ContainerInstance)
+		if err != nil {
+			return nil, err
+		}
+		instanceArn = aws.StringValue(instance.ContainerInstanceArn)
+	}
+	var serviceGroup string
+	if input.ServiceName != nil {
+		service, err := s.getService(clusterName, input.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		serviceGroup = serviceGroupPrefix + aws.StringValue(service.ServiceName)
+	}
+	desiredStatus := aws.StringValue(input.DesiredStatus)
+	if desiredStatus == "" {
+		desiredStatus = taskStatusRunning
+	}
+
+	var arns []string
+	for _, task := range s.tasks[clusterName] {
+		switch {
+		case aws.StringValue(task.DesiredStatus) != desiredStatus:
+			continue
+		case instanceArn != "" && aws.StringValue(task.ContainerInstanceArn) != instanceArn:
+			continue
+		case serviceGroup != "" && aws.StringValue(task.Group) != serviceGroup:
+			continue
+		case input.StartedBy != nil && aws.StringValue(task.StartedBy) != aws.StringValue(input.StartedBy):
+			continue
+		case input.LaunchType != nil && aws.StringValue(task.LaunchType) != aws.StringValue(input.LaunchType):
+			continue
+		case input.Family != nil && s.taskFamily(task) != aws.StringValue(input.Family):
+			continue
+		}
+		arns = append(arns, aws.StringValue(task.TaskArn))
+	}
+	sort.Strings(arns)
+	taskArns, nextToken, err := pageStrings(arns, input.MaxResults, input.NextToken, listTasksDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListTasksOutput{
+		TaskArns:  taskArns,
+		NextToken: nextToken,
+	}, nil
+}
+
+// SubmitTaskStateChangeWithContext records the status of a task and of its
+// containers, as reported by an agent
+func (s *Server) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("SubmitTaskStateChange"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.getTask(aws.StringValue(cluster.ClusterName), input.Task)
+	if err != nil {
+		return nil, err
+	}
+	if input.Status != nil {
+		task.LastStatus = input.Status
+		if aws.StringValue(input.Status) == taskStatusRunning && task.StartedAt == nil {
+			task.StartedAt = aws.Time(time.Now())
+		}
+	}
+	if input.PullStartedAt != nil {
+		task.PullStartedAt = input.PullStartedAt
+	}
+	if input.PullStoppedAt != nil {
+		task.PullStoppedAt = input.PullStoppedAt
+	}
+	if input.ExecutionStoppedAt != nil {
+		task.ExecutionStoppedAt = input.ExecutionStoppedAt
+	}
+	for _, change := range input.Containers {
+		container := findContainer(task, aws.StringValue(change.ContainerName))
+		if container == nil {
+			continue
+		}
+		container.LastStatus = change.Status
+		container.ExitCode = change.ExitCode
+		container.Reason = change.Reason
+		container.NetworkBindings = change.NetworkBindings
+	}
+	task.Version = aws.Int64(aws.Int64Value(task.Version) + 1)
+	return &ecs.SubmitTaskStateChangeOutput{
+		Acknowledgment: aws.String(acknowledgment),
+	}, nil
+}
+
+// SubmitContainerStateChangeWithContext records the status of a container, as
+// reported by an agent
+func (s *Server) SubmitContainerStateChangeWithContext(ctx aws.Context, input *ecs.SubmitContainerStateChangeInput, opts ...request.Option) (*ecs.SubmitContainerStateChangeOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("SubmitContainerStateChange"); err != nil {
+		return nil, err
+	}
+
+	cluster, err := s.getCluster(input.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.getTask(aws.StringValue(cluster.ClusterName), input.Task)
+	if err != nil {
+		return nil, err
+	}
+	container := findContainer(task, aws.StringValue(input.ContainerName))
+	if container == nil {
+		return nil, invalidParameterException("Container not found: %s", aws.StringValue(input.ContainerName))
+	}
+	container.LastStatus = input.Status
+	container.ExitCode = input.ExitCode
+	container.Reason = input.Reason
+	container.NetworkBindings = input.NetworkBindings
+	return &ecs.SubmitContainerStateChangeOutput{
+		Acknowledgment: aws.String(acknowledgment),
+	}, nil
+}
+
+// taskLaunch holds the parameters of a task being started
+type taskLaunch struct {
+	containerInstanceArn *string
+	launchType           string
+	startedBy            *string
+	group                *string
+	overrides            *ecs.TaskOverride
+	tags                 []*ecs.Tag
+}
+
+// startTask creates a PENDING task from a task definition and returns a copy
+// of it. The lock must be held by the caller.
+func (s *Server) startTask(clusterName string, taskDefinition *ecs.TaskDefinition, launch *taskLaunch) *ecs.Task {
+	id := s.nextID()
+	taskArn := aws.String(s.arn("task/" + clusterName + "/" + id))
+	group := launch.group
+	if aws.StringValue(group) == "" {
+		group = aws.String("family:" + aws.StringValue(taskDefinition.Family))
+	}
+	task := &ecs.Task{
+		TaskArn:              taskArn,
+		ClusterArn:           s.clusters[clusterName].ClusterArn,
+		TaskDefinitionArn:    taskDefinition.TaskDefinitionArn,
+		ContainerInstanceArn: launch.containerInstanceArn,
+		LaunchType:           aws.String(launch.launchType),
+		DesiredStatus:        aws.String(taskStatusRunning),
+		LastStatus:           aws.String(taskStatusPending),
+		StartedBy:            launch.startedBy,
+		Group:                group,
+		Overrides:            launch.overrides,
+		Cpu:                  taskDefinition.Cpu,
+		Memory:               taskDefinition.Memory,
+		CreatedAt:            aws.Time(time.Now()),
+		Version:              aws.Int64(1),
+	}
+	for _, containerDefinition := range taskDefinition.ContainerDefinitions {
+		task.Containers = append(task.Containers, &ecs.Container{
+			ContainerArn: aws.String(s.arn("container/" + s.nextID())),
+			TaskArn:      taskArn,
+			Name:         containerDefinition.Name,
+			LastStatus:   aws.String(taskStatusPending),
+		})
+	}
+	if s.tasks[clusterName] == nil {
+		s.tasks[clusterName] = make(map[string]*ecs.Task)
+	}
+	s.tasks[clusterName][id] = task
+	if len(launch.tags) > 0 {
+		s.tags[aws.StringValue(taskArn)] = copyTagList(launch.tags)
+	}
+	return s.describeTask(task)
+}
+
+// describeTask returns a copy of a task with its tags. The lock must be held
+// by the caller.
+func (s *Server) describeTask(task *ecs.Task) *ecs.Task {
+	described := awsutil.CopyOf(task).(*ecs.Task)
+	described.Tags = s.copyTags(aws.StringValue(task.TaskArn))
+	return described
+}
+
+// activeContainerInstances returns the ACTIVE container instances of a
+// cluster, sorted by ARN. The lock must be held by the caller.
+func (s *Server) activeContainerInstances(clusterName string) []*ecs.ContainerInstance {
+	var instances []*ecs.ContainerInstance
+	for _, instance := range s.containerInstances[clusterName] {
+		if aws.StringValue(instance.Status) == ecs.ContainerInstanceStatusActive {
+			instances = append(instances, instance)
+		}
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return aws.StringValue(instances[i].ContainerInstanceArn) < aws.StringValue(instances[j].ContainerInstanceArn)
+	})
+	return instances
+}
+
+// getTask looks up a task by id or ARN. The lock must be held by the caller.
+func (s *Server) getTask(clusterName string, identifier *string) (*ecs.Task, error) {
+	task, ok := s.tasks[clusterName][resourceName(aws.StringValue(identifier))]
+	if !ok {
+		return nil, invalidParameterException("The referenced task was not found.")
+	}
+	return task, nil
+}
+
+// taskFamily returns the task definition family of a task. The lock must be
+// held by the caller.
+func (s *Server) taskFamily(task *ecs.Task) string {
+	taskDefinition, err := s.getTaskDefinition(aws.StringValue(task.TaskDefinitionArn))
+	if err != nil {
+		return ""
+	}
+	return aws.StringValue(taskDefinition.Family)
+}
+
+func findContainer(task *ecs.Task, name string) *ecs.Container {
+	for _, container := range task.Containers {
+		if aws.StringValue(container.Name) == name {
+			return container
+		}
+	}
+	return nil
+}
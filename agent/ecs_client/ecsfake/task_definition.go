@@ -0,0 +1,240 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const listTaskDefinitionsDefaultMaxResults = 100
+
+// RegisterTaskDefinitionWithContext registers a new revision of a task
+// definition family
+func (s *Server) RegisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.RegisterTaskDefinitionInput, opts ...request.Option) (*ecs.RegisterTaskDefinitionOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("RegisterTaskDefinition"); err != nil {
+		return nil, err
+	}
+
+	family := aws.StringValue(input.Family)
+	revision := int64(len(s.taskDefinitions[family]) + 1)
+	compatibilities := []string{ecs.CompatibilityEc2}
+	for _, compatibility := range aws.StringValueSlice(input.RequiresCompatibilities) {
+		if compatibility == ecs.CompatibilityFargate {
+			compatibilities = append(compatibilities, ecs.CompatibilityFargate)
+		}
+	}
+	registered := awsutil.CopyOf(input).(*ecs.RegisterTaskDefinitionInput)
+	taskDefinition := &ecs.TaskDefinition{
+		TaskDefinitionArn:       aws.String(s.arn(fmt.Sprintf("task-definition/%s:%d", family, revision))),
+		Family:                  aws.String(family),
+		Revision:                aws.Int64(revision),
+		Status:                  aws.String(ecs.TaskDefinitionStatusActive),
+		ContainerDefinitions:    registered.ContainerDefinitions,
+		Cpu:                     registered.Cpu,
+		Memory:                  registered.Memory,
+		ExecutionRoleArn:        registered.ExecutionRoleArn,
+		TaskRoleArn:             registered.TaskRoleArn,
+		NetworkMode:             registered.NetworkMode,
+		IpcMode:                 registered.IpcMode,
+		InferenceAccelerators:   registered.InferenceAccelerators,
+		PidMode:                 registered.PidMode,
+		PlacementConstraints:    registered.PlacementConstraints,
+		RequiresCompatibilities: registered.RequiresCompatibilities,
+		Volumes:                 registered.Volumes,
+		Compatibilities:         aws.StringSlice(compatibilities),
+		RegisteredAt:            aws.Time(time.Now()),
+	}
+	if taskDefinition.NetworkMode == nil {
+		taskDefinition.NetworkMode = aws.String(ecs.NetworkModeBridge)
+	}
+	s.taskDefinitions[family] = append(s.taskDefinitions[family], taskDefinition)
+	if len(input.Tags) > 0 {
+		s.tags[aws.StringValue(taskDefinition.TaskDefinitionArn)] = registered.Tags
+	}
+	return &ecs.RegisterTaskDefinitionOutput{
+		TaskDefinition: awsutil.CopyOf(taskDefinition).(*ecs.TaskDefinition),
+	}, nil
+}
+
+// DeregisterTaskDefinitionWithContext marks a task definition revision as
+// INACTIVE
+func (s *Server) DeregisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.DeregisterTaskDefinitionInput, opts ...request.Option) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DeregisterTaskDefinition"); err != nil {
+		return nil, err
+	}
+
+	taskDefinition, err := s.getTaskDefinition(aws.StringValue(input.TaskDefinition))
+	if err != nil {
+		return nil, err
+	}
+	taskDefinition.Status = aws.String(ecs.TaskDefinitionStatusInactive)
+	if taskDefinition.DeregisteredAt == nil {
+		taskDefinition.DeregisteredAt = aws.Time(time.Now())
+	}
+	return &ecs.DeregisterTaskDefinitionOutput{
+		TaskDefinition: awsutil.CopyOf(taskDefinition).(*ecs.TaskDefinition),
+	}, nil
+}
+
+// DescribeTaskDefinitionWithContext describes a task definition given as a
+// family, a family:revision pair or an ARN
+func (s *Server) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("DescribeTaskDefinition"); err != nil {
+		return nil, err
+	}
+
+	taskDefinition, err := s.getTaskDefinition(aws.StringValue(input.TaskDefinition))
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.DescribeTaskDefinitionOutput{
+		TaskDefinition: awsutil.CopyOf(taskDefinition).(*ecs.TaskDefinition),
+	}, nil
+}
+
+// ListTaskDefinitionFamiliesWithContext lists the task definition families,
+// sorted by name. By default only families with ACTIVE revisions are listed.
+func (s *Server) ListTaskDefinitionFamiliesWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionFamiliesInput, opts ...request.Option) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListTaskDefinitionFamilies"); err != nil {
+		return nil, err
+	}
+
+	status := aws.StringValue(input.Status)
+	if status == "" {
+		status = ecs.TaskDefinitionFamilyStatusActive
+	}
+	var families []string
+	for family, revisions := range s.taskDefinitions {
+		if !strings.HasPrefix(family, aws.StringValue(input.FamilyPrefix)) {
+			continue
+		}
+		active := false
+		for _, revision := range revisions {
+			if aws.StringValue(revision.Status) == ecs.TaskDefinitionStatusActive {
+				active = true
+			}
+		}
+		switch {
+		case status == ecs.TaskDefinitionFamilyStatusActive && !active:
+			continue
+		case status == ecs.TaskDefinitionFamilyStatusInactive && active:
+			continue
+		}
+		families = append(families, family)
+	}
+	sort.Strings(families)
+	page, nextToken, err := pageStrings(families, input.MaxResults, input.NextToken, listTaskDefinitionsDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListTaskDefinitionFamiliesOutput{
+		Families:  page,
+		NextToken: nextToken,
+	}, nil
+}
+
+// ListTaskDefinitionsWithContext lists task definition ARNs, ordered by family
+// and revision. FamilyPrefix selects a single family, as it does in the API.
+func (s *Server) ListTaskDefinitionsWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput, opts ...request.Option) (*ecs.ListTaskDefinitionsOutput, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.injectFault("ListTaskDefinitions"); err != nil {
+		return nil, err
+	}
+
+	status := aws.StringValue(input.Status)
+	if status == "" {
+		status = ecs.TaskDefinitionStatusActive
+	}
+	var families []string
+	for family := range s.taskDefinitions {
+		if input.FamilyPrefix == nil || family == aws.StringValue(input.FamilyPrefix) {
+			families = append(families, family)
+		}
+	}
+	sort.Strings(families)
+
+	var arns []string
+	for _, family := range families {
+		for _, taskDefinition := range s.taskDefinitions[family] {
+			if aws.StringValue(taskDefinition.Status) == status {
+				arns = append(arns, aws.StringValue(taskDefinition.TaskDefinitionArn))
+			}
+		}
+	}
+	if aws.StringValue(input.Sort) == ecs.SortOrderDesc {
+		for i, j := 0, len(arns)-1; i < j; i, j = i+1, j-1 {
+			arns[i], arns[j] = arns[j], arns[i]
+		}
+	}
+	page, nextToken, err := pageStrings(arns, input.MaxResults, input.NextToken, listTaskDefinitionsDefaultMaxResults)
+	if err != nil {
+		return nil, err
+	}
+	return &ecs.ListTaskDefinitionsOutput{
+		TaskDefinitionArns: page,
+		NextToken:          nextToken,
+	}, nil
+}
+
+// getTaskDefinition looks up a task definition by family, family:revision or
+// ARN. A bare family resolves to its latest ACTIVE revision. The lock must be
+// held by the caller.
+func (s *Server) getTaskDefinition(identifier string) (*ecs.TaskDefinition, error) {
+	familyRevision := resourceName(identifier)
+	family := familyRevision
+	revision := int64(0)
+	if i := strings.LastIndex(familyRevision, ":"); i >= 0 {
+		family = familyRevision[:i]
+		parsed, err := strconv.ParseInt(familyRevision[i+1:], 10, 64)
+		if err != nil {
+			return nil, clientException("Invalid revision number. Number: %s", familyRevision[i+1:])
+		}
+		revision = parsed
+	}
+
+	revisions := s.taskDefinitions[family]
+	if revision == 0 {
+		for i := len(revisions) - 1; i >= 0; i-- {
+			if aws.StringValue(revisions[i].Status) == ecs.TaskDefinitionStatusActive {
+				return revisions[i], nil
+			}
+		}
+	} else if revision <= int64(len(revisions)) {
+		return revisions[revision-1], nil
+	}
+	return nil, clientException("Unable to describe task definition.")
+}
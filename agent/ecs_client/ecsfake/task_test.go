@@ -0,0 +1,289 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsfake
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCluster = "cluster"
+	testFamily  = "family"
+)
+
+// setupTaskCluster creates a cluster with one container instance and a task
+// definition to run tasks from
+func setupTaskCluster(t *testing.T, client *ecs.ECS) {
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+	_, err = client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+		Cluster: aws.String(testCluster),
+	})
+	require.NoError(t, err)
+	_, err = client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String(testFamily),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	})
+	require.NoError(t, err)
+}
+
+func TestRunTaskWithTags(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+
+	output, err := client.RunTask(&ecs.RunTaskInput{
+		Cluster:        aws.String(testCluster),
+		TaskDefinition: aws.String(testFamily),
+		Count:          aws.Int64(2),
+		Tags:           testTags(2),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Tasks, 2)
+	for _, task := range output.Tasks {
+		assert.Equal(t, testTags(2), task.Tags)
+	}
+
+	described, err := client.DescribeTasks(&ecs.DescribeTasksInput{
+		Cluster: aws.String(testCluster),
+		Tasks:   []*string{output.Tasks[0].TaskArn},
+	})
+	require.NoError(t, err)
+	require.Len(t, described.Tasks, 1)
+	assert.Equal(t, testTags(2), described.Tasks[0].Tags)
+}
+
+func TestRunTaskWithoutTags(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+
+	output, err := client.RunTask(&ecs.RunTaskInput{
+		Cluster:        aws.String(testCluster),
+		TaskDefinition: aws.String(testFamily),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Tasks, 1)
+	assert.Nil(t, output.Tasks[0].Tags)
+}
+
+func TestRunTaskTagLimit(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+
+	_, err := client.RunTask(&ecs.RunTaskInput{
+		Cluster:        aws.String(testCluster),
+		TaskDefinition: aws.String(testFamily),
+		Tags:           testTags(51),
+	})
+	require.Error(t, err)
+	assert.Equal(t, request.InvalidParameterErrCode, err.(awserr.Error).Code())
+}
+
+func TestRunTaskGroup(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+
+	testCases := []struct {
+		name     string
+		group    *string
+		expected string
+	}{
+		{"unset", nil, "family:" + testFamily},
+		{"empty", aws.String(""), "family:" + testFamily},
+		{"set", aws.String("web"), "web"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := client.RunTask(&ecs.RunTaskInput{
+				Cluster:        aws.String(testCluster),
+				TaskDefinition: aws.String(testFamily),
+				Group:          tc.group,
+			})
+			require.NoError(t, err)
+			require.Len(t, output.Tasks, 1)
+			assert.Equal(t, tc.expected, aws.StringValue(output.Tasks[0].Group))
+
+			described, err := client.DescribeTasks(&ecs.DescribeTasksInput{
+				Cluster: aws.String(testCluster),
+				Tasks:   []*string{output.Tasks[0].TaskArn},
+			})
+			require.NoError(t, err)
+			require.Len(t, described.Tasks, 1)
+			assert.Equal(t, tc.expected, aws.StringValue(described.Tasks[0].Group))
+		})
+	}
+}
+
+func TestStartTaskWithTags(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+	instances, err := client.ListContainerInstances(&ecs.ListContainerInstancesInput{
+		Cluster: aws.String(testCluster),
+	})
+	require.NoError(t, err)
+
+	output, err := client.StartTask(&ecs.StartTaskInput{
+		Cluster:            aws.String(testCluster),
+		TaskDefinition:     aws.String(testFamily),
+		ContainerInstances: instances.ContainerInstanceArns,
+		Tags:               testTags(2),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Tasks, 1)
+	assert.Equal(t, testTags(2), output.Tasks[0].Tags)
+
+	_, err = client.StartTask(&ecs.StartTaskInput{
+		Cluster:            aws.String(testCluster),
+		TaskDefinition:     aws.String(testFamily),
+		ContainerInstances: instances.ContainerInstanceArns,
+		Tags:               testTags(51),
+	})
+	require.Error(t, err)
+	assert.Equal(t, request.InvalidParameterErrCode, err.(awserr.Error).Code())
+}
+
+func TestTaskDefinitionRegistrationTimes(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+
+	described, err := client.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(testFamily),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, described.TaskDefinition.RegisteredAt)
+	assert.Nil(t, described.TaskDefinition.DeregisteredAt)
+
+	deregistered, err := client.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: aws.String(testFamily + ":1"),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, deregistered.TaskDefinition.DeregisteredAt)
+	assert.False(t, deregistered.TaskDefinition.DeregisteredAt.Before(*described.TaskDefinition.RegisteredAt))
+}
+
+func TestRegisterTaskDefinitionInferenceAccelerators(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	accelerators := []*ecs.InferenceAccelerator{{
+		DeviceName: aws.String("device_1"),
+		DeviceType: aws.String("eia1.medium"),
+	}}
+	_, err := client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String(testFamily),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+		InferenceAccelerators: accelerators,
+	})
+	require.NoError(t, err)
+
+	described, err := client.DescribeTaskDefinition(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(testFamily),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, accelerators, described.TaskDefinition.InferenceAccelerators)
+
+	_, err = client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String(testFamily),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+		InferenceAccelerators: []*ecs.InferenceAccelerator{{
+			DeviceName: aws.String(""),
+			DeviceType: aws.String("eia1.medium"),
+		}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, request.InvalidParameterErrCode, err.(awserr.Error).Code())
+}
+
+func TestDescribeTasksLastStatus(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.Client()
+	setupTaskCluster(t, client)
+
+	output, err := client.RunTask(&ecs.RunTaskInput{
+		Cluster:        aws.String(testCluster),
+		TaskDefinition: aws.String(testFamily),
+		Count:          aws.Int64(2),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Tasks, 2)
+	arns := []*string{output.Tasks[0].TaskArn, output.Tasks[1].TaskArn}
+	_, err = client.StopTask(&ecs.StopTaskInput{
+		Cluster: aws.String(testCluster),
+		Task:    arns[1],
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		lastStatus *string
+		expected   []*string
+	}{
+		{"unset", nil, arns},
+		{"pending", aws.String("PENDING"), arns[:1]},
+		{"stopped", aws.String("STOPPED"), arns[1:]},
+		{"running", aws.String("RUNNING"), nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			described, err := client.DescribeTasks(&ecs.DescribeTasksInput{
+				Cluster: aws.String(testCluster),
+				Tasks:   arns,
+			})
+			require.NoError(t, err)
+			assert.Empty(t, described.Failures)
+			tasks := described.Tasks
+			if tc.lastStatus != nil {
+				tasks = ecs.FilterTasksByStatus(tasks, aws.StringValue(tc.lastStatus))
+			}
+			var describedArns []*string
+			for _, task := range tasks {
+				describedArns = append(describedArns, task.TaskArn)
+			}
+			assert.Equal(t, tc.expected, describedArns)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// EventTailer returns the events of a service that it has not returned yet,
+// going by their CreatedAt. DescribeServices only returns the most recent
+// events of a service, so events are missed if the tailer is polled less
+// often than they are emitted. It is safe for concurrent use.
+type EventTailer struct {
+	client  ecs.ECSClientInterface
+	cluster string
+	service string
+
+	lock sync.Mutex
+	// last is the CreatedAt of the newest event returned so far
+	last time.Time
+	// lastIDs are the IDs of the events returned so far created at last, so
+	// that events sharing a timestamp are returned once
+	lastIDs map[string]bool
+}
+
+// NewEventTailer creates an EventTailer for a service of a cluster. Its first
+// Poll returns all the events DescribeServices reports.
+func NewEventTailer(client ecs.ECSClientInterface, cluster, service string) *EventTailer {
+	return &EventTailer{
+		client:  client,
+		cluster: cluster,
+		service: service,
+		lastIDs: make(map[string]bool),
+	}
+}
+
+// Poll describes the service and returns its events newer than the newest
+// event returned by the previous polls, oldest first. Events without a
+// CreatedAt are skipped.
+func (t *EventTailer) Poll(ctx context.Context) ([]*ecs.ServiceEvent, error) {
+	output, err := t.client.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String(t.cluster),
+		Services: aws.StringSlice([]string{t.service}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Services) == 0 {
+		reason := "MISSING"
+		if len(output.Failures) > 0 {
+			reason = aws.StringValue(output.Failures[0].Reason)
+		}
+		return nil, fmt.Errorf("unable to describe service %s: %s", t.service, reason)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var events []*ecs.ServiceEvent
+	for _, event := range output.Services[0].Events {
+		if event == nil || event.CreatedAt == nil {
+			continue
+		}
+		createdAt := *event.CreatedAt
+		if createdAt.Before(t.last) || (createdAt.Equal(t.last) && t.lastIDs[aws.StringValue(event.Id)]) {
+			continue
+		}
+		events = append(events, event)
+	}
+	// DescribeServices returns the newest events first
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(*events[j].CreatedAt)
+	})
+
+	for _, event := range events {
+		if event.CreatedAt.After(t.last) {
+			t.last = *event.CreatedAt
+			t.lastIDs = make(map[string]bool)
+		}
+		t.lastIDs[aws.StringValue(event.Id)] = true
+	}
+	return events, nil
+}
@@ -0,0 +1,127 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceEventsClient describes a service with events, newest first, or
+// returns err when set
+type serviceEventsClient struct {
+	ecs.ECSClientInterface
+	events []*ecs.ServiceEvent
+	err    error
+}
+
+func (c *serviceEventsClient) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if aws.StringValue(input.Services[0]) != "service" {
+		return &ecs.DescribeServicesOutput{
+			Failures: []*ecs.Failure{{Reason: aws.String("MISSING")}},
+		}, nil
+	}
+	return &ecs.DescribeServicesOutput{
+		Services: []*ecs.Service{{ServiceName: aws.String("service"), Events: c.events}},
+	}, nil
+}
+
+// emit adds an event as the newest one
+func (c *serviceEventsClient) emit(id string, createdAt time.Time) {
+	event := &ecs.ServiceEvent{
+		Id:        aws.String(id),
+		CreatedAt: aws.Time(createdAt),
+		Message:   aws.String("(service service) " + id),
+	}
+	c.events = append([]*ecs.ServiceEvent{event}, c.events...)
+}
+
+func eventIDs(events []*ecs.ServiceEvent) []string {
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, aws.StringValue(event.Id))
+	}
+	return ids
+}
+
+func TestEventTailer(t *testing.T) {
+	start := time.Now()
+	client := &serviceEventsClient{}
+	client.emit("1", start)
+	client.emit("2", start.Add(time.Second))
+	tailer := NewEventTailer(client, "cluster", "service")
+
+	events, err := tailer.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, eventIDs(events), "the existing events should be returned oldest first")
+
+	events, err = tailer.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, events, "events seen by the previous poll should not be returned again")
+
+	client.emit("3", start.Add(2*time.Second))
+	client.emit("4", start.Add(3*time.Second))
+	events, err = tailer.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "4"}, eventIDs(events))
+}
+
+func TestEventTailerSameCreatedAt(t *testing.T) {
+	start := time.Now()
+	client := &serviceEventsClient{}
+	client.emit("1", start)
+	tailer := NewEventTailer(client, "cluster", "service")
+
+	events, err := tailer.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, eventIDs(events))
+
+	client.emit("2", start)
+	events, err = tailer.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2"}, eventIDs(events), "an event created at the same time as the last one should be returned once")
+}
+
+func TestEventTailerErrors(t *testing.T) {
+	client := &serviceEventsClient{}
+	client.emit("1", time.Now())
+
+	_, err := NewEventTailer(client, "cluster", "missing").Poll(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MISSING")
+
+	tailer := NewEventTailer(client, "cluster", "service")
+	client.err = errors.New("throttled")
+	_, err = tailer.Poll(context.Background())
+	require.Error(t, err)
+
+	// A failed poll does not mark any event as seen
+	client.err = nil
+	events, err := tailer.Poll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, eventIDs(events))
+}
@@ -0,0 +1,443 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	// LogFieldOperation is the name of the API operation that was called
+	LogFieldOperation = "operation"
+	// LogFieldDuration is the time.Duration the call took
+	LogFieldDuration = "duration"
+	// LogFieldErrorCode is the error code of a failed call. It is not set
+	// for calls that succeed.
+	LogFieldErrorCode = "errorCode"
+
+	unknownErrorCode = "UnknownError"
+)
+
+// summaryFields are the input fields that are logged by LoggingECS, under
+// the name they have in the API. Only fields identifying the resources acted
+// on are listed, so that no secret material, such as RepositoryCredentials or
+// Secrets, ever makes it to the logs.
+var summaryFields = []string{
+	"Cluster",
+	"ClusterName",
+	"ContainerInstance",
+	"ContainerInstanceArn",
+	"Family",
+	"ResourceArn",
+	"Service",
+	"ServiceName",
+	"Task",
+	"TaskDefinition",
+}
+
+// Logger receives one structured entry per call made through LoggingECS
+type Logger interface {
+	Log(fields map[string]interface{})
+}
+
+// LoggingECS wraps an ecs.ECSClientInterface and logs a structured entry for
+// every call made through it, with the operation name, a summary of the
+// input, the duration of the call and the error code, if any.
+type LoggingECS struct {
+	client ecs.ECSClientInterface
+	logger Logger
+}
+
+var _ ecs.ECSClientInterface = (*LoggingECS)(nil)
+
+// NewLoggingECS creates a LoggingECS that logs the calls made to client to
+// logger
+func NewLoggingECS(client ecs.ECSClientInterface, logger Logger) *LoggingECS {
+	return &LoggingECS{
+		client: client,
+		logger: logger,
+	}
+}
+
+// log emits the entry for a call to operation that started at start
+func (l *LoggingECS) log(operation string, input interface{}, start time.Time, err error) {
+	fields := summarizeInput(input)
+	fields[LogFieldOperation] = operation
+	fields[LogFieldDuration] = time.Since(start)
+	if err != nil {
+		fields[LogFieldErrorCode] = errorCode(err)
+	}
+	l.logger.Log(fields)
+}
+
+// summarizeInput returns the summaryFields set in input, keyed by their name
+// in the API
+func summarizeInput(input interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	value := reflect.ValueOf(input)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fields
+	}
+	value = value.Elem()
+	for _, name := range summaryFields {
+		structField, ok := value.Type().FieldByName(name)
+		if !ok {
+			continue
+		}
+		field, ok := value.FieldByIndex(structField.Index).Interface().(*string)
+		if !ok || field == nil {
+			continue
+		}
+		key := structField.Tag.Get("locationName")
+		if key == "" {
+			key = name
+		}
+		fields[key] = aws.StringValue(field)
+	}
+	return fields
+}
+
+func errorCode(err error) string {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code()
+	}
+	return unknownErrorCode
+}
+
+// CreateClusterWithContext calls CreateClusterWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) CreateClusterWithContext(ctx aws.Context, input *ecs.CreateClusterInput, opts ...request.Option) (*ecs.CreateClusterOutput, error) {
+	start := time.Now()
+	output, err := l.client.CreateClusterWithContext(ctx, input, opts...)
+	l.log("CreateCluster", input, start, err)
+	return output, err
+}
+
+// CreateServiceWithContext calls CreateServiceWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) CreateServiceWithContext(ctx aws.Context, input *ecs.CreateServiceInput, opts ...request.Option) (*ecs.CreateServiceOutput, error) {
+	start := time.Now()
+	output, err := l.client.CreateServiceWithContext(ctx, input, opts...)
+	l.log("CreateService", input, start, err)
+	return output, err
+}
+
+// DeleteAccountSettingWithContext calls DeleteAccountSettingWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DeleteAccountSettingWithContext(ctx aws.Context, input *ecs.DeleteAccountSettingInput, opts ...request.Option) (*ecs.DeleteAccountSettingOutput, error) {
+	start := time.Now()
+	output, err := l.client.DeleteAccountSettingWithContext(ctx, input, opts...)
+	l.log("DeleteAccountSetting", input, start, err)
+	return output, err
+}
+
+// DeleteAttributesWithContext calls DeleteAttributesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DeleteAttributesWithContext(ctx aws.Context, input *ecs.DeleteAttributesInput, opts ...request.Option) (*ecs.DeleteAttributesOutput, error) {
+	start := time.Now()
+	output, err := l.client.DeleteAttributesWithContext(ctx, input, opts...)
+	l.log("DeleteAttributes", input, start, err)
+	return output, err
+}
+
+// DeleteClusterWithContext calls DeleteClusterWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DeleteClusterWithContext(ctx aws.Context, input *ecs.DeleteClusterInput, opts ...request.Option) (*ecs.DeleteClusterOutput, error) {
+	start := time.Now()
+	output, err := l.client.DeleteClusterWithContext(ctx, input, opts...)
+	l.log("DeleteCluster", input, start, err)
+	return output, err
+}
+
+// DeleteServiceWithContext calls DeleteServiceWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DeleteServiceWithContext(ctx aws.Context, input *ecs.DeleteServiceInput, opts ...request.Option) (*ecs.DeleteServiceOutput, error) {
+	start := time.Now()
+	output, err := l.client.DeleteServiceWithContext(ctx, input, opts...)
+	l.log("DeleteService", input, start, err)
+	return output, err
+}
+
+// DeregisterContainerInstanceWithContext calls DeregisterContainerInstanceWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DeregisterContainerInstanceWithContext(ctx aws.Context, input *ecs.DeregisterContainerInstanceInput, opts ...request.Option) (*ecs.DeregisterContainerInstanceOutput, error) {
+	start := time.Now()
+	output, err := l.client.DeregisterContainerInstanceWithContext(ctx, input, opts...)
+	l.log("DeregisterContainerInstance", input, start, err)
+	return output, err
+}
+
+// DeregisterTaskDefinitionWithContext calls DeregisterTaskDefinitionWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DeregisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.DeregisterTaskDefinitionInput, opts ...request.Option) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	start := time.Now()
+	output, err := l.client.DeregisterTaskDefinitionWithContext(ctx, input, opts...)
+	l.log("DeregisterTaskDefinition", input, start, err)
+	return output, err
+}
+
+// DescribeClustersWithContext calls DescribeClustersWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput, opts ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	start := time.Now()
+	output, err := l.client.DescribeClustersWithContext(ctx, input, opts...)
+	l.log("DescribeClusters", input, start, err)
+	return output, err
+}
+
+// DescribeContainerInstancesWithContext calls DescribeContainerInstancesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DescribeContainerInstancesWithContext(ctx aws.Context, input *ecs.DescribeContainerInstancesInput, opts ...request.Option) (*ecs.DescribeContainerInstancesOutput, error) {
+	start := time.Now()
+	output, err := l.client.DescribeContainerInstancesWithContext(ctx, input, opts...)
+	l.log("DescribeContainerInstances", input, start, err)
+	return output, err
+}
+
+// DescribeServicesWithContext calls DescribeServicesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	start := time.Now()
+	output, err := l.client.DescribeServicesWithContext(ctx, input, opts...)
+	l.log("DescribeServices", input, start, err)
+	return output, err
+}
+
+// DescribeTaskDefinitionWithContext calls DescribeTaskDefinitionWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	start := time.Now()
+	output, err := l.client.DescribeTaskDefinitionWithContext(ctx, input, opts...)
+	l.log("DescribeTaskDefinition", input, start, err)
+	return output, err
+}
+
+// DescribeTasksWithContext calls DescribeTasksWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	start := time.Now()
+	output, err := l.client.DescribeTasksWithContext(ctx, input, opts...)
+	l.log("DescribeTasks", input, start, err)
+	return output, err
+}
+
+// DiscoverPollEndpointWithContext calls DiscoverPollEndpointWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) DiscoverPollEndpointWithContext(ctx aws.Context, input *ecs.DiscoverPollEndpointInput, opts ...request.Option) (*ecs.DiscoverPollEndpointOutput, error) {
+	start := time.Now()
+	output, err := l.client.DiscoverPollEndpointWithContext(ctx, input, opts...)
+	l.log("DiscoverPollEndpoint", input, start, err)
+	return output, err
+}
+
+// ListAccountSettingsWithContext calls ListAccountSettingsWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListAccountSettingsWithContext(ctx aws.Context, input *ecs.ListAccountSettingsInput, opts ...request.Option) (*ecs.ListAccountSettingsOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListAccountSettingsWithContext(ctx, input, opts...)
+	l.log("ListAccountSettings", input, start, err)
+	return output, err
+}
+
+// ListAttributesWithContext calls ListAttributesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListAttributesWithContext(ctx aws.Context, input *ecs.ListAttributesInput, opts ...request.Option) (*ecs.ListAttributesOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListAttributesWithContext(ctx, input, opts...)
+	l.log("ListAttributes", input, start, err)
+	return output, err
+}
+
+// ListClustersWithContext calls ListClustersWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListClustersWithContext(ctx, input, opts...)
+	l.log("ListClusters", input, start, err)
+	return output, err
+}
+
+// ListContainerInstancesWithContext calls ListContainerInstancesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListContainerInstancesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput, opts ...request.Option) (*ecs.ListContainerInstancesOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListContainerInstancesWithContext(ctx, input, opts...)
+	l.log("ListContainerInstances", input, start, err)
+	return output, err
+}
+
+// ListServicesWithContext calls ListServicesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput, opts ...request.Option) (*ecs.ListServicesOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListServicesWithContext(ctx, input, opts...)
+	l.log("ListServices", input, start, err)
+	return output, err
+}
+
+// ListTagsForResourceWithContext calls ListTagsForResourceWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListTagsForResourceWithContext(ctx aws.Context, input *ecs.ListTagsForResourceInput, opts ...request.Option) (*ecs.ListTagsForResourceOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListTagsForResourceWithContext(ctx, input, opts...)
+	l.log("ListTagsForResource", input, start, err)
+	return output, err
+}
+
+// ListTaskDefinitionFamiliesWithContext calls ListTaskDefinitionFamiliesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListTaskDefinitionFamiliesWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionFamiliesInput, opts ...request.Option) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListTaskDefinitionFamiliesWithContext(ctx, input, opts...)
+	l.log("ListTaskDefinitionFamilies", input, start, err)
+	return output, err
+}
+
+// ListTaskDefinitionsWithContext calls ListTaskDefinitionsWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListTaskDefinitionsWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput, opts ...request.Option) (*ecs.ListTaskDefinitionsOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListTaskDefinitionsWithContext(ctx, input, opts...)
+	l.log("ListTaskDefinitions", input, start, err)
+	return output, err
+}
+
+// ListTasksWithContext calls ListTasksWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) ListTasksWithContext(ctx aws.Context, input *ecs.ListTasksInput, opts ...request.Option) (*ecs.ListTasksOutput, error) {
+	start := time.Now()
+	output, err := l.client.ListTasksWithContext(ctx, input, opts...)
+	l.log("ListTasks", input, start, err)
+	return output, err
+}
+
+// PutAccountSettingWithContext calls PutAccountSettingWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) PutAccountSettingWithContext(ctx aws.Context, input *ecs.PutAccountSettingInput, opts ...request.Option) (*ecs.PutAccountSettingOutput, error) {
+	start := time.Now()
+	output, err := l.client.PutAccountSettingWithContext(ctx, input, opts...)
+	l.log("PutAccountSetting", input, start, err)
+	return output, err
+}
+
+// PutAttributesWithContext calls PutAttributesWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) PutAttributesWithContext(ctx aws.Context, input *ecs.PutAttributesInput, opts ...request.Option) (*ecs.PutAttributesOutput, error) {
+	start := time.Now()
+	output, err := l.client.PutAttributesWithContext(ctx, input, opts...)
+	l.log("PutAttributes", input, start, err)
+	return output, err
+}
+
+// RegisterContainerInstanceWithContext calls RegisterContainerInstanceWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) RegisterContainerInstanceWithContext(ctx aws.Context, input *ecs.RegisterContainerInstanceInput, opts ...request.Option) (*ecs.RegisterContainerInstanceOutput, error) {
+	start := time.Now()
+	output, err := l.client.RegisterContainerInstanceWithContext(ctx, input, opts...)
+	l.log("RegisterContainerInstance", input, start, err)
+	return output, err
+}
+
+// RegisterTaskDefinitionWithContext calls RegisterTaskDefinitionWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) RegisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.RegisterTaskDefinitionInput, opts ...request.Option) (*ecs.RegisterTaskDefinitionOutput, error) {
+	start := time.Now()
+	output, err := l.client.RegisterTaskDefinitionWithContext(ctx, input, opts...)
+	l.log("RegisterTaskDefinition", input, start, err)
+	return output, err
+}
+
+// RunTaskBatch makes the RunTask calls of the batch through the LoggingECS, so
+// that each of them is logged
+func (l *LoggingECS) RunTaskBatch(ctx aws.Context, input *ecs.RunTaskInput, count int) ([]*ecs.Task, []*ecs.Failure, error) {
+	return ecs.RunTaskInChunks(ctx, l, input, count)
+}
+
+// RunTaskWithContext calls RunTaskWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) RunTaskWithContext(ctx aws.Context, input *ecs.RunTaskInput, opts ...request.Option) (*ecs.RunTaskOutput, error) {
+	start := time.Now()
+	output, err := l.client.RunTaskWithContext(ctx, input, opts...)
+	l.log("RunTask", input, start, err)
+	return output, err
+}
+
+// StartTaskWithContext calls StartTaskWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) StartTaskWithContext(ctx aws.Context, input *ecs.StartTaskInput, opts ...request.Option) (*ecs.StartTaskOutput, error) {
+	start := time.Now()
+	output, err := l.client.StartTaskWithContext(ctx, input, opts...)
+	l.log("StartTask", input, start, err)
+	return output, err
+}
+
+// StopTaskWithContext calls StopTaskWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) StopTaskWithContext(ctx aws.Context, input *ecs.StopTaskInput, opts ...request.Option) (*ecs.StopTaskOutput, error) {
+	start := time.Now()
+	output, err := l.client.StopTaskWithContext(ctx, input, opts...)
+	l.log("StopTask", input, start, err)
+	return output, err
+}
+
+// SubmitContainerStateChangeWithContext calls SubmitContainerStateChangeWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) SubmitContainerStateChangeWithContext(ctx aws.Context, input *ecs.SubmitContainerStateChangeInput, opts ...request.Option) (*ecs.SubmitContainerStateChangeOutput, error) {
+	start := time.Now()
+	output, err := l.client.SubmitContainerStateChangeWithContext(ctx, input, opts...)
+	l.log("SubmitContainerStateChange", input, start, err)
+	return output, err
+}
+
+// SubmitTaskStateChangeWithContext calls SubmitTaskStateChangeWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	start := time.Now()
+	output, err := l.client.SubmitTaskStateChangeWithContext(ctx, input, opts...)
+	l.log("SubmitTaskStateChange", input, start, err)
+	return output, err
+}
+
+// UpdateContainerAgentWithContext calls UpdateContainerAgentWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) UpdateContainerAgentWithContext(ctx aws.Context, input *ecs.UpdateContainerAgentInput, opts ...request.Option) (*ecs.UpdateContainerAgentOutput, error) {
+	start := time.Now()
+	output, err := l.client.UpdateContainerAgentWithContext(ctx, input, opts...)
+	l.log("UpdateContainerAgent", input, start, err)
+	return output, err
+}
+
+// UpdateContainerInstancesStateWithContext calls UpdateContainerInstancesStateWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) UpdateContainerInstancesStateWithContext(ctx aws.Context, input *ecs.UpdateContainerInstancesStateInput, opts ...request.Option) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	start := time.Now()
+	output, err := l.client.UpdateContainerInstancesStateWithContext(ctx, input, opts...)
+	l.log("UpdateContainerInstancesState", input, start, err)
+	return output, err
+}
+
+// UpdateServiceWithContext calls UpdateServiceWithContext on the wrapped client and logs the
+// call
+func (l *LoggingECS) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput, opts ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	start := time.Now()
+	output, err := l.client.UpdateServiceWithContext(ctx, input, opts...)
+	l.log("UpdateService", input, start, err)
+	return output, err
+}
@@ -0,0 +1,103 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/ecsfake"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingLogger struct {
+	entries []map[string]interface{}
+}
+
+func (l *recordingLogger) Log(fields map[string]interface{}) {
+	l.entries = append(l.entries, fields)
+}
+
+func TestLoggingECSLogsCall(t *testing.T) {
+	logger := &recordingLogger{}
+	client := NewLoggingECS(ecsfake.NewServer(), logger)
+
+	_, err := client.CreateClusterWithContext(aws.BackgroundContext(), &ecs.CreateClusterInput{
+		ClusterName: aws.String("cluster"),
+	})
+	require.NoError(t, err)
+	_, err = client.StopTaskWithContext(aws.BackgroundContext(), &ecs.StopTaskInput{
+		Cluster: aws.String("cluster"),
+		Task:    aws.String("arn:aws:ecs:us-west-2:123456789012:task/cluster/missing"),
+		Reason:  aws.String("not logged"),
+	})
+	require.Error(t, err)
+
+	require.Len(t, logger.entries, 2)
+	created := logger.entries[0]
+	assert.Equal(t, "CreateCluster", created[LogFieldOperation])
+	assert.Equal(t, "cluster", created["clusterName"])
+	assert.IsType(t, time.Duration(0), created[LogFieldDuration])
+	assert.NotContains(t, created, LogFieldErrorCode)
+
+	stopped := logger.entries[1]
+	assert.Equal(t, "StopTask", stopped[LogFieldOperation])
+	assert.Equal(t, "cluster", stopped["cluster"])
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:task/cluster/missing", stopped["task"])
+	assert.Equal(t, ecs.ErrCodeInvalidParameterException, stopped[LogFieldErrorCode])
+	assert.NotContains(t, stopped, "reason")
+}
+
+func TestLoggingECSDoesNotLogSecrets(t *testing.T) {
+	logger := &recordingLogger{}
+	client := NewLoggingECS(ecsfake.NewServer(), logger)
+
+	_, err := client.RegisterTaskDefinitionWithContext(aws.BackgroundContext(), &ecs.RegisterTaskDefinitionInput{
+		Family: aws.String("family"),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("registry.example.com/image"),
+			RepositoryCredentials: &ecs.RepositoryCredentials{
+				CredentialsParameter: aws.String("repository-credentials-secret"),
+			},
+			Secrets: []*ecs.Secret{{
+				Name:      aws.String("PASSWORD"),
+				ValueFrom: aws.String("password-secret"),
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, logger.entries, 1)
+	entry := fmt.Sprint(logger.entries[0])
+	assert.Equal(t, "family", logger.entries[0]["family"])
+	assert.NotContains(t, entry, "repository-credentials-secret")
+	assert.NotContains(t, entry, "password-secret")
+}
+
+func TestLoggingECSUnknownErrorCode(t *testing.T) {
+	assert.Equal(t, unknownErrorCode, errorCode(fmt.Errorf("not an aws error")))
+}
+
+func TestLoggingECSInterfaceCompliance(t *testing.T) {
+	logger := &recordingLogger{}
+	ecsfake.TestInterfaceCompliance(t, NewLoggingECS(ecsfake.NewServer(), logger))
+	assert.NotEmpty(t, logger.entries)
+}
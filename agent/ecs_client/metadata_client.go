@@ -0,0 +1,169 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/amazon-ecs-agent/agent/handlers/v2"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+const (
+	// TaskMetadataEndpointV4EnvVar is the environment variable holding the
+	// URI of the version 4 task metadata endpoint in each container
+	TaskMetadataEndpointV4EnvVar = "ECS_CONTAINER_METADATA_URI_V4"
+
+	defaultTaskMetadataTimeout = 5 * time.Second
+	taskMetadataPath           = "/task"
+)
+
+// TaskFromMetadata returns the task metadata as an ecs.Task, as
+// DescribeTasks would describe it. Only the fields known to both are set.
+func TaskFromMetadata(metadata *v2.TaskResponse) *ecs.Task {
+	task := &ecs.Task{
+		ClusterArn:         aws.String(metadata.Cluster),
+		TaskArn:            aws.String(metadata.TaskARN),
+		LastStatus:         aws.String(metadata.KnownStatus),
+		PullStartedAt:      metadata.PullStartedAt,
+		PullStoppedAt:      metadata.PullStoppedAt,
+		ExecutionStoppedAt: metadata.ExecutionStoppedAt,
+	}
+	if metadata.DesiredStatus != "" {
+		task.DesiredStatus = aws.String(metadata.DesiredStatus)
+	}
+	if metadata.LaunchType != "" {
+		task.LaunchType = aws.String(metadata.LaunchType)
+	}
+	// Task definition ARNs share the prefix of task ARNs
+	if i := strings.Index(metadata.TaskARN, ":task/"); i >= 0 && metadata.Family != "" {
+		task.TaskDefinitionArn = aws.String(fmt.Sprintf("%s:task-definition/%s:%s",
+			metadata.TaskARN[:i], metadata.Family, metadata.Revision))
+	}
+	for i := range metadata.Containers {
+		task.Containers = append(task.Containers, ContainerFromMetadata(&metadata.Containers[i], metadata.TaskARN))
+	}
+	return task
+}
+
+// ContainerFromMetadata returns the container metadata as an ecs.Container
+// of the task taskArn. Only the fields known to both are set.
+func ContainerFromMetadata(metadata *v2.ContainerResponse, taskArn string) *ecs.Container {
+	container := &ecs.Container{
+		Name:       aws.String(metadata.Name),
+		TaskArn:    aws.String(taskArn),
+		LastStatus: aws.String(metadata.KnownStatus),
+	}
+	if metadata.ExitCode != nil {
+		container.ExitCode = aws.Int64(int64(*metadata.ExitCode))
+	}
+	if metadata.ContainerARN != "" {
+		container.ContainerArn = aws.String(metadata.ContainerARN)
+	}
+	for _, network := range metadata.Networks {
+		for _, address := range network.IPv4Addresses {
+			container.NetworkInterfaces = append(container.NetworkInterfaces, &ecs.NetworkInterface{
+				PrivateIpv4Address: aws.String(address),
+			})
+		}
+	}
+	return container
+}
+
+// TaskMetadataClient reads the version 4 task metadata endpoint, which the
+// agent exposes to the containers of the tasks it runs
+type TaskMetadataClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewTaskMetadataClient creates a TaskMetadataClient for the endpoint URI. A
+// client with a short timeout is used if httpClient is nil.
+func NewTaskMetadataClient(endpoint string, httpClient *http.Client) *TaskMetadataClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTaskMetadataTimeout}
+	}
+	return &TaskMetadataClient{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// NewTaskMetadataClientFromEnv creates a TaskMetadataClient for the endpoint
+// given by TaskMetadataEndpointV4EnvVar, which is only set inside containers
+// run by the agent
+func NewTaskMetadataClientFromEnv() (*TaskMetadataClient, error) {
+	endpoint := os.Getenv(TaskMetadataEndpointV4EnvVar)
+	if endpoint == "" {
+		return nil, fmt.Errorf("task metadata: %s is not set", TaskMetadataEndpointV4EnvVar)
+	}
+	return NewTaskMetadataClient(endpoint, nil), nil
+}
+
+// GetTaskMetadata returns the metadata of the task, including all of its
+// containers
+func (c *TaskMetadataClient) GetTaskMetadata(ctx aws.Context) (*v2.TaskResponse, error) {
+	metadata := &v2.TaskResponse{}
+	if err := c.get(ctx, c.endpoint+taskMetadataPath, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// GetContainerMetadata returns the metadata of the container of the task
+// named containerName, as it is named in the task definition
+func (c *TaskMetadataClient) GetContainerMetadata(ctx aws.Context, containerName string) (*v2.ContainerResponse, error) {
+	metadata, err := c.GetTaskMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range metadata.Containers {
+		if metadata.Containers[i].Name == containerName {
+			return &metadata.Containers[i], nil
+		}
+	}
+	return nil, fmt.Errorf("task metadata: no container named %s in task %s", containerName, metadata.TaskARN)
+}
+
+// get decodes the JSON document at uri into v
+func (c *TaskMetadataClient) get(ctx aws.Context, uri string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("task metadata: unable to get %s: %v", uri, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("task metadata: unable to read the response from %s: %v", uri, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("task metadata: unexpected status %d from %s: %s", resp.StatusCode, uri, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("task metadata: unable to parse the response from %s: %v", uri, err)
+	}
+	return nil
+}
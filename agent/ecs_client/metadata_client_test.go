@@ -0,0 +1,175 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTaskMetadata is a task metadata document as the version 4 endpoint
+// serves it for an awsvpc task
+const testTaskMetadata = `{
+  "Cluster": "arn:aws:ecs:us-west-2:123456789012:cluster/default",
+  "TaskARN": "arn:aws:ecs:us-west-2:123456789012:task/default/158d1c8083dd49d6b527399fd6414f5c",
+  "Family": "curltest",
+  "Revision": "26",
+  "DesiredStatus": "RUNNING",
+  "KnownStatus": "RUNNING",
+  "Limits": {"CPU": 0.25, "Memory": 512},
+  "PullStartedAt": "2020-10-02T00:43:06.202617438Z",
+  "PullStoppedAt": "2020-10-02T00:43:06.31288465Z",
+  "AvailabilityZone": "us-west-2d",
+  "LaunchType": "FARGATE",
+  "Containers": [
+    {
+      "DockerId": "598cba581fe3f939459eaba1e071d5c93bb2c49b7d1ba7db6bb19deeb70d8e38",
+      "Name": "~internal~ecs~pause",
+      "DockerName": "ecs-curltest-26-internalecspause-e292d586b6f9dade4a00",
+      "Image": "amazon/amazon-ecs-pause:0.1.0",
+      "ImageID": "",
+      "DesiredStatus": "RESOURCES_PROVISIONED",
+      "KnownStatus": "RESOURCES_PROVISIONED",
+      "Type": "CNI_PAUSE"
+    },
+    {
+      "DockerId": "ee08638adaaf009d78c248913f629e38299471d45fe7dc944d1039077e3424ca",
+      "Name": "curl",
+      "DockerName": "ecs-curltest-26-curl-a0e7dba5aca6d8cb2e00",
+      "Image": "111122223333.dkr.ecr.us-west-2.amazonaws.com/curltest:latest",
+      "ImageID": "sha256:d691691e9652791a60114e67b365688d20d19940dde7c4736ea30e660d8d3553",
+      "Labels": {"com.amazonaws.ecs.container-name": "curl"},
+      "DesiredStatus": "RUNNING",
+      "KnownStatus": "RUNNING",
+      "ExitCode": 0,
+      "Limits": {"CPU": 10, "Memory": 128},
+      "CreatedAt": "2020-10-02T00:15:07.620912337Z",
+      "StartedAt": "2020-10-02T00:15:08.062559351Z",
+      "Type": "NORMAL",
+      "ContainerARN": "arn:aws:ecs:us-west-2:123456789012:container/0206b271-b33f-47ab-86c6-a0ba208a70a9",
+      "Networks": [
+        {"NetworkMode": "awsvpc", "IPv4Addresses": ["10.0.2.106"]}
+      ]
+    }
+  ]
+}`
+
+func newTestTaskMetadataServer(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/task" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestGetTaskMetadata(t *testing.T) {
+	server := newTestTaskMetadataServer(http.StatusOK, testTaskMetadata)
+	defer server.Close()
+
+	metadata, err := NewTaskMetadataClient(server.URL, nil).GetTaskMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "curltest", metadata.Family)
+	assert.Equal(t, "26", metadata.Revision)
+	assert.Equal(t, 0.25, aws.Float64Value(metadata.Limits.CPU))
+	assert.Equal(t, int64(512), aws.Int64Value(metadata.Limits.Memory))
+	require.Len(t, metadata.Containers, 2)
+	assert.Equal(t, []string{"10.0.2.106"}, metadata.Containers[1].Networks[0].IPv4Addresses)
+
+	task := TaskFromMetadata(metadata)
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:cluster/default", aws.StringValue(task.ClusterArn))
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:task-definition/curltest:26", aws.StringValue(task.TaskDefinitionArn))
+	assert.Equal(t, "RUNNING", aws.StringValue(task.LastStatus))
+	assert.Equal(t, "FARGATE", aws.StringValue(task.LaunchType))
+	require.NotNil(t, task.PullStartedAt)
+	assert.Equal(t, int64(1601599386), task.PullStartedAt.Unix())
+	require.Len(t, task.Containers, 2)
+	assert.Equal(t, metadata.TaskARN, aws.StringValue(task.Containers[1].TaskArn))
+	assert.Equal(t, "10.0.2.106", aws.StringValue(task.Containers[1].NetworkInterfaces[0].PrivateIpv4Address))
+}
+
+func TestGetContainerMetadata(t *testing.T) {
+	server := newTestTaskMetadataServer(http.StatusOK, testTaskMetadata)
+	defer server.Close()
+	client := NewTaskMetadataClient(server.URL+"/", nil)
+
+	metadata, err := client.GetContainerMetadata(context.Background(), "curl")
+	require.NoError(t, err)
+	assert.Equal(t, "ee08638adaaf009d78c248913f629e38299471d45fe7dc944d1039077e3424ca", metadata.ID)
+	assert.Equal(t, "curl", metadata.Labels["com.amazonaws.ecs.container-name"])
+	require.NotNil(t, metadata.ExitCode)
+	assert.Equal(t, 0, *metadata.ExitCode)
+
+	container := ContainerFromMetadata(metadata, "arn:aws:ecs:us-west-2:123456789012:task/default/158d1c8083dd49d6b527399fd6414f5c")
+	assert.Equal(t, "curl", aws.StringValue(container.Name))
+	assert.Equal(t, metadata.ContainerARN, aws.StringValue(container.ContainerArn))
+
+	_, err = client.GetContainerMetadata(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestGetTaskMetadataErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		status int
+		body   string
+	}{
+		"server error": {http.StatusInternalServerError, "internal error"},
+		"bad json":     {http.StatusOK, "{"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := newTestTaskMetadataServer(tc.status, tc.body)
+			defer server.Close()
+
+			_, err := NewTaskMetadataClient(server.URL, nil).GetTaskMetadata(context.Background())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGetTaskMetadataContextCanceled(t *testing.T) {
+	server := newTestTaskMetadataServer(http.StatusOK, testTaskMetadata)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewTaskMetadataClient(server.URL, nil).GetTaskMetadata(ctx)
+	assert.Error(t, err)
+}
+
+func TestNewTaskMetadataClientFromEnv(t *testing.T) {
+	server := newTestTaskMetadataServer(http.StatusOK, testTaskMetadata)
+	defer server.Close()
+
+	os.Setenv(TaskMetadataEndpointV4EnvVar, "")
+	_, err := NewTaskMetadataClientFromEnv()
+	assert.Error(t, err)
+
+	os.Setenv(TaskMetadataEndpointV4EnvVar, server.URL)
+	defer os.Unsetenv(TaskMetadataEndpointV4EnvVar)
+	client, err := NewTaskMetadataClientFromEnv()
+	require.NoError(t, err)
+	_, err = client.GetTaskMetadata(context.Background())
+	assert.NoError(t, err)
+}
@@ -1389,6 +1389,143 @@ func (c *ECS) DiscoverPollEndpointWithContext(ctx aws.Context, input *DiscoverPo
 	return out, req.Send()
 }
 
+const opListAccountSettings = "ListAccountSettings"
+
+// ListAccountSettingsRequest generates a "aws/request.Request" representing the
+// client's request for the ListAccountSettings operation. The "output" return
+// value will be populated with the request's response once the request completes
+// successfully.
+//
+// Use "Send" method on the returned Request to send the API call to the service.
+// the "output" return value is not valid until after Send returns without error.
+//
+// See ListAccountSettings for more information on using the ListAccountSettings
+// API call, and error handling.
+//
+// This method is useful when you want to inject custom logic or configuration
+// into the SDK's request lifecycle. Such as custom headers, or retry logic.
+//
+//	// Example sending a request using the ListAccountSettingsRequest method.
+//	req, resp := client.ListAccountSettingsRequest(params)
+//
+//	err := req.Send()
+//	if err == nil { // resp is now filled
+//	    fmt.Println(resp)
+//	}
+func (c *ECS) ListAccountSettingsRequest(input *ListAccountSettingsInput) (req *request.Request, output *ListAccountSettingsOutput) {
+	op := &request.Operation{
+		Name:       opListAccountSettings,
+		HTTPMethod: "POST",
+		HTTPPath:   "/",
+		Paginator: &request.Paginator{
+			InputTokens:     []string{"nextToken"},
+			OutputTokens:    []string{"nextToken"},
+			LimitToken:      "maxResults",
+			TruncationToken: "",
+		},
+	}
+
+	if input == nil {
+		input = &ListAccountSettingsInput{}
+	}
+
+	output = &ListAccountSettingsOutput{}
+	req = c.newRequest(op, input, output)
+	return
+}
+
+// ListAccountSettings API operation for Amazon EC2 Container Service.
+//
+// Returns awserr.Error for service API and SDK errors. Use runtime type assertions
+// with awserr.Error's Code and Message methods to get detailed information about
+// the error.
+//
+// See the AWS API reference guide for Amazon EC2 Container Service's
+// API operation ListAccountSettings for usage and error information.
+//
+// Returned Error Codes:
+//
+//   - ErrCodeServerException "ServerException"
+//     These errors are usually caused by a server issue.
+//
+//   - ErrCodeClientException "ClientException"
+//     These errors are usually caused by a client action, such as using an action
+//     or resource on behalf of a user that doesn't have permissions to use the
+//     action or resource, or specifying an identifier that is not valid.
+//
+//   - ErrCodeInvalidParameterException "InvalidParameterException"
+//     The specified parameter is invalid. Review the available parameters for the
+//     API request.
+func (c *ECS) ListAccountSettings(input *ListAccountSettingsInput) (*ListAccountSettingsOutput, error) {
+	req, out := c.ListAccountSettingsRequest(input)
+	return out, req.Send()
+}
+
+// ListAccountSettingsWithContext is the same as ListAccountSettings with the addition of
+// the ability to pass a context and additional request options.
+//
+// See ListAccountSettings for details on how to use this API operation.
+//
+// The context must be non-nil and will be used for request cancellation. If
+// the context is nil a panic will occur. In the future the SDK may create
+// sub-contexts for http.Requests. See https://golang.org/pkg/context/
+// for more information on using Contexts.
+func (c *ECS) ListAccountSettingsWithContext(ctx aws.Context, input *ListAccountSettingsInput, opts ...request.Option) (*ListAccountSettingsOutput, error) {
+	req, out := c.ListAccountSettingsRequest(input)
+	req.SetContext(ctx)
+	req.ApplyOptions(opts...)
+	return out, req.Send()
+}
+
+// ListAccountSettingsPages iterates over the pages of a ListAccountSettings operation,
+// calling the "fn" function with the response data for each page. To stop
+// iterating, return false from the fn function.
+//
+// See ListAccountSettings method for more information on how to use this operation.
+//
+// Note: This operation can generate multiple requests to a service.
+//
+//	// Example iterating over at most 3 pages of a ListAccountSettings operation.
+//	pageNum := 0
+//	err := client.ListAccountSettingsPages(params,
+//	    func(page *ListAccountSettingsOutput, lastPage bool) bool {
+//	        pageNum++
+//	        fmt.Println(page)
+//	        return pageNum <= 3
+//	    })
+func (c *ECS) ListAccountSettingsPages(input *ListAccountSettingsInput, fn func(*ListAccountSettingsOutput, bool) bool) error {
+	return c.ListAccountSettingsPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+// ListAccountSettingsPagesWithContext same as ListAccountSettingsPages except
+// it takes a Context and allows setting request options on the pages.
+//
+// The context must be non-nil and will be used for request cancellation. If
+// the context is nil a panic will occur. In the future the SDK may create
+// sub-contexts for http.Requests. See https://golang.org/pkg/context/
+// for more information on using Contexts.
+func (c *ECS) ListAccountSettingsPagesWithContext(ctx aws.Context, input *ListAccountSettingsInput, fn func(*ListAccountSettingsOutput, bool) bool, opts ...request.Option) error {
+	p := request.Pagination{
+		NewRequest: func() (*request.Request, error) {
+			var inCpy *ListAccountSettingsInput
+			if input != nil {
+				tmp := *input
+				inCpy = &tmp
+			}
+			req, _ := c.ListAccountSettingsRequest(inCpy)
+			req.SetContext(ctx)
+			req.ApplyOptions(opts...)
+			return req, nil
+		},
+	}
+
+	cont := true
+	for p.Next() && cont {
+		cont = fn(p.Page().(*ListAccountSettingsOutput), !p.HasNextPage())
+	}
+	return p.Err()
+}
+
 const opListAttributes = "ListAttributes"
 
 // ListAttributesRequest generates a "aws/request.Request" representing the
@@ -1418,6 +1555,12 @@ func (c *ECS) ListAttributesRequest(input *ListAttributesInput) (req *request.Re
 		Name:       opListAttributes,
 		HTTPMethod: "POST",
 		HTTPPath:   "/",
+		Paginator: &request.Paginator{
+			InputTokens:     []string{"nextToken"},
+			OutputTokens:    []string{"nextToken"},
+			LimitToken:      "maxResults",
+			TruncationToken: "",
+		},
 	}
 
 	if input == nil {
@@ -1476,6 +1619,55 @@ func (c *ECS) ListAttributesWithContext(ctx aws.Context, input *ListAttributesIn
 	return out, req.Send()
 }
 
+// ListAttributesPages iterates over the pages of a ListAttributes operation,
+// calling the "fn" function with the response data for each page. To stop
+// iterating, return false from the fn function.
+//
+// See ListAttributes method for more information on how to use this operation.
+//
+// Note: This operation can generate multiple requests to a service.
+//
+//	// Example iterating over at most 3 pages of a ListAttributes operation.
+//	pageNum := 0
+//	err := client.ListAttributesPages(params,
+//	    func(page *ListAttributesOutput, lastPage bool) bool {
+//	        pageNum++
+//	        fmt.Println(page)
+//	        return pageNum <= 3
+//	    })
+func (c *ECS) ListAttributesPages(input *ListAttributesInput, fn func(*ListAttributesOutput, bool) bool) error {
+	return c.ListAttributesPagesWithContext(aws.BackgroundContext(), input, fn)
+}
+
+// ListAttributesPagesWithContext same as ListAttributesPages except
+// it takes a Context and allows setting request options on the pages.
+//
+// The context must be non-nil and will be used for request cancellation. If
+// the context is nil a panic will occur. In the future the SDK may create
+// sub-contexts for http.Requests. See https://golang.org/pkg/context/
+// for more information on using Contexts.
+func (c *ECS) ListAttributesPagesWithContext(ctx aws.Context, input *ListAttributesInput, fn func(*ListAttributesOutput, bool) bool, opts ...request.Option) error {
+	p := request.Pagination{
+		NewRequest: func() (*request.Request, error) {
+			var inCpy *ListAttributesInput
+			if input != nil {
+				tmp := *input
+				inCpy = &tmp
+			}
+			req, _ := c.ListAttributesRequest(inCpy)
+			req.SetContext(ctx)
+			req.ApplyOptions(opts...)
+			return req, nil
+		},
+	}
+
+	cont := true
+	for p.Next() && cont {
+		cont = fn(p.Page().(*ListAttributesOutput), !p.HasNextPage())
+	}
+	return p.Err()
+}
+
 const opListClusters = "ListClusters"
 
 // ListClustersRequest generates a "aws/request.Request" representing the
@@ -3994,6 +4186,53 @@ func (s *AwsVpcConfiguration) SetSubnets(v []*string) *AwsVpcConfiguration {
 	return s
 }
 
+// The details of a capacity provider strategy, which determines how the tasks
+// of a service are spread across capacity providers.
+type CapacityProviderStrategyItem struct {
+	_ struct{} `type:"structure"`
+
+	// The minimum number of tasks to run on the specified capacity provider. Only
+	// one capacity provider in a strategy can have a base defined.
+	Base *int64 `locationName:"base" type:"integer"`
+
+	// The short name of the capacity provider.
+	//
+	// CapacityProvider is a required field
+	CapacityProvider *string `locationName:"capacityProvider" type:"string" required:"true"`
+
+	// The relative percentage of the total number of launched tasks that should
+	// use the specified capacity provider.
+	Weight *int64 `locationName:"weight" type:"integer"`
+}
+
+// String returns the string representation
+func (s CapacityProviderStrategyItem) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s CapacityProviderStrategyItem) GoString() string {
+	return s.String()
+}
+
+// SetBase sets the Base field's value.
+func (s *CapacityProviderStrategyItem) SetBase(v int64) *CapacityProviderStrategyItem {
+	s.Base = &v
+	return s
+}
+
+// SetCapacityProvider sets the CapacityProvider field's value.
+func (s *CapacityProviderStrategyItem) SetCapacityProvider(v string) *CapacityProviderStrategyItem {
+	s.CapacityProvider = &v
+	return s
+}
+
+// SetWeight sets the Weight field's value.
+func (s *CapacityProviderStrategyItem) SetWeight(v int64) *CapacityProviderStrategyItem {
+	s.Weight = &v
+	return s
+}
+
 // A regional grouping of one or more container instances on which you can run
 // task requests. Each account receives a default cluster the first time you
 // use the Amazon ECS service, but you may also create other clusters. Clusters
@@ -4027,6 +4266,12 @@ type Cluster struct {
 	// indicates that you can register container instances with the cluster and
 	// the associated instances can accept tasks.
 	Status *string `locationName:"status" type:"string"`
+
+	// The metadata that you apply to the cluster to help you categorize and organize
+	// them. Each tag consists of a key and an optional value, both of which you
+	// define. Tags are only returned when TAGS is specified in the include parameter
+	// of DescribeClusters.
+	Tags []*Tag `locationName:"tags" type:"list"`
 }
 
 // String returns the string representation
@@ -4081,6 +4326,12 @@ func (s *Cluster) SetStatus(v string) *Cluster {
 	return s
 }
 
+// SetTags sets the Tags field's value.
+func (s *Cluster) SetTags(v []*Tag) *Cluster {
+	s.Tags = v
+	return s
+}
+
 // A Docker container that is part of a task.
 type Container struct {
 	_ struct{} `type:"structure"`
@@ -4389,6 +4640,13 @@ type ContainerDefinition struct {
 	//    name (for example, quay.io/assemblyline/ubuntu).
 	Image *string `locationName:"image" type:"string"`
 
+	// When this parameter is true, you can deploy containerized applications that
+	// require stdin or a tty to be allocated. This parameter maps to OpenStdin
+	// in the Create a container (https://docs.docker.com/engine/reference/api/docker_remote_api_v1.27/#create-a-container)
+	// section of the Docker Remote API (https://docs.docker.com/engine/reference/api/docker_remote_api_v1.27/)
+	// and the --interactive option to docker run (https://docs.docker.com/engine/reference/run/).
+	//
+	// If interactive is true, pseudoTerminal must also be true.
 	Interactive *bool `locationName:"interactive" type:"boolean"`
 
 	// The link parameter allows containers to communicate with each other without
@@ -4552,6 +4810,10 @@ type ContainerDefinition struct {
 	// Fargate launch type.
 	Privileged *bool `locationName:"privileged" type:"boolean"`
 
+	// When this parameter is true, a TTY is allocated. This parameter maps to Tty
+	// in the Create a container (https://docs.docker.com/engine/reference/api/docker_remote_api_v1.27/#create-a-container)
+	// section of the Docker Remote API (https://docs.docker.com/engine/reference/api/docker_remote_api_v1.27/)
+	// and the --tty option to docker run (https://docs.docker.com/engine/reference/run/).
 	PseudoTerminal *bool `locationName:"pseudoTerminal" type:"boolean"`
 
 	// When this parameter is true, the container is given read-only access to its
@@ -4893,6 +5155,11 @@ type ContainerInstance struct {
 	// agent at instance registration or manually with the PutAttributes operation.
 	Attributes []*Attribute `locationName:"attributes" type:"list"`
 
+	// The Availability Zone of the container instance. The service does not return
+	// it: the client populates it from the ecs.availability-zone attribute of the
+	// container instance, if present.
+	AvailabilityZone *string `locationName:"availabilityZone" type:"string"`
+
 	ClientToken *string `locationName:"clientToken" type:"string"`
 
 	// The Amazon Resource Name (ARN) of the container instance. The ARN contains
@@ -4904,6 +5171,11 @@ type ContainerInstance struct {
 	// The EC2 instance ID of the container instance.
 	Ec2InstanceId *string `locationName:"ec2InstanceId" type:"string"`
 
+	// An object representing the health status of the container instance. It is
+	// only included when CONTAINER_INSTANCE_HEALTH is specified in the include
+	// parameter of DescribeContainerInstances.
+	HealthStatus *ContainerInstanceHealthStatus `locationName:"healthStatus" type:"structure"`
+
 	// The number of tasks on the container instance that are in the PENDING status.
 	PendingTasksCount *int64 `locationName:"pendingTasksCount" type:"integer"`
 
@@ -4939,6 +5211,11 @@ type ContainerInstance struct {
 	// in the Amazon Elastic Container Service Developer Guide.
 	Status *string `locationName:"status" type:"string"`
 
+	// The metadata that you apply to the container instance to help you categorize
+	// and organize them. Tags are only included when TAGS is specified in the include
+	// parameter of DescribeContainerInstances.
+	Tags []*Tag `locationName:"tags" type:"list"`
+
 	// The version counter for the container instance. Every time a container instance
 	// experiences a change that triggers a CloudWatch event, the version counter
 	// is incremented. If you are replicating your Amazon ECS container instance
@@ -4987,6 +5264,12 @@ func (s *ContainerInstance) SetAttributes(v []*Attribute) *ContainerInstance {
 	return s
 }
 
+// SetAvailabilityZone sets the AvailabilityZone field's value.
+func (s *ContainerInstance) SetAvailabilityZone(v string) *ContainerInstance {
+	s.AvailabilityZone = &v
+	return s
+}
+
 // SetClientToken sets the ClientToken field's value.
 func (s *ContainerInstance) SetClientToken(v string) *ContainerInstance {
 	s.ClientToken = &v
@@ -5005,6 +5288,12 @@ func (s *ContainerInstance) SetEc2InstanceId(v string) *ContainerInstance {
 	return s
 }
 
+// SetHealthStatus sets the HealthStatus field's value.
+func (s *ContainerInstance) SetHealthStatus(v *ContainerInstanceHealthStatus) *ContainerInstance {
+	s.HealthStatus = v
+	return s
+}
+
 // SetPendingTasksCount sets the PendingTasksCount field's value.
 func (s *ContainerInstance) SetPendingTasksCount(v int64) *ContainerInstance {
 	s.PendingTasksCount = &v
@@ -5041,6 +5330,12 @@ func (s *ContainerInstance) SetStatus(v string) *ContainerInstance {
 	return s
 }
 
+// SetTags sets the Tags field's value.
+func (s *ContainerInstance) SetTags(v []*Tag) *ContainerInstance {
+	s.Tags = v
+	return s
+}
+
 // SetVersion sets the Version field's value.
 func (s *ContainerInstance) SetVersion(v int64) *ContainerInstance {
 	s.Version = &v
@@ -5053,6 +5348,41 @@ func (s *ContainerInstance) SetVersionInfo(v *VersionInfo) *ContainerInstance {
 	return s
 }
 
+// An object representing the health status of the container instance.
+type ContainerInstanceHealthStatus struct {
+	_ struct{} `type:"structure"`
+
+	// An array of objects representing the details of the container instance health
+	// status.
+	Details []*InstanceHealthCheckResult `locationName:"details" type:"list"`
+
+	// The overall health status of the container instance. This is an aggregate
+	// status of all container instance health checks.
+	OverallStatus *string `locationName:"overallStatus" type:"string" enum:"InstanceHealthCheckState"`
+}
+
+// String returns the string representation
+func (s ContainerInstanceHealthStatus) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ContainerInstanceHealthStatus) GoString() string {
+	return s.String()
+}
+
+// SetDetails sets the Details field's value.
+func (s *ContainerInstanceHealthStatus) SetDetails(v []*InstanceHealthCheckResult) *ContainerInstanceHealthStatus {
+	s.Details = v
+	return s
+}
+
+// SetOverallStatus sets the OverallStatus field's value.
+func (s *ContainerInstanceHealthStatus) SetOverallStatus(v string) *ContainerInstanceHealthStatus {
+	s.OverallStatus = &v
+	return s
+}
+
 // The overrides that should be sent to a container.
 type ContainerOverride struct {
 	_ struct{} `type:"structure"`
@@ -5202,6 +5532,11 @@ type CreateClusterInput struct {
 	// you create a cluster named default. Up to 255 letters (uppercase and lowercase),
 	// numbers, hyphens, and underscores are allowed.
 	ClusterName *string `locationName:"clusterName" type:"string"`
+
+	// The metadata that you apply to the cluster to help you categorize and organize
+	// them. Each tag consists of a key and an optional value, both of which you
+	// define. A cluster can have a maximum of 50 tags.
+	Tags []*Tag `locationName:"tags" type:"list"`
 }
 
 // String returns the string representation
@@ -5214,12 +5549,38 @@ func (s CreateClusterInput) GoString() string {
 	return s.String()
 }
 
+// Validate inspects the fields of the type to determine if they are valid.
+func (s *CreateClusterInput) Validate() error {
+	invalidParams := request.ErrInvalidParams{Context: "CreateClusterInput"}
+	if s.Tags != nil {
+		for i, v := range s.Tags {
+			if v == nil {
+				continue
+			}
+			if err := v.Validate(); err != nil {
+				invalidParams.AddNested(fmt.Sprintf("%s[%v]", "Tags", i), err.(request.ErrInvalidParams))
+			}
+		}
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
 // SetClusterName sets the ClusterName field's value.
 func (s *CreateClusterInput) SetClusterName(v string) *CreateClusterInput {
 	s.ClusterName = &v
 	return s
 }
 
+// SetTags sets the Tags field's value.
+func (s *CreateClusterInput) SetTags(v []*Tag) *CreateClusterInput {
+	s.Tags = v
+	return s
+}
+
 type CreateClusterOutput struct {
 	_ struct{} `type:"structure"`
 
@@ -5263,6 +5624,12 @@ type CreateServiceInput struct {
 	// keep running on your cluster.
 	DesiredCount *int64 `locationName:"desiredCount" type:"integer"`
 
+	// Specifies whether to turn on Amazon ECS managed tags for the tasks within
+	// the service. For more information, see Tagging Your Amazon ECS Resources
+	// (https://docs.aws.amazon.com/AmazonECS/latest/developerguide/ecs-using-tags.html)
+	// in the Amazon Elastic Container Service Developer Guide.
+	EnableECSManagedTags *bool `locationName:"enableECSManagedTags" type:"boolean"`
+
 	// The period of time, in seconds, that the Amazon ECS service scheduler should
 	// ignore unhealthy Elastic Load Balancing target health checks after a task
 	// has first started. This is only valid if your service is configured to use
@@ -5275,6 +5642,10 @@ type CreateServiceInput struct {
 	HealthCheckGracePeriodSeconds *int64 `locationName:"healthCheckGracePeriodSeconds" type:"integer"`
 
 	// The launch type on which to run your service.
+	//
+	// FARGATE_SPOT is not a launch type but a capacity provider. Services that
+	// should run on Fargate Spot must set a capacity provider strategy instead
+	// of a launch type.
 	LaunchType *string `locationName:"launchType" type:"string" enum:"LaunchType"`
 
 	// A load balancer object representing the load balancer to use with your service.
@@ -5323,6 +5694,13 @@ type CreateServiceInput struct {
 	// the latest version is used by default.
 	PlatformVersion *string `locationName:"platformVersion" type:"string"`
 
+	// Specifies whether to propagate the tags from the task definition or the service
+	// to the tasks in the service. If no value is specified, the tags are not propagated.
+	// Tags can only be propagated to the tasks within the service during service
+	// creation. To add tags to a task after service creation, use the TagResource
+	// API action.
+	PropagateTags *string `locationName:"propagateTags" type:"string" enum:"PropagateTags"`
+
 	// The name or full Amazon Resource Name (ARN) of the IAM role that allows Amazon
 	// ECS to make calls to your load balancer on your behalf. This parameter is
 	// only permitted if you are using a load balancer with your service and your
@@ -5443,6 +5821,12 @@ func (s *CreateServiceInput) SetDesiredCount(v int64) *CreateServiceInput {
 	return s
 }
 
+// SetEnableECSManagedTags sets the EnableECSManagedTags field's value.
+func (s *CreateServiceInput) SetEnableECSManagedTags(v bool) *CreateServiceInput {
+	s.EnableECSManagedTags = &v
+	return s
+}
+
 // SetHealthCheckGracePeriodSeconds sets the HealthCheckGracePeriodSeconds field's value.
 func (s *CreateServiceInput) SetHealthCheckGracePeriodSeconds(v int64) *CreateServiceInput {
 	s.HealthCheckGracePeriodSeconds = &v
@@ -5485,6 +5869,12 @@ func (s *CreateServiceInput) SetPlatformVersion(v string) *CreateServiceInput {
 	return s
 }
 
+// SetPropagateTags sets the PropagateTags field's value.
+func (s *CreateServiceInput) SetPropagateTags(v string) *CreateServiceInput {
+	s.PropagateTags = &v
+	return s
+}
+
 // SetRole sets the Role field's value.
 func (s *CreateServiceInput) SetRole(v string) *CreateServiceInput {
 	s.Role = &v
@@ -5860,9 +6250,27 @@ type Deployment struct {
 	// The platform version on which your service is running.
 	PlatformVersion *string `locationName:"platformVersion" type:"string"`
 
+	// The rollout state of the deployment. When a service deployment is started,
+	// it begins in an IN_PROGRESS state. When the service reaches a steady state,
+	// the deployment transitions to a COMPLETED state. If the deployment circuit
+	// breaker is turned on and the deployment fails, the deployment transitions
+	// to a FAILED state and the service is rolled back to the last completed deployment.
+	RolloutState *string `locationName:"rolloutState" type:"string" enum:"DeploymentRolloutState"`
+
+	// A description of the rollout state of the deployment.
+	RolloutStateReason *string `locationName:"rolloutStateReason" type:"string"`
+
 	// The number of tasks in the deployment that are in the RUNNING status.
 	RunningCount *int64 `locationName:"runningCount" type:"integer"`
 
+	// The Service Connect configuration of the deployment. While a Service Connect
+	// update is in flight, each deployment of the service carries the configuration
+	// its tasks were started with.
+	ServiceConnectConfiguration *ServiceConnectConfiguration `locationName:"serviceConnectConfiguration" type:"structure"`
+
+	// The Cloud Map services created for the Service Connect services of the deployment.
+	ServiceConnectResources []*ServiceConnectServiceResource `locationName:"serviceConnectResources" type:"list"`
+
 	// The status of the deployment. Valid values are PRIMARY (for the most recent
 	// deployment), ACTIVE (for previous deployments that still have tasks running,
 	// but are being replaced with the PRIMARY deployment), and INACTIVE (for deployments
@@ -5928,12 +6336,36 @@ func (s *Deployment) SetPlatformVersion(v string) *Deployment {
 	return s
 }
 
+// SetRolloutState sets the RolloutState field's value.
+func (s *Deployment) SetRolloutState(v string) *Deployment {
+	s.RolloutState = &v
+	return s
+}
+
+// SetRolloutStateReason sets the RolloutStateReason field's value.
+func (s *Deployment) SetRolloutStateReason(v string) *Deployment {
+	s.RolloutStateReason = &v
+	return s
+}
+
 // SetRunningCount sets the RunningCount field's value.
 func (s *Deployment) SetRunningCount(v int64) *Deployment {
 	s.RunningCount = &v
 	return s
 }
 
+// SetServiceConnectConfiguration sets the ServiceConnectConfiguration field's value.
+func (s *Deployment) SetServiceConnectConfiguration(v *ServiceConnectConfiguration) *Deployment {
+	s.ServiceConnectConfiguration = v
+	return s
+}
+
+// SetServiceConnectResources sets the ServiceConnectResources field's value.
+func (s *Deployment) SetServiceConnectResources(v []*ServiceConnectServiceResource) *Deployment {
+	s.ServiceConnectResources = v
+	return s
+}
+
 // SetStatus sets the Status field's value.
 func (s *Deployment) SetStatus(v string) *Deployment {
 	s.Status = &v
@@ -6158,8 +6590,10 @@ type DescribeClustersInput struct {
 	// entries. If you do not specify a cluster, the default cluster is assumed.
 	Clusters []*string `locationName:"clusters" type:"list"`
 
-	// Additional information about your clusters to be separated by launch type,
-	// including:
+	// Additional information about your clusters to be included in the response.
+	// If TAGS is specified, the metadata tags associated with the cluster are included.
+	// If STATISTICS is specified, the following additional information, separated
+	// by launch type, is included:
 	//
 	//    * runningEC2TasksCount
 	//
@@ -6246,6 +6680,13 @@ type DescribeContainerInstancesInput struct {
 	//
 	// ContainerInstances is a required field
 	ContainerInstances []*string `locationName:"containerInstances" type:"list" required:"true"`
+
+	// Specifies whether you want to see the resource tags for the container instance.
+	// If TAGS is specified, the tags are included in the response. If CONTAINER_INSTANCE_HEALTH
+	// is specified, the container instance health is included in the response.
+	// If this field is omitted, tags and container instance health status are not
+	// included in the response.
+	Include []*string `locationName:"include" type:"list"`
 }
 
 // String returns the string representation
@@ -6283,9 +6724,15 @@ func (s *DescribeContainerInstancesInput) SetContainerInstances(v []*string) *De
 	return s
 }
 
-type DescribeContainerInstancesOutput struct {
-	_ struct{} `type:"structure"`
-
+// SetInclude sets the Include field's value.
+func (s *DescribeContainerInstancesInput) SetInclude(v []*string) *DescribeContainerInstancesInput {
+	s.Include = v
+	return s
+}
+
+type DescribeContainerInstancesOutput struct {
+	_ struct{} `type:"structure"`
+
 	// The list of container instances.
 	ContainerInstances []*ContainerInstance `locationName:"containerInstances" type:"list"`
 
@@ -6940,6 +7387,114 @@ func (s *HostVolumeProperties) SetSourcePath(v string) *HostVolumeProperties {
 	return s
 }
 
+// Details on an Elastic Inference accelerator attached to a task.
+type InferenceAccelerator struct {
+	_ struct{} `type:"structure"`
+
+	// The Elastic Inference accelerator device name.
+	//
+	// DeviceName is a required field
+	DeviceName *string `locationName:"deviceName" min:"1" type:"string" required:"true"`
+
+	// The Elastic Inference accelerator type to use, for example eia1.medium.
+	//
+	// DeviceType is a required field
+	DeviceType *string `locationName:"deviceType" type:"string" required:"true"`
+}
+
+// String returns the string representation
+func (s InferenceAccelerator) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s InferenceAccelerator) GoString() string {
+	return s.String()
+}
+
+// Validate inspects the fields of the type to determine if they are valid.
+func (s *InferenceAccelerator) Validate() error {
+	invalidParams := request.ErrInvalidParams{Context: "InferenceAccelerator"}
+	if s.DeviceName == nil {
+		invalidParams.Add(request.NewErrParamRequired("DeviceName"))
+	}
+	if s.DeviceName != nil && len(*s.DeviceName) < 1 {
+		invalidParams.Add(request.NewErrParamMinLen("DeviceName", 1))
+	}
+	if s.DeviceType == nil {
+		invalidParams.Add(request.NewErrParamRequired("DeviceType"))
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// SetDeviceName sets the DeviceName field's value.
+func (s *InferenceAccelerator) SetDeviceName(v string) *InferenceAccelerator {
+	s.DeviceName = &v
+	return s
+}
+
+// SetDeviceType sets the DeviceType field's value.
+func (s *InferenceAccelerator) SetDeviceType(v string) *InferenceAccelerator {
+	s.DeviceType = &v
+	return s
+}
+
+// An object representing the result of a container instance health status check.
+type InstanceHealthCheckResult struct {
+	_ struct{} `type:"structure"`
+
+	// The Unix timestamp for when the container instance health status last changed.
+	LastStatusChange *time.Time `locationName:"lastStatusChange" type:"timestamp"`
+
+	// The Unix timestamp for when the container instance health status was last
+	// updated.
+	LastUpdated *time.Time `locationName:"lastUpdated" type:"timestamp"`
+
+	// The container instance health status.
+	Status *string `locationName:"status" type:"string" enum:"InstanceHealthCheckState"`
+
+	// The type of container instance health status that was verified.
+	Type *string `locationName:"type" type:"string" enum:"InstanceHealthCheckType"`
+}
+
+// String returns the string representation
+func (s InstanceHealthCheckResult) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s InstanceHealthCheckResult) GoString() string {
+	return s.String()
+}
+
+// SetLastStatusChange sets the LastStatusChange field's value.
+func (s *InstanceHealthCheckResult) SetLastStatusChange(v time.Time) *InstanceHealthCheckResult {
+	s.LastStatusChange = &v
+	return s
+}
+
+// SetLastUpdated sets the LastUpdated field's value.
+func (s *InstanceHealthCheckResult) SetLastUpdated(v time.Time) *InstanceHealthCheckResult {
+	s.LastUpdated = &v
+	return s
+}
+
+// SetStatus sets the Status field's value.
+func (s *InstanceHealthCheckResult) SetStatus(v string) *InstanceHealthCheckResult {
+	s.Status = &v
+	return s
+}
+
+// SetType sets the Type field's value.
+func (s *InstanceHealthCheckResult) SetType(v string) *InstanceHealthCheckResult {
+	s.Type = &v
+	return s
+}
+
 // The Linux capabilities for the container that are added to or dropped from
 // the default configuration provided by Docker. For more information on the
 // default capabilities and the non-default available capabilities, see Runtime
@@ -7156,6 +7711,98 @@ func (s *LinuxParameters) SetTmpfs(v []*Tmpfs) *LinuxParameters {
 	return s
 }
 
+type ListAccountSettingsInput struct {
+	_ struct{} `type:"structure"`
+
+	EffectiveSettings *bool `locationName:"effectiveSettings" type:"boolean"`
+
+	MaxResults *int64 `locationName:"maxResults" type:"integer"`
+
+	Name *string `locationName:"name" type:"string" enum:"SettingName"`
+
+	NextToken *string `locationName:"nextToken" type:"string"`
+
+	PrincipalArn *string `locationName:"principalArn" type:"string"`
+
+	Value *string `locationName:"value" type:"string"`
+}
+
+// String returns the string representation
+func (s ListAccountSettingsInput) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ListAccountSettingsInput) GoString() string {
+	return s.String()
+}
+
+// SetEffectiveSettings sets the EffectiveSettings field's value.
+func (s *ListAccountSettingsInput) SetEffectiveSettings(v bool) *ListAccountSettingsInput {
+	s.EffectiveSettings = &v
+	return s
+}
+
+// SetMaxResults sets the MaxResults field's value.
+func (s *ListAccountSettingsInput) SetMaxResults(v int64) *ListAccountSettingsInput {
+	s.MaxResults = &v
+	return s
+}
+
+// SetName sets the Name field's value.
+func (s *ListAccountSettingsInput) SetName(v string) *ListAccountSettingsInput {
+	s.Name = &v
+	return s
+}
+
+// SetNextToken sets the NextToken field's value.
+func (s *ListAccountSettingsInput) SetNextToken(v string) *ListAccountSettingsInput {
+	s.NextToken = &v
+	return s
+}
+
+// SetPrincipalArn sets the PrincipalArn field's value.
+func (s *ListAccountSettingsInput) SetPrincipalArn(v string) *ListAccountSettingsInput {
+	s.PrincipalArn = &v
+	return s
+}
+
+// SetValue sets the Value field's value.
+func (s *ListAccountSettingsInput) SetValue(v string) *ListAccountSettingsInput {
+	s.Value = &v
+	return s
+}
+
+type ListAccountSettingsOutput struct {
+	_ struct{} `type:"structure"`
+
+	NextToken *string `locationName:"nextToken" type:"string"`
+
+	Settings []*Setting `locationName:"settings" type:"list"`
+}
+
+// String returns the string representation
+func (s ListAccountSettingsOutput) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ListAccountSettingsOutput) GoString() string {
+	return s.String()
+}
+
+// SetNextToken sets the NextToken field's value.
+func (s *ListAccountSettingsOutput) SetNextToken(v string) *ListAccountSettingsOutput {
+	s.NextToken = &v
+	return s
+}
+
+// SetSettings sets the Settings field's value.
+func (s *ListAccountSettingsOutput) SetSettings(v []*Setting) *ListAccountSettingsOutput {
+	s.Settings = v
+	return s
+}
+
 type ListAttributesInput struct {
 	_ struct{} `type:"structure"`
 
@@ -7932,8 +8579,10 @@ type ListTasksInput struct {
 	ServiceName *string `locationName:"serviceName" type:"string"`
 
 	// The startedBy value with which to filter the task results. Specifying a startedBy
-	// value limits the results to tasks that were started with that value.
-	StartedBy *string `locationName:"startedBy" type:"string"`
+	// value limits the results to tasks that were started with that value. Up to
+	// 36 letters (uppercase and lowercase), numbers, hyphens, and underscores are
+	// allowed.
+	StartedBy *string `locationName:"startedBy" min:"1" type:"string"`
 }
 
 // String returns the string representation
@@ -7946,6 +8595,19 @@ func (s ListTasksInput) GoString() string {
 	return s.String()
 }
 
+// Validate inspects the fields of the type to determine if they are valid.
+func (s *ListTasksInput) Validate() error {
+	invalidParams := request.ErrInvalidParams{Context: "ListTasksInput"}
+	if s.StartedBy != nil && len(*s.StartedBy) < 1 {
+		invalidParams.Add(request.NewErrParamMinLen("StartedBy", 1))
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
 // SetCluster sets the Cluster field's value.
 func (s *ListTasksInput) SetCluster(v string) *ListTasksInput {
 	s.Cluster = &v
@@ -8056,6 +8718,10 @@ type LoadBalancer struct {
 	// mapping.
 	ContainerPort *int64 `locationName:"containerPort" type:"integer"`
 
+	// The full Amazon Resource Name (ARN) of the Application Load Balancer or Network
+	// Load Balancer associated with a service.
+	LoadBalancerArn *string `locationName:"loadBalancerArn" type:"string"`
+
 	// The name of a load balancer.
 	LoadBalancerName *string `locationName:"loadBalancerName" type:"string"`
 
@@ -8091,6 +8757,12 @@ func (s *LoadBalancer) SetContainerPort(v int64) *LoadBalancer {
 	return s
 }
 
+// SetLoadBalancerArn sets the LoadBalancerArn field's value.
+func (s *LoadBalancer) SetLoadBalancerArn(v string) *LoadBalancer {
+	s.LoadBalancerArn = &v
+	return s
+}
+
 // SetLoadBalancerName sets the LoadBalancerName field's value.
 func (s *LoadBalancer) SetLoadBalancerName(v string) *LoadBalancer {
 	s.LoadBalancerName = &v
@@ -8172,6 +8844,83 @@ func (s *LogConfiguration) SetOptions(v map[string]*string) *LogConfiguration {
 	return s
 }
 
+// An object representing a change in state for a managed agent, such as the
+// ExecuteCommandAgent used by ECS Exec.
+type ManagedAgentStateChange struct {
+	_ struct{} `type:"structure"`
+
+	// The name of the container that is associated with the managed agent.
+	//
+	// ContainerName is a required field
+	ContainerName *string `locationName:"containerName" type:"string" required:"true"`
+
+	// The name of the managed agent.
+	//
+	// ManagedAgentName is a required field
+	ManagedAgentName *string `locationName:"managedAgentName" type:"string" required:"true" enum:"ManagedAgentName"`
+
+	// The reason for the status of the managed agent.
+	Reason *string `locationName:"reason" type:"string"`
+
+	// The status of the managed agent, such as RUNNING or STOPPED.
+	//
+	// Status is a required field
+	Status *string `locationName:"status" type:"string" required:"true"`
+}
+
+// String returns the string representation
+func (s ManagedAgentStateChange) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ManagedAgentStateChange) GoString() string {
+	return s.String()
+}
+
+// Validate inspects the fields of the type to determine if they are valid.
+func (s *ManagedAgentStateChange) Validate() error {
+	invalidParams := request.ErrInvalidParams{Context: "ManagedAgentStateChange"}
+	if s.ContainerName == nil {
+		invalidParams.Add(request.NewErrParamRequired("ContainerName"))
+	}
+	if s.ManagedAgentName == nil {
+		invalidParams.Add(request.NewErrParamRequired("ManagedAgentName"))
+	}
+	if s.Status == nil {
+		invalidParams.Add(request.NewErrParamRequired("Status"))
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// SetContainerName sets the ContainerName field's value.
+func (s *ManagedAgentStateChange) SetContainerName(v string) *ManagedAgentStateChange {
+	s.ContainerName = &v
+	return s
+}
+
+// SetManagedAgentName sets the ManagedAgentName field's value.
+func (s *ManagedAgentStateChange) SetManagedAgentName(v string) *ManagedAgentStateChange {
+	s.ManagedAgentName = &v
+	return s
+}
+
+// SetReason sets the Reason field's value.
+func (s *ManagedAgentStateChange) SetReason(v string) *ManagedAgentStateChange {
+	s.Reason = &v
+	return s
+}
+
+// SetStatus sets the Status field's value.
+func (s *ManagedAgentStateChange) SetStatus(v string) *ManagedAgentStateChange {
+	s.Status = &v
+	return s
+}
+
 // Details on a volume mount point that is used in a container definition.
 type MountPoint struct {
 	_ struct{} `type:"structure"`
@@ -8896,7 +9645,10 @@ type RegisterTaskDefinitionInput struct {
 	// hyphens, and underscores are allowed.
 	//
 	// Family is a required field
-	Family *string `locationName:"family" type:"string" required:"true"`
+	Family *string `locationName:"family" min:"1" type:"string" required:"true"`
+
+	// The Elastic Inference accelerators to use for the containers in the task.
+	InferenceAccelerators []*InferenceAccelerator `locationName:"inferenceAccelerators" type:"list"`
 
 	IpcMode *string `locationName:"ipcMode" type:"string" enum:"IpcMode"`
 
@@ -9006,6 +9758,9 @@ func (s *RegisterTaskDefinitionInput) Validate() error {
 	if s.Family == nil {
 		invalidParams.Add(request.NewErrParamRequired("Family"))
 	}
+	if s.Family != nil && len(*s.Family) < 1 {
+		invalidParams.Add(request.NewErrParamMinLen("Family", 1))
+	}
 	if s.ContainerDefinitions != nil {
 		for i, v := range s.ContainerDefinitions {
 			if v == nil {
@@ -9016,6 +9771,16 @@ func (s *RegisterTaskDefinitionInput) Validate() error {
 			}
 		}
 	}
+	if s.InferenceAccelerators != nil {
+		for i, v := range s.InferenceAccelerators {
+			if v == nil {
+				continue
+			}
+			if err := v.Validate(); err != nil {
+				invalidParams.AddNested(fmt.Sprintf("%s[%v]", "InferenceAccelerators", i), err.(request.ErrInvalidParams))
+			}
+		}
+	}
 	if s.Tags != nil {
 		for i, v := range s.Tags {
 			if v == nil {
@@ -9057,6 +9822,12 @@ func (s *RegisterTaskDefinitionInput) SetFamily(v string) *RegisterTaskDefinitio
 	return s
 }
 
+// SetInferenceAccelerators sets the InferenceAccelerators field's value.
+func (s *RegisterTaskDefinitionInput) SetInferenceAccelerators(v []*InferenceAccelerator) *RegisterTaskDefinitionInput {
+	s.InferenceAccelerators = v
+	return s
+}
+
 // SetIpcMode sets the IpcMode field's value.
 func (s *RegisterTaskDefinitionInput) SetIpcMode(v string) *RegisterTaskDefinitionInput {
 	s.IpcMode = &v
@@ -9257,7 +10028,7 @@ type RunTaskInput struct {
 
 	// The number of instantiations of the specified task to place on your cluster.
 	// You can specify up to 10 tasks per call.
-	Count *int64 `locationName:"count" type:"integer"`
+	Count *int64 `locationName:"count" min:"1" type:"integer"`
 
 	// The name of the task group to associate with the task. The default value
 	// is the family name of the task definition (for example, family:my-family-name).
@@ -9298,6 +10069,11 @@ type RunTaskInput struct {
 	// the latest version is used by default.
 	PlatformVersion *string `locationName:"platformVersion" type:"string"`
 
+	// The reference ID to use for the task, such as an idempotency token of an
+	// external system. Up to 64 characters are allowed, where startedBy allows
+	// 36.
+	ReferenceId *string `locationName:"referenceId" type:"string"`
+
 	// An optional tag specified when a task is started. For example if you automatically
 	// trigger a task to run a batch process job, you could apply a unique identifier
 	// for that job to your task with the startedBy parameter. You can then identify
@@ -9309,11 +10085,16 @@ type RunTaskInput struct {
 	// contains the deployment ID of the service that starts it.
 	StartedBy *string `locationName:"startedBy" type:"string"`
 
+	// The metadata that you apply to the task to help you categorize and organize
+	// them. Each tag consists of a key and an optional value, both of which you
+	// define.
+	Tags []*Tag `locationName:"tags" type:"list"`
+
 	// The family and revision (family:revision) or full ARN of the task definition
 	// to run. If a revision is not specified, the latest ACTIVE revision is used.
 	//
 	// TaskDefinition is a required field
-	TaskDefinition *string `locationName:"taskDefinition" type:"string" required:"true"`
+	TaskDefinition *string `locationName:"taskDefinition" min:"1" type:"string" required:"true"`
 }
 
 // String returns the string representation
@@ -9329,14 +10110,30 @@ func (s RunTaskInput) GoString() string {
 // Validate inspects the fields of the type to determine if they are valid.
 func (s *RunTaskInput) Validate() error {
 	invalidParams := request.ErrInvalidParams{Context: "RunTaskInput"}
+	if s.Count != nil && *s.Count < 1 {
+		invalidParams.Add(request.NewErrParamMinValue("Count", 1))
+	}
 	if s.TaskDefinition == nil {
 		invalidParams.Add(request.NewErrParamRequired("TaskDefinition"))
 	}
+	if s.TaskDefinition != nil && len(*s.TaskDefinition) < 1 {
+		invalidParams.Add(request.NewErrParamMinLen("TaskDefinition", 1))
+	}
 	if s.NetworkConfiguration != nil {
 		if err := s.NetworkConfiguration.Validate(); err != nil {
 			invalidParams.AddNested("NetworkConfiguration", err.(request.ErrInvalidParams))
 		}
 	}
+	if s.Tags != nil {
+		for i, v := range s.Tags {
+			if v == nil {
+				continue
+			}
+			if err := v.Validate(); err != nil {
+				invalidParams.AddNested(fmt.Sprintf("%s[%v]", "Tags", i), err.(request.ErrInvalidParams))
+			}
+		}
+	}
 
 	if invalidParams.Len() > 0 {
 		return invalidParams
@@ -9398,12 +10195,24 @@ func (s *RunTaskInput) SetPlatformVersion(v string) *RunTaskInput {
 	return s
 }
 
+// SetReferenceId sets the ReferenceId field's value.
+func (s *RunTaskInput) SetReferenceId(v string) *RunTaskInput {
+	s.ReferenceId = &v
+	return s
+}
+
 // SetStartedBy sets the StartedBy field's value.
 func (s *RunTaskInput) SetStartedBy(v string) *RunTaskInput {
 	s.StartedBy = &v
 	return s
 }
 
+// SetTags sets the Tags field's value.
+func (s *RunTaskInput) SetTags(v []*Tag) *RunTaskInput {
+	s.Tags = v
+	return s
+}
+
 // SetTaskDefinition sets the TaskDefinition field's value.
 func (s *RunTaskInput) SetTaskDefinition(v string) *RunTaskInput {
 	s.TaskDefinition = &v
@@ -9443,30 +10252,65 @@ func (s *RunTaskOutput) SetTasks(v []*Task) *RunTaskOutput {
 	return s
 }
 
-type Secret struct {
+// A floating-point percentage of the desired number of tasks to place and keep
+// running in the task set.
+type Scale struct {
 	_ struct{} `type:"structure"`
 
-	// Name is a required field
-	Name *string `locationName:"name" type:"string" required:"true"`
+	// The unit of measure for the scale value.
+	Unit *string `locationName:"unit" type:"string" enum:"ScaleUnit"`
 
-	// ValueFrom is a required field
-	ValueFrom *string `locationName:"valueFrom" type:"string" required:"true"`
+	// The value, specified as a percent total of a service's desiredCount, to scale
+	// the task set. Accepted values are numbers between 0 and 100.
+	Value *float64 `locationName:"value" type:"double"`
 }
 
 // String returns the string representation
-func (s Secret) String() string {
+func (s Scale) String() string {
 	return awsutil.Prettify(s)
 }
 
 // GoString returns the string representation
-func (s Secret) GoString() string {
+func (s Scale) GoString() string {
 	return s.String()
 }
 
-// Validate inspects the fields of the type to determine if they are valid.
-func (s *Secret) Validate() error {
-	invalidParams := request.ErrInvalidParams{Context: "Secret"}
-	if s.Name == nil {
+// SetUnit sets the Unit field's value.
+func (s *Scale) SetUnit(v string) *Scale {
+	s.Unit = &v
+	return s
+}
+
+// SetValue sets the Value field's value.
+func (s *Scale) SetValue(v float64) *Scale {
+	s.Value = &v
+	return s
+}
+
+type Secret struct {
+	_ struct{} `type:"structure"`
+
+	// Name is a required field
+	Name *string `locationName:"name" type:"string" required:"true"`
+
+	// ValueFrom is a required field
+	ValueFrom *string `locationName:"valueFrom" type:"string" required:"true"`
+}
+
+// String returns the string representation
+func (s Secret) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// Validate inspects the fields of the type to determine if they are valid.
+func (s *Secret) Validate() error {
+	invalidParams := request.ErrInvalidParams{Context: "Secret"}
+	if s.Name == nil {
 		invalidParams.Add(request.NewErrParamRequired("Name"))
 	}
 	if s.ValueFrom == nil {
@@ -9495,12 +10339,18 @@ func (s *Secret) SetValueFrom(v string) *Secret {
 type Service struct {
 	_ struct{} `type:"structure"`
 
+	// The capacity provider strategy associated with the service.
+	CapacityProviderStrategy []*CapacityProviderStrategyItem `locationName:"capacityProviderStrategy" type:"list"`
+
 	// The Amazon Resource Name (ARN) of the cluster that hosts the service.
 	ClusterArn *string `locationName:"clusterArn" type:"string"`
 
 	// The Unix time stamp for when the service was created.
 	CreatedAt *time.Time `locationName:"createdAt" type:"timestamp"`
 
+	// The principal that created the service.
+	CreatedBy *string `locationName:"createdBy" type:"string"`
+
 	// Optional deployment parameters that control how many tasks run during the
 	// deployment and the ordering of stopping and starting tasks.
 	DeploymentConfiguration *DeploymentConfiguration `locationName:"deploymentConfiguration" type:"structure"`
@@ -9513,8 +10363,13 @@ type Service struct {
 	// CreateService, and it can be modified with UpdateService.
 	DesiredCount *int64 `locationName:"desiredCount" type:"integer"`
 
-	// The event stream for your service. A maximum of 100 of the latest events
-	// are displayed.
+	// Specifies whether to turn on Amazon ECS managed tags for the tasks in the
+	// service.
+	EnableECSManagedTags *bool `locationName:"enableECSManagedTags" type:"boolean"`
+
+	// The event stream for your service, newest first. Only the 100 most recent
+	// events are returned: older events are dropped, and there is no token to page
+	// through them.
 	Events []*ServiceEvent `locationName:"events" type:"list"`
 
 	// The period of time, in seconds, that the Amazon ECS service scheduler ignores
@@ -9555,6 +10410,10 @@ type Service struct {
 	// in the Amazon Elastic Container Service Developer Guide.
 	PlatformVersion *string `locationName:"platformVersion" type:"string"`
 
+	// Specifies whether to propagate the tags from the task definition or the service
+	// to the task. If no value is specified, the tags are not propagated.
+	PropagateTags *string `locationName:"propagateTags" type:"string" enum:"PropagateTags"`
+
 	// The ARN of the IAM role associated with the service that allows the Amazon
 	// ECS container agent to register container instances with an Elastic Load
 	// Balancing load balancer.
@@ -9596,10 +10455,21 @@ type Service struct {
 	// The status of the service. The valid values are ACTIVE, DRAINING, or INACTIVE.
 	Status *string `locationName:"status" type:"string"`
 
+	// The metadata that you apply to the service to help you categorize and organize
+	// them. Each tag consists of a key and an optional value, both of which you
+	// define.
+	Tags []*Tag `locationName:"tags" type:"list"`
+
 	// The task definition to use for tasks in the service. This value is specified
 	// when the service is created with CreateService, and it can be modified with
 	// UpdateService.
 	TaskDefinition *string `locationName:"taskDefinition" type:"string"`
+
+	// Information about a set of Amazon ECS tasks in either an AWS CodeDeploy or
+	// an EXTERNAL deployment. An Amazon ECS task set includes details such as the
+	// desired number of tasks, how many tasks are running, and whether the task
+	// set serves production traffic.
+	TaskSets []*TaskSet `locationName:"taskSets" type:"list"`
 }
 
 // String returns the string representation
@@ -9612,6 +10482,12 @@ func (s Service) GoString() string {
 	return s.String()
 }
 
+// SetCapacityProviderStrategy sets the CapacityProviderStrategy field's value.
+func (s *Service) SetCapacityProviderStrategy(v []*CapacityProviderStrategyItem) *Service {
+	s.CapacityProviderStrategy = v
+	return s
+}
+
 // SetClusterArn sets the ClusterArn field's value.
 func (s *Service) SetClusterArn(v string) *Service {
 	s.ClusterArn = &v
@@ -9624,6 +10500,12 @@ func (s *Service) SetCreatedAt(v time.Time) *Service {
 	return s
 }
 
+// SetCreatedBy sets the CreatedBy field's value.
+func (s *Service) SetCreatedBy(v string) *Service {
+	s.CreatedBy = &v
+	return s
+}
+
 // SetDeploymentConfiguration sets the DeploymentConfiguration field's value.
 func (s *Service) SetDeploymentConfiguration(v *DeploymentConfiguration) *Service {
 	s.DeploymentConfiguration = v
@@ -9642,6 +10524,12 @@ func (s *Service) SetDesiredCount(v int64) *Service {
 	return s
 }
 
+// SetEnableECSManagedTags sets the EnableECSManagedTags field's value.
+func (s *Service) SetEnableECSManagedTags(v bool) *Service {
+	s.EnableECSManagedTags = &v
+	return s
+}
+
 // SetEvents sets the Events field's value.
 func (s *Service) SetEvents(v []*ServiceEvent) *Service {
 	s.Events = v
@@ -9696,6 +10584,12 @@ func (s *Service) SetPlatformVersion(v string) *Service {
 	return s
 }
 
+// SetPropagateTags sets the PropagateTags field's value.
+func (s *Service) SetPropagateTags(v string) *Service {
+	s.PropagateTags = &v
+	return s
+}
+
 // SetRoleArn sets the RoleArn field's value.
 func (s *Service) SetRoleArn(v string) *Service {
 	s.RoleArn = &v
@@ -9738,12 +10632,205 @@ func (s *Service) SetStatus(v string) *Service {
 	return s
 }
 
+// SetTags sets the Tags field's value.
+func (s *Service) SetTags(v []*Tag) *Service {
+	s.Tags = v
+	return s
+}
+
 // SetTaskDefinition sets the TaskDefinition field's value.
 func (s *Service) SetTaskDefinition(v string) *Service {
 	s.TaskDefinition = &v
 	return s
 }
 
+// SetTaskSets sets the TaskSets field's value.
+func (s *Service) SetTaskSets(v []*TaskSet) *Service {
+	s.TaskSets = v
+	return s
+}
+
+// A client alias through which the tasks of other services in the namespace
+// reach a Service Connect service.
+type ServiceConnectClientAlias struct {
+	_ struct{} `type:"structure"`
+
+	// The DNS name that client tasks use to connect to the service.
+	DnsName *string `locationName:"dnsName" type:"string"`
+
+	// The port that client tasks use to connect to the service.
+	//
+	// Port is a required field
+	Port *int64 `locationName:"port" type:"integer" required:"true"`
+}
+
+// String returns the string representation
+func (s ServiceConnectClientAlias) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ServiceConnectClientAlias) GoString() string {
+	return s.String()
+}
+
+// SetDnsName sets the DnsName field's value.
+func (s *ServiceConnectClientAlias) SetDnsName(v string) *ServiceConnectClientAlias {
+	s.DnsName = &v
+	return s
+}
+
+// SetPort sets the Port field's value.
+func (s *ServiceConnectClientAlias) SetPort(v int64) *ServiceConnectClientAlias {
+	s.Port = &v
+	return s
+}
+
+// The Service Connect configuration of a service deployment.
+type ServiceConnectConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	// Whether Service Connect is enabled for the service.
+	//
+	// Enabled is a required field
+	Enabled *bool `locationName:"enabled" type:"boolean" required:"true"`
+
+	// The log configuration of the Service Connect proxy container.
+	LogConfiguration *LogConfiguration `locationName:"logConfiguration" type:"structure"`
+
+	// The name or ARN of the Cloud Map namespace that the service is part of.
+	Namespace *string `locationName:"namespace" type:"string"`
+
+	// The ports of the service that are made available to the other services of
+	// the namespace.
+	Services []*ServiceConnectService `locationName:"services" type:"list"`
+}
+
+// String returns the string representation
+func (s ServiceConnectConfiguration) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ServiceConnectConfiguration) GoString() string {
+	return s.String()
+}
+
+// SetEnabled sets the Enabled field's value.
+func (s *ServiceConnectConfiguration) SetEnabled(v bool) *ServiceConnectConfiguration {
+	s.Enabled = &v
+	return s
+}
+
+// SetLogConfiguration sets the LogConfiguration field's value.
+func (s *ServiceConnectConfiguration) SetLogConfiguration(v *LogConfiguration) *ServiceConnectConfiguration {
+	s.LogConfiguration = v
+	return s
+}
+
+// SetNamespace sets the Namespace field's value.
+func (s *ServiceConnectConfiguration) SetNamespace(v string) *ServiceConnectConfiguration {
+	s.Namespace = &v
+	return s
+}
+
+// SetServices sets the Services field's value.
+func (s *ServiceConnectConfiguration) SetServices(v []*ServiceConnectService) *ServiceConnectConfiguration {
+	s.Services = v
+	return s
+}
+
+// A port of the service that is made available to the other services of the
+// namespace.
+type ServiceConnectService struct {
+	_ struct{} `type:"structure"`
+
+	// The names and ports that client tasks use to connect to this service. If
+	// no alias is given, the discovery name and the port of the port mapping are
+	// used.
+	ClientAliases []*ServiceConnectClientAlias `locationName:"clientAliases" type:"list"`
+
+	// The name under which the service is registered in Cloud Map. The port name
+	// is used if none is given.
+	DiscoveryName *string `locationName:"discoveryName" type:"string"`
+
+	// The port on which the Service Connect proxy listens for traffic, instead
+	// of the default port.
+	IngressPortOverride *int64 `locationName:"ingressPortOverride" type:"integer"`
+
+	// The name of a port mapping of the task definition of the service.
+	//
+	// PortName is a required field
+	PortName *string `locationName:"portName" type:"string" required:"true"`
+}
+
+// String returns the string representation
+func (s ServiceConnectService) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ServiceConnectService) GoString() string {
+	return s.String()
+}
+
+// SetClientAliases sets the ClientAliases field's value.
+func (s *ServiceConnectService) SetClientAliases(v []*ServiceConnectClientAlias) *ServiceConnectService {
+	s.ClientAliases = v
+	return s
+}
+
+// SetDiscoveryName sets the DiscoveryName field's value.
+func (s *ServiceConnectService) SetDiscoveryName(v string) *ServiceConnectService {
+	s.DiscoveryName = &v
+	return s
+}
+
+// SetIngressPortOverride sets the IngressPortOverride field's value.
+func (s *ServiceConnectService) SetIngressPortOverride(v int64) *ServiceConnectService {
+	s.IngressPortOverride = &v
+	return s
+}
+
+// SetPortName sets the PortName field's value.
+func (s *ServiceConnectService) SetPortName(v string) *ServiceConnectService {
+	s.PortName = &v
+	return s
+}
+
+// A Cloud Map service created for a Service Connect service of a deployment.
+type ServiceConnectServiceResource struct {
+	_ struct{} `type:"structure"`
+
+	// The discovery name of the Service Connect service.
+	DiscoveryName *string `locationName:"discoveryName" type:"string"`
+
+	// The ARN of the Cloud Map service that provides the Service Connect service.
+	ProviderArn *string `locationName:"providerArn" type:"string"`
+}
+
+// String returns the string representation
+func (s ServiceConnectServiceResource) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s ServiceConnectServiceResource) GoString() string {
+	return s.String()
+}
+
+// SetDiscoveryName sets the DiscoveryName field's value.
+func (s *ServiceConnectServiceResource) SetDiscoveryName(v string) *ServiceConnectServiceResource {
+	s.DiscoveryName = &v
+	return s
+}
+
+// SetProviderArn sets the ProviderArn field's value.
+func (s *ServiceConnectServiceResource) SetProviderArn(v string) *ServiceConnectServiceResource {
+	s.ProviderArn = &v
+	return s
+}
+
 // Details on an event associated with a service.
 type ServiceEvent struct {
 	_ struct{} `type:"structure"`
@@ -9937,6 +11024,11 @@ type StartTaskInput struct {
 	// contains the deployment ID of the service that starts it.
 	StartedBy *string `locationName:"startedBy" type:"string"`
 
+	// The metadata that you apply to the task to help you categorize and organize
+	// them. Each tag consists of a key and an optional value, both of which you
+	// define.
+	Tags []*Tag `locationName:"tags" type:"list"`
+
 	// The family and revision (family:revision) or full ARN of the task definition
 	// to start. If a revision is not specified, the latest ACTIVE revision is used.
 	//
@@ -9968,6 +11060,16 @@ func (s *StartTaskInput) Validate() error {
 			invalidParams.AddNested("NetworkConfiguration", err.(request.ErrInvalidParams))
 		}
 	}
+	if s.Tags != nil {
+		for i, v := range s.Tags {
+			if v == nil {
+				continue
+			}
+			if err := v.Validate(); err != nil {
+				invalidParams.AddNested(fmt.Sprintf("%s[%v]", "Tags", i), err.(request.ErrInvalidParams))
+			}
+		}
+	}
 
 	if invalidParams.Len() > 0 {
 		return invalidParams
@@ -10011,6 +11113,12 @@ func (s *StartTaskInput) SetStartedBy(v string) *StartTaskInput {
 	return s
 }
 
+// SetTags sets the Tags field's value.
+func (s *StartTaskInput) SetTags(v []*Tag) *StartTaskInput {
+	s.Tags = v
+	return s
+}
+
 // SetTaskDefinition sets the TaskDefinition field's value.
 func (s *StartTaskInput) SetTaskDefinition(v string) *StartTaskInput {
 	s.TaskDefinition = &v
@@ -10251,6 +11359,9 @@ type SubmitTaskStateChangeInput struct {
 	// The Unix time stamp for when the task execution stopped.
 	ExecutionStoppedAt *time.Time `locationName:"executionStoppedAt" type:"timestamp"`
 
+	// The details for the managed agent that is associated with the task.
+	ManagedAgents []*ManagedAgentStateChange `locationName:"managedAgents" type:"list"`
+
 	// The Unix time stamp for when the container image pull began.
 	PullStartedAt *time.Time `locationName:"pullStartedAt" type:"timestamp"`
 
@@ -10290,6 +11401,16 @@ func (s *SubmitTaskStateChangeInput) Validate() error {
 			}
 		}
 	}
+	if s.ManagedAgents != nil {
+		for i, v := range s.ManagedAgents {
+			if v == nil {
+				continue
+			}
+			if err := v.Validate(); err != nil {
+				invalidParams.AddNested(fmt.Sprintf("%s[%v]", "ManagedAgents", i), err.(request.ErrInvalidParams))
+			}
+		}
+	}
 
 	if invalidParams.Len() > 0 {
 		return invalidParams
@@ -10321,6 +11442,12 @@ func (s *SubmitTaskStateChangeInput) SetExecutionStoppedAt(v time.Time) *SubmitT
 	return s
 }
 
+// SetManagedAgents sets the ManagedAgents field's value.
+func (s *SubmitTaskStateChangeInput) SetManagedAgents(v []*ManagedAgentStateChange) *SubmitTaskStateChangeInput {
+	s.ManagedAgents = v
+	return s
+}
+
 // SetPullStartedAt sets the PullStartedAt field's value.
 func (s *SubmitTaskStateChangeInput) SetPullStartedAt(v time.Time) *SubmitTaskStateChangeInput {
 	s.PullStartedAt = &v
@@ -10591,6 +11718,11 @@ type Task struct {
 	// state to STOPPED).
 	StoppingAt *time.Time `locationName:"stoppingAt" type:"timestamp"`
 
+	// The metadata that you apply to the task to help you categorize and organize
+	// them. Each tag consists of a key and an optional value, both of which you
+	// define.
+	Tags []*Tag `locationName:"tags" type:"list"`
+
 	// The Amazon Resource Name (ARN) of the task.
 	TaskArn *string `locationName:"taskArn" type:"string"`
 
@@ -10760,6 +11892,12 @@ func (s *Task) SetStoppingAt(v time.Time) *Task {
 	return s
 }
 
+// SetTags sets the Tags field's value.
+func (s *Task) SetTags(v []*Tag) *Task {
+	s.Tags = v
+	return s
+}
+
 // SetTaskArn sets the TaskArn field's value.
 func (s *Task) SetTaskArn(v string) *Task {
 	s.TaskArn = &v
@@ -10814,6 +11952,9 @@ type TaskDefinition struct {
 	//    (30 GB) in increments of 1024 (1 GB)
 	Cpu *string `locationName:"cpu" type:"string"`
 
+	// The Unix time stamp for when the task definition was deregistered.
+	DeregisteredAt *time.Time `locationName:"deregisteredAt" type:"timestamp"`
+
 	// The Amazon Resource Name (ARN) of the task execution role that the Amazon
 	// ECS container agent and the Docker daemon can assume.
 	ExecutionRoleArn *string `locationName:"executionRoleArn" type:"string"`
@@ -10821,6 +11962,9 @@ type TaskDefinition struct {
 	// The family of your task definition, used as the definition name.
 	Family *string `locationName:"family" type:"string"`
 
+	// The Elastic Inference accelerators used by the containers in the task.
+	InferenceAccelerators []*InferenceAccelerator `locationName:"inferenceAccelerators" type:"list"`
+
 	IpcMode *string `locationName:"ipcMode" type:"string" enum:"IpcMode"`
 
 	// The amount (in MiB) of memory used by the task. If using the EC2 launch type,
@@ -10887,6 +12031,12 @@ type TaskDefinition struct {
 	// not valid if using the Fargate launch type for your task.
 	PlacementConstraints []*TaskDefinitionPlacementConstraint `locationName:"placementConstraints" type:"list"`
 
+	// The Unix time stamp for when the task definition was registered.
+	RegisteredAt *time.Time `locationName:"registeredAt" type:"timestamp"`
+
+	// The principal that registered the task definition.
+	RegisteredBy *string `locationName:"registeredBy" type:"string"`
+
 	// The container instance attributes required by your task. This field is not
 	// valid if using the Fargate launch type for your task.
 	RequiresAttributes []*Attribute `locationName:"requiresAttributes" type:"list"`
@@ -10956,6 +12106,12 @@ func (s *TaskDefinition) SetCpu(v string) *TaskDefinition {
 	return s
 }
 
+// SetDeregisteredAt sets the DeregisteredAt field's value.
+func (s *TaskDefinition) SetDeregisteredAt(v time.Time) *TaskDefinition {
+	s.DeregisteredAt = &v
+	return s
+}
+
 // SetExecutionRoleArn sets the ExecutionRoleArn field's value.
 func (s *TaskDefinition) SetExecutionRoleArn(v string) *TaskDefinition {
 	s.ExecutionRoleArn = &v
@@ -10968,6 +12124,12 @@ func (s *TaskDefinition) SetFamily(v string) *TaskDefinition {
 	return s
 }
 
+// SetInferenceAccelerators sets the InferenceAccelerators field's value.
+func (s *TaskDefinition) SetInferenceAccelerators(v []*InferenceAccelerator) *TaskDefinition {
+	s.InferenceAccelerators = v
+	return s
+}
+
 // SetIpcMode sets the IpcMode field's value.
 func (s *TaskDefinition) SetIpcMode(v string) *TaskDefinition {
 	s.IpcMode = &v
@@ -10998,6 +12160,18 @@ func (s *TaskDefinition) SetPlacementConstraints(v []*TaskDefinitionPlacementCon
 	return s
 }
 
+// SetRegisteredAt sets the RegisteredAt field's value.
+func (s *TaskDefinition) SetRegisteredAt(v time.Time) *TaskDefinition {
+	s.RegisteredAt = &v
+	return s
+}
+
+// SetRegisteredBy sets the RegisteredBy field's value.
+func (s *TaskDefinition) SetRegisteredBy(v string) *TaskDefinition {
+	s.RegisteredBy = &v
+	return s
+}
+
 // SetRequiresAttributes sets the RequiresAttributes field's value.
 func (s *TaskDefinition) SetRequiresAttributes(v []*Attribute) *TaskDefinition {
 	s.RequiresAttributes = v
@@ -11128,6 +12302,164 @@ func (s *TaskOverride) SetTaskRoleArn(v string) *TaskOverride {
 	return s
 }
 
+// Information about a set of Amazon ECS tasks in either an AWS CodeDeploy or
+// an EXTERNAL deployment. A task set includes details such as the desired number
+// of tasks, how many tasks are running, and whether the task set serves production
+// traffic.
+type TaskSet struct {
+	_ struct{} `type:"structure"`
+
+	// The computed desired count for the task set. This is calculated by multiplying
+	// the service's desiredCount by the task set's scale percentage.
+	ComputedDesiredCount *int64 `locationName:"computedDesiredCount" type:"integer"`
+
+	// The Unix timestamp for when the task set was created.
+	CreatedAt *time.Time `locationName:"createdAt" type:"timestamp"`
+
+	// The external ID associated with the task set.
+	ExternalId *string `locationName:"externalId" type:"string"`
+
+	// The ID of the task set.
+	Id *string `locationName:"id" type:"string"`
+
+	// The launch type the tasks in the task set are using.
+	LaunchType *string `locationName:"launchType" type:"string" enum:"LaunchType"`
+
+	// Details on a load balancer that is used with a task set.
+	LoadBalancers []*LoadBalancer `locationName:"loadBalancers" type:"list"`
+
+	// The network configuration for the task set.
+	NetworkConfiguration *NetworkConfiguration `locationName:"networkConfiguration" type:"structure"`
+
+	// The number of tasks in the task set that are in the PENDING status.
+	PendingCount *int64 `locationName:"pendingCount" type:"integer"`
+
+	// The number of tasks in the task set that are in the RUNNING status.
+	RunningCount *int64 `locationName:"runningCount" type:"integer"`
+
+	// A floating-point percentage of the desired number of tasks to place and keep
+	// running in the task set.
+	Scale *Scale `locationName:"scale" type:"structure"`
+
+	// The tag specified when a task set is started. If the task set is created
+	// by an AWS CodeDeploy deployment, the startedBy parameter is CODE_DEPLOY.
+	// For a task set created for an external deployment, the startedBy field isn't
+	// used.
+	StartedBy *string `locationName:"startedBy" type:"string"`
+
+	// The status of the task set: PRIMARY, ACTIVE or DRAINING.
+	Status *string `locationName:"status" type:"string"`
+
+	// The task definition the task set is using.
+	TaskDefinition *string `locationName:"taskDefinition" type:"string"`
+
+	// The Amazon Resource Name (ARN) of the task set.
+	TaskSetArn *string `locationName:"taskSetArn" type:"string"`
+
+	// The Unix timestamp for when the task set was last updated.
+	UpdatedAt *time.Time `locationName:"updatedAt" type:"timestamp"`
+}
+
+// String returns the string representation
+func (s TaskSet) String() string {
+	return awsutil.Prettify(s)
+}
+
+// GoString returns the string representation
+func (s TaskSet) GoString() string {
+	return s.String()
+}
+
+// SetComputedDesiredCount sets the ComputedDesiredCount field's value.
+func (s *TaskSet) SetComputedDesiredCount(v int64) *TaskSet {
+	s.ComputedDesiredCount = &v
+	return s
+}
+
+// SetCreatedAt sets the CreatedAt field's value.
+func (s *TaskSet) SetCreatedAt(v time.Time) *TaskSet {
+	s.CreatedAt = &v
+	return s
+}
+
+// SetExternalId sets the ExternalId field's value.
+func (s *TaskSet) SetExternalId(v string) *TaskSet {
+	s.ExternalId = &v
+	return s
+}
+
+// SetId sets the Id field's value.
+func (s *TaskSet) SetId(v string) *TaskSet {
+	s.Id = &v
+	return s
+}
+
+// SetLaunchType sets the LaunchType field's value.
+func (s *TaskSet) SetLaunchType(v string) *TaskSet {
+	s.LaunchType = &v
+	return s
+}
+
+// SetLoadBalancers sets the LoadBalancers field's value.
+func (s *TaskSet) SetLoadBalancers(v []*LoadBalancer) *TaskSet {
+	s.LoadBalancers = v
+	return s
+}
+
+// SetNetworkConfiguration sets the NetworkConfiguration field's value.
+func (s *TaskSet) SetNetworkConfiguration(v *NetworkConfiguration) *TaskSet {
+	s.NetworkConfiguration = v
+	return s
+}
+
+// SetPendingCount sets the PendingCount field's value.
+func (s *TaskSet) SetPendingCount(v int64) *TaskSet {
+	s.PendingCount = &v
+	return s
+}
+
+// SetRunningCount sets the RunningCount field's value.
+func (s *TaskSet) SetRunningCount(v int64) *TaskSet {
+	s.RunningCount = &v
+	return s
+}
+
+// SetScale sets the Scale field's value.
+func (s *TaskSet) SetScale(v *Scale) *TaskSet {
+	s.Scale = v
+	return s
+}
+
+// SetStartedBy sets the StartedBy field's value.
+func (s *TaskSet) SetStartedBy(v string) *TaskSet {
+	s.StartedBy = &v
+	return s
+}
+
+// SetStatus sets the Status field's value.
+func (s *TaskSet) SetStatus(v string) *TaskSet {
+	s.Status = &v
+	return s
+}
+
+// SetTaskDefinition sets the TaskDefinition field's value.
+func (s *TaskSet) SetTaskDefinition(v string) *TaskSet {
+	s.TaskDefinition = &v
+	return s
+}
+
+// SetTaskSetArn sets the TaskSetArn field's value.
+func (s *TaskSet) SetTaskSetArn(v string) *TaskSet {
+	s.TaskSetArn = &v
+	return s
+}
+
+// SetUpdatedAt sets the UpdatedAt field's value.
+func (s *TaskSet) SetUpdatedAt(v time.Time) *TaskSet {
+	s.UpdatedAt = &v
+	return s
+}
+
 // The container path, mount options, and size of the tmpfs mount.
 type Tmpfs struct {
 	_ struct{} `type:"structure"`
@@ -11447,6 +12779,11 @@ type UpdateServiceInput struct {
 	// service.
 	DesiredCount *int64 `locationName:"desiredCount" type:"integer"`
 
+	// Whether to turn on ECS Exec for the tasks of the service. The setting only
+	// applies to tasks started by a new deployment, so the tasks that are already
+	// running keep their setting unless forceNewDeployment is also set.
+	EnableExecuteCommand *bool `locationName:"enableExecuteCommand" type:"boolean"`
+
 	// Whether to force a new deployment of the service. Deployments are not forced
 	// by default. You can use this option to trigger a new deployment with no service
 	// definition changes. For example, you can update a service's tasks to use
@@ -11539,6 +12876,12 @@ func (s *UpdateServiceInput) SetDesiredCount(v int64) *UpdateServiceInput {
 	return s
 }
 
+// SetEnableExecuteCommand sets the EnableExecuteCommand field's value.
+func (s *UpdateServiceInput) SetEnableExecuteCommand(v bool) *UpdateServiceInput {
+	s.EnableExecuteCommand = &v
+	return s
+}
+
 // SetForceNewDeployment sets the ForceNewDeployment field's value.
 func (s *UpdateServiceInput) SetForceNewDeployment(v bool) *UpdateServiceInput {
 	s.ForceNewDeployment = &v
@@ -11761,6 +13104,9 @@ const (
 const (
 	// ClusterFieldStatistics is a ClusterField enum value
 	ClusterFieldStatistics = "STATISTICS"
+
+	// ClusterFieldTags is a ClusterField enum value
+	ClusterFieldTags = "TAGS"
 )
 
 const (
@@ -11779,6 +13125,14 @@ const (
 	ConnectivityDisconnected = "DISCONNECTED"
 )
 
+const (
+	// ContainerInstanceFieldTags is a ContainerInstanceField enum value
+	ContainerInstanceFieldTags = "TAGS"
+
+	// ContainerInstanceFieldContainerInstanceHealth is a ContainerInstanceField enum value
+	ContainerInstanceFieldContainerInstanceHealth = "CONTAINER_INSTANCE_HEALTH"
+)
+
 const (
 	// ContainerInstanceStatusActive is a ContainerInstanceStatus enum value
 	ContainerInstanceStatusActive = "ACTIVE"
@@ -11787,6 +13141,17 @@ const (
 	ContainerInstanceStatusDraining = "DRAINING"
 )
 
+const (
+	// DeploymentRolloutStateCompleted is a DeploymentRolloutState enum value
+	DeploymentRolloutStateCompleted = "COMPLETED"
+
+	// DeploymentRolloutStateFailed is a DeploymentRolloutState enum value
+	DeploymentRolloutStateFailed = "FAILED"
+
+	// DeploymentRolloutStateInProgress is a DeploymentRolloutState enum value
+	DeploymentRolloutStateInProgress = "IN_PROGRESS"
+)
+
 const (
 	// DesiredStatusRunning is a DesiredStatus enum value
 	DesiredStatusRunning = "RUNNING"
@@ -11820,6 +13185,25 @@ const (
 	HealthStatusUnknown = "UNKNOWN"
 )
 
+const (
+	// InstanceHealthCheckStateOk is a InstanceHealthCheckState enum value
+	InstanceHealthCheckStateOk = "OK"
+
+	// InstanceHealthCheckStateImpaired is a InstanceHealthCheckState enum value
+	InstanceHealthCheckStateImpaired = "IMPAIRED"
+
+	// InstanceHealthCheckStateInsufficientData is a InstanceHealthCheckState enum value
+	InstanceHealthCheckStateInsufficientData = "INSUFFICIENT_DATA"
+
+	// InstanceHealthCheckStateInitializing is a InstanceHealthCheckState enum value
+	InstanceHealthCheckStateInitializing = "INITIALIZING"
+)
+
+const (
+	// InstanceHealthCheckTypeContainerRuntime is a InstanceHealthCheckType enum value
+	InstanceHealthCheckTypeContainerRuntime = "CONTAINER_RUNTIME"
+)
+
 const (
 	// IpcModeHost is a IpcMode enum value
 	IpcModeHost = "host"
@@ -11837,6 +13221,9 @@ const (
 
 	// LaunchTypeFargate is a LaunchType enum value
 	LaunchTypeFargate = "FARGATE"
+
+	// LaunchTypeExternal is a LaunchType enum value
+	LaunchTypeExternal = "EXTERNAL"
 )
 
 const (
@@ -11862,6 +13249,11 @@ const (
 	LogDriverSplunk = "splunk"
 )
 
+const (
+	// ManagedAgentNameExecuteCommandAgent is a ManagedAgentName enum value
+	ManagedAgentNameExecuteCommandAgent = "ExecuteCommandAgent"
+)
+
 const (
 	// NetworkModeBridge is a NetworkMode enum value
 	NetworkModeBridge = "bridge"
@@ -11903,6 +13295,22 @@ const (
 	PlacementStrategyTypeBinpack = "binpack"
 )
 
+const (
+	// PropagateTagsTaskDefinition is a PropagateTags enum value
+	PropagateTagsTaskDefinition = "TASK_DEFINITION"
+
+	// PropagateTagsService is a PropagateTags enum value
+	PropagateTagsService = "SERVICE"
+
+	// PropagateTagsNone is a PropagateTags enum value
+	PropagateTagsNone = "NONE"
+)
+
+const (
+	// ScaleUnitPercent is a ScaleUnit enum value
+	ScaleUnitPercent = "PERCENT"
+)
+
 const (
 	// SchedulingStrategyReplica is a SchedulingStrategy enum value
 	SchedulingStrategyReplica = "REPLICA"
@@ -0,0 +1,2316 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/pkg/errors"
+)
+
+// MissingTasksError is returned when a DescribeTasks response does not
+// include every task that was requested.
+type MissingTasksError struct {
+	// Arns lists the requested task ARNs (or IDs) that were not returned
+	Arns []string
+	// Failures holds the failures reported by ECS for the missing tasks, if any
+	Failures []*Failure
+}
+
+func (err *MissingTasksError) Error() string {
+	return fmt.Sprintf("describe tasks: %d of the requested tasks were not returned: %s",
+		len(err.Arns), strings.Join(err.Arns, ", "))
+}
+
+// CheckDescribeTasksComplete verifies that every task in requested was
+// returned in the Tasks of output. A *MissingTasksError listing the missing
+// tasks is returned otherwise, including the tasks that ECS reported back as
+// failures.
+func CheckDescribeTasksComplete(requested []string, output *DescribeTasksOutput) error {
+	if output == nil {
+		output = &DescribeTasksOutput{}
+	}
+	if len(requested) == len(output.Tasks)+len(output.Failures) && len(output.Failures) == 0 {
+		return nil
+	}
+
+	var missing []string
+	var failures []*Failure
+	for _, arn := range requested {
+		if containsTask(output.Tasks, arn) {
+			continue
+		}
+		missing = append(missing, arn)
+		if failure := findFailure(output.Failures, arn); failure != nil {
+			failures = append(failures, failure)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &MissingTasksError{Arns: missing, Failures: failures}
+}
+
+// TasksByArn indexes the described tasks by task ARN. The map is empty, not
+// nil, when there are no tasks.
+func (o *DescribeTasksOutput) TasksByArn() map[string]*Task {
+	tasks := make(map[string]*Task)
+	if o == nil {
+		return tasks
+	}
+	for _, task := range o.Tasks {
+		if task != nil {
+			tasks[aws.StringValue(task.TaskArn)] = task
+		}
+	}
+	return tasks
+}
+
+// FailuresByArn indexes the failures of the response by ARN. The map is
+// empty, not nil, when there are no failures.
+func (o *DescribeTasksOutput) FailuresByArn() map[string]*Failure {
+	failures := make(map[string]*Failure)
+	if o == nil {
+		return failures
+	}
+	for _, failure := range o.Failures {
+		if failure != nil {
+			failures[aws.StringValue(failure.Arn)] = failure
+		}
+	}
+	return failures
+}
+
+// FilterTasksByStatus returns the tasks whose last status is status, such as
+// RUNNING, in their original order. DescribeTasks cannot filter the tasks it
+// describes, so this is how they are filtered by their last status.
+func FilterTasksByStatus(tasks []*Task, status string) []*Task {
+	var filtered []*Task
+	for _, task := range tasks {
+		if task != nil && aws.StringValue(task.LastStatus) == status {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+// FilterClustersByPrefix returns the cluster ARNs whose cluster name starts
+// with prefix, in their original order. ListClusters cannot filter the
+// clusters it lists, so this is how they are filtered by name.
+func FilterClustersByPrefix(arns []string, prefix string) []string {
+	var filtered []string
+	for _, arn := range arns {
+		if strings.HasPrefix(arn[strings.LastIndex(arn, "/")+1:], prefix) {
+			filtered = append(filtered, arn)
+		}
+	}
+	return filtered
+}
+
+// Filter selects the container instances whose field named by Name, one of
+// FilterNameStatus and FilterNameAgentConnected, has one of Values. It is not
+// a parameter of the API, which cannot filter the container instances it
+// describes: filters are applied to the described container instances with
+// FilterContainerInstances.
+type Filter struct {
+	// Name is the name of the field to filter on
+	Name *string
+
+	// Values are the values that match the filter, for example ACTIVE and
+	// DRAINING for status, or true for agentConnected
+	Values []*string
+}
+
+// matches returns true if the container instance has one of the values of
+// the filter
+func (f *Filter) matches(instance *ContainerInstance) bool {
+	var value string
+	switch aws.StringValue(f.Name) {
+	case FilterNameStatus:
+		value = aws.StringValue(instance.Status)
+	case FilterNameAgentConnected:
+		value = strconv.FormatBool(aws.BoolValue(instance.AgentConnected))
+	}
+	for _, v := range f.Values {
+		if aws.StringValue(v) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterContainerInstances returns the container instances that match every
+// filter, in their original order. It returns a request.ErrInvalidParams if
+// a filter has no name or an unknown one.
+func FilterContainerInstances(instances []*ContainerInstance, filters []*Filter) ([]*ContainerInstance, error) {
+	invalidParams := request.ErrInvalidParams{Context: "FilterContainerInstances"}
+	for i, filter := range filters {
+		if filter != nil {
+			addNestedCustom(&invalidParams, fmt.Sprintf("%s[%v]", "Filters", i), filter)
+		}
+	}
+	if invalidParams.Len() > 0 {
+		return nil, invalidParams
+	}
+
+	var filtered []*ContainerInstance
+	for _, instance := range instances {
+		if instance == nil {
+			continue
+		}
+		matched := true
+		for _, filter := range filters {
+			if filter != nil && !filter.matches(instance) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			filtered = append(filtered, instance)
+		}
+	}
+	return filtered, nil
+}
+
+// AttributeFilter returns the cluster query language expression selecting
+// the container instances that have every attribute of attrs, such as
+// "attribute:env == prod and attribute:tier == backend", for the Filter of
+// ListContainerInstancesInput. An attribute with an empty value only needs to
+// exist. The attributes are sorted by name, and an empty map gives an empty
+// expression, which does not filter.
+func AttributeFilter(attrs map[string]string) string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	conditions := make([]string, len(names))
+	for i, name := range names {
+		if attrs[name] == "" {
+			conditions[i] = fmt.Sprintf("attribute:%s exists", name)
+		} else {
+			conditions[i] = fmt.Sprintf("attribute:%s == %s", name, attrs[name])
+		}
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// ConstraintBuilder builds the cluster query language expression of a
+// memberOf placement constraint, grouping the expressions it combines with
+// parentheses:
+//
+//	expression, err := NewConstraintBuilder().
+//		AttributeEquals("ecs.instance-type", "t3.micro").
+//		Or(NewConstraintBuilder().AttributeExists("gpu")).
+//		Build()
+type ConstraintBuilder struct {
+	expression string
+	// or is true if the expression is a disjunction, which must be grouped
+	// before it is combined with and
+	or bool
+	// err is the first invalid attribute name or value added, reported by
+	// Build
+	err error
+}
+
+// NewConstraintBuilder creates an empty ConstraintBuilder
+func NewConstraintBuilder() *ConstraintBuilder {
+	return &ConstraintBuilder{}
+}
+
+// AttributeEquals requires the attribute key to have the value val. The
+// cluster query language cannot quote a value, so a key or value that is
+// empty or contains a space, a parenthesis or one of =!&| makes Build fail.
+func (b *ConstraintBuilder) AttributeEquals(key, val string) *ConstraintBuilder {
+	b.checkWord("attribute name", key)
+	b.checkWord("attribute value", val)
+	return b.and(fmt.Sprintf("attribute:%s == %s", key, val))
+}
+
+// AttributeExists requires the attribute key to be set, with any value
+func (b *ConstraintBuilder) AttributeExists(key string) *ConstraintBuilder {
+	b.checkWord("attribute name", key)
+	return b.and(fmt.Sprintf("attribute:%s exists", key))
+}
+
+// And requires the expression of other as well. An empty other is ignored.
+func (b *ConstraintBuilder) And(other *ConstraintBuilder) *ConstraintBuilder {
+	if other == nil {
+		return b
+	}
+	if b.err == nil {
+		b.err = other.err
+	}
+	if other.expression == "" {
+		return b
+	}
+	return b.and("(" + other.expression + ")")
+}
+
+// Or accepts the expression of other instead. An empty other is ignored.
+func (b *ConstraintBuilder) Or(other *ConstraintBuilder) *ConstraintBuilder {
+	if other == nil {
+		return b
+	}
+	if b.err == nil {
+		b.err = other.err
+	}
+	if other.expression == "" {
+		return b
+	}
+	if b.expression == "" {
+		b.expression, b.or = other.expression, other.or
+		return b
+	}
+	b.expression = "(" + b.expression + ") or (" + other.expression + ")"
+	b.or = true
+	return b
+}
+
+// Build returns the expression, or an empty expression, which does not
+// constrain placement, if nothing was added. An error is returned if an
+// attribute name or value cannot be written in the expression.
+func (b *ConstraintBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return b.expression, nil
+}
+
+// constraintSeparators are the characters that end a word of the cluster
+// query language, which has no quoting
+const constraintSeparators = " \t()=!&|"
+
+// checkWord records an error if word cannot be written as a single word of
+// the expression, unless an error was already recorded
+func (b *ConstraintBuilder) checkWord(kind, word string) {
+	if b.err != nil {
+		return
+	}
+	if word == "" {
+		b.err = fmt.Errorf("build constraint: empty %s", kind)
+	} else if strings.ContainsAny(word, constraintSeparators) {
+		b.err = fmt.Errorf("build constraint: %s %q contains one of %q", kind, word, constraintSeparators)
+	}
+}
+
+// and adds a condition that must hold along with the expression
+func (b *ConstraintBuilder) and(condition string) *ConstraintBuilder {
+	switch {
+	case b.expression == "":
+		b.expression = condition
+	case b.or:
+		b.expression = "(" + b.expression + ") and " + condition
+		b.or = false
+	default:
+		b.expression += " and " + condition
+	}
+	return b
+}
+
+// UnknownContainerInstancesError is returned when some of the container
+// instance ids given to DescribeContainerInstancesByShortId are not
+// registered in the cluster.
+type UnknownContainerInstancesError struct {
+	// ShortIds lists the ids that did not match any container instance
+	ShortIds []string
+}
+
+func (err *UnknownContainerInstancesError) Error() string {
+	return fmt.Sprintf("describe container instances: no container instance found for ids: %s",
+		strings.Join(err.ShortIds, ", "))
+}
+
+// DescribeContainerInstancesByShortId describes container instances of a
+// cluster given only their ids. The full ARNs are first resolved by listing
+// the container instances of the cluster and matching the ids against the end
+// of each ARN. An *UnknownContainerInstancesError is returned if any id has
+// no match, without describing the others. The container instances are
+// described up to 100 at a time, and the outputs merged.
+func (c *ECS) DescribeContainerInstancesByShortId(ctx aws.Context, cluster string, shortIds []string, opts ...request.Option) (*DescribeContainerInstancesOutput, error) {
+	var arns []string
+	err := c.ListContainerInstancesPagesWithContext(ctx, &ListContainerInstancesInput{
+		Cluster: aws.String(cluster),
+	}, func(output *ListContainerInstancesOutput, lastPage bool) bool {
+		arns = append(arns, aws.StringValueSlice(output.ContainerInstanceArns)...)
+		return true
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved []string
+	var unknown []string
+	for _, shortId := range shortIds {
+		arn := findArn(arns, shortId)
+		if arn == "" {
+			unknown = append(unknown, shortId)
+			continue
+		}
+		resolved = append(resolved, arn)
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownContainerInstancesError{ShortIds: unknown}
+	}
+
+	merged := &DescribeContainerInstancesOutput{}
+	for start := 0; start < len(resolved); start += maxDescribeContainerInstances {
+		end := start + maxDescribeContainerInstances
+		if end > len(resolved) {
+			end = len(resolved)
+		}
+		described, err := c.DescribeContainerInstancesWithContext(ctx, &DescribeContainerInstancesInput{
+			Cluster:            aws.String(cluster),
+			ContainerInstances: aws.StringSlice(resolved[start:end]),
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		merged.ContainerInstances = append(merged.ContainerInstances, described.ContainerInstances...)
+		merged.Failures = append(merged.Failures, described.Failures...)
+	}
+	return merged, nil
+}
+
+// maxDescribeContainerInstances is the number of container instances that
+// can be described by a single DescribeContainerInstances call
+const maxDescribeContainerInstances = 100
+
+// DuplicateContainerInstancesError is returned by
+// FindExistingContainerInstance when several container instances of the
+// cluster run on the same EC2 instance.
+type DuplicateContainerInstancesError struct {
+	// Ec2InstanceId is the EC2 instance that was looked up
+	Ec2InstanceId string
+	// Arns lists the container instances running on the EC2 instance
+	Arns []string
+}
+
+func (err *DuplicateContainerInstancesError) Error() string {
+	return fmt.Sprintf("find existing container instance: %d container instances found for %s: %s",
+		len(err.Arns), err.Ec2InstanceId, strings.Join(err.Arns, ", "))
+}
+
+// FindExistingContainerInstance returns the container instance of the
+// cluster running on an EC2 instance, so that a retried registration can
+// reuse it instead of registering a duplicate. Every container instance of
+// the cluster is listed and described, up to 100 at a time. nil is returned
+// if none runs on the EC2 instance, and a *DuplicateContainerInstancesError
+// if several do.
+func (c *ECS) FindExistingContainerInstance(ctx aws.Context, cluster, ec2InstanceId string) (*ContainerInstance, error) {
+	if ec2InstanceId == "" {
+		return nil, errors.New("find existing container instance: no EC2 instance id given")
+	}
+	var arns []string
+	err := c.ListContainerInstancesPagesWithContext(ctx, &ListContainerInstancesInput{
+		Cluster: aws.String(cluster),
+	}, func(output *ListContainerInstancesOutput, lastPage bool) bool {
+		arns = append(arns, aws.StringValueSlice(output.ContainerInstanceArns)...)
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "find existing container instance: unable to list container instances")
+	}
+
+	var matches []*ContainerInstance
+	for start := 0; start < len(arns); start += maxDescribeContainerInstances {
+		end := start + maxDescribeContainerInstances
+		if end > len(arns) {
+			end = len(arns)
+		}
+		described, err := c.DescribeContainerInstancesWithContext(ctx, &DescribeContainerInstancesInput{
+			Cluster:            aws.String(cluster),
+			ContainerInstances: aws.StringSlice(arns[start:end]),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "find existing container instance: unable to describe container instances")
+		}
+		for _, instance := range described.ContainerInstances {
+			if instance != nil && aws.StringValue(instance.Ec2InstanceId) == ec2InstanceId {
+				matches = append(matches, instance)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	duplicateErr := &DuplicateContainerInstancesError{Ec2InstanceId: ec2InstanceId}
+	for _, instance := range matches {
+		duplicateErr.Arns = append(duplicateErr.Arns, aws.StringValue(instance.ContainerInstanceArn))
+	}
+	return nil, duplicateErr
+}
+
+// ec2InstanceIdAttribute is the attribute holding the EC2 instance id of a
+// container instance
+const ec2InstanceIdAttribute = "ecs.instance-id"
+
+// ec2InstanceIdFilter returns the cluster query language expression
+// selecting the container instances running on any of the EC2 instances, such
+// as "attribute:ecs.instance-id in [i-1, i-2]"
+func ec2InstanceIdFilter(ec2Ids []string) string {
+	return fmt.Sprintf("attribute:%s in [%s]", ec2InstanceIdAttribute, strings.Join(ec2Ids, ", "))
+}
+
+// DescribeContainerInstancesByEc2Id describes the container instances of a
+// cluster running on EC2 instances, given their ids. The container instances
+// are listed with a filter on their ecs.instance-id attribute, for up to 100
+// EC2 instances at a time, then described. An EC2 instance without a
+// container instance is reported as a MISSING failure, keyed by its id.
+func (c *ECS) DescribeContainerInstancesByEc2Id(ctx aws.Context, cluster string, ec2Ids []string) ([]*ContainerInstance, []*Failure, error) {
+	var instances []*ContainerInstance
+	var failures []*Failure
+	found := make(map[string]bool, len(ec2Ids))
+	for start := 0; start < len(ec2Ids); start += maxDescribeContainerInstances {
+		end := start + maxDescribeContainerInstances
+		if end > len(ec2Ids) {
+			end = len(ec2Ids)
+		}
+		wanted := make(map[string]bool, end-start)
+		for _, ec2Id := range ec2Ids[start:end] {
+			wanted[ec2Id] = true
+		}
+
+		var arns []string
+		err := c.ListContainerInstancesPagesWithContext(ctx, &ListContainerInstancesInput{
+			Cluster: aws.String(cluster),
+			Filter:  aws.String(ec2InstanceIdFilter(ec2Ids[start:end])),
+		}, func(output *ListContainerInstancesOutput, lastPage bool) bool {
+			arns = append(arns, aws.StringValueSlice(output.ContainerInstanceArns)...)
+			return true
+		})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "describe container instances by EC2 instance id: unable to list container instances")
+		}
+
+		for describeStart := 0; describeStart < len(arns); describeStart += maxDescribeContainerInstances {
+			describeEnd := describeStart + maxDescribeContainerInstances
+			if describeEnd > len(arns) {
+				describeEnd = len(arns)
+			}
+			described, err := c.DescribeContainerInstancesWithContext(ctx, &DescribeContainerInstancesInput{
+				Cluster:            aws.String(cluster),
+				ContainerInstances: aws.StringSlice(arns[describeStart:describeEnd]),
+			})
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "describe container instances by EC2 instance id: unable to describe container instances")
+			}
+			for _, instance := range described.ContainerInstances {
+				// Only keep the instances the filter selects, should the
+				// filter not be applied
+				if instance == nil || !wanted[aws.StringValue(instance.Ec2InstanceId)] {
+					continue
+				}
+				found[aws.StringValue(instance.Ec2InstanceId)] = true
+				instances = append(instances, instance)
+			}
+			failures = append(failures, described.Failures...)
+		}
+	}
+
+	for _, ec2Id := range ec2Ids {
+		if !found[ec2Id] {
+			failures = append(failures, &Failure{Arn: aws.String(ec2Id), Reason: aws.String("MISSING")})
+		}
+	}
+	return instances, failures, nil
+}
+
+// maxDescribeServices is the number of services that can be described by a
+// single DescribeServices call
+const maxDescribeServices = 10
+
+// DescribeAllServicesInput selects the services described by
+// DescribeAllServices
+type DescribeAllServicesInput struct {
+	// Cluster hosting the services. The default cluster is assumed if unset.
+	Cluster *string
+	// IncludeTags sets the Tags of each service, at the cost of one extra
+	// ListTagsForResource call per service
+	IncludeTags *bool
+}
+
+// DescribeAllServices describes every service of a cluster. The services are
+// listed with ListServices and described in batches of 10. The failures of
+// all the batches are collected in the output.
+func (c *ECS) DescribeAllServices(ctx aws.Context, input *DescribeAllServicesInput, opts ...request.Option) (*DescribeServicesOutput, error) {
+	var arns []*string
+	err := c.ListServicesPagesWithContext(ctx, &ListServicesInput{
+		Cluster: input.Cluster,
+	}, func(output *ListServicesOutput, lastPage bool) bool {
+		arns = append(arns, output.ServiceArns...)
+		return true
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	described := &DescribeServicesOutput{}
+	for start := 0; start < len(arns); start += maxDescribeServices {
+		end := start + maxDescribeServices
+		if end > len(arns) {
+			end = len(arns)
+		}
+		output, err := c.DescribeServicesWithContext(ctx, &DescribeServicesInput{
+			Cluster:  input.Cluster,
+			Services: arns[start:end],
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		described.Services = append(described.Services, output.Services...)
+		described.Failures = append(described.Failures, output.Failures...)
+	}
+
+	if !aws.BoolValue(input.IncludeTags) {
+		return described, nil
+	}
+	for _, service := range described.Services {
+		output, err := c.ListTagsForResourceWithContext(ctx, &ListTagsForResourceInput{
+			ResourceArn: service.ServiceArn,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+		service.Tags = output.Tags
+	}
+	return described, nil
+}
+
+// safeDeregisterPollInterval is how often SafeDeregisterContainerInstance
+// checks whether the tasks of the instance have stopped
+var safeDeregisterPollInterval = 5 * time.Second
+
+// SafeDeregisterContainerInstance drains a container instance before
+// deregistering it. The instance is set to DRAINING first, then the tasks
+// running on it are polled until they have all stopped, for up to
+// gracePeriod. The instance is only deregistered once it has no running tasks
+// left, so that no task is orphaned; an error is returned if tasks are still
+// running after gracePeriod.
+func (c *ECS) SafeDeregisterContainerInstance(ctx aws.Context, cluster, containerInstanceArn string, gracePeriod time.Duration) error {
+	drained, err := c.UpdateContainerInstancesStateWithContext(ctx, &UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(cluster),
+		ContainerInstances: aws.StringSlice([]string{containerInstanceArn}),
+		Status:             aws.String(ContainerInstanceStatusDraining),
+	})
+	if err != nil {
+		return err
+	}
+	if len(drained.Failures) > 0 {
+		return fmt.Errorf("deregister container instance: unable to drain %s: %s",
+			containerInstanceArn, aws.StringValue(drained.Failures[0].Reason))
+	}
+
+	deadline := time.Now().Add(gracePeriod)
+	for {
+		running, err := c.ListTasksWithContext(ctx, &ListTasksInput{
+			Cluster:           aws.String(cluster),
+			ContainerInstance: aws.String(containerInstanceArn),
+			DesiredStatus:     aws.String(DesiredStatusRunning),
+		})
+		if err != nil {
+			return err
+		}
+		if len(running.TaskArns) == 0 {
+			break
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("deregister container instance: %d tasks still running on %s after %s",
+				len(running.TaskArns), containerInstanceArn, gracePeriod)
+		}
+		wait := safeDeregisterPollInterval
+		if remaining < wait {
+			wait = remaining
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	_, err = c.DeregisterContainerInstanceWithContext(ctx, &DeregisterContainerInstanceInput{
+		Cluster:           aws.String(cluster),
+		ContainerInstance: aws.String(containerInstanceArn),
+		Force:             aws.Bool(false),
+	})
+	return err
+}
+
+// DefaultDrainTimeout is a reasonable time for DrainAndWait to wait for tasks
+// to stop
+const DefaultDrainTimeout = 5 * time.Minute
+
+// maxDrainTimeout is the longest time DrainAndWait waits for tasks to stop
+const maxDrainTimeout = time.Hour
+
+// drainAndWaitPollInterval is how often DrainAndWait checks whether the tasks
+// of the draining instances have stopped
+var drainAndWaitPollInterval = 5 * time.Second
+
+// DrainAndWait sets container instances to DRAINING and waits up to timeout,
+// between 0 and an hour, for the tasks running on them to stop. Tasks still
+// running after the timeout are stopped with StopTask, and their ARNs are
+// returned.
+func (c *ECS) DrainAndWait(ctx aws.Context, input *UpdateContainerInstancesStateInput, timeout time.Duration) ([]string, error) {
+	if aws.StringValue(input.Status) != ContainerInstanceStatusDraining {
+		return nil, fmt.Errorf("drain container instances: status must be %s, not %s",
+			ContainerInstanceStatusDraining, aws.StringValue(input.Status))
+	}
+	if timeout < 0 || timeout > maxDrainTimeout {
+		return nil, fmt.Errorf("drain container instances: timeout must be between 0 and %s, not %s",
+			maxDrainTimeout, timeout)
+	}
+
+	drained, err := c.UpdateContainerInstancesStateWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(drained.Failures) > 0 {
+		return nil, fmt.Errorf("drain container instances: unable to drain %s: %s",
+			aws.StringValue(drained.Failures[0].Arn), aws.StringValue(drained.Failures[0].Reason))
+	}
+
+	deadline := time.Now().Add(timeout)
+	var running []string
+	for {
+		running = running[:0]
+		for _, instance := range drained.ContainerInstances {
+			err := c.ListTasksPagesWithContext(ctx, &ListTasksInput{
+				Cluster:           input.Cluster,
+				ContainerInstance: instance.ContainerInstanceArn,
+				DesiredStatus:     aws.String(DesiredStatusRunning),
+			}, func(page *ListTasksOutput, lastPage bool) bool {
+				running = append(running, aws.StringValueSlice(page.TaskArns)...)
+				return true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		remaining := time.Until(deadline)
+		if len(running) == 0 || remaining <= 0 {
+			break
+		}
+		wait := drainAndWaitPollInterval
+		if remaining < wait {
+			wait = remaining
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	var stopped []string
+	for _, taskArn := range running {
+		_, err := c.StopTaskWithContext(ctx, &StopTaskInput{
+			Cluster: input.Cluster,
+			Task:    aws.String(taskArn),
+			Reason:  aws.String(fmt.Sprintf("Container instance drain timed out after %s", timeout)),
+		})
+		if err != nil {
+			return stopped, err
+		}
+		stopped = append(stopped, taskArn)
+	}
+	return stopped, nil
+}
+
+// SelectDrainCandidates picks the n container instances that are cheapest to
+// drain: those running the fewest tasks, the oldest registered first among
+// equals. Instances already DRAINING are skipped. Fewer than n instances are
+// returned if there are not enough candidates.
+func SelectDrainCandidates(instances []*ContainerInstance, n int) []*ContainerInstance {
+	if n <= 0 {
+		return nil
+	}
+	var candidates []*ContainerInstance
+	for _, instance := range instances {
+		if instance != nil && aws.StringValue(instance.Status) != ContainerInstanceStatusDraining {
+			candidates = append(candidates, instance)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		left, right := aws.Int64Value(candidates[i].RunningTasksCount), aws.Int64Value(candidates[j].RunningTasksCount)
+		if left != right {
+			return left < right
+		}
+		return aws.TimeValue(candidates[i].RegisteredAt).Before(aws.TimeValue(candidates[j].RegisteredAt))
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// waitForServiceStablePollInterval is how often WaitForServiceStable
+// describes the service
+var waitForServiceStablePollInterval = 15 * time.Second
+
+// WaitForServiceStable polls a service until it is stable and returns it. The
+// service is stable once it has a single deployment, which runs as many tasks
+// as desired. Unlike Service.IsStable, tasks may still be pending, as they are
+// while a stopped task is replaced. If the service is not stable within
+// timeout, or before ctx is done, the last observed service is returned along
+// with the context error, wrapped.
+func (c *ECS) WaitForServiceStable(ctx aws.Context, cluster, service string, timeout time.Duration) (*Service, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var last *Service
+	for {
+		output, err := c.DescribeServicesWithContext(ctx, &DescribeServicesInput{
+			Cluster:  aws.String(cluster),
+			Services: aws.StringSlice([]string{service}),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return last, errors.Wrapf(ctx.Err(), "wait for service %s to be stable", service)
+			}
+			return last, err
+		}
+		if len(output.Services) == 0 {
+			reason := "MISSING"
+			if len(output.Failures) > 0 {
+				reason = aws.StringValue(output.Failures[0].Reason)
+			}
+			return last, errors.Errorf("wait for service %s to be stable: unable to describe service: %s", service, reason)
+		}
+		last = output.Services[0]
+		if last.deploymentComplete() {
+			return last, nil
+		}
+
+		timer := time.NewTimer(waitForServiceStablePollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, errors.Wrapf(ctx.Err(), "wait for service %s to be stable", service)
+		case <-timer.C:
+		}
+	}
+}
+
+// IsStable returns true if the service has converged: it has a single
+// deployment, which runs as many tasks as desired and has no pending task
+func (s *Service) IsStable() bool {
+	if s == nil || len(s.Deployments) != 1 || s.Deployments[0] == nil {
+		return false
+	}
+	deployment := s.Deployments[0]
+	return aws.Int64Value(deployment.RunningCount) == aws.Int64Value(deployment.DesiredCount) &&
+		aws.Int64Value(deployment.PendingCount) == 0
+}
+
+// deploymentComplete returns true if the service has a single deployment,
+// which runs as many tasks as desired
+func (s *Service) deploymentComplete() bool {
+	if s == nil || len(s.Deployments) != 1 || s.Deployments[0] == nil {
+		return false
+	}
+	deployment := s.Deployments[0]
+	return aws.Int64Value(deployment.RunningCount) == aws.Int64Value(deployment.DesiredCount)
+}
+
+// Age returns how long ago the service was created, or 0 if its creation
+// time is not known
+func (s *Service) Age() time.Duration {
+	if s == nil || s.CreatedAt == nil {
+		return 0
+	}
+	return time.Since(*s.CreatedAt)
+}
+
+// TruncateEvents returns the service events created within maxAge, in their
+// original order. Events without a creation time are kept, as their age is
+// not known. DescribeServices only returns the 100 most recent events of a
+// service, which can span anything from minutes to weeks.
+func TruncateEvents(events []*ServiceEvent, maxAge time.Duration) []*ServiceEvent {
+	cutoff := time.Now().Add(-maxAge)
+	var recent []*ServiceEvent
+	for _, event := range events {
+		if event == nil || (event.CreatedAt != nil && event.CreatedAt.Before(cutoff)) {
+			continue
+		}
+		recent = append(recent, event)
+	}
+	return recent
+}
+
+// ServiceFieldChange is a field that differs between two revisions of a
+// service, with its values rendered as strings. Structures and lists are
+// rendered as their API JSON, and an unset value as the empty string.
+type ServiceFieldChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// CompareServices returns the changes from old to new of the fields of a
+// service that a deployment changes: its task definition, desired count,
+// deployment configuration, load balancers, network configuration and
+// placement strategy, in that order. A nil service is compared as an empty
+// one.
+func CompareServices(old, new *Service) []ServiceFieldChange {
+	if old == nil {
+		old = &Service{}
+	}
+	if new == nil {
+		new = &Service{}
+	}
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"TaskDefinition", aws.StringValue(old.TaskDefinition), aws.StringValue(new.TaskDefinition)},
+		{"DesiredCount", serviceFieldInt(old.DesiredCount), serviceFieldInt(new.DesiredCount)},
+		{"DeploymentConfiguration", serviceFieldJSON(old.DeploymentConfiguration), serviceFieldJSON(new.DeploymentConfiguration)},
+		{"LoadBalancers", serviceFieldJSON(old.LoadBalancers), serviceFieldJSON(new.LoadBalancers)},
+		{"NetworkConfiguration", serviceFieldJSON(old.NetworkConfiguration), serviceFieldJSON(new.NetworkConfiguration)},
+		{"PlacementStrategy", serviceFieldJSON(old.PlacementStrategy), serviceFieldJSON(new.PlacementStrategy)},
+	}
+
+	var changes []ServiceFieldChange
+	for _, field := range fields {
+		if field.old != field.new {
+			changes = append(changes, ServiceFieldChange{
+				Field:    field.name,
+				OldValue: field.old,
+				NewValue: field.new,
+			})
+		}
+	}
+	return changes
+}
+
+func serviceFieldInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+func serviceFieldJSON(v interface{}) string {
+	value := reflect.ValueOf(v)
+	if (value.Kind() == reflect.Ptr && value.IsNil()) || (value.Kind() == reflect.Slice && value.Len() == 0) {
+		return ""
+	}
+	body, err := jsonutil.BuildJSON(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(body)
+}
+
+func findArn(arns []string, identifier string) string {
+	for _, arn := range arns {
+		if arnMatches(arn, identifier) {
+			return arn
+		}
+	}
+	return ""
+}
+
+func containsTask(tasks []*Task, arn string) bool {
+	for _, task := range tasks {
+		if task != nil && arnMatches(aws.StringValue(task.TaskArn), arn) {
+			return true
+		}
+	}
+	return false
+}
+
+func findFailure(failures []*Failure, arn string) *Failure {
+	for _, failure := range failures {
+		if failure != nil && arnMatches(aws.StringValue(failure.Arn), arn) {
+			return failure
+		}
+	}
+	return nil
+}
+
+// arnMatches returns true if identifier is either the full arn or the
+// resource id at the end of it
+func arnMatches(arn, identifier string) bool {
+	if arn == "" || identifier == "" {
+		return false
+	}
+	return arn == identifier || strings.HasSuffix(arn, "/"+identifier)
+}
+
+// GetAttribute returns the value of the named attribute of the container
+// instance, and whether the container instance has it
+func (ci *ContainerInstance) GetAttribute(name string) (string, bool) {
+	for _, attribute := range ci.Attributes {
+		if attribute != nil && aws.StringValue(attribute.Name) == name {
+			return aws.StringValue(attribute.Value), true
+		}
+	}
+	return "", false
+}
+
+// IsConnected returns true if the task has reported being CONNECTED
+func (t *Task) IsConnected() bool {
+	return t != nil && aws.StringValue(t.Connectivity) == ConnectivityConnected
+}
+
+// rolloutStateMessages describe each rollout state of a deployment
+var rolloutStateMessages = map[string]string{
+	DeploymentRolloutStateCompleted:  "deployment completed",
+	DeploymentRolloutStateFailed:     "circuit breaker triggered rollback",
+	DeploymentRolloutStateInProgress: "deployment in progress",
+}
+
+// NormalizeCapacityProviderStrategy returns a copy of a capacity provider
+// strategy that can be compared against the one reported by DescribeServices.
+// ECS reports an omitted base or weight as 0 and does not preserve the order
+// of the items, so unset values are set to 0 and the items are sorted by
+// capacity provider.
+func NormalizeCapacityProviderStrategy(strategy []*CapacityProviderStrategyItem) []*CapacityProviderStrategyItem {
+	if len(strategy) == 0 {
+		return nil
+	}
+	normalized := make([]*CapacityProviderStrategyItem, 0, len(strategy))
+	for _, item := range strategy {
+		if item == nil {
+			continue
+		}
+		normalized = append(normalized, &CapacityProviderStrategyItem{
+			CapacityProvider: aws.String(aws.StringValue(item.CapacityProvider)),
+			Base:             aws.Int64(aws.Int64Value(item.Base)),
+			Weight:           aws.Int64(aws.Int64Value(item.Weight)),
+		})
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return aws.StringValue(normalized[i].CapacityProvider) < aws.StringValue(normalized[j].CapacityProvider)
+	})
+	return normalized
+}
+
+// DeploymentCircuitBreakerMessage returns a human readable description of the
+// rollout state of a deployment, such as "FAILED: circuit breaker triggered
+// rollback". The reason reported by ECS, if any, is appended to it.
+func DeploymentCircuitBreakerMessage(deployment *Deployment) string {
+	if deployment == nil || deployment.RolloutState == nil {
+		return "rollout state not reported"
+	}
+	state := aws.StringValue(deployment.RolloutState)
+	message, ok := rolloutStateMessages[state]
+	if !ok {
+		message = "unknown rollout state"
+	}
+	message = state + ": " + message
+	if reason := aws.StringValue(deployment.RolloutStateReason); reason != "" {
+		message += " (" + reason + ")"
+	}
+	return message
+}
+
+// Age returns how long ago the task definition was registered, or 0 if its
+// registration time is not known
+func (td *TaskDefinition) Age() time.Duration {
+	if td == nil || td.RegisteredAt == nil {
+		return 0
+	}
+	return time.Since(*td.RegisteredAt)
+}
+
+// RequiredAttributeMap returns the attributes required by the task definition
+// keyed by name. Attributes that are required without a value map to the
+// empty string.
+func (td *TaskDefinition) RequiredAttributeMap() map[string]string {
+	attributes := make(map[string]string)
+	if td == nil {
+		return attributes
+	}
+	for _, attribute := range td.RequiresAttributes {
+		if attribute == nil {
+			continue
+		}
+		attributes[aws.StringValue(attribute.Name)] = aws.StringValue(attribute.Value)
+	}
+	return attributes
+}
+
+// TaskDefinitionSummary identifies a task definition revision returned by
+// ListTaskDefinitionSummaries
+type TaskDefinitionSummary struct {
+	Arn    string
+	Family string
+	// Revision is 0 if the ARN has no revision suffix
+	Revision int64
+	Status   string
+}
+
+// ListTaskDefinitionSummaries lists the task definitions matching
+// familyPrefix and status, going through all the pages of ListTaskDefinitions.
+// Empty arguments are not sent, in which case the API lists the ACTIVE
+// revisions of every family. The family and revision of each summary are
+// parsed from its ARN.
+func (c *ECS) ListTaskDefinitionSummaries(ctx aws.Context, familyPrefix, status string) ([]*TaskDefinitionSummary, error) {
+	input := &ListTaskDefinitionsInput{}
+	if familyPrefix != "" {
+		input.FamilyPrefix = aws.String(familyPrefix)
+	}
+	if status == "" {
+		status = TaskDefinitionStatusActive
+	} else {
+		input.Status = aws.String(status)
+	}
+
+	var summaries []*TaskDefinitionSummary
+	err := c.ListTaskDefinitionsPagesWithContext(ctx, input, func(output *ListTaskDefinitionsOutput, lastPage bool) bool {
+		for _, arn := range aws.StringValueSlice(output.TaskDefinitionArns) {
+			summary := parseTaskDefinitionArn(arn)
+			summary.Status = status
+			summaries = append(summaries, summary)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// parseTaskDefinitionArn returns the summary of the task definition ARN
+// arn:aws:ecs:region:account:task-definition/family:revision, without its
+// status
+func parseTaskDefinitionArn(arn string) *TaskDefinitionSummary {
+	summary := &TaskDefinitionSummary{Arn: arn}
+	familyRevision := arn[strings.LastIndex(arn, "/")+1:]
+	summary.Family = familyRevision
+	if i := strings.LastIndex(familyRevision, ":"); i >= 0 {
+		if revision, err := strconv.ParseInt(familyRevision[i+1:], 10, 64); err == nil {
+			summary.Family = familyRevision[:i]
+			summary.Revision = revision
+		}
+	}
+	return summary
+}
+
+// IterateTaskDefinitionRevisions calls fn with each ACTIVE revision of family,
+// newest first, describing each revision as it goes through the pages of
+// ListTaskDefinitions. It stops once fn returns false or every revision was
+// visited, and returns the first error of either API.
+func (c *ECS) IterateTaskDefinitionRevisions(ctx aws.Context, family string, fn func(td *TaskDefinition) bool) error {
+	var describeErr error
+	err := c.ListTaskDefinitionsPagesWithContext(ctx, &ListTaskDefinitionsInput{
+		FamilyPrefix: aws.String(family),
+		Sort:         aws.String(SortOrderDesc),
+	}, func(output *ListTaskDefinitionsOutput, lastPage bool) bool {
+		for _, arn := range output.TaskDefinitionArns {
+			described, err := c.DescribeTaskDefinitionWithContext(ctx, &DescribeTaskDefinitionInput{
+				TaskDefinition: arn,
+			})
+			if err != nil {
+				describeErr = err
+				return false
+			}
+			if !fn(described.TaskDefinition) {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return describeErr
+}
+
+// describeLatestTaskDefinitionsConcurrency is the number of families whose
+// latest task definition DescribeLatestTaskDefinitionsForAllFamilies looks up
+// at once
+const describeLatestTaskDefinitionsConcurrency = 10
+
+// DescribeLatestTaskDefinitionsForAllFamilies returns the latest ACTIVE
+// revision of every task definition family, in family order. The families are
+// listed with ListTaskDefinitionFamilies, then the latest revision of up to 10
+// families at a time is found with ListTaskDefinitions and described.
+// Families without an ACTIVE revision are left out, and the first error of
+// any call is returned.
+func (c *ECS) DescribeLatestTaskDefinitionsForAllFamilies(ctx aws.Context) ([]*TaskDefinition, error) {
+	var families []string
+	err := c.ListTaskDefinitionFamiliesPagesWithContext(ctx, &ListTaskDefinitionFamiliesInput{
+		Status: aws.String(TaskDefinitionFamilyStatusActive),
+	}, func(output *ListTaskDefinitionFamiliesOutput, lastPage bool) bool {
+		families = append(families, aws.StringValueSlice(output.Families)...)
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "describe latest task definitions: unable to list families")
+	}
+
+	latest := make([]*TaskDefinition, len(families))
+	errs := make([]error, len(families))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < describeLatestTaskDefinitionsConcurrency && worker < len(families); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				latest[i], errs[i] = c.describeLatestTaskDefinition(ctx, families[i])
+			}
+		}()
+	}
+	for i := range families {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var taskDefinitions []*TaskDefinition
+	for i, taskDefinition := range latest {
+		if errs[i] != nil {
+			return nil, errors.Wrapf(errs[i], "describe latest task definitions: family %s", families[i])
+		}
+		if taskDefinition != nil {
+			taskDefinitions = append(taskDefinitions, taskDefinition)
+		}
+	}
+	return taskDefinitions, nil
+}
+
+// describeLatestTaskDefinition describes the latest ACTIVE revision of
+// family, or returns nil if it has none
+func (c *ECS) describeLatestTaskDefinition(ctx aws.Context, family string) (*TaskDefinition, error) {
+	listed, err := c.ListTaskDefinitionsWithContext(ctx, &ListTaskDefinitionsInput{
+		FamilyPrefix: aws.String(family),
+		Status:       aws.String(TaskDefinitionStatusActive),
+		Sort:         aws.String(SortOrderDesc),
+		MaxResults:   aws.Int64(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(listed.TaskDefinitionArns) == 0 {
+		return nil, nil
+	}
+	described, err := c.DescribeTaskDefinitionWithContext(ctx, &DescribeTaskDefinitionInput{
+		TaskDefinition: listed.TaskDefinitionArns[0],
+	})
+	if err != nil {
+		return nil, err
+	}
+	return described.TaskDefinition, nil
+}
+
+// NewRepositoryCredentials returns the repository credentials of a container
+// that pulls its image with the credentials stored in a Secrets Manager
+// secret, given as arn:aws:secretsmanager:region:account:secret:name
+func NewRepositoryCredentials(secretArn string) (*RepositoryCredentials, error) {
+	if secretArn == "" {
+		return nil, fmt.Errorf("repository credentials: no secret ARN")
+	}
+	parsed, err := arn.Parse(secretArn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "repository credentials: invalid secret ARN %s", secretArn)
+	}
+	if parsed.Service != "secretsmanager" || !strings.HasPrefix(parsed.Resource, "secret:") {
+		return nil, fmt.Errorf("repository credentials: %s is not a Secrets Manager secret ARN", secretArn)
+	}
+	return &RepositoryCredentials{CredentialsParameter: aws.String(secretArn)}, nil
+}
+
+// ExportTaskDefinition returns the input that registers a new revision of a
+// task definition, such as one returned by DescribeTaskDefinition, with the
+// same configuration. The fields set by ECS on registration, such as the ARN,
+// Revision, Status, RequiresAttributes and Compatibilities, are left out.
+func ExportTaskDefinition(td *TaskDefinition) (*RegisterTaskDefinitionInput, error) {
+	if td == nil {
+		return nil, fmt.Errorf("export task definition: no task definition")
+	}
+	if aws.StringValue(td.Family) == "" {
+		return nil, fmt.Errorf("export task definition: %s has no family", aws.StringValue(td.TaskDefinitionArn))
+	}
+	copied := awsutil.CopyOf(td).(*TaskDefinition)
+	return &RegisterTaskDefinitionInput{
+		ContainerDefinitions:    copied.ContainerDefinitions,
+		Cpu:                     copied.Cpu,
+		ExecutionRoleArn:        copied.ExecutionRoleArn,
+		Family:                  copied.Family,
+		InferenceAccelerators:   copied.InferenceAccelerators,
+		IpcMode:                 copied.IpcMode,
+		Memory:                  copied.Memory,
+		NetworkMode:             copied.NetworkMode,
+		PidMode:                 copied.PidMode,
+		PlacementConstraints:    copied.PlacementConstraints,
+		RequiresCompatibilities: copied.RequiresCompatibilities,
+		TaskRoleArn:             copied.TaskRoleArn,
+		Volumes:                 copied.Volumes,
+	}, nil
+}
+
+// ValidateTaskDefinition validates the task definition as a dry run and
+// returns it as it would be registered, without registering it. The
+// validation is done locally, as the service has no dry run: fields that a
+// registration needs but a dry run may leave out, such as cpu and memory for
+// tasks that require the Fargate launch type, are reported by the
+// ValidationWarnings of the input instead of by the returned error. The ARN
+// and revision of the returned task definition are not set.
+func (c *ECS) ValidateTaskDefinition(ctx aws.Context, input *RegisterTaskDefinitionInput) (*TaskDefinition, error) {
+	if input == nil {
+		return nil, errors.New("validate task definition: no task definition")
+	}
+	if err := validateParamsWith(input, input.validateDefinition); err != nil {
+		return nil, err
+	}
+	copied := awsutil.CopyOf(input).(*RegisterTaskDefinitionInput)
+	return &TaskDefinition{
+		ContainerDefinitions:    copied.ContainerDefinitions,
+		Cpu:                     copied.Cpu,
+		ExecutionRoleArn:        copied.ExecutionRoleArn,
+		Family:                  copied.Family,
+		InferenceAccelerators:   copied.InferenceAccelerators,
+		IpcMode:                 copied.IpcMode,
+		Memory:                  copied.Memory,
+		NetworkMode:             copied.NetworkMode,
+		PidMode:                 copied.PidMode,
+		PlacementConstraints:    copied.PlacementConstraints,
+		RequiresCompatibilities: copied.RequiresCompatibilities,
+		TaskRoleArn:             copied.TaskRoleArn,
+		Volumes:                 copied.Volumes,
+	}, nil
+}
+
+// GetOrRegisterTaskDefinition returns the latest ACTIVE revision of the
+// family of the input if it has the configuration of the input, and registers
+// a new revision otherwise. It returns true if a revision was registered. The
+// configurations are compared with DiffTaskDefinitions, so the defaults that
+// ECS fills in on registration and the order of the containers do not count as
+// differences. Tags are not compared, as they are not described with the task
+// definition.
+func (c *ECS) GetOrRegisterTaskDefinition(ctx aws.Context, input *RegisterTaskDefinitionInput) (*TaskDefinition, bool, error) {
+	if input == nil || aws.StringValue(input.Family) == "" {
+		return nil, false, errors.New("get or register task definition: no family")
+	}
+	family := aws.StringValue(input.Family)
+	latest, err := c.describeLatestTaskDefinition(ctx, family)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "get or register task definition %s", family)
+	}
+	if latest != nil {
+		exported, err := ExportTaskDefinition(latest)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "get or register task definition %s", family)
+		}
+		if len(DiffTaskDefinitions(exported, input)) == 0 {
+			return latest, false, nil
+		}
+	}
+
+	registered, err := c.RegisterTaskDefinitionWithContext(ctx, input)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "get or register task definition %s", family)
+	}
+	return registered.TaskDefinition, true, nil
+}
+
+// TaskDefinitionFieldChange is a field that differs between two task
+// definitions, with its values rendered as strings. Container definitions are
+// compared one by one, with fields such as "ContainerDefinitions[web]".
+type TaskDefinitionFieldChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// DiffTaskDefinitions returns the fields that differ between the
+// configurations of two task definitions, such as the export of a described
+// revision and the input of a registration. The defaults that ECS fills in on
+// registration are applied to both before they are compared: a container cpu
+// of 0, essential containers, the tcp protocol for port mappings, a host port
+// equal to the container port in the awsvpc and host network modes and 0
+// otherwise, and empty lists for mount points, volumes from and environment.
+// The order of the containers and of lists that ECS does not keep in order,
+// such as the environment, port mappings and volumes, is ignored. Tags are not
+// compared.
+func DiffTaskDefinitions(old, new *RegisterTaskDefinitionInput) []TaskDefinitionFieldChange {
+	old = normalizeTaskDefinition(old)
+	new = normalizeTaskDefinition(new)
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"Family", aws.StringValue(old.Family), aws.StringValue(new.Family)},
+		{"Cpu", aws.StringValue(old.Cpu), aws.StringValue(new.Cpu)},
+		{"Memory", aws.StringValue(old.Memory), aws.StringValue(new.Memory)},
+		{"NetworkMode", aws.StringValue(old.NetworkMode), aws.StringValue(new.NetworkMode)},
+		{"IpcMode", aws.StringValue(old.IpcMode), aws.StringValue(new.IpcMode)},
+		{"PidMode", aws.StringValue(old.PidMode), aws.StringValue(new.PidMode)},
+		{"ExecutionRoleArn", aws.StringValue(old.ExecutionRoleArn), aws.StringValue(new.ExecutionRoleArn)},
+		{"TaskRoleArn", aws.StringValue(old.TaskRoleArn), aws.StringValue(new.TaskRoleArn)},
+		{"RequiresCompatibilities", serviceFieldJSON(old.RequiresCompatibilities), serviceFieldJSON(new.RequiresCompatibilities)},
+		{"PlacementConstraints", serviceFieldJSON(old.PlacementConstraints), serviceFieldJSON(new.PlacementConstraints)},
+		{"InferenceAccelerators", serviceFieldJSON(old.InferenceAccelerators), serviceFieldJSON(new.InferenceAccelerators)},
+		{"Volumes", serviceFieldJSON(old.Volumes), serviceFieldJSON(new.Volumes)},
+	}
+
+	var changes []TaskDefinitionFieldChange
+	for _, field := range fields {
+		if field.old != field.new {
+			changes = append(changes, TaskDefinitionFieldChange{
+				Field:    field.name,
+				OldValue: field.old,
+				NewValue: field.new,
+			})
+		}
+	}
+
+	oldContainers := make(map[string]*ContainerDefinition)
+	for _, container := range old.ContainerDefinitions {
+		oldContainers[aws.StringValue(container.Name)] = container
+	}
+	newContainers := make(map[string]*ContainerDefinition)
+	for _, container := range new.ContainerDefinitions {
+		newContainers[aws.StringValue(container.Name)] = container
+	}
+	var names []string
+	for name := range oldContainers {
+		names = append(names, name)
+	}
+	for name := range newContainers {
+		if _, ok := oldContainers[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldValue := serviceFieldJSON(oldContainers[name])
+		newValue := serviceFieldJSON(newContainers[name])
+		if oldValue != newValue {
+			changes = append(changes, TaskDefinitionFieldChange{
+				Field:    fmt.Sprintf("ContainerDefinitions[%s]", name),
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+	return changes
+}
+
+// normalizeTaskDefinition returns a copy of the input with the defaults of
+// ECS applied and its unordered lists sorted, for DiffTaskDefinitions
+func normalizeTaskDefinition(input *RegisterTaskDefinitionInput) *RegisterTaskDefinitionInput {
+	if input == nil {
+		return &RegisterTaskDefinitionInput{}
+	}
+	normalized := awsutil.CopyOf(input).(*RegisterTaskDefinitionInput)
+	normalized.Tags = nil
+	if normalized.NetworkMode == nil {
+		normalized.NetworkMode = aws.String(NetworkModeBridge)
+	}
+	networkMode := aws.StringValue(normalized.NetworkMode)
+
+	sortByJSON(normalized.RequiresCompatibilities)
+	sortByJSON(normalized.PlacementConstraints)
+	sortByJSON(normalized.InferenceAccelerators)
+	sortByJSON(normalized.Volumes)
+
+	var containers []*ContainerDefinition
+	for _, container := range normalized.ContainerDefinitions {
+		if container == nil {
+			continue
+		}
+		normalizeContainerDefinition(container, networkMode)
+		containers = append(containers, container)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return aws.StringValue(containers[i].Name) < aws.StringValue(containers[j].Name)
+	})
+	normalized.ContainerDefinitions = containers
+	return normalized
+}
+
+// normalizeContainerDefinition applies the defaults of ECS to the container
+// definition and sorts its unordered lists, in place
+func normalizeContainerDefinition(container *ContainerDefinition, networkMode string) {
+	if container.Cpu == nil {
+		container.Cpu = aws.Int64(0)
+	}
+	if container.Essential == nil {
+		container.Essential = aws.Bool(true)
+	}
+	for _, portMapping := range container.PortMappings {
+		if portMapping == nil {
+			continue
+		}
+		if portMapping.Protocol == nil {
+			portMapping.Protocol = aws.String(TransportProtocolTcp)
+		}
+		if portMapping.HostPort == nil {
+			if networkMode == NetworkModeAwsvpc || networkMode == NetworkModeHost {
+				portMapping.HostPort = portMapping.ContainerPort
+			} else {
+				portMapping.HostPort = aws.Int64(0)
+			}
+		}
+	}
+	sortByJSON(container.PortMappings)
+	sortByJSON(container.Environment)
+	sortByJSON(container.Secrets)
+	sortByJSON(container.MountPoints)
+	sortByJSON(container.VolumesFrom)
+	sortByJSON(container.Ulimits)
+	if len(container.PortMappings) == 0 {
+		container.PortMappings = nil
+	}
+	if len(container.Environment) == 0 {
+		container.Environment = nil
+	}
+	if len(container.MountPoints) == 0 {
+		container.MountPoints = nil
+	}
+	if len(container.VolumesFrom) == 0 {
+		container.VolumesFrom = nil
+	}
+}
+
+// sortByJSON sorts a list of the model, such as []*KeyValuePair, by the JSON
+// of its elements
+func sortByJSON(list interface{}) {
+	value := reflect.ValueOf(list)
+	sort.Slice(list, func(i, j int) bool {
+		return serviceFieldJSON(value.Index(i).Interface()) < serviceFieldJSON(value.Index(j).Interface())
+	})
+}
+
+// ToJSON renders the input as indented JSON, with the field names used by the
+// ECS API, so that it can be registered with the CLI's --cli-input-json
+func (s *RegisterTaskDefinitionInput) ToJSON() ([]byte, error) {
+	body, err := jsonutil.BuildJSON(s)
+	if err != nil {
+		return nil, err
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
+
+// ToYAML renders the input as YAML, with the field names used by the ECS API
+// and the keys of each object sorted
+func (s *RegisterTaskDefinitionInput) ToYAML() ([]byte, error) {
+	body, err := jsonutil.BuildJSON(s)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAML(body)
+}
+
+// ParseTaskDefinitionFromCLIJSON parses a task definition in the JSON format
+// of the CLI, as given to register-task-definition --cli-input-json or
+// returned by describe-task-definition. The fields that only describe a
+// registered revision, such as its ARN and status, are dropped.
+func ParseTaskDefinitionFromCLIJSON(data []byte) (*RegisterTaskDefinitionInput, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.Wrap(err, "parse task definition")
+	}
+	if _, ok := fields["containerDefinitions"]; !ok {
+		described, ok := fields["taskDefinition"]
+		if !ok {
+			return nil, errors.New("parse task definition: no containerDefinitions")
+		}
+		return ParseTaskDefinitionFromCLIJSON(described)
+	}
+
+	input := &RegisterTaskDefinitionInput{}
+	if err := jsonutil.UnmarshalJSON(input, bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrap(err, "parse task definition")
+	}
+	return input, nil
+}
+
+// FitsInstance returns true if the containers of the task definition fit on
+// an instance providing cpuUnits CPU units and memoryMib MiB of memory. The
+// CPU of each container is summed, as is its memory, taken from the hard
+// Memory limit, or from MemoryReservation when no hard limit is set. When the
+// task definition does not fit, a message is returned for each resource it
+// overflows.
+func (td *TaskDefinition) FitsInstance(cpuUnits, memoryMib int64) (bool, []string) {
+	var cpu, memory int64
+	if td != nil {
+		for _, container := range td.ContainerDefinitions {
+			if container == nil {
+				continue
+			}
+			cpu += aws.Int64Value(container.Cpu)
+			if container.Memory != nil {
+				memory += aws.Int64Value(container.Memory)
+			} else {
+				memory += aws.Int64Value(container.MemoryReservation)
+			}
+		}
+	}
+
+	var overflows []string
+	if cpu > cpuUnits {
+		overflows = append(overflows, fmt.Sprintf("cpu: containers require %d units, instance provides %d", cpu, cpuUnits))
+	}
+	if memory > memoryMib {
+		overflows = append(overflows, fmt.Sprintf("memory: containers require %d MiB, instance provides %d", memory, memoryMib))
+	}
+	return len(overflows) == 0, overflows
+}
+
+// InvalidPlacementStrategiesError is returned by ValidatePlacementStrategies
+// when some of the strategies combine a type with a field it does not accept
+type InvalidPlacementStrategiesError struct {
+	// Problems describe each invalid strategy, prefixed by its index
+	Problems []string
+}
+
+func (err *InvalidPlacementStrategiesError) Error() string {
+	return fmt.Sprintf("invalid placement strategies: %s", strings.Join(err.Problems, "; "))
+}
+
+// ValidatePlacementStrategies checks the field of each placement strategy
+// against its type: binpack accepts cpu or memory, spread accepts instanceId,
+// host or an attribute:<name>, and random takes no field. Every invalid
+// strategy is described in the *InvalidPlacementStrategiesError returned.
+func ValidatePlacementStrategies(strategies []*PlacementStrategy) error {
+	var problems []string
+	for i, strategy := range strategies {
+		if problem := placementStrategyProblem(strategy); problem != "" {
+			problems = append(problems, fmt.Sprintf("[%d] %s", i, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return &InvalidPlacementStrategiesError{Problems: problems}
+	}
+	return nil
+}
+
+// placementStrategyProblem returns what is wrong with a placement strategy,
+// or "" if it is valid
+func placementStrategyProblem(strategy *PlacementStrategy) string {
+	if strategy == nil {
+		return "no placement strategy"
+	}
+	strategyType := aws.StringValue(strategy.Type)
+	field := aws.StringValue(strategy.Field)
+	switch strategyType {
+	case PlacementStrategyTypeBinpack:
+		if field != "cpu" && field != "memory" {
+			return fmt.Sprintf("binpack field must be cpu or memory, not %q", field)
+		}
+	case PlacementStrategyTypeSpread:
+		attribute := strings.HasPrefix(field, "attribute:") && len(field) > len("attribute:")
+		if field != "instanceId" && field != "host" && !attribute {
+			return fmt.Sprintf("spread field must be instanceId, host or attribute:<name>, not %q", field)
+		}
+	case PlacementStrategyTypeRandom:
+		if field != "" {
+			return fmt.Sprintf("random takes no field, not %q", field)
+		}
+	case "":
+		return "no placement strategy type"
+	default:
+		return fmt.Sprintf("unknown placement strategy type %q", strategyType)
+	}
+	return ""
+}
+
+// maxRunTaskCount is the number of tasks a single RunTask call can launch
+const maxRunTaskCount = 10
+
+// RunTaskBatchError is returned by RunTaskBatch when some of the RunTask
+// calls it made failed. The tasks launched by the other calls are still
+// returned along with it.
+type RunTaskBatchError struct {
+	// Errors are the errors of the failed RunTask calls
+	Errors []error
+}
+
+func (err *RunTaskBatchError) Error() string {
+	messages := make([]string, len(err.Errors))
+	for i, callErr := range err.Errors {
+		messages[i] = callErr.Error()
+	}
+	return fmt.Sprintf("run task batch: %d RunTask calls failed: %s",
+		len(err.Errors), strings.Join(messages, "; "))
+}
+
+// runTaskBatchConcurrency is the number of RunTask calls RunTaskBatch makes
+// concurrently
+const runTaskBatchConcurrency = 5
+
+// RunTaskBatch launches count tasks as described by input, with as many
+// RunTask calls of up to 10 tasks as needed, up to 5 of them in flight at a
+// time. The Count of input is ignored. The tasks and failures of all the calls are merged. A call that
+// fails does not stop the others: its error is reported in a
+// *RunTaskBatchError, returned along with the tasks that were launched.
+func (c *ECS) RunTaskBatch(ctx aws.Context, input *RunTaskInput, count int) ([]*Task, []*Failure, error) {
+	return RunTaskInChunks(ctx, c, input, count)
+}
+
+// RunTaskInChunks implements RunTaskBatch with the RunTaskWithContext method
+// of client. Wrappers of ECSClientInterface use it so that each RunTask call
+// goes through the wrapper.
+func RunTaskInChunks(ctx aws.Context, client ECSClientInterface, input *RunTaskInput, count int) ([]*Task, []*Failure, error) {
+	if count < 1 {
+		return nil, nil, fmt.Errorf("run task batch: count must be at least 1, got %d", count)
+	}
+
+	chunks := (count + maxRunTaskCount - 1) / maxRunTaskCount
+	outputs := make([]*RunTaskOutput, chunks)
+	errs := make([]error, chunks)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < runTaskBatchConcurrency && worker < chunks; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				chunkInput := *input
+				chunkInput.Count = aws.Int64(int64(maxRunTaskCount))
+				if remaining := count - i*maxRunTaskCount; remaining < maxRunTaskCount {
+					chunkInput.Count = aws.Int64(int64(remaining))
+				}
+				outputs[i], errs[i] = client.RunTaskWithContext(ctx, &chunkInput)
+			}
+		}()
+	}
+	for i := 0; i < chunks; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	var tasks []*Task
+	var failures []*Failure
+	batchErr := &RunTaskBatchError{}
+	for i := range outputs {
+		if errs[i] != nil {
+			batchErr.Errors = append(batchErr.Errors, errs[i])
+			continue
+		}
+		tasks = append(tasks, outputs[i].Tasks...)
+		failures = append(failures, outputs[i].Failures...)
+	}
+	if len(batchErr.Errors) > 0 {
+		return tasks, failures, batchErr
+	}
+	return tasks, failures, nil
+}
+
+// RegisterContainerInstancesConcurrently registers a container instance for
+// each of inputs, with up to concurrency RegisterContainerInstance calls in
+// flight. The container instances and errors returned are indexed like
+// inputs: a call that fails leaves a nil container instance and its error,
+// without stopping the others. Once ctx is done, the inputs not yet handed to
+// a call fail with the error of ctx, which is also returned.
+func RegisterContainerInstancesConcurrently(ctx aws.Context, client ECSClientInterface, inputs []*RegisterContainerInstanceInput, concurrency int) ([]*ContainerInstance, []error, error) {
+	if concurrency < 1 {
+		return nil, nil, fmt.Errorf("register container instances: concurrency must be at least 1, got %d", concurrency)
+	}
+
+	instances := make([]*ContainerInstance, len(inputs))
+	errs := make([]error, len(inputs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency && worker < len(inputs); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				output, err := client.RegisterContainerInstanceWithContext(ctx, inputs[i])
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				instances[i] = output.ContainerInstance
+			}
+		}()
+	}
+
+	var ctxErr error
+	for i := range inputs {
+		if ctxErr == nil {
+			select {
+			case indexes <- i:
+				continue
+			case <-ctx.Done():
+				ctxErr = ctx.Err()
+			}
+		}
+		errs[i] = ctxErr
+	}
+	close(indexes)
+	wg.Wait()
+	return instances, errs, ctxErr
+}
+
+// GetEffectiveSettings returns the account settings that apply to the calling
+// principal, by name. Settings the principal has not overridden have the
+// value set for the account, or the default value.
+func (c *ECS) GetEffectiveSettings(ctx aws.Context) (map[string]string, error) {
+	settings := make(map[string]string)
+	err := c.ListAccountSettingsPagesWithContext(ctx, &ListAccountSettingsInput{
+		EffectiveSettings: aws.Bool(true),
+	}, func(page *ListAccountSettingsOutput, lastPage bool) bool {
+		for _, setting := range page.Settings {
+			settings[aws.StringValue(setting.Name)] = aws.StringValue(setting.Value)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// defaultClusterName is the cluster used by the API when none is given
+const defaultClusterName = "default"
+
+// CreateClusterIfNotExists creates a cluster, or returns the existing
+// cluster of the same name. CreateCluster normally returns the existing
+// cluster itself, but can fail with a ClientException saying the cluster
+// already exists, in which case the cluster is described instead.
+func (c *ECS) CreateClusterIfNotExists(ctx aws.Context, input *CreateClusterInput) (*Cluster, error) {
+	created, err := c.CreateClusterWithContext(ctx, input)
+	if err == nil {
+		return created.Cluster, nil
+	}
+	awsErr, ok := err.(awserr.Error)
+	if !ok || awsErr.Code() != ErrCodeClientException || !strings.Contains(awsErr.Message(), "already exists") {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.ClusterName)
+	if name == "" {
+		name = defaultClusterName
+	}
+	described, err := c.DescribeClustersWithContext(ctx, &DescribeClustersInput{
+		Clusters: aws.StringSlice([]string{name}),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "create cluster: unable to describe existing cluster %s", name)
+	}
+	if len(described.Clusters) == 0 {
+		reason := "MISSING"
+		if len(described.Failures) > 0 {
+			reason = aws.StringValue(described.Failures[0].Reason)
+		}
+		return nil, errors.Errorf("create cluster: unable to describe existing cluster %s: %s", name, reason)
+	}
+	return described.Clusters[0], nil
+}
+
+// ListAllContainerInstances returns the ARNs of the container instances of
+// every cluster, keyed by cluster ARN, going through all the pages of
+// ListClusters and of ListContainerInstances for each cluster. A cluster
+// without container instances maps to an empty slice. ctx is checked before
+// each cluster is listed, so that a cancelled audit stops early.
+func (c *ECS) ListAllContainerInstances(ctx aws.Context) (map[string][]string, error) {
+	var clusters []string
+	err := c.ListClustersPagesWithContext(ctx, &ListClustersInput{}, func(page *ListClustersOutput, lastPage bool) bool {
+		clusters = append(clusters, aws.StringValueSlice(page.ClusterArns)...)
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list all container instances: unable to list clusters")
+	}
+
+	instances := make(map[string][]string, len(clusters))
+	for _, cluster := range clusters {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "list all container instances")
+		}
+		arns := []string{}
+		err := c.ListContainerInstancesPagesWithContext(ctx, &ListContainerInstancesInput{
+			Cluster: aws.String(cluster),
+		}, func(page *ListContainerInstancesOutput, lastPage bool) bool {
+			arns = append(arns, aws.StringValueSlice(page.ContainerInstanceArns)...)
+			return true
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list all container instances: unable to list the container instances of %s", cluster)
+		}
+		instances[cluster] = arns
+	}
+	return instances, nil
+}
+
+// containerInsightsNamespace is the CloudWatch namespace of the task metrics
+// published by Container Insights
+const containerInsightsNamespace = "ECS/ContainerInsights"
+
+// taskUsageMetrics are the Container Insights metrics GetTaskResourceUsage
+// queries, keyed by query id
+var taskUsageMetrics = map[string]string{
+	"cpuUtilized":    "CpuUtilized",
+	"cpuReserved":    "CpuReserved",
+	"memoryUtilized": "MemoryUtilized",
+	"memoryReserved": "MemoryReserved",
+}
+
+// CloudWatchGetMetricDataAPI is the subset of the CloudWatch API used by
+// GetTaskResourceUsage
+type CloudWatchGetMetricDataAPI interface {
+	GetMetricDataWithContext(aws.Context, *cloudwatch.GetMetricDataInput, ...request.Option) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// TaskResourceUsage is how much of its reserved CPU and memory a task uses
+type TaskResourceUsage struct {
+	// CpuPercent is the CPU units used, as a percentage of those reserved
+	CpuPercent float64
+	// MemoryPercent is the memory used, as a percentage of the memory
+	// reserved
+	MemoryPercent float64
+}
+
+// GetTaskResourceUsage returns the average CPU and memory utilization of a
+// task over the last period, from the Container Insights metrics of its
+// cluster. The cluster and task may be given as ARNs or names. Container
+// Insights must be enabled on the cluster for the metrics to be published.
+func (c *ECS) GetTaskResourceUsage(ctx aws.Context, cluster, taskArn string, cwClient CloudWatchGetMetricDataAPI, period time.Duration) (*TaskResourceUsage, error) {
+	if period < time.Second {
+		return nil, errors.Errorf("get task resource usage: period %s is shorter than a second", period)
+	}
+	dimensions := []*cloudwatch.Dimension{
+		{Name: aws.String("ClusterName"), Value: aws.String(cluster[strings.LastIndex(cluster, "/")+1:])},
+		{Name: aws.String("TaskId"), Value: aws.String(taskArn[strings.LastIndex(taskArn, "/")+1:])},
+	}
+	var queries []*cloudwatch.MetricDataQuery
+	for id, metric := range taskUsageMetrics {
+		queries = append(queries, &cloudwatch.MetricDataQuery{
+			Id: aws.String(id),
+			MetricStat: &cloudwatch.MetricStat{
+				Metric: &cloudwatch.Metric{
+					Namespace:  aws.String(containerInsightsNamespace),
+					MetricName: aws.String(metric),
+					Dimensions: dimensions,
+				},
+				Period: aws.Int64(int64(period / time.Second)),
+				Stat:   aws.String(cloudwatch.StatisticAverage),
+			},
+		})
+	}
+
+	end := time.Now()
+	output, err := cwClient.GetMetricDataWithContext(ctx, &cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(end.Add(-period)),
+		EndTime:           aws.Time(end),
+		MetricDataQueries: queries,
+		ScanBy:            aws.String(cloudwatch.ScanByTimestampDescending),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "get task resource usage of %s", taskArn)
+	}
+	// The values are the latest datapoint of each query
+	values := make(map[string]float64)
+	for _, result := range output.MetricDataResults {
+		if len(result.Values) > 0 {
+			values[aws.StringValue(result.Id)] = aws.Float64Value(result.Values[0])
+		}
+	}
+	for id, metric := range taskUsageMetrics {
+		if _, ok := values[id]; !ok {
+			return nil, errors.Errorf("get task resource usage of %s: no %s datapoint", taskArn, metric)
+		}
+	}
+	if values["cpuReserved"] == 0 || values["memoryReserved"] == 0 {
+		return nil, errors.Errorf("get task resource usage of %s: no CPU or memory reserved", taskArn)
+	}
+	return &TaskResourceUsage{
+		CpuPercent:    100 * values["cpuUtilized"] / values["cpuReserved"],
+		MemoryPercent: 100 * values["memoryUtilized"] / values["memoryReserved"],
+	}, nil
+}
+
+// maxPutAttributesCount is the number of attributes a single PutAttributes
+// call can put
+const maxPutAttributesCount = 10
+
+// PutAttributesBulkError is returned by PutAttributesBulk when some of the
+// PutAttributes calls it made failed. The attributes of the other calls are
+// still put.
+type PutAttributesBulkError struct {
+	// Errors are the errors of the failed PutAttributes calls
+	Errors []error
+}
+
+func (err *PutAttributesBulkError) Error() string {
+	messages := make([]string, len(err.Errors))
+	for i, callErr := range err.Errors {
+		messages[i] = callErr.Error()
+	}
+	return fmt.Sprintf("put attributes bulk: %d PutAttributes calls failed: %s",
+		len(err.Errors), strings.Join(messages, "; "))
+}
+
+// PutAttributesBulk puts attributes on many container instances of a
+// cluster, given as lists of attributes keyed by container instance ARN. The
+// TargetId of each attribute is set to its container instance, and all the
+// attributes are put with as few PutAttributes calls of up to 10 attributes
+// as possible, in container instance order. A call that fails does not stop
+// the others: its error is reported in a *PutAttributesBulkError.
+func (c *ECS) PutAttributesBulk(ctx aws.Context, cluster string, attrs map[string][]*Attribute) error {
+	instances := make([]string, 0, len(attrs))
+	for instance := range attrs {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+
+	var attributes []*Attribute
+	for _, instance := range instances {
+		for _, attribute := range attrs[instance] {
+			if attribute == nil {
+				continue
+			}
+			targeted := *attribute
+			targeted.TargetId = aws.String(instance)
+			if targeted.TargetType == nil {
+				targeted.TargetType = aws.String(TargetTypeContainerInstance)
+			}
+			attributes = append(attributes, &targeted)
+		}
+	}
+
+	var errs []error
+	for start := 0; start < len(attributes); start += maxPutAttributesCount {
+		end := start + maxPutAttributesCount
+		if end > len(attributes) {
+			end = len(attributes)
+		}
+		_, err := c.PutAttributesWithContext(ctx, &PutAttributesInput{
+			Cluster:    aws.String(cluster),
+			Attributes: attributes[start:end],
+		})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "attributes %d to %d", start, end-1))
+		}
+	}
+	if len(errs) > 0 {
+		return &PutAttributesBulkError{Errors: errs}
+	}
+	return nil
+}
+
+// ListTasksForServiceSafe returns the ARNs of the tasks of a service, going
+// through all the pages of ListTasks. A service that does not exist has no
+// tasks: ListTasks fails with a ServiceNotFoundException for it, which is
+// returned as an empty list so that polling a service that was deleted, or is
+// not created yet, is not an error.
+func (c *ECS) ListTasksForServiceSafe(ctx aws.Context, cluster, service string) ([]string, error) {
+	arns := []string{}
+	err := c.ListTasksPagesWithContext(ctx, &ListTasksInput{
+		Cluster:     aws.String(cluster),
+		ServiceName: aws.String(service),
+	}, func(page *ListTasksOutput, lastPage bool) bool {
+		arns = append(arns, aws.StringValueSlice(page.TaskArns)...)
+		return true
+	})
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ErrCodeServiceNotFoundException {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return arns, nil
+}
+
+// GetAttributeMap returns the attributes of the targets of a type, such as
+// container-instance, in a cluster, going through all the pages of
+// ListAttributes. The values are keyed by target id, then attribute name.
+// Attributes without a value map to the empty string.
+func (c *ECS) GetAttributeMap(ctx aws.Context, cluster, targetType string) (map[string]map[string]string, error) {
+	attributes := make(map[string]map[string]string)
+	err := c.ListAttributesPagesWithContext(ctx, &ListAttributesInput{
+		Cluster:    aws.String(cluster),
+		TargetType: aws.String(targetType),
+	}, func(page *ListAttributesOutput, lastPage bool) bool {
+		for _, attribute := range page.Attributes {
+			if attribute == nil {
+				continue
+			}
+			targetId := aws.StringValue(attribute.TargetId)
+			if attributes[targetId] == nil {
+				attributes[targetId] = make(map[string]string)
+			}
+			attributes[targetId][aws.StringValue(attribute.Name)] = aws.StringValue(attribute.Value)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "get attribute map")
+	}
+	return attributes, nil
+}
+
+// maxDescribeTasks is the number of tasks that can be described by a single
+// DescribeTasks call
+const maxDescribeTasks = 100
+
+// stalePendingTaskReason is the reason CleanStalePendingTasks gives when
+// stopping a task
+const stalePendingTaskReason = "ECS agent cleanup: task exceeded max pending age"
+
+// CleanStalePendingTasksError is returned by CleanStalePendingTasks when some
+// of the StopTask calls it made failed. The other stale tasks are still
+// stopped.
+type CleanStalePendingTasksError struct {
+	// Errors are the errors of the failed StopTask calls
+	Errors []error
+}
+
+func (err *CleanStalePendingTasksError) Error() string {
+	messages := make([]string, len(err.Errors))
+	for i, callErr := range err.Errors {
+		messages[i] = callErr.Error()
+	}
+	return fmt.Sprintf("clean stale pending tasks: %d StopTask calls failed: %s",
+		len(err.Errors), strings.Join(messages, "; "))
+}
+
+// CleanStalePendingTasks stops the tasks of a cluster that have been PENDING
+// for longer than maxPendingAge, going by their CreatedAt, and returns the
+// ARNs of the tasks it stopped. A StopTask call that fails does not stop the
+// others: its error is reported in a *CleanStalePendingTasksError.
+func (c *ECS) CleanStalePendingTasks(ctx aws.Context, cluster string, maxPendingAge time.Duration) (stopped []string, err error) {
+	// PENDING is a last status: a task waiting to start has a desired
+	// status of RUNNING
+	var arns []string
+	err = c.ListTasksPagesWithContext(ctx, &ListTasksInput{
+		Cluster:       aws.String(cluster),
+		DesiredStatus: aws.String(DesiredStatusRunning),
+	}, func(page *ListTasksOutput, lastPage bool) bool {
+		arns = append(arns, aws.StringValueSlice(page.TaskArns)...)
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "clean stale pending tasks: unable to list tasks")
+	}
+
+	var stale []*Task
+	for start := 0; start < len(arns); start += maxDescribeTasks {
+		end := start + maxDescribeTasks
+		if end > len(arns) {
+			end = len(arns)
+		}
+		described, err := c.DescribeTasksWithContext(ctx, &DescribeTasksInput{
+			Cluster: aws.String(cluster),
+			Tasks:   aws.StringSlice(arns[start:end]),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "clean stale pending tasks: unable to describe tasks")
+		}
+		for _, task := range FilterTasksByStatus(described.Tasks, DesiredStatusPending) {
+			if task.CreatedAt != nil && time.Since(*task.CreatedAt) > maxPendingAge {
+				stale = append(stale, task)
+			}
+		}
+	}
+
+	var errs []error
+	for _, task := range stale {
+		taskArn := aws.StringValue(task.TaskArn)
+		_, err := c.StopTaskWithContext(ctx, &StopTaskInput{
+			Cluster: aws.String(cluster),
+			Task:    task.TaskArn,
+			Reason:  aws.String(stalePendingTaskReason),
+		})
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "task %s", taskArn))
+			continue
+		}
+		stopped = append(stopped, taskArn)
+	}
+	if len(errs) > 0 {
+		return stopped, &CleanStalePendingTasksError{Errors: errs}
+	}
+	return stopped, nil
+}
+
+// containerInstanceStatusInactive is the status of a deregistered container
+// instance, which is not in the ContainerInstanceStatus enum
+const containerInstanceStatusInactive = "INACTIVE"
+
+// ClusterCapacityReport summarizes the capacity of the container instances
+// of a cluster. CPU is in CPU units and memory in MiB.
+type ClusterCapacityReport struct {
+	// ClusterArn is the ARN of the cluster, if known
+	ClusterArn string
+
+	TotalCPU    int64
+	UsedCPU     int64
+	TotalMemory int64
+	UsedMemory  int64
+	TotalGPUs   int64
+	UsedGPUs    int64
+
+	// DrainedInstances is the number of DRAINING instances. Their capacity
+	// is counted, as they still run tasks.
+	DrainedInstances int
+	// InactiveInstances is the number of deregistered instances. Their
+	// capacity is not counted.
+	InactiveInstances int
+}
+
+// BuildCapacityReport sums the registered and used resources of the
+// container instances of a cluster, as returned by DescribeContainerInstances.
+// The resources used by an instance are its registered resources less its
+// remaining ones.
+func BuildCapacityReport(cluster *Cluster, instances []*ContainerInstance) *ClusterCapacityReport {
+	report := &ClusterCapacityReport{}
+	if cluster != nil {
+		report.ClusterArn = aws.StringValue(cluster.ClusterArn)
+	}
+	for _, instance := range instances {
+		if instance == nil {
+			continue
+		}
+		switch aws.StringValue(instance.Status) {
+		case containerInstanceStatusInactive:
+			report.InactiveInstances++
+			continue
+		case ContainerInstanceStatusDraining:
+			report.DrainedInstances++
+		}
+		registered := resourceAmounts(instance.RegisteredResources)
+		remaining := resourceAmounts(instance.RemainingResources)
+		report.TotalCPU += registered["CPU"]
+		report.UsedCPU += registered["CPU"] - remaining["CPU"]
+		report.TotalMemory += registered["MEMORY"]
+		report.UsedMemory += registered["MEMORY"] - remaining["MEMORY"]
+		report.TotalGPUs += registered["GPU"]
+		report.UsedGPUs += registered["GPU"] - remaining["GPU"]
+	}
+	return report
+}
+
+// resourceAmounts returns the amount of each resource, keyed by name. The
+// amount of a STRINGSET resource, such as the GPU ids, is its size.
+func resourceAmounts(resources []*Resource) map[string]int64 {
+	amounts := make(map[string]int64)
+	for _, resource := range resources {
+		if resource == nil {
+			continue
+		}
+		switch aws.StringValue(resource.Type) {
+		case "STRINGSET":
+			amounts[aws.StringValue(resource.Name)] = int64(len(resource.StringSetValue))
+		case "LONG":
+			amounts[aws.StringValue(resource.Name)] = aws.Int64Value(resource.LongValue)
+		default:
+			amounts[aws.StringValue(resource.Name)] = aws.Int64Value(resource.IntegerValue)
+		}
+	}
+	return amounts
+}
+
+// TaskFailureCategory is a known reason for a task to stop
+type TaskFailureCategory string
+
+const (
+	// TaskFailureCategoryOOMKilled is a container killed for exceeding its
+	// memory limit
+	TaskFailureCategoryOOMKilled TaskFailureCategory = "OOMKilled"
+	// TaskFailureCategoryImagePullFailure is a container image that could not
+	// be pulled
+	TaskFailureCategoryImagePullFailure TaskFailureCategory = "ImagePullFailure"
+	// TaskFailureCategoryContainerExited is an essential container that exited
+	TaskFailureCategoryContainerExited TaskFailureCategory = "ContainerExited"
+	// TaskFailureCategoryTimeoutExceeded is a step, such as provisioning the
+	// network interface or stopping a container, that timed out
+	TaskFailureCategoryTimeoutExceeded TaskFailureCategory = "TimeoutExceeded"
+	// TaskFailureCategoryCapacityUnavailable is a lack of capacity to place or
+	// start the task
+	TaskFailureCategoryCapacityUnavailable TaskFailureCategory = "CapacityUnavailable"
+	// TaskFailureCategoryUnknown is a reason that matches no known failure mode
+	TaskFailureCategoryUnknown TaskFailureCategory = "Unknown"
+)
+
+// taskFailurePatterns maps lowercase substrings of a stopped reason to their
+// category, in the order they are tried
+var taskFailurePatterns = []struct {
+	substring string
+	category  TaskFailureCategory
+}{
+	{"outofmemory", TaskFailureCategoryOOMKilled},
+	{"oomkilled", TaskFailureCategoryOOMKilled},
+	{"cannotpullcontainer", TaskFailureCategoryImagePullFailure},
+	{"pullimagemanifest", TaskFailureCategoryImagePullFailure},
+	{"unable to pull", TaskFailureCategoryImagePullFailure},
+	{"resource:", TaskFailureCategoryCapacityUnavailable},
+	{"capacity is unavailable", TaskFailureCategoryCapacityUnavailable},
+	{"insufficient", TaskFailureCategoryCapacityUnavailable},
+	{"no container instances", TaskFailureCategoryCapacityUnavailable},
+	{"timeout", TaskFailureCategoryTimeoutExceeded},
+	{"timed out", TaskFailureCategoryTimeoutExceeded},
+	{"essential container in task exited", TaskFailureCategoryContainerExited},
+}
+
+// AnalyzeTaskFailure categorises why a task stopped, from its stopped reason
+// and the reasons and exit codes of its containers. A container that exited
+// with a non-zero code counts as ContainerExited when no reason is more
+// specific.
+func AnalyzeTaskFailure(task *Task) TaskFailureCategory {
+	if task == nil {
+		return TaskFailureCategoryUnknown
+	}
+	reasons := []string{aws.StringValue(task.StoppedReason)}
+	for _, container := range task.Containers {
+		if container != nil {
+			reasons = append(reasons, aws.StringValue(container.Reason))
+		}
+	}
+	for _, pattern := range taskFailurePatterns {
+		for _, reason := range reasons {
+			if strings.Contains(strings.ToLower(reason), pattern.substring) {
+				return pattern.category
+			}
+		}
+	}
+	for _, container := range task.Containers {
+		if container != nil && aws.Int64Value(container.ExitCode) != 0 {
+			return TaskFailureCategoryContainerExited
+		}
+	}
+	return TaskFailureCategoryUnknown
+}
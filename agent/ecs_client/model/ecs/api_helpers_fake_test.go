@@ -0,0 +1,1526 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/ecsfake"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCluster = "cluster"
+
+// registerContainerInstances creates testCluster in the fake and registers n
+// container instances in it, returning their ARNs
+func registerContainerInstances(t *testing.T, client *ecs.ECS, n int) []string {
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+
+	var arns []string
+	for i := 0; i < n; i++ {
+		output, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+			Cluster: aws.String(testCluster),
+		})
+		require.NoError(t, err)
+		arns = append(arns, aws.StringValue(output.ContainerInstance.ContainerInstanceArn))
+	}
+	return arns
+}
+
+func registerEC2Instance(t *testing.T, client *ecs.ECS, ec2InstanceId string) string {
+	output, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+		Cluster:                  aws.String(testCluster),
+		InstanceIdentityDocument: aws.String(fmt.Sprintf(`{"instanceId": "%s"}`, ec2InstanceId)),
+	})
+	require.NoError(t, err)
+	return aws.StringValue(output.ContainerInstance.ContainerInstanceArn)
+}
+
+func TestFindExistingContainerInstance(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	registerContainerInstances(t, client, 2)
+	registerEC2Instance(t, client, "i-0123456789abcdef0")
+	arn := registerEC2Instance(t, client, "i-0fedcba9876543210")
+
+	instance, err := client.FindExistingContainerInstance(aws.BackgroundContext(), testCluster, "i-0fedcba9876543210")
+	require.NoError(t, err)
+	require.NotNil(t, instance)
+	assert.Equal(t, arn, aws.StringValue(instance.ContainerInstanceArn))
+
+	instance, err = client.FindExistingContainerInstance(aws.BackgroundContext(), testCluster, "i-00000000000000000")
+	assert.NoError(t, err)
+	assert.Nil(t, instance)
+
+	_, err = client.FindExistingContainerInstance(aws.BackgroundContext(), testCluster, "")
+	assert.Error(t, err)
+}
+
+func TestFindExistingContainerInstanceDuplicates(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	registerContainerInstances(t, client, 1)
+	arns := []string{
+		registerEC2Instance(t, client, "i-0123456789abcdef0"),
+		registerEC2Instance(t, client, "i-0123456789abcdef0"),
+	}
+
+	instance, err := client.FindExistingContainerInstance(aws.BackgroundContext(), testCluster, "i-0123456789abcdef0")
+	assert.Nil(t, instance)
+	require.Error(t, err)
+	duplicateErr, ok := err.(*ecs.DuplicateContainerInstancesError)
+	require.True(t, ok)
+	assert.Equal(t, "i-0123456789abcdef0", duplicateErr.Ec2InstanceId)
+	assert.ElementsMatch(t, arns, duplicateErr.Arns)
+}
+
+func TestDescribeContainerInstancesByEc2Id(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	registerContainerInstances(t, client, 1)
+	first := registerEC2Instance(t, client, "i-0123456789abcdef0")
+	registerEC2Instance(t, client, "i-0fedcba9876543210")
+	third := registerEC2Instance(t, client, "i-0aaaaaaaaaaaaaaaa")
+
+	instances, failures, err := client.DescribeContainerInstancesByEc2Id(aws.BackgroundContext(), testCluster,
+		[]string{"i-0123456789abcdef0", "i-00000000000000000", "i-0aaaaaaaaaaaaaaaa"})
+	require.NoError(t, err)
+	var arns []string
+	for _, instance := range instances {
+		arns = append(arns, aws.StringValue(instance.ContainerInstanceArn))
+	}
+	assert.ElementsMatch(t, []string{first, third}, arns)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "i-00000000000000000", aws.StringValue(failures[0].Arn))
+	assert.Equal(t, "MISSING", aws.StringValue(failures[0].Reason))
+
+	instances, failures, err = client.DescribeContainerInstancesByEc2Id(aws.BackgroundContext(), testCluster, nil)
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+	assert.Empty(t, failures)
+}
+
+func TestDescribeContainerInstancesByEc2IdError(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("DescribeContainerInstances", 1))
+	defer server.Close()
+	client := server.Client()
+	registerContainerInstances(t, client, 1)
+	registerEC2Instance(t, client, "i-0123456789abcdef0")
+
+	_, _, err := client.DescribeContainerInstancesByEc2Id(aws.BackgroundContext(), testCluster, []string{"i-0123456789abcdef0"})
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeServerException, errors.Cause(err).(awserr.Error).Code())
+}
+
+func TestContainerInstanceAvailabilityZone(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+
+	registered, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+		Cluster: aws.String(testCluster),
+		Attributes: []*ecs.Attribute{{
+			Name:  aws.String("ecs.availability-zone"),
+			Value: aws.String("us-west-2b"),
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "us-west-2b", aws.StringValue(registered.ContainerInstance.AvailabilityZone))
+	unzoned, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+		Cluster: aws.String(testCluster),
+	})
+	require.NoError(t, err)
+	assert.Nil(t, unzoned.ContainerInstance.AvailabilityZone)
+
+	described, err := client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster: aws.String(testCluster),
+		ContainerInstances: []*string{
+			registered.ContainerInstance.ContainerInstanceArn,
+			unzoned.ContainerInstance.ContainerInstanceArn,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, described.ContainerInstances, 2)
+	assert.Equal(t, "us-west-2b", aws.StringValue(described.ContainerInstances[0].AvailabilityZone))
+	assert.Nil(t, described.ContainerInstances[1].AvailabilityZone)
+}
+
+func shortID(arn string) string {
+	return arn[strings.LastIndex(arn, "/")+1:]
+}
+
+func TestDescribeContainerInstancesByShortId(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 3)
+
+	output, err := client.DescribeContainerInstancesByShortId(aws.BackgroundContext(), testCluster,
+		[]string{shortID(arns[2]), shortID(arns[0])})
+	require.NoError(t, err)
+	require.Len(t, output.ContainerInstances, 2)
+	assert.Equal(t, arns[2], aws.StringValue(output.ContainerInstances[0].ContainerInstanceArn))
+	assert.Equal(t, arns[0], aws.StringValue(output.ContainerInstances[1].ContainerInstanceArn))
+	assert.Empty(t, output.Failures)
+}
+
+func TestDescribeContainerInstancesByShortIdAcrossPages(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	// The fake lists 100 container instances per page by default
+	arns := registerContainerInstances(t, client, 101)
+
+	output, err := client.DescribeContainerInstancesByShortId(aws.BackgroundContext(), testCluster,
+		[]string{shortID(arns[100])})
+	require.NoError(t, err)
+	require.Len(t, output.ContainerInstances, 1)
+	assert.Equal(t, arns[100], aws.StringValue(output.ContainerInstances[0].ContainerInstanceArn))
+}
+
+func TestDescribeContainerInstancesByShortIdInChunks(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	// The fake describes at most 100 container instances per call
+	arns := registerContainerInstances(t, client, 101)
+	shortIds := make([]string, len(arns))
+	for i, arn := range arns {
+		shortIds[i] = shortID(arn)
+	}
+
+	output, err := client.DescribeContainerInstancesByShortId(aws.BackgroundContext(), testCluster, shortIds)
+	require.NoError(t, err)
+	require.Len(t, output.ContainerInstances, 101)
+	for i, instance := range output.ContainerInstances {
+		assert.Equal(t, arns[i], aws.StringValue(instance.ContainerInstanceArn))
+	}
+	assert.Empty(t, output.Failures)
+}
+
+func TestDescribeContainerInstancesByShortIdUnknown(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+
+	_, err := client.DescribeContainerInstancesByShortId(aws.BackgroundContext(), testCluster,
+		[]string{shortID(arns[0]), "unknown1", "unknown2"})
+	require.Error(t, err)
+	unknownErr, ok := err.(*ecs.UnknownContainerInstancesError)
+	require.True(t, ok)
+	assert.Equal(t, []string{"unknown1", "unknown2"}, unknownErr.ShortIds)
+}
+
+func TestDescribeContainerInstancesByShortIdClusterNotFound(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+
+	_, err := server.Client().DescribeContainerInstancesByShortId(aws.BackgroundContext(), "missing",
+		[]string{"id"})
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeClusterNotFoundException, err.(awserr.Error).Code())
+}
+
+// createServices creates testCluster in the fake with n services, each tagged
+// with its own name, and returns the service ARNs
+func createServices(t *testing.T, server *ecsfake.Server, n int) []string {
+	client := server.Client()
+	_, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+	_, err = client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String("family"),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	})
+	require.NoError(t, err)
+
+	var arns []string
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("service%02d", i)
+		output, err := client.CreateService(&ecs.CreateServiceInput{
+			Cluster:        aws.String(testCluster),
+			ServiceName:    aws.String(name),
+			TaskDefinition: aws.String("family"),
+		})
+		require.NoError(t, err)
+		arn := aws.StringValue(output.Service.ServiceArn)
+		server.SetTags(arn, []*ecs.Tag{{Key: aws.String("name"), Value: aws.String(name)}})
+		arns = append(arns, arn)
+	}
+	return arns
+}
+
+func TestListTasksForServiceSafe(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	createServices(t, server, 2)
+	registered, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+		Cluster: aws.String(testCluster),
+	})
+	require.NoError(t, err)
+	started, err := client.StartTask(&ecs.StartTaskInput{
+		Cluster:            aws.String(testCluster),
+		TaskDefinition:     aws.String("family"),
+		ContainerInstances: []*string{registered.ContainerInstance.ContainerInstanceArn},
+		Group:              aws.String("service:service00"),
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		service  string
+		expected []string
+	}{
+		{"service with tasks", "service00", []string{aws.StringValue(started.Tasks[0].TaskArn)}},
+		{"service without tasks", "service01", []string{}},
+		{"service not found", "missing", []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			arns, err := client.ListTasksForServiceSafe(aws.BackgroundContext(), testCluster, tc.service)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, arns)
+		})
+	}
+}
+
+func TestListTasksForServiceSafeError(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+
+	_, err := server.Client().ListTasksForServiceSafe(aws.BackgroundContext(), "missing", "service")
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeClusterNotFoundException, err.(awserr.Error).Code())
+}
+
+func TestDescribeAllServices(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	// More services than fit in a single page or DescribeServices call
+	arns := createServices(t, server, 12)
+
+	output, err := server.Client().DescribeAllServices(aws.BackgroundContext(), &ecs.DescribeAllServicesInput{
+		Cluster: aws.String(testCluster),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Services, len(arns))
+	for i, service := range output.Services {
+		assert.Equal(t, arns[i], aws.StringValue(service.ServiceArn))
+		assert.Nil(t, service.Tags, "tags should only be fetched when requested")
+	}
+	assert.Empty(t, output.Failures)
+}
+
+func TestDescribeAllServicesIncludeTags(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	arns := createServices(t, server, 12)
+
+	output, err := server.Client().DescribeAllServices(aws.BackgroundContext(), &ecs.DescribeAllServicesInput{
+		Cluster:     aws.String(testCluster),
+		IncludeTags: aws.Bool(true),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Services, len(arns))
+	for _, service := range output.Services {
+		require.Len(t, service.Tags, 1)
+		assert.Equal(t, aws.StringValue(service.ServiceName), aws.StringValue(service.Tags[0].Value))
+	}
+}
+
+func TestDescribeAllServicesClusterNotFound(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+
+	_, err := server.Client().DescribeAllServices(aws.BackgroundContext(), &ecs.DescribeAllServicesInput{
+		Cluster: aws.String("missing"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeClusterNotFoundException, err.(awserr.Error).Code())
+}
+
+// startTaskOnInstance registers a task definition and starts a task from it
+// on the container instance, returning the task ARN
+func startTaskOnInstance(t *testing.T, client *ecs.ECS, containerInstanceArn string) string {
+	_, err := client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String("family"),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	})
+	require.NoError(t, err)
+	output, err := client.StartTask(&ecs.StartTaskInput{
+		Cluster:            aws.String(testCluster),
+		TaskDefinition:     aws.String("family"),
+		ContainerInstances: aws.StringSlice([]string{containerInstanceArn}),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Tasks, 1)
+	return aws.StringValue(output.Tasks[0].TaskArn)
+}
+
+func TestSafeDeregisterContainerInstance(t *testing.T) {
+	defer ecs.SetSafeDeregisterPollInterval(10 * time.Millisecond)()
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+	taskArn := startTaskOnInstance(t, client, arns[0])
+
+	stopped := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := client.StopTask(&ecs.StopTaskInput{
+			Cluster: aws.String(testCluster),
+			Task:    aws.String(taskArn),
+		})
+		stopped <- err
+	}()
+
+	err := client.SafeDeregisterContainerInstance(aws.BackgroundContext(), testCluster, arns[0], 5*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, <-stopped)
+
+	output, err := client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, output.ContainerInstances, "the container instance should be deregistered")
+}
+
+func TestSafeDeregisterContainerInstanceTasksStillRunning(t *testing.T) {
+	defer ecs.SetSafeDeregisterPollInterval(10 * time.Millisecond)()
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+	startTaskOnInstance(t, client, arns[0])
+
+	err := client.SafeDeregisterContainerInstance(aws.BackgroundContext(), testCluster, arns[0], 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 tasks still running")
+
+	output, err := client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.ContainerInstances, 1, "the container instance should still be registered")
+	assert.Equal(t, ecs.ContainerInstanceStatusDraining, aws.StringValue(output.ContainerInstances[0].Status))
+}
+
+func TestSafeDeregisterContainerInstanceNoTasks(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+
+	// No task is running, so the instance is deregistered without polling
+	err := client.SafeDeregisterContainerInstance(aws.BackgroundContext(), testCluster, arns[0], time.Minute)
+	assert.NoError(t, err)
+}
+
+func TestDrainAndWait(t *testing.T) {
+	defer ecs.SetDrainAndWaitPollInterval(10 * time.Millisecond)()
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+	taskArn := startTaskOnInstance(t, client, arns[0])
+
+	stopped := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := client.StopTask(&ecs.StopTaskInput{
+			Cluster: aws.String(testCluster),
+			Task:    aws.String(taskArn),
+		})
+		stopped <- err
+	}()
+
+	forced, err := client.DrainAndWait(aws.BackgroundContext(), &ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns),
+		Status:             aws.String(ecs.ContainerInstanceStatusDraining),
+	}, 5*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, <-stopped)
+	assert.Empty(t, forced, "the task stopped by itself")
+}
+
+func TestDrainAndWaitTimeoutStopsTasks(t *testing.T) {
+	defer ecs.SetDrainAndWaitPollInterval(10 * time.Millisecond)()
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+	taskArn := startTaskOnInstance(t, client, arns[0])
+
+	forced, err := client.DrainAndWait(aws.BackgroundContext(), &ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns),
+		Status:             aws.String(ecs.ContainerInstanceStatusDraining),
+	}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{taskArn}, forced)
+
+	output, err := client.DescribeTasks(&ecs.DescribeTasksInput{
+		Cluster: aws.String(testCluster),
+		Tasks:   aws.StringSlice([]string{taskArn}),
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Tasks, 1)
+	assert.Equal(t, ecs.DesiredStatusStopped, aws.StringValue(output.Tasks[0].DesiredStatus))
+	assert.Contains(t, aws.StringValue(output.Tasks[0].StoppedReason), "drain timed out")
+}
+
+func TestDrainAndWaitRequiresDraining(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+
+	_, err := client.DrainAndWait(aws.BackgroundContext(), &ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns),
+		Status:             aws.String(ecs.ContainerInstanceStatusActive),
+	}, ecs.DefaultDrainTimeout)
+	assert.Error(t, err)
+}
+
+func TestDrainAndWaitTimeoutRange(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := registerContainerInstances(t, client, 1)
+	input := &ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns),
+		Status:             aws.String(ecs.ContainerInstanceStatusDraining),
+	}
+
+	for _, timeout := range []time.Duration{-time.Second, time.Hour + time.Second} {
+		_, err := client.DrainAndWait(aws.BackgroundContext(), input, timeout)
+		assert.Error(t, err, "timeout %s", timeout)
+	}
+	described, err := client.DescribeContainerInstances(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(testCluster),
+		ContainerInstances: aws.StringSlice(arns),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ecs.ContainerInstanceStatusActive, aws.StringValue(described.ContainerInstances[0].Status),
+		"the instance should not be drained with an invalid timeout")
+
+	forced, err := client.DrainAndWait(aws.BackgroundContext(), input, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, forced)
+}
+
+func TestWaitForServiceStable(t *testing.T) {
+	defer ecs.SetWaitForServiceStablePollInterval(10 * time.Millisecond)()
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := createServices(t, server, 1)
+	_, err := client.UpdateService(&ecs.UpdateServiceInput{
+		Cluster:      aws.String(testCluster),
+		Service:      aws.String(arns[0]),
+		DesiredCount: aws.Int64(1),
+	})
+	require.NoError(t, err)
+
+	// The fake never starts service tasks, so scaling back in is what makes
+	// the service stable
+	updated := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, err := client.UpdateService(&ecs.UpdateServiceInput{
+			Cluster:      aws.String(testCluster),
+			Service:      aws.String(arns[0]),
+			DesiredCount: aws.Int64(0),
+		})
+		updated <- err
+	}()
+
+	service, err := client.WaitForServiceStable(aws.BackgroundContext(), testCluster, arns[0], 5*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, <-updated)
+	assert.Equal(t, arns[0], aws.StringValue(service.ServiceArn))
+	assert.Len(t, service.Deployments, 1)
+}
+
+func TestWaitForServiceStableTimeout(t *testing.T) {
+	defer ecs.SetWaitForServiceStablePollInterval(10 * time.Millisecond)()
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	arns := createServices(t, server, 1)
+	_, err := client.UpdateService(&ecs.UpdateServiceInput{
+		Cluster:            aws.String(testCluster),
+		Service:            aws.String(arns[0]),
+		ForceNewDeployment: aws.Bool(true),
+	})
+	require.NoError(t, err)
+
+	service, err := client.WaitForServiceStable(aws.BackgroundContext(), testCluster, arns[0], 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	require.NotNil(t, service, "the last observed service should be returned")
+	assert.Len(t, service.Deployments, 2)
+}
+
+func TestWaitForServiceStableTasksPending(t *testing.T) {
+	var describes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		describes++
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		fmt.Fprint(w, `{"services": [{"serviceName": "service", "deployments": [
+			{"id": "ecs-svc/1", "status": "PRIMARY", "desiredCount": 2, "runningCount": 2, "pendingCount": 1}
+		]}], "failures": []}`)
+	}))
+	defer server.Close()
+	client := ecs.New(session.New(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+
+	// A pending task, such as the replacement of a stopped one, does not
+	// keep the service from being stable once as many tasks run as desired
+	service, err := client.WaitForServiceStable(aws.BackgroundContext(), testCluster, "service", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, describes)
+	assert.False(t, service.IsStable(), "IsStable also requires no pending task")
+}
+
+func TestWaitForServiceStableMissing(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	createServices(t, server, 0)
+
+	service, err := client.WaitForServiceStable(aws.BackgroundContext(), testCluster, "missing", time.Minute)
+	require.Error(t, err)
+	assert.Nil(t, service)
+	assert.Contains(t, err.Error(), "MISSING")
+}
+
+func TestListTaskDefinitionSummaries(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for _, family := range []string{"web", "web", "worker"} {
+		_, err := client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+			Family: aws.String(family),
+			ContainerDefinitions: []*ecs.ContainerDefinition{{
+				Name:  aws.String("container"),
+				Image: aws.String("busybox"),
+			}},
+		})
+		require.NoError(t, err)
+	}
+	_, err := client.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{
+		TaskDefinition: aws.String("web:1"),
+	})
+	require.NoError(t, err)
+
+	summaries, err := client.ListTaskDefinitionSummaries(aws.BackgroundContext(), "", "")
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, &ecs.TaskDefinitionSummary{
+		Arn:      "arn:aws:ecs:us-west-2:123456789012:task-definition/web:2",
+		Family:   "web",
+		Revision: 2,
+		Status:   ecs.TaskDefinitionStatusActive,
+	}, summaries[0])
+	assert.Equal(t, "worker", summaries[1].Family)
+
+	summaries, err = client.ListTaskDefinitionSummaries(aws.BackgroundContext(), "web", ecs.TaskDefinitionStatusInactive)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, int64(1), summaries[0].Revision)
+	assert.Equal(t, ecs.TaskDefinitionStatusInactive, summaries[0].Status)
+}
+
+func TestIterateTaskDefinitionRevisions(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for _, family := range []string{"web", "web", "worker"} {
+		_, err := client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+			Family: aws.String(family),
+			ContainerDefinitions: []*ecs.ContainerDefinition{{
+				Name:  aws.String("container"),
+				Image: aws.String("busybox"),
+			}},
+		})
+		require.NoError(t, err)
+	}
+
+	var revisions []int64
+	err := client.IterateTaskDefinitionRevisions(aws.BackgroundContext(), "web", func(td *ecs.TaskDefinition) bool {
+		assert.Equal(t, "web", aws.StringValue(td.Family))
+		revisions = append(revisions, aws.Int64Value(td.Revision))
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, revisions)
+
+	revisions = nil
+	err = client.IterateTaskDefinitionRevisions(aws.BackgroundContext(), "web", func(td *ecs.TaskDefinition) bool {
+		revisions = append(revisions, aws.Int64Value(td.Revision))
+		return false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, revisions)
+}
+
+func TestIterateTaskDefinitionRevisionsDescribeError(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("DescribeTaskDefinition", 1))
+	defer server.Close()
+	client := server.Client()
+
+	_, err := client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String("web"),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	})
+	require.NoError(t, err)
+
+	called := false
+	err = client.IterateTaskDefinitionRevisions(aws.BackgroundContext(), "web", func(td *ecs.TaskDefinition) bool {
+		called = true
+		return true
+	})
+	assert.Error(t, err)
+	assert.False(t, called)
+}
+
+func registerFamily(t *testing.T, client *ecs.ECS, family string) string {
+	output, err := client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String(family),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	})
+	require.NoError(t, err)
+	return aws.StringValue(output.TaskDefinition.TaskDefinitionArn)
+}
+
+func TestDescribeLatestTaskDefinitionsForAllFamilies(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	// More families than are looked up at once
+	var expected []string
+	for i := 0; i < 25; i++ {
+		family := fmt.Sprintf("family-%02d", i)
+		registerFamily(t, client, family)
+		expected = append(expected, registerFamily(t, client, family))
+	}
+	// The latest ACTIVE revision is returned even if a newer one is INACTIVE
+	expected = append(expected, registerFamily(t, client, "rolled-back"))
+	for _, arn := range []string{registerFamily(t, client, "rolled-back"), registerFamily(t, client, "retired")} {
+		_, err := client.DeregisterTaskDefinition(&ecs.DeregisterTaskDefinitionInput{TaskDefinition: aws.String(arn)})
+		require.NoError(t, err)
+	}
+
+	taskDefinitions, err := client.DescribeLatestTaskDefinitionsForAllFamilies(aws.BackgroundContext())
+	require.NoError(t, err)
+	var arns []string
+	for _, taskDefinition := range taskDefinitions {
+		assert.Len(t, taskDefinition.ContainerDefinitions, 1, "the task definitions should be described")
+		arns = append(arns, aws.StringValue(taskDefinition.TaskDefinitionArn))
+	}
+	assert.Equal(t, expected, arns)
+}
+
+func TestDescribeLatestTaskDefinitionsForAllFamiliesError(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("DescribeTaskDefinition", 3))
+	defer server.Close()
+	client := server.Client()
+	for i := 0; i < 5; i++ {
+		registerFamily(t, client, fmt.Sprintf("family-%d", i))
+	}
+
+	taskDefinitions, err := client.DescribeLatestTaskDefinitionsForAllFamilies(aws.BackgroundContext())
+	require.Error(t, err)
+	assert.Nil(t, taskDefinitions)
+	assert.Equal(t, ecs.ErrCodeServerException, errors.Cause(err).(awserr.Error).Code())
+}
+
+func TestDescribeLatestTaskDefinitionsForAllFamiliesEmpty(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+
+	taskDefinitions, err := server.Client().DescribeLatestTaskDefinitionsForAllFamilies(aws.BackgroundContext())
+	require.NoError(t, err)
+	assert.Empty(t, taskDefinitions)
+}
+
+func testGetOrRegisterInput(image string) *ecs.RegisterTaskDefinitionInput {
+	return &ecs.RegisterTaskDefinitionInput{
+		Family:      aws.String("web"),
+		NetworkMode: aws.String(ecs.NetworkModeBridge),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:   aws.String("web"),
+			Image:  aws.String(image),
+			Memory: aws.Int64(256),
+			LogConfiguration: &ecs.LogConfiguration{
+				LogDriver: aws.String(ecs.LogDriverAwslogs),
+				Options:   aws.StringMap(map[string]string{"awslogs-group": "web", "awslogs-region": "us-west-2"}),
+			},
+		}},
+	}
+}
+
+func TestGetOrRegisterTaskDefinition(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	first, registered, err := client.GetOrRegisterTaskDefinition(aws.BackgroundContext(), testGetOrRegisterInput("nginx:1.15"))
+	require.NoError(t, err)
+	assert.True(t, registered, "the family has no revision yet")
+	assert.Equal(t, int64(1), aws.Int64Value(first.Revision))
+
+	input := testGetOrRegisterInput("nginx:1.15")
+	input.Tags = []*ecs.Tag{{Key: aws.String("team"), Value: aws.String("web")}}
+	same, registered, err := client.GetOrRegisterTaskDefinition(aws.BackgroundContext(), input)
+	require.NoError(t, err)
+	assert.False(t, registered, "the latest revision has the same configuration")
+	assert.Equal(t, aws.StringValue(first.TaskDefinitionArn), aws.StringValue(same.TaskDefinitionArn))
+
+	changed, registered, err := client.GetOrRegisterTaskDefinition(aws.BackgroundContext(), testGetOrRegisterInput("nginx:1.16"))
+	require.NoError(t, err)
+	assert.True(t, registered)
+	assert.Equal(t, int64(2), aws.Int64Value(changed.Revision))
+	assert.Equal(t, "nginx:1.16", aws.StringValue(changed.ContainerDefinitions[0].Image))
+
+	// Going back to the first configuration registers a new revision, as
+	// only the latest one is compared
+	_, registered, err = client.GetOrRegisterTaskDefinition(aws.BackgroundContext(), testGetOrRegisterInput("nginx:1.15"))
+	require.NoError(t, err)
+	assert.True(t, registered)
+}
+
+func TestGetOrRegisterTaskDefinitionErrors(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("ListTaskDefinitions", 1))
+	defer server.Close()
+	client := server.Client()
+
+	_, registered, err := client.GetOrRegisterTaskDefinition(aws.BackgroundContext(), testGetOrRegisterInput("nginx:1.15"))
+	require.Error(t, err)
+	assert.False(t, registered)
+	assert.Equal(t, ecs.ErrCodeServerException, errors.Cause(err).(awserr.Error).Code())
+
+	_, _, err = client.GetOrRegisterTaskDefinition(aws.BackgroundContext(), &ecs.RegisterTaskDefinitionInput{})
+	assert.Error(t, err)
+}
+
+func TestValidateTaskDefinition(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	input := &ecs.RegisterTaskDefinitionInput{
+		Family:                  aws.String("web"),
+		RequiresCompatibilities: aws.StringSlice([]string{ecs.CompatibilityFargate}),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	}
+	_, err := client.RegisterTaskDefinition(input)
+	require.Error(t, err, "Fargate task definitions need cpu and memory to be registered")
+	_, ok := err.(request.ErrInvalidParams)
+	assert.True(t, ok, "the request should be rejected before it is sent")
+
+	validated, err := client.ValidateTaskDefinition(aws.BackgroundContext(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "web", aws.StringValue(validated.Family))
+	assert.Nil(t, validated.TaskDefinitionArn, "the task definition should not be registered")
+	assert.Len(t, input.ValidationWarnings(), 2)
+
+	input.ContainerDefinitions = nil
+	_, err = client.ValidateTaskDefinition(aws.BackgroundContext(), input)
+	assert.Error(t, err, "the input should be validated")
+	input.ContainerDefinitions = []*ecs.ContainerDefinition{{
+		Name:  aws.String("container"),
+		Image: aws.String("busybox"),
+	}}
+	input.Family = aws.String("web app")
+	_, err = client.ValidateTaskDefinition(aws.BackgroundContext(), input)
+	assert.Error(t, err, "the custom constraints should be validated")
+	input.Family = aws.String("web")
+
+	// The dry run did not use up the first revision
+	input.Cpu = aws.String("256")
+	input.Memory = aws.String("512")
+	registered, err := client.RegisterTaskDefinition(input)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), aws.Int64Value(registered.TaskDefinition.Revision))
+}
+
+// setupRunTaskBatch registers a container instance and a task definition to
+// run tasks from in the fake
+func setupRunTaskBatch(t *testing.T, client *ecs.ECS) *ecs.RunTaskInput {
+	registerContainerInstances(t, client, 1)
+	_, err := client.RegisterTaskDefinition(&ecs.RegisterTaskDefinitionInput{
+		Family: aws.String("family"),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	})
+	require.NoError(t, err)
+	return &ecs.RunTaskInput{
+		Cluster:        aws.String(testCluster),
+		TaskDefinition: aws.String("family"),
+	}
+}
+
+func TestRunTaskBatch(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	input := setupRunTaskBatch(t, client)
+
+	tasks, failures, err := client.RunTaskBatch(aws.BackgroundContext(), input, 25)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.Len(t, tasks, 25)
+	assert.Nil(t, input.Count, "the input should not be modified")
+
+	listed, err := client.ListTasks(&ecs.ListTasksInput{Cluster: aws.String(testCluster)})
+	require.NoError(t, err)
+	assert.Len(t, listed.TaskArns, 25)
+}
+
+func TestRunTaskBatchAggregatesErrors(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("RunTask", 2))
+	defer server.Close()
+	client := server.Client()
+	input := setupRunTaskBatch(t, client)
+
+	// One of the three RunTask calls fails, without stopping the other two
+	tasks, _, err := client.RunTaskBatch(aws.BackgroundContext(), input, 25)
+	require.Error(t, err)
+	batchErr, ok := err.(*ecs.RunTaskBatchError)
+	require.True(t, ok)
+	require.Len(t, batchErr.Errors, 1)
+	assert.Equal(t, ecs.ErrCodeServerException, batchErr.Errors[0].(awserr.Error).Code())
+	assert.True(t, len(tasks) == 15 || len(tasks) == 20, "unexpected number of tasks: %d", len(tasks))
+}
+
+// countingRunTaskClient tracks how many RunTask calls are in flight
+type countingRunTaskClient struct {
+	ecs.ECSClientInterface
+	lock     sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *countingRunTaskClient) RunTaskWithContext(ctx aws.Context, input *ecs.RunTaskInput, opts ...request.Option) (*ecs.RunTaskOutput, error) {
+	c.lock.Lock()
+	c.inFlight++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
+	c.lock.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	output, err := c.ECSClientInterface.RunTaskWithContext(ctx, input, opts...)
+	c.lock.Lock()
+	c.inFlight--
+	c.lock.Unlock()
+	return output, err
+}
+
+func TestRunTaskInChunksBoundsConcurrency(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	input := setupRunTaskBatch(t, server.Client())
+	client := &countingRunTaskClient{ECSClientInterface: server}
+
+	tasks, failures, err := ecs.RunTaskInChunks(aws.BackgroundContext(), client, input, 100)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.Len(t, tasks, 100)
+	assert.True(t, client.peak <= 5, "at most 5 calls in flight, got %d", client.peak)
+}
+
+func TestRunTaskBatchInvalidCount(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	_, _, err := client.RunTaskBatch(aws.BackgroundContext(), &ecs.RunTaskInput{}, 0)
+	assert.Error(t, err)
+}
+
+func TestGetEffectiveSettings(t *testing.T) {
+	var requestBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requestBody = string(body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		fmt.Fprint(w, `{"settings": [
+			{"name": "taskLongArnFormat", "value": "enabled", "principalArn": "arn:aws:iam::123456789012:root"},
+			{"name": "serviceLongArnFormat", "value": "disabled", "principalArn": "arn:aws:iam::123456789012:user/dev"}
+		]}`)
+	}))
+	defer server.Close()
+	client := ecs.New(session.New(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+
+	settings, err := client.GetEffectiveSettings(aws.BackgroundContext())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		ecs.SettingNameTaskLongArnFormat:    "enabled",
+		ecs.SettingNameServiceLongArnFormat: "disabled",
+	}, settings)
+	assert.Contains(t, requestBody, `"effectiveSettings":true`)
+}
+
+func TestGetEffectiveSettingsFromFake(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	_, err := client.PutAccountSetting(&ecs.PutAccountSettingInput{
+		Name:  aws.String(ecs.SettingNameServiceLongArnFormat),
+		Value: aws.String("disabled"),
+	})
+	require.NoError(t, err)
+
+	settings, err := client.GetEffectiveSettings(aws.BackgroundContext())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		ecs.SettingNameServiceLongArnFormat:           "disabled",
+		ecs.SettingNameTaskLongArnFormat:              "enabled",
+		ecs.SettingNameContainerInstanceLongArnFormat: "enabled",
+	}, settings)
+}
+
+func TestListAllContainerInstances(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	expected := make(map[string][]string)
+	for cluster, count := range map[string]int{"a": 3, "b": 0, "c": 1} {
+		created, err := client.CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(cluster)})
+		require.NoError(t, err)
+		arns := []string{}
+		for i := 0; i < count; i++ {
+			registered, err := client.RegisterContainerInstance(&ecs.RegisterContainerInstanceInput{
+				Cluster: aws.String(cluster),
+			})
+			require.NoError(t, err)
+			arns = append(arns, aws.StringValue(registered.ContainerInstance.ContainerInstanceArn))
+		}
+		expected[aws.StringValue(created.Cluster.ClusterArn)] = arns
+	}
+
+	instances, err := client.ListAllContainerInstances(aws.BackgroundContext())
+	require.NoError(t, err)
+	require.Len(t, instances, 3)
+	for cluster, arns := range expected {
+		assert.ElementsMatch(t, arns, instances[cluster], cluster)
+	}
+}
+
+func TestListAllContainerInstancesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var operations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.Header.Get("X-Amz-Target")
+		operation := target[strings.LastIndex(target, ".")+1:]
+		operations = append(operations, operation)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if operation == "ListClusters" {
+			fmt.Fprint(w, `{"clusterArns": [
+				"arn:aws:ecs:us-west-2:123456789012:cluster/a",
+				"arn:aws:ecs:us-west-2:123456789012:cluster/b"
+			]}`)
+			return
+		}
+		// The audit is cancelled while the first cluster is listed
+		cancel()
+		fmt.Fprint(w, `{"containerInstanceArns": []}`)
+	}))
+	defer server.Close()
+	client := ecs.New(session.New(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+
+	instances, err := client.ListAllContainerInstances(ctx)
+	require.Error(t, err)
+	assert.Nil(t, instances)
+	assert.Equal(t, []string{"ListClusters", "ListContainerInstances"}, operations,
+		"the second cluster should not be listed once cancelled")
+}
+
+func TestCreateClusterIfNotExists(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+
+	cluster, err := server.Client().CreateClusterIfNotExists(aws.BackgroundContext(), &ecs.CreateClusterInput{
+		ClusterName: aws.String(testCluster),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testCluster, aws.StringValue(cluster.ClusterName))
+}
+
+// alreadyExistsServer fails CreateCluster with a ClientException saying the
+// cluster already exists, and answers DescribeClusters with describeStatus
+// and describeBody. It records the operations called.
+func alreadyExistsServer(describeStatus int, describeBody string, operations *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.Header.Get("X-Amz-Target")
+		operation := target[strings.LastIndex(target, ".")+1:]
+		*operations = append(*operations, operation)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if operation == "CreateCluster" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"__type": "ClientException", "message": "Cluster cluster already exists."}`)
+			return
+		}
+		w.WriteHeader(describeStatus)
+		fmt.Fprint(w, describeBody)
+	}))
+}
+
+func TestCreateClusterIfNotExistsAlreadyExists(t *testing.T) {
+	var operations []string
+	server := alreadyExistsServer(http.StatusOK,
+		`{"clusters": [{"clusterName": "cluster", "status": "ACTIVE"}], "failures": []}`, &operations)
+	defer server.Close()
+	client := ecs.New(session.New(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+
+	cluster, err := client.CreateClusterIfNotExists(aws.BackgroundContext(), &ecs.CreateClusterInput{
+		ClusterName: aws.String("cluster"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cluster", aws.StringValue(cluster.ClusterName))
+	assert.Equal(t, []string{"CreateCluster", "DescribeClusters"}, operations)
+}
+
+func TestCreateClusterIfNotExistsDescribeFailure(t *testing.T) {
+	testCases := []struct {
+		name           string
+		describeStatus int
+		describeBody   string
+		message        string
+	}{
+		{"error", http.StatusBadRequest, `{"__type": "AccessDeniedException", "message": "denied"}`, "AccessDeniedException"},
+		{"failure", http.StatusOK, `{"clusters": [], "failures": [{"arn": "cluster", "reason": "MISSING"}]}`, "MISSING"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var operations []string
+			server := alreadyExistsServer(tc.describeStatus, tc.describeBody, &operations)
+			defer server.Close()
+			client := ecs.New(session.New(&aws.Config{
+				Region:      aws.String("us-west-2"),
+				Endpoint:    aws.String(server.URL),
+				Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+			}))
+
+			cluster, err := client.CreateClusterIfNotExists(aws.BackgroundContext(), &ecs.CreateClusterInput{
+				ClusterName: aws.String("cluster"),
+			})
+			require.Error(t, err)
+			assert.Nil(t, cluster)
+			assert.Contains(t, err.Error(), tc.message)
+			assert.Equal(t, []string{"CreateCluster", "DescribeClusters"}, operations)
+		})
+	}
+}
+
+func TestCreateClusterIfNotExistsOtherClientException(t *testing.T) {
+	var operations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operations = append(operations, r.Header.Get("X-Amz-Target"))
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"__type": "ClientException", "message": "Too many clusters."}`)
+	}))
+	defer server.Close()
+	client := ecs.New(session.New(&aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	}))
+
+	_, err := client.CreateClusterIfNotExists(aws.BackgroundContext(), &ecs.CreateClusterInput{
+		ClusterName: aws.String("cluster"),
+	})
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeClientException, err.(awserr.Error).Code())
+	assert.Len(t, operations, 1, "the cluster should not be described")
+}
+
+// testBulkAttributes returns n custom attributes for each instance
+func testBulkAttributes(instances []string, n int) map[string][]*ecs.Attribute {
+	attrs := make(map[string][]*ecs.Attribute)
+	for _, instance := range instances {
+		for i := 0; i < n; i++ {
+			attrs[instance] = append(attrs[instance], &ecs.Attribute{
+				Name:  aws.String(fmt.Sprintf("attribute-%d", i)),
+				Value: aws.String(shortID(instance)),
+			})
+		}
+	}
+	return attrs
+}
+
+func TestPutAttributesBulk(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	instances := registerContainerInstances(t, client, 3)
+	attrs := testBulkAttributes(instances, 7)
+
+	// 21 attributes take 3 calls
+	require.NoError(t, client.PutAttributesBulk(aws.BackgroundContext(), testCluster, attrs))
+	assert.Nil(t, attrs[instances[0]][0].TargetId, "the attributes given should not be modified")
+
+	for _, instance := range instances {
+		listed, err := client.ListAttributes(&ecs.ListAttributesInput{
+			Cluster:       aws.String(testCluster),
+			TargetType:    aws.String(ecs.TargetTypeContainerInstance),
+			AttributeName: aws.String("attribute-6"),
+		})
+		require.NoError(t, err)
+		require.Len(t, listed.Attributes, 3)
+		found := false
+		for _, attribute := range listed.Attributes {
+			if aws.StringValue(attribute.TargetId) == instance {
+				found = true
+				assert.Equal(t, shortID(instance), aws.StringValue(attribute.Value))
+			}
+		}
+		assert.True(t, found, "attribute-6 should be put on %s", instance)
+	}
+}
+
+func TestPutAttributesBulkAggregatesErrors(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("PutAttributes", 2))
+	defer server.Close()
+	client := server.Client()
+	instances := registerContainerInstances(t, client, 3)
+
+	// The second of the three calls fails, without stopping the third
+	err := client.PutAttributesBulk(aws.BackgroundContext(), testCluster, testBulkAttributes(instances, 7))
+	require.Error(t, err)
+	bulkErr, ok := err.(*ecs.PutAttributesBulkError)
+	require.True(t, ok)
+	require.Len(t, bulkErr.Errors, 1)
+	assert.Equal(t, ecs.ErrCodeServerException, errors.Cause(bulkErr.Errors[0]).(awserr.Error).Code())
+	assert.Contains(t, err.Error(), "attributes 10 to 19")
+
+	listed, err := client.ListAttributes(&ecs.ListAttributesInput{
+		Cluster:    aws.String(testCluster),
+		TargetType: aws.String(ecs.TargetTypeContainerInstance),
+	})
+	require.NoError(t, err)
+	assert.Len(t, listed.Attributes, 11)
+}
+
+func TestPutAttributesBulkEmpty(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("PutAttributes", 1))
+	defer server.Close()
+	client := server.Client()
+
+	assert.NoError(t, client.PutAttributesBulk(aws.BackgroundContext(), testCluster, nil))
+}
+
+func TestCleanStalePendingTasks(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	instances := registerContainerInstances(t, client, 1)
+	var pending []string
+	for i := 0; i < 3; i++ {
+		pending = append(pending, startTaskOnInstance(t, client, instances[0]))
+	}
+	running := pending[0]
+	pending = pending[1:]
+	_, err := client.SubmitTaskStateChange(&ecs.SubmitTaskStateChangeInput{
+		Cluster: aws.String(testCluster),
+		Task:    aws.String(running),
+		Status:  aws.String(ecs.DesiredStatusRunning),
+	})
+	require.NoError(t, err)
+
+	stopped, err := client.CleanStalePendingTasks(aws.BackgroundContext(), testCluster, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, stopped, "no task has been pending for an hour")
+
+	stopped, err = client.CleanStalePendingTasks(aws.BackgroundContext(), testCluster, 0)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, pending, stopped)
+
+	output, err := client.DescribeTasks(&ecs.DescribeTasksInput{
+		Cluster: aws.String(testCluster),
+		Tasks:   aws.StringSlice(append([]string{running}, pending...)),
+	})
+	require.NoError(t, err)
+	for _, task := range output.Tasks {
+		if aws.StringValue(task.TaskArn) == running {
+			assert.Equal(t, ecs.DesiredStatusRunning, aws.StringValue(task.DesiredStatus))
+			continue
+		}
+		assert.Equal(t, ecs.DesiredStatusStopped, aws.StringValue(task.DesiredStatus))
+		assert.Equal(t, "ECS agent cleanup: task exceeded max pending age", aws.StringValue(task.StoppedReason))
+	}
+}
+
+func TestCleanStalePendingTasksAggregatesErrors(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("StopTask", 2))
+	defer server.Close()
+	client := server.Client()
+	instances := registerContainerInstances(t, client, 1)
+	var pending []string
+	for i := 0; i < 3; i++ {
+		pending = append(pending, startTaskOnInstance(t, client, instances[0]))
+	}
+
+	// The second of the three StopTask calls fails, without stopping the third
+	stopped, err := client.CleanStalePendingTasks(aws.BackgroundContext(), testCluster, 0)
+	require.Error(t, err)
+	cleanErr, ok := err.(*ecs.CleanStalePendingTasksError)
+	require.True(t, ok)
+	require.Len(t, cleanErr.Errors, 1)
+	assert.Equal(t, ecs.ErrCodeServerException, errors.Cause(cleanErr.Errors[0]).(awserr.Error).Code())
+	assert.Len(t, stopped, 2)
+	for _, taskArn := range stopped {
+		assert.Contains(t, pending, taskArn)
+	}
+}
+
+func TestGetAttributeMap(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	instances := registerContainerInstances(t, client, 3)
+
+	attributes, err := client.GetAttributeMap(aws.BackgroundContext(), testCluster, ecs.TargetTypeContainerInstance)
+	require.NoError(t, err)
+	assert.Empty(t, attributes)
+
+	_, err = client.PutAttributes(&ecs.PutAttributesInput{
+		Cluster: aws.String(testCluster),
+		Attributes: []*ecs.Attribute{
+			{Name: aws.String("env"), Value: aws.String("prod"), TargetId: aws.String(instances[0])},
+			{Name: aws.String("gpu"), TargetId: aws.String(instances[0])},
+		},
+	})
+	require.NoError(t, err)
+	attributes, err = client.GetAttributeMap(aws.BackgroundContext(), testCluster, ecs.TargetTypeContainerInstance)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		instances[0]: {"env": "prod", "gpu": ""},
+	}, attributes)
+}
+
+func TestGetAttributeMapMultipleTargets(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	client := server.Client()
+	instances := registerContainerInstances(t, client, 12)
+	// 108 attributes take two pages
+	require.NoError(t, client.PutAttributesBulk(aws.BackgroundContext(), testCluster, testBulkAttributes(instances, 9)))
+
+	attributes, err := client.GetAttributeMap(aws.BackgroundContext(), testCluster, ecs.TargetTypeContainerInstance)
+	require.NoError(t, err)
+	require.Len(t, attributes, 12)
+	for _, instance := range instances {
+		require.Len(t, attributes[instance], 9)
+		// Every instance has attribute-0, with its own value
+		assert.Equal(t, shortID(instance), attributes[instance]["attribute-0"])
+		assert.Equal(t, shortID(instance), attributes[instance]["attribute-8"])
+	}
+}
+
+func TestGetAttributeMapError(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+
+	_, err := server.Client().GetAttributeMap(aws.BackgroundContext(), "missing", ecs.TargetTypeContainerInstance)
+	require.Error(t, err)
+	assert.Equal(t, ecs.ErrCodeClusterNotFoundException, errors.Cause(err).(awserr.Error).Code())
+}
+
+func registerInputs(n int) []*ecs.RegisterContainerInstanceInput {
+	inputs := make([]*ecs.RegisterContainerInstanceInput, n)
+	for i := range inputs {
+		inputs[i] = &ecs.RegisterContainerInstanceInput{Cluster: aws.String(testCluster)}
+	}
+	return inputs
+}
+
+func TestRegisterContainerInstancesConcurrently(t *testing.T) {
+	server := ecsfake.NewServer()
+	defer server.Close()
+	_, err := server.Client().CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+
+	instances, errs, err := ecs.RegisterContainerInstancesConcurrently(aws.BackgroundContext(), server, registerInputs(20), 4)
+	require.NoError(t, err)
+	require.Len(t, instances, 20)
+	require.Len(t, errs, 20)
+	arns := make(map[string]bool)
+	for i := range instances {
+		assert.NoError(t, errs[i])
+		require.NotNil(t, instances[i])
+		arns[aws.StringValue(instances[i].ContainerInstanceArn)] = true
+	}
+	assert.Len(t, arns, 20)
+}
+
+func TestRegisterContainerInstancesConcurrentlyPartialFailure(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("RegisterContainerInstance", 5))
+	defer server.Close()
+	_, err := server.Client().CreateCluster(&ecs.CreateClusterInput{ClusterName: aws.String(testCluster)})
+	require.NoError(t, err)
+
+	instances, errs, err := ecs.RegisterContainerInstancesConcurrently(aws.BackgroundContext(), server, registerInputs(20), 3)
+	require.NoError(t, err)
+	failed := 0
+	for i := range instances {
+		if errs[i] != nil {
+			failed++
+			assert.Nil(t, instances[i])
+			assert.Equal(t, ecs.ErrCodeServerException, errs[i].(awserr.Error).Code())
+			continue
+		}
+		assert.NotNil(t, instances[i])
+	}
+	assert.Equal(t, 4, failed)
+}
+
+// blockingRegisterClient blocks RegisterContainerInstance calls until their
+// context is done, tracking how many are in flight
+type blockingRegisterClient struct {
+	ecs.ECSClientInterface
+	lock     sync.Mutex
+	inFlight int
+	peak     int
+	started  chan struct{}
+}
+
+func (c *blockingRegisterClient) RegisterContainerInstanceWithContext(ctx aws.Context, input *ecs.RegisterContainerInstanceInput, opts ...request.Option) (*ecs.RegisterContainerInstanceOutput, error) {
+	c.lock.Lock()
+	c.inFlight++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
+	c.lock.Unlock()
+	c.started <- struct{}{}
+
+	<-ctx.Done()
+	c.lock.Lock()
+	c.inFlight--
+	c.lock.Unlock()
+	return nil, ctx.Err()
+}
+
+func TestRegisterContainerInstancesConcurrentlyCancelled(t *testing.T) {
+	client := &blockingRegisterClient{started: make(chan struct{}, 10)}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for i := 0; i < 3; i++ {
+			<-client.started
+		}
+		cancel()
+	}()
+
+	done := make(chan struct{})
+	var instances []*ecs.ContainerInstance
+	var errs []error
+	var err error
+	go func() {
+		defer close(done)
+		instances, errs, err = ecs.RegisterContainerInstancesConcurrently(ctx, client, registerInputs(10), 3)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RegisterContainerInstancesConcurrently did not return once cancelled")
+	}
+
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, client.peak <= 3, "at most 3 calls in flight, got %d", client.peak)
+	require.Len(t, errs, 10)
+	for i := range errs {
+		assert.Nil(t, instances[i])
+		assert.Equal(t, context.Canceled, errs[i])
+	}
+}
+
+func TestRegisterContainerInstancesConcurrentlyInvalidConcurrency(t *testing.T) {
+	_, _, err := ecs.RegisterContainerInstancesConcurrently(aws.BackgroundContext(), nil, registerInputs(1), 0)
+	assert.Error(t, err)
+}
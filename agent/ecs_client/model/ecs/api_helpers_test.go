@@ -0,0 +1,1778 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTaskArn1 = "arn:aws:ecs:us-west-2:123456789012:task/default/task1"
+	testTaskArn2 = "arn:aws:ecs:us-west-2:123456789012:task/default/task2"
+	testTaskArn3 = "arn:aws:ecs:us-west-2:123456789012:task/default/task3"
+)
+
+func TestCheckDescribeTasksCompleteAllReturned(t *testing.T) {
+	output := &DescribeTasksOutput{
+		Tasks: []*Task{
+			{TaskArn: aws.String(testTaskArn1)},
+			{TaskArn: aws.String(testTaskArn2)},
+		},
+	}
+	assert.NoError(t, CheckDescribeTasksComplete([]string{testTaskArn1, testTaskArn2}, output))
+	assert.NoError(t, CheckDescribeTasksComplete([]string{"task1", "task2"}, output))
+}
+
+func TestDescribeTasksOutputByArn(t *testing.T) {
+	output := &DescribeTasksOutput{
+		Tasks: []*Task{
+			{TaskArn: aws.String(testTaskArn1)},
+			nil,
+			{TaskArn: aws.String(testTaskArn2)},
+		},
+		Failures: []*Failure{
+			{Arn: aws.String(testTaskArn3), Reason: aws.String("MISSING")},
+		},
+	}
+
+	tasks := output.TasksByArn()
+	require.Len(t, tasks, 2)
+	assert.True(t, output.Tasks[0] == tasks[testTaskArn1])
+	assert.True(t, output.Tasks[2] == tasks[testTaskArn2])
+	assert.Nil(t, tasks[testTaskArn3])
+
+	failures := output.FailuresByArn()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "MISSING", aws.StringValue(failures[testTaskArn3].Reason))
+}
+
+func TestDescribeTasksOutputByArnNil(t *testing.T) {
+	var output *DescribeTasksOutput
+	assert.NotNil(t, output.TasksByArn())
+	assert.Empty(t, output.TasksByArn())
+	assert.NotNil(t, output.FailuresByArn())
+	assert.Empty(t, output.FailuresByArn())
+	assert.Empty(t, (&DescribeTasksOutput{}).TasksByArn())
+}
+
+func TestFilterTasksByStatus(t *testing.T) {
+	tasks := []*Task{
+		{TaskArn: aws.String(testTaskArn1), LastStatus: aws.String("RUNNING")},
+		nil,
+		{TaskArn: aws.String(testTaskArn2), LastStatus: aws.String("PENDING")},
+		{TaskArn: aws.String(testTaskArn3), LastStatus: aws.String("RUNNING")},
+		{TaskArn: aws.String("unknown")},
+	}
+
+	running := FilterTasksByStatus(tasks, "RUNNING")
+	require.Len(t, running, 2)
+	assert.True(t, tasks[0] == running[0])
+	assert.True(t, tasks[3] == running[1])
+	assert.Equal(t, []*Task{tasks[2]}, FilterTasksByStatus(tasks, "PENDING"))
+	assert.Empty(t, FilterTasksByStatus(tasks, "STOPPED"))
+	assert.Empty(t, FilterTasksByStatus(nil, "RUNNING"))
+}
+
+func TestFilterClustersByPrefix(t *testing.T) {
+	arns := []string{
+		"arn:aws:ecs:us-west-2:123456789012:cluster/prod",
+		"arn:aws:ecs:us-west-2:123456789012:cluster/prod-batch",
+		"arn:aws:ecs:us-west-2:123456789012:cluster/staging-prod",
+	}
+
+	testCases := []struct {
+		name     string
+		prefix   string
+		expected []string
+	}{
+		{"exact match", "prod-batch", arns[1:2]},
+		{"partial prefix", "pro", arns[:2]},
+		{"no match", "dev", nil},
+		{"empty prefix", "", arns},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FilterClustersByPrefix(arns, tc.prefix))
+		})
+	}
+}
+
+func TestFilterContainerInstances(t *testing.T) {
+	instances := []*ContainerInstance{
+		{Ec2InstanceId: aws.String("i-1"), Status: aws.String(ContainerInstanceStatusActive), AgentConnected: aws.Bool(true)},
+		{Ec2InstanceId: aws.String("i-2"), Status: aws.String(ContainerInstanceStatusDraining), AgentConnected: aws.Bool(true)},
+		{Ec2InstanceId: aws.String("i-3"), Status: aws.String(ContainerInstanceStatusActive), AgentConnected: aws.Bool(false)},
+	}
+
+	filtered, err := FilterContainerInstances(instances, []*Filter{
+		{Name: aws.String(FilterNameStatus), Values: aws.StringSlice([]string{ContainerInstanceStatusActive, ContainerInstanceStatusDraining})},
+		{Name: aws.String(FilterNameAgentConnected), Values: aws.StringSlice([]string{"true"})},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, instances[:2], filtered)
+
+	filtered, err = FilterContainerInstances(instances, nil)
+	require.NoError(t, err)
+	assert.Equal(t, instances, filtered)
+
+	_, err = FilterContainerInstances(instances, []*Filter{{Name: aws.String("ec2InstanceType")}, {}})
+	require.Error(t, err)
+	invalidParams, ok := err.(request.ErrInvalidParams)
+	require.True(t, ok)
+	require.Equal(t, 2, invalidParams.Len())
+	assert.Equal(t, ParamEnumErrCode, invalidParams.OrigErrs()[0].(request.ErrInvalidParam).Code())
+	assert.Equal(t, "FilterContainerInstances.Filters[0].Name", invalidParams.OrigErrs()[0].(request.ErrInvalidParam).Field())
+	assert.Equal(t, request.ParamRequiredErrCode, invalidParams.OrigErrs()[1].(request.ErrInvalidParam).Code())
+}
+
+func TestAttributeFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attrs    map[string]string
+		expected string
+	}{
+		{"single attribute", map[string]string{"env": "prod"}, "attribute:env == prod"},
+		{
+			"multiple attributes",
+			map[string]string{"tier": "backend", "env": "prod", "ecs.instance-type": "t2.micro"},
+			"attribute:ecs.instance-type == t2.micro and attribute:env == prod and attribute:tier == backend",
+		},
+		{"attribute without value", map[string]string{"gpu": "", "env": "prod"}, "attribute:env == prod and attribute:gpu exists"},
+		{"empty map", map[string]string{}, ""},
+		{"nil map", nil, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, AttributeFilter(tc.attrs))
+		})
+	}
+}
+
+func TestEc2InstanceIdFilter(t *testing.T) {
+	assert.Equal(t, "attribute:ecs.instance-id in [i-0123456789abcdef0]",
+		ec2InstanceIdFilter([]string{"i-0123456789abcdef0"}))
+	assert.Equal(t, "attribute:ecs.instance-id in [i-0123456789abcdef0, i-0fedcba9876543210]",
+		ec2InstanceIdFilter([]string{"i-0123456789abcdef0", "i-0fedcba9876543210"}))
+}
+
+func TestCheckDescribeTasksCompleteWithFailures(t *testing.T) {
+	output := &DescribeTasksOutput{
+		Tasks: []*Task{
+			{TaskArn: aws.String(testTaskArn1)},
+		},
+		Failures: []*Failure{
+			{Arn: aws.String(testTaskArn2), Reason: aws.String("MISSING")},
+		},
+	}
+	err := CheckDescribeTasksComplete([]string{testTaskArn1, testTaskArn2}, output)
+	require.Error(t, err)
+	missingErr, ok := err.(*MissingTasksError)
+	require.True(t, ok, "expected a MissingTasksError, got %T", err)
+	assert.Equal(t, []string{testTaskArn2}, missingErr.Arns)
+	require.Len(t, missingErr.Failures, 1)
+	assert.Equal(t, "MISSING", aws.StringValue(missingErr.Failures[0].Reason))
+}
+
+func TestCheckDescribeTasksCompleteWithUnaccountedTasks(t *testing.T) {
+	output := &DescribeTasksOutput{
+		Tasks: []*Task{
+			{TaskArn: aws.String(testTaskArn2)},
+		},
+	}
+	err := CheckDescribeTasksComplete([]string{testTaskArn1, testTaskArn2, testTaskArn3}, output)
+	require.Error(t, err)
+	missingErr, ok := err.(*MissingTasksError)
+	require.True(t, ok, "expected a MissingTasksError, got %T", err)
+	assert.Equal(t, []string{testTaskArn1, testTaskArn3}, missingErr.Arns)
+	assert.Empty(t, missingErr.Failures)
+}
+
+func TestCheckDescribeTasksCompleteNilOutput(t *testing.T) {
+	assert.NoError(t, CheckDescribeTasksComplete(nil, nil))
+
+	err := CheckDescribeTasksComplete([]string{testTaskArn1}, nil)
+	require.Error(t, err)
+	assert.Equal(t, []string{testTaskArn1}, err.(*MissingTasksError).Arns)
+}
+
+func TestTaskIsConnected(t *testing.T) {
+	testCases := []struct {
+		name      string
+		task      *Task
+		connected bool
+	}{
+		{"nil task", nil, false},
+		{"unset", &Task{}, false},
+		{"connected", &Task{Connectivity: aws.String(ConnectivityConnected)}, true},
+		{"disconnected", &Task{Connectivity: aws.String(ConnectivityDisconnected)}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.connected, tc.task.IsConnected())
+		})
+	}
+}
+
+func TestTaskConnectivityJSONRoundTrip(t *testing.T) {
+	connectivityAt := time.Date(2018, time.November, 5, 17, 30, 12, 0, time.UTC)
+	task := (&Task{}).
+		SetConnectivity(ConnectivityConnected).
+		SetConnectivityAt(connectivityAt)
+
+	body, err := jsonutil.BuildJSON(task)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"connectivity":"CONNECTED"`)
+
+	decoded := &Task{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(body)))
+	assert.Equal(t, ConnectivityConnected, aws.StringValue(decoded.Connectivity))
+	require.NotNil(t, decoded.ConnectivityAt)
+	assert.True(t, connectivityAt.Equal(*decoded.ConnectivityAt),
+		"expected %v, got %v", connectivityAt, *decoded.ConnectivityAt)
+	assert.True(t, decoded.IsConnected())
+}
+
+func TestDeploymentCircuitBreakerMessage(t *testing.T) {
+	testCases := []struct {
+		name       string
+		deployment *Deployment
+		message    string
+	}{
+		{
+			name:       "nil deployment",
+			deployment: nil,
+			message:    "rollout state not reported",
+		},
+		{
+			name:       "no rollout state",
+			deployment: &Deployment{},
+			message:    "rollout state not reported",
+		},
+		{
+			name:       "in progress",
+			deployment: &Deployment{RolloutState: aws.String(DeploymentRolloutStateInProgress)},
+			message:    "IN_PROGRESS: deployment in progress",
+		},
+		{
+			name:       "completed",
+			deployment: &Deployment{RolloutState: aws.String(DeploymentRolloutStateCompleted)},
+			message:    "COMPLETED: deployment completed",
+		},
+		{
+			name:       "failed",
+			deployment: &Deployment{RolloutState: aws.String(DeploymentRolloutStateFailed)},
+			message:    "FAILED: circuit breaker triggered rollback",
+		},
+		{
+			name: "failed with reason",
+			deployment: &Deployment{
+				RolloutState:       aws.String(DeploymentRolloutStateFailed),
+				RolloutStateReason: aws.String("tasks failed to start"),
+			},
+			message: "FAILED: circuit breaker triggered rollback (tasks failed to start)",
+		},
+		{
+			name:       "unknown state",
+			deployment: &Deployment{RolloutState: aws.String("ROLLING_BACK")},
+			message:    "ROLLING_BACK: unknown rollout state",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.message, DeploymentCircuitBreakerMessage(tc.deployment))
+		})
+	}
+}
+
+func TestServiceCreatedByUnmarshal(t *testing.T) {
+	body := `{
+  "services": [
+    {
+      "serviceArn": "arn:aws:ecs:us-west-2:123456789012:service/default/web",
+      "serviceName": "web",
+      "clusterArn": "arn:aws:ecs:us-west-2:123456789012:cluster/default",
+      "status": "ACTIVE",
+      "desiredCount": 1,
+      "runningCount": 1,
+      "pendingCount": 0,
+      "createdAt": 1541439012.0,
+      "createdBy": "arn:aws:iam::123456789012:user/foo"
+    }
+  ],
+  "failures": []
+}`
+
+	output := &DescribeServicesOutput{}
+	require.NoError(t, jsonutil.UnmarshalJSON(output, bytes.NewReader([]byte(body))))
+	require.Len(t, output.Services, 1)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/foo", aws.StringValue(output.Services[0].CreatedBy))
+}
+
+func TestServiceJSONRoundTrip(t *testing.T) {
+	body := `{
+  "serviceArn": "arn:aws:ecs:us-west-2:123456789012:service/default/web",
+  "serviceName": "web",
+  "clusterArn": "arn:aws:ecs:us-west-2:123456789012:cluster/default",
+  "loadBalancers": [
+    {
+      "targetGroupArn": "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/web/73e2d6bc24d8a067",
+      "containerName": "web",
+      "containerPort": 80
+    }
+  ],
+  "status": "ACTIVE",
+  "desiredCount": 2,
+  "runningCount": 2,
+  "pendingCount": 0,
+  "launchType": "EC2",
+  "taskDefinition": "arn:aws:ecs:us-west-2:123456789012:task-definition/web:3",
+  "deploymentConfiguration": {"maximumPercent": 200, "minimumHealthyPercent": 100},
+  "deployments": [
+    {
+      "id": "ecs-svc/9223370495415764051",
+      "status": "PRIMARY",
+      "taskDefinition": "arn:aws:ecs:us-west-2:123456789012:task-definition/web:3",
+      "desiredCount": 2,
+      "pendingCount": 0,
+      "runningCount": 2,
+      "createdAt": 1541439012.0,
+      "updatedAt": 1541439112.0,
+      "launchType": "EC2"
+    }
+  ],
+  "roleArn": "arn:aws:iam::123456789012:role/aws-service-role/ecs.amazonaws.com/AWSServiceRoleForECS",
+  "events": [],
+  "createdAt": 1541439012.0,
+  "placementConstraints": [],
+  "placementStrategy": [{"type": "spread", "field": "attribute:ecs.availability-zone"}],
+  "healthCheckGracePeriodSeconds": 60,
+  "schedulingStrategy": "REPLICA",
+  "networkConfiguration": {
+    "awsvpcConfiguration": {
+      "subnets": ["subnet-12344321"],
+      "securityGroups": ["sg-12344321"],
+      "assignPublicIp": "DISABLED"
+    }
+  },
+  "createdBy": "arn:aws:iam::123456789012:user/foo"
+}`
+
+	service := &Service{}
+	require.NoError(t, jsonutil.UnmarshalJSON(service, bytes.NewReader([]byte(body))))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/aws-service-role/ecs.amazonaws.com/AWSServiceRoleForECS",
+		aws.StringValue(service.RoleArn))
+
+	assert.Equal(t, &NetworkConfiguration{
+		AwsvpcConfiguration: &AwsVpcConfiguration{
+			Subnets:        aws.StringSlice([]string{"subnet-12344321"}),
+			SecurityGroups: aws.StringSlice([]string{"sg-12344321"}),
+			AssignPublicIp: aws.String(AssignPublicIpDisabled),
+		},
+	}, service.NetworkConfiguration)
+
+	encoded, err := jsonutil.BuildJSON(service)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"roleArn":"arn:aws:iam::123456789012:role/aws-service-role/ecs.amazonaws.com/AWSServiceRoleForECS"`)
+
+	decoded := &Service{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(encoded)))
+	assert.Equal(t, service, decoded)
+}
+
+func TestServiceTaskSetsJSONRoundTrip(t *testing.T) {
+	body := `{
+  "serviceName": "web",
+  "taskSets": [
+    {
+      "id": "ecs-svc/1234567890123456789",
+      "taskSetArn": "arn:aws:ecs:us-west-2:123456789012:task-set/default/web/ecs-svc/1234567890123456789",
+      "startedBy": "controller",
+      "externalId": "blue",
+      "status": "PRIMARY",
+      "taskDefinition": "arn:aws:ecs:us-west-2:123456789012:task-definition/web:4",
+      "computedDesiredCount": 4,
+      "pendingCount": 1,
+      "runningCount": 3,
+      "createdAt": 1541439012.0,
+      "updatedAt": 1541439112.0,
+      "launchType": "FARGATE",
+      "networkConfiguration": {
+        "awsvpcConfiguration": {"subnets": ["subnet-12344321"], "assignPublicIp": "DISABLED"}
+      },
+      "loadBalancers": [
+        {
+          "targetGroupArn": "arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/blue/73e2d6bc24d8a067",
+          "containerName": "web",
+          "containerPort": 80
+        }
+      ],
+      "scale": {"value": 50.0, "unit": "PERCENT"}
+    }
+  ]
+}`
+
+	service := &Service{}
+	require.NoError(t, jsonutil.UnmarshalJSON(service, bytes.NewReader([]byte(body))))
+	require.Len(t, service.TaskSets, 1)
+	taskSet := service.TaskSets[0]
+	assert.Equal(t, "blue", aws.StringValue(taskSet.ExternalId))
+	assert.Equal(t, "controller", aws.StringValue(taskSet.StartedBy))
+	assert.Equal(t, int64(4), aws.Int64Value(taskSet.ComputedDesiredCount))
+	assert.Equal(t, int64(3), aws.Int64Value(taskSet.RunningCount))
+	assert.Equal(t, LaunchTypeFargate, aws.StringValue(taskSet.LaunchType))
+	assert.Equal(t, int64(1541439112), aws.TimeValue(taskSet.UpdatedAt).Unix())
+	assert.Equal(t, &Scale{Value: aws.Float64(50), Unit: aws.String(ScaleUnitPercent)}, taskSet.Scale)
+	require.Len(t, taskSet.LoadBalancers, 1)
+	assert.Equal(t, "web", aws.StringValue(taskSet.LoadBalancers[0].ContainerName))
+
+	encoded, err := jsonutil.BuildJSON(service)
+	require.NoError(t, err)
+	decoded := &Service{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(encoded)))
+	assert.Equal(t, service, decoded)
+
+	assert.Equal(t, service.TaskSets, (&Service{}).SetTaskSets(service.TaskSets).TaskSets)
+}
+
+func TestContainerInstanceHealthStatusJSONRoundTrip(t *testing.T) {
+	body := `{
+  "containerInstanceArn": "arn:aws:ecs:us-west-2:123456789012:container-instance/default/f2756532-8f13-4d53-87c9-aed50dc94cd7",
+  "status": "ACTIVE",
+  "healthStatus": {
+    "overallStatus": "IMPAIRED",
+    "details": [
+      {
+        "type": "CONTAINER_RUNTIME",
+        "status": "OK",
+        "lastUpdated": 1700000100.0,
+        "lastStatusChange": 1700000000.0
+      },
+      {
+        "type": "CONTAINER_RUNTIME",
+        "status": "IMPAIRED",
+        "lastUpdated": 1700000200.0,
+        "lastStatusChange": 1700000150.0
+      }
+    ]
+  }
+}`
+
+	instance := &ContainerInstance{}
+	require.NoError(t, jsonutil.UnmarshalJSON(instance, bytes.NewReader([]byte(body))))
+	require.NotNil(t, instance.HealthStatus)
+	assert.Equal(t, InstanceHealthCheckStateImpaired, aws.StringValue(instance.HealthStatus.OverallStatus))
+	require.Len(t, instance.HealthStatus.Details, 2)
+	passing, failing := instance.HealthStatus.Details[0], instance.HealthStatus.Details[1]
+	assert.Equal(t, InstanceHealthCheckTypeContainerRuntime, aws.StringValue(passing.Type))
+	assert.Equal(t, InstanceHealthCheckStateOk, aws.StringValue(passing.Status))
+	assert.Equal(t, int64(1700000000), aws.TimeValue(passing.LastStatusChange).Unix())
+	assert.Equal(t, int64(1700000100), aws.TimeValue(passing.LastUpdated).Unix())
+	assert.Equal(t, InstanceHealthCheckStateImpaired, aws.StringValue(failing.Status))
+	assert.Equal(t, int64(1700000150), aws.TimeValue(failing.LastStatusChange).Unix())
+
+	encoded, err := jsonutil.BuildJSON(instance)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"overallStatus":"IMPAIRED"`)
+
+	decoded := &ContainerInstance{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(encoded)))
+	assert.Equal(t, instance, decoded)
+}
+
+func TestNormalizeCapacityProviderStrategy(t *testing.T) {
+	configured := []*CapacityProviderStrategyItem{
+		{CapacityProvider: aws.String("FARGATE_SPOT"), Weight: aws.Int64(3)},
+		{CapacityProvider: aws.String("FARGATE"), Base: aws.Int64(1), Weight: aws.Int64(1)},
+	}
+	body := `{
+  "serviceName": "web",
+  "capacityProviderStrategy": [
+    {"capacityProvider": "FARGATE", "weight": 1, "base": 1},
+    {"capacityProvider": "FARGATE_SPOT", "weight": 3, "base": 0}
+  ]
+}`
+	described := &Service{}
+	require.NoError(t, jsonutil.UnmarshalJSON(described, bytes.NewReader([]byte(body))))
+	require.Len(t, described.CapacityProviderStrategy, 2)
+
+	assert.NotEqual(t, configured, described.CapacityProviderStrategy)
+	assert.Equal(t, NormalizeCapacityProviderStrategy(configured),
+		NormalizeCapacityProviderStrategy(described.CapacityProviderStrategy))
+
+	// The configuration is left untouched
+	assert.Nil(t, configured[0].Base)
+	assert.Equal(t, "FARGATE_SPOT", aws.StringValue(configured[0].CapacityProvider))
+}
+
+func TestNormalizeCapacityProviderStrategyDiffers(t *testing.T) {
+	configured := []*CapacityProviderStrategyItem{
+		{CapacityProvider: aws.String("FARGATE"), Weight: aws.Int64(1)},
+	}
+	described := []*CapacityProviderStrategyItem{
+		{CapacityProvider: aws.String("FARGATE"), Weight: aws.Int64(2), Base: aws.Int64(0)},
+	}
+	assert.NotEqual(t, NormalizeCapacityProviderStrategy(configured), NormalizeCapacityProviderStrategy(described))
+	assert.Nil(t, NormalizeCapacityProviderStrategy(nil))
+	assert.Nil(t, NormalizeCapacityProviderStrategy([]*CapacityProviderStrategyItem{}))
+}
+
+func TestTaskDefinitionRequiredAttributeMap(t *testing.T) {
+	testCases := []struct {
+		name       string
+		attributes []*Attribute
+		expected   map[string]string
+	}{
+		{"nil slice", nil, map[string]string{}},
+		{"empty slice", []*Attribute{}, map[string]string{}},
+		{
+			name: "with values",
+			attributes: []*Attribute{
+				{Name: aws.String("com.amazonaws.ecs.capability.docker-remote-api.1.17")},
+				{Name: aws.String("ecs.os-type"), Value: aws.String("linux")},
+			},
+			expected: map[string]string{
+				"com.amazonaws.ecs.capability.docker-remote-api.1.17": "",
+				"ecs.os-type": "linux",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			taskDefinition := &TaskDefinition{RequiresAttributes: tc.attributes}
+			assert.Equal(t, tc.expected, taskDefinition.RequiredAttributeMap())
+		})
+	}
+}
+
+func TestParseTaskDefinitionArn(t *testing.T) {
+	testCases := []struct {
+		name     string
+		arn      string
+		family   string
+		revision int64
+	}{
+		{
+			name:     "with revision",
+			arn:      "arn:aws:ecs:us-west-2:123456789012:task-definition/web-app:12",
+			family:   "web-app",
+			revision: 12,
+		},
+		{
+			name:     "without revision",
+			arn:      "arn:aws:ecs:us-west-2:123456789012:task-definition/web-app",
+			family:   "web-app",
+			revision: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			summary := parseTaskDefinitionArn(tc.arn)
+			assert.Equal(t, tc.arn, summary.Arn)
+			assert.Equal(t, tc.family, summary.Family)
+			assert.Equal(t, tc.revision, summary.Revision)
+		})
+	}
+}
+
+func TestLoadBalancerArnJSONRoundTrip(t *testing.T) {
+	loadBalancerArn := "arn:aws:elasticloadbalancing:us-west-2:123456789012:loadbalancer/app/web/50dc6c495c0c9188"
+	service := (&Service{}).SetLoadBalancers([]*LoadBalancer{
+		(&LoadBalancer{}).
+			SetLoadBalancerArn(loadBalancerArn).
+			SetTargetGroupArn("arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/web/73e2d6bc24d8a067").
+			SetContainerName("web").
+			SetContainerPort(80),
+	})
+
+	body, err := jsonutil.BuildJSON(service)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"loadBalancerArn":"`+loadBalancerArn+`"`)
+
+	decoded := &Service{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(body)))
+	assert.Equal(t, service, decoded)
+}
+
+func TestStartTaskInputTagsJSON(t *testing.T) {
+	input := (&StartTaskInput{}).
+		SetTaskDefinition("web:3").
+		SetContainerInstances(aws.StringSlice([]string{"instance1", "instance2"})).
+		SetTags([]*Tag{(&Tag{}).SetKey("team").SetValue("web")})
+	require.NoError(t, input.Validate())
+
+	body, err := jsonutil.BuildJSON(input)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"containerInstances":["instance1","instance2"]`)
+	assert.Contains(t, string(body), `"tags":[{"key":"team","value":"web"}]`)
+}
+
+func TestCreateServiceInputEnableECSManagedTagsJSON(t *testing.T) {
+	input := (&CreateServiceInput{}).
+		SetServiceName("web").
+		SetTaskDefinition("web:3")
+	body, err := jsonutil.BuildJSON(input)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "enableECSManagedTags", "unset fields should be left out")
+
+	input.SetEnableECSManagedTags(true)
+	require.NoError(t, input.Validate())
+	body, err = jsonutil.BuildJSON(input)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"enableECSManagedTags":true`)
+
+	service := &Service{}
+	require.NoError(t, jsonutil.UnmarshalJSON(service, bytes.NewReader([]byte(`{"enableECSManagedTags":true}`))))
+	assert.Equal(t, (&Service{}).SetEnableECSManagedTags(true), service)
+}
+
+func TestStartTaskInputValidateTagLimit(t *testing.T) {
+	input := (&StartTaskInput{}).
+		SetTaskDefinition("web:3").
+		SetContainerInstances(aws.StringSlice([]string{"instance1"}))
+	tags := make([]*Tag, 50)
+	for i := range tags {
+		tags[i] = (&Tag{}).SetKey(fmt.Sprintf("key%d", i))
+	}
+	assert.NoError(t, ValidateParams(input.SetTags(tags)))
+
+	err := ValidateParams(input.SetTags(append(tags, (&Tag{}).SetKey("key50"))))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "StartTaskInput.Tags")
+}
+
+func TestDeploymentServiceConnectJSONRoundTrip(t *testing.T) {
+	deployment := (&Deployment{}).
+		SetId("ecs-svc/1234567890").
+		SetServiceConnectConfiguration((&ServiceConnectConfiguration{}).
+			SetEnabled(true).
+			SetNamespace("internal").
+			SetServices([]*ServiceConnectService{
+				(&ServiceConnectService{}).
+					SetPortName("http").
+					SetDiscoveryName("web").
+					SetClientAliases([]*ServiceConnectClientAlias{
+						(&ServiceConnectClientAlias{}).SetPort(80).SetDnsName("web.internal"),
+					}),
+			})).
+		SetServiceConnectResources([]*ServiceConnectServiceResource{
+			(&ServiceConnectServiceResource{}).
+				SetDiscoveryName("web").
+				SetProviderArn("arn:aws:servicediscovery:us-west-2:123456789012:service/srv-utcrh6wavdkggqtk"),
+		})
+
+	body, err := jsonutil.BuildJSON(deployment)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"serviceConnectConfiguration":{`)
+	assert.Contains(t, string(body), `"clientAliases":[{"dnsName":"web.internal","port":80}]`)
+	assert.Contains(t, string(body), `"serviceConnectResources":[{`)
+
+	decoded := &Deployment{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(body)))
+	assert.Equal(t, deployment, decoded)
+}
+
+func testExportedTaskDefinition() *TaskDefinition {
+	return &TaskDefinition{
+		TaskDefinitionArn: aws.String("arn:aws:ecs:us-west-2:123456789012:task-definition/web:3"),
+		Family:            aws.String("web"),
+		Revision:          aws.Int64(3),
+		Status:            aws.String(TaskDefinitionStatusActive),
+		NetworkMode:       aws.String(NetworkModeBridge),
+		Compatibilities:   aws.StringSlice([]string{CompatibilityEc2}),
+		RequiresAttributes: []*Attribute{
+			{Name: aws.String("com.amazonaws.ecs.capability.docker-remote-api.1.17")},
+		},
+		ContainerDefinitions: []*ContainerDefinition{{
+			Name:      aws.String("web"),
+			Image:     aws.String("nginx:1.15"),
+			Memory:    aws.Int64(256),
+			Essential: aws.Bool(true),
+			Command:   aws.StringSlice([]string{"nginx", "-g", "daemon off;"}),
+		}},
+	}
+}
+
+func TestNewRepositoryCredentials(t *testing.T) {
+	testCases := []struct {
+		name      string
+		secretArn string
+		valid     bool
+	}{
+		{"secrets manager", "arn:aws:secretsmanager:us-west-2:123456789012:secret:registry-auth-I0nqxs", true},
+		{"secrets manager in another partition", "arn:aws-cn:secretsmanager:cn-north-1:123456789012:secret:registry-auth", true},
+		{"ssm parameter", "arn:aws:ssm:us-west-2:123456789012:parameter/registry-auth", false},
+		{"secrets manager without a secret", "arn:aws:secretsmanager:us-west-2:123456789012:registry-auth", false},
+		{"secret name", "registry-auth", false},
+		{"empty", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			credentials, err := NewRepositoryCredentials(tc.secretArn)
+			if !tc.valid {
+				assert.Error(t, err)
+				assert.Nil(t, credentials)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.secretArn, aws.StringValue(credentials.CredentialsParameter))
+			assert.NoError(t, credentials.Validate())
+		})
+	}
+}
+
+func TestExportTaskDefinition(t *testing.T) {
+	taskDefinition := testExportedTaskDefinition()
+
+	input, err := ExportTaskDefinition(taskDefinition)
+	require.NoError(t, err)
+	assert.NoError(t, input.Validate())
+	assert.Equal(t, "web", aws.StringValue(input.Family))
+	assert.Equal(t, NetworkModeBridge, aws.StringValue(input.NetworkMode))
+	require.Len(t, input.ContainerDefinitions, 1)
+	assert.Equal(t, taskDefinition.ContainerDefinitions[0], input.ContainerDefinitions[0])
+	assert.Nil(t, input.InferenceAccelerators)
+
+	// The input does not share memory with the task definition
+	input.ContainerDefinitions[0].Image = aws.String("nginx:latest")
+	assert.Equal(t, "nginx:1.15", aws.StringValue(taskDefinition.ContainerDefinitions[0].Image))
+}
+
+func TestExportTaskDefinitionInferenceAccelerators(t *testing.T) {
+	taskDefinition := testExportedTaskDefinition()
+	taskDefinition.InferenceAccelerators = []*InferenceAccelerator{{
+		DeviceName: aws.String("device_1"),
+		DeviceType: aws.String("eia1.medium"),
+	}}
+
+	input, err := ExportTaskDefinition(taskDefinition)
+	require.NoError(t, err)
+	assert.NoError(t, input.Validate())
+	assert.Equal(t, taskDefinition.InferenceAccelerators, input.InferenceAccelerators)
+}
+
+func TestExportTaskDefinitionInvalid(t *testing.T) {
+	_, err := ExportTaskDefinition(nil)
+	assert.Error(t, err)
+	_, err = ExportTaskDefinition(&TaskDefinition{})
+	assert.Error(t, err)
+}
+
+func TestRegisterTaskDefinitionInputToJSON(t *testing.T) {
+	input, err := ExportTaskDefinition(testExportedTaskDefinition())
+	require.NoError(t, err)
+
+	body, err := input.ToJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "containerDefinitions": [
+    {
+      "command": [
+        "nginx",
+        "-g",
+        "daemon off;"
+      ],
+      "essential": true,
+      "image": "nginx:1.15",
+      "memory": 256,
+      "name": "web"
+    }
+  ],
+  "family": "web",
+  "networkMode": "bridge"
+}
+`, string(body))
+	assert.NotContains(t, string(body), "requiresAttributes")
+	assert.NotContains(t, string(body), "compatibilities")
+	assert.NotContains(t, string(body), "revision")
+
+	decoded := &RegisterTaskDefinitionInput{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(body)))
+	assert.Equal(t, input, decoded)
+}
+
+func TestRegisterTaskDefinitionInputToYAML(t *testing.T) {
+	input, err := ExportTaskDefinition(testExportedTaskDefinition())
+	require.NoError(t, err)
+
+	body, err := input.ToYAML()
+	require.NoError(t, err)
+	assert.Equal(t, `containerDefinitions:
+  - command:
+      - nginx
+      - "-g"
+      - "daemon off;"
+    essential: true
+    image: "nginx:1.15"
+    memory: 256
+    name: web
+family: web
+networkMode: bridge
+`, string(body))
+}
+
+func TestParseTaskDefinitionFromCLIJSONMinimal(t *testing.T) {
+	input, err := ParseTaskDefinitionFromCLIJSON([]byte(`{
+  "family": "web",
+  "containerDefinitions": [{"name": "web", "image": "nginx:1.15", "memory": 256}]
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, &RegisterTaskDefinitionInput{
+		Family: aws.String("web"),
+		ContainerDefinitions: []*ContainerDefinition{
+			{Name: aws.String("web"), Image: aws.String("nginx:1.15"), Memory: aws.Int64(256)},
+		},
+	}, input)
+	assert.NoError(t, input.Validate())
+}
+
+func TestParseTaskDefinitionFromCLIJSONFull(t *testing.T) {
+	input, err := ParseTaskDefinitionFromCLIJSON([]byte(`{
+  "family": "api",
+  "taskRoleArn": "arn:aws:iam::123456789012:role/api",
+  "executionRoleArn": "arn:aws:iam::123456789012:role/ecsTaskExecutionRole",
+  "networkMode": "awsvpc",
+  "requiresCompatibilities": ["FARGATE"],
+  "cpu": "512",
+  "memory": "1024",
+  "volumes": [{"name": "scratch"}],
+  "tags": [{"key": "team", "value": "payments"}],
+  "containerDefinitions": [
+    {
+      "name": "api",
+      "image": "123456789012.dkr.ecr.us-west-2.amazonaws.com/api:latest",
+      "essential": true,
+      "portMappings": [{"containerPort": 8080, "protocol": "tcp"}],
+      "environment": [{"name": "STAGE", "value": "prod"}],
+      "secrets": [{"name": "DB_PASSWORD", "valueFrom": "arn:aws:ssm:us-west-2:123456789012:parameter/db"}],
+      "mountPoints": [{"sourceVolume": "scratch", "containerPath": "/scratch"}],
+      "logConfiguration": {
+        "logDriver": "awslogs",
+        "options": {"awslogs-group": "/ecs/api", "awslogs-region": "us-west-2"}
+      },
+      "healthCheck": {"command": ["CMD-SHELL", "curl -f http://localhost:8080/"], "interval": 30}
+    },
+    {"name": "sidecar", "image": "envoy", "essential": false}
+  ]
+}`))
+	require.NoError(t, err)
+	require.NoError(t, input.Validate())
+
+	assert.Equal(t, "api", aws.StringValue(input.Family))
+	assert.Equal(t, NetworkModeAwsvpc, aws.StringValue(input.NetworkMode))
+	assert.Equal(t, []string{CompatibilityFargate}, aws.StringValueSlice(input.RequiresCompatibilities))
+	assert.Equal(t, "512", aws.StringValue(input.Cpu))
+	assert.Equal(t, "1024", aws.StringValue(input.Memory))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/api", aws.StringValue(input.TaskRoleArn))
+	require.Len(t, input.Volumes, 1)
+	require.Len(t, input.Tags, 1)
+	assert.Equal(t, "payments", aws.StringValue(input.Tags[0].Value))
+
+	require.Len(t, input.ContainerDefinitions, 2)
+	api := input.ContainerDefinitions[0]
+	assert.True(t, aws.BoolValue(api.Essential))
+	require.Len(t, api.PortMappings, 1)
+	assert.Equal(t, int64(8080), aws.Int64Value(api.PortMappings[0].ContainerPort))
+	assert.Equal(t, TransportProtocolTcp, aws.StringValue(api.PortMappings[0].Protocol))
+	require.Len(t, api.Environment, 1)
+	assert.Equal(t, "prod", aws.StringValue(api.Environment[0].Value))
+	require.Len(t, api.Secrets, 1)
+	assert.Equal(t, "DB_PASSWORD", aws.StringValue(api.Secrets[0].Name))
+	require.Len(t, api.MountPoints, 1)
+	assert.Equal(t, LogDriverAwslogs, aws.StringValue(api.LogConfiguration.LogDriver))
+	assert.Equal(t, "/ecs/api", aws.StringValue(api.LogConfiguration.Options["awslogs-group"]))
+	assert.Equal(t, int64(30), aws.Int64Value(api.HealthCheck.Interval))
+	assert.False(t, aws.BoolValue(input.ContainerDefinitions[1].Essential))
+}
+
+func TestParseTaskDefinitionFromCLIJSONDescribeOutput(t *testing.T) {
+	input, err := ParseTaskDefinitionFromCLIJSON([]byte(`{
+  "taskDefinition": {
+    "taskDefinitionArn": "arn:aws:ecs:us-west-2:123456789012:task-definition/web:3",
+    "family": "web",
+    "revision": 3,
+    "status": "ACTIVE",
+    "containerDefinitions": [{"name": "web", "image": "nginx:1.15", "memory": 256}]
+  }
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, "web", aws.StringValue(input.Family))
+	require.Len(t, input.ContainerDefinitions, 1)
+	assert.Equal(t, "nginx:1.15", aws.StringValue(input.ContainerDefinitions[0].Image))
+}
+
+func TestParseTaskDefinitionFromCLIJSONRoundTrip(t *testing.T) {
+	input, err := ExportTaskDefinition(testExportedTaskDefinition())
+	require.NoError(t, err)
+	body, err := input.ToJSON()
+	require.NoError(t, err)
+
+	parsed, err := ParseTaskDefinitionFromCLIJSON(body)
+	require.NoError(t, err)
+	assert.Equal(t, input, parsed)
+}
+
+func TestParseTaskDefinitionFromCLIJSONInvalid(t *testing.T) {
+	for _, data := range []string{``, `[]`, `{"family": "web"}`, `{"containerDefinitions": {"name": "web"}}`} {
+		_, err := ParseTaskDefinitionFromCLIJSON([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestTaskDefinitionFitsInstance(t *testing.T) {
+	taskDefinition := &TaskDefinition{
+		ContainerDefinitions: []*ContainerDefinition{
+			{Name: aws.String("web"), Cpu: aws.Int64(512), Memory: aws.Int64(1024)},
+			{Name: aws.String("sidecar"), Cpu: aws.Int64(512), MemoryReservation: aws.Int64(1024)},
+		},
+	}
+	testCases := []struct {
+		name      string
+		cpuUnits  int64
+		memoryMib int64
+		fits      bool
+		overflows []string
+	}{
+		{"exact fit", 1024, 2048, true, nil},
+		{"under fit", 2048, 4096, true, nil},
+		{"cpu over fit", 1023, 2048, false, []string{"cpu: containers require 1024 units, instance provides 1023"}},
+		{
+			name:      "cpu and memory over fit",
+			cpuUnits:  512,
+			memoryMib: 1024,
+			fits:      false,
+			overflows: []string{
+				"cpu: containers require 1024 units, instance provides 512",
+				"memory: containers require 2048 MiB, instance provides 1024",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fits, overflows := taskDefinition.FitsInstance(tc.cpuUnits, tc.memoryMib)
+			assert.Equal(t, tc.fits, fits)
+			assert.Equal(t, tc.overflows, overflows)
+		})
+	}
+}
+
+func TestRunTaskBatchErrorMessage(t *testing.T) {
+	err := &RunTaskBatchError{Errors: []error{fmt.Errorf("throttled"), fmt.Errorf("server error")}}
+	assert.Equal(t, "run task batch: 2 RunTask calls failed: throttled; server error", err.Error())
+}
+
+func TestTaskDefinitionAge(t *testing.T) {
+	assert.Equal(t, time.Duration(0), (*TaskDefinition)(nil).Age())
+	assert.Equal(t, time.Duration(0), (&TaskDefinition{}).Age())
+
+	taskDefinition := (&TaskDefinition{}).SetRegisteredAt(time.Now().Add(-time.Hour))
+	age := taskDefinition.Age()
+	assert.True(t, age >= time.Hour && age < time.Hour+time.Minute, "unexpected age %v", age)
+}
+
+func TestServiceAge(t *testing.T) {
+	assert.Equal(t, time.Duration(0), (*Service)(nil).Age())
+	assert.Equal(t, time.Duration(0), (&Service{}).Age())
+
+	service := (&Service{}).SetCreatedAt(time.Now().Add(-24 * time.Hour))
+	age := service.Age()
+	assert.True(t, age >= 24*time.Hour && age < 24*time.Hour+time.Minute, "unexpected age %v", age)
+}
+
+func TestTruncateEvents(t *testing.T) {
+	now := time.Now()
+	event := func(id string, age time.Duration) *ServiceEvent {
+		return (&ServiceEvent{}).SetId(id).SetCreatedAt(now.Add(-age))
+	}
+	events := []*ServiceEvent{
+		event("new", time.Minute),
+		event("hour", time.Hour-time.Minute),
+		nil,
+		(&ServiceEvent{}).SetId("unknown"),
+		event("old", time.Hour+time.Minute),
+		event("older", 24*time.Hour),
+	}
+
+	var ids []string
+	for _, event := range TruncateEvents(events, time.Hour) {
+		ids = append(ids, aws.StringValue(event.Id))
+	}
+	assert.Equal(t, []string{"new", "hour", "unknown"}, ids)
+	assert.Empty(t, TruncateEvents(events[4:], time.Hour))
+	assert.Len(t, TruncateEvents(events, 48*time.Hour), 5)
+	assert.Empty(t, TruncateEvents(nil, time.Hour))
+}
+
+func testComparedService() *Service {
+	return &Service{
+		ServiceName:    aws.String("web"),
+		TaskDefinition: aws.String("arn:aws:ecs:us-west-2:123456789012:task-definition/web:1"),
+		DesiredCount:   aws.Int64(2),
+		DeploymentConfiguration: &DeploymentConfiguration{
+			MaximumPercent:        aws.Int64(200),
+			MinimumHealthyPercent: aws.Int64(100),
+		},
+		LoadBalancers: []*LoadBalancer{{
+			TargetGroupArn: aws.String("arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/web/1"),
+			ContainerName:  aws.String("web"),
+			ContainerPort:  aws.Int64(80),
+		}},
+		PlacementStrategy: []*PlacementStrategy{{
+			Type:  aws.String(PlacementStrategyTypeSpread),
+			Field: aws.String("attribute:ecs.availability-zone"),
+		}},
+		RunningCount: aws.Int64(2),
+	}
+}
+
+func TestCompareServicesNoChange(t *testing.T) {
+	old := testComparedService()
+	new := testComparedService()
+	new.RunningCount = aws.Int64(1)
+	assert.Empty(t, CompareServices(old, new), "fields a deployment does not change should be ignored")
+	assert.Empty(t, CompareServices(nil, &Service{LoadBalancers: []*LoadBalancer{}}))
+}
+
+func TestCompareServicesSingleField(t *testing.T) {
+	old := testComparedService()
+	new := testComparedService()
+	new.TaskDefinition = aws.String("arn:aws:ecs:us-west-2:123456789012:task-definition/web:2")
+
+	assert.Equal(t, []ServiceFieldChange{{
+		Field:    "TaskDefinition",
+		OldValue: "arn:aws:ecs:us-west-2:123456789012:task-definition/web:1",
+		NewValue: "arn:aws:ecs:us-west-2:123456789012:task-definition/web:2",
+	}}, CompareServices(old, new))
+}
+
+func TestCompareServicesMultipleFields(t *testing.T) {
+	old := testComparedService()
+	new := testComparedService()
+	new.DesiredCount = aws.Int64(4)
+	new.DeploymentConfiguration.MinimumHealthyPercent = aws.Int64(50)
+	new.LoadBalancers = nil
+	new.NetworkConfiguration = &NetworkConfiguration{
+		AwsvpcConfiguration: &AwsVpcConfiguration{Subnets: aws.StringSlice([]string{"subnet-1"})},
+	}
+	new.PlacementStrategy[0].Type = aws.String(PlacementStrategyTypeBinpack)
+	new.PlacementStrategy[0].Field = aws.String("memory")
+
+	assert.Equal(t, []ServiceFieldChange{
+		{Field: "DesiredCount", OldValue: "2", NewValue: "4"},
+		{
+			Field:    "DeploymentConfiguration",
+			OldValue: `{"maximumPercent":200,"minimumHealthyPercent":100}`,
+			NewValue: `{"maximumPercent":200,"minimumHealthyPercent":50}`,
+		},
+		{
+			Field:    "LoadBalancers",
+			OldValue: `[{"containerName":"web","containerPort":80,"targetGroupArn":"arn:aws:elasticloadbalancing:us-west-2:123456789012:targetgroup/web/1"}]`,
+			NewValue: "",
+		},
+		{
+			Field:    "NetworkConfiguration",
+			OldValue: "",
+			NewValue: `{"awsvpcConfiguration":{"subnets":["subnet-1"]}}`,
+		},
+		{
+			Field:    "PlacementStrategy",
+			OldValue: `[{"field":"attribute:ecs.availability-zone","type":"spread"}]`,
+			NewValue: `[{"field":"memory","type":"binpack"}]`,
+		},
+	}, CompareServices(old, new))
+}
+
+func TestTaskDefinitionRegisteredByJSONRoundTrip(t *testing.T) {
+	taskDefinition := (&TaskDefinition{}).
+		SetFamily("web").
+		SetRevision(4).
+		SetRegisteredBy("arn:aws:iam::123456789012:role/deployer")
+
+	body, err := jsonutil.BuildJSON(taskDefinition)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"registeredBy":"arn:aws:iam::123456789012:role/deployer"`)
+
+	decoded := &TaskDefinition{}
+	require.NoError(t, jsonutil.UnmarshalJSON(decoded, bytes.NewReader(body)))
+	assert.Equal(t, "web", aws.StringValue(decoded.Family))
+	assert.Equal(t, int64(4), aws.Int64Value(decoded.Revision))
+	assert.Equal(t, "arn:aws:iam::123456789012:role/deployer", aws.StringValue(decoded.RegisteredBy))
+}
+
+func TestServiceIsStable(t *testing.T) {
+	deployment := func(desired, running, pending int64) *Deployment {
+		return &Deployment{
+			DesiredCount: aws.Int64(desired),
+			RunningCount: aws.Int64(running),
+			PendingCount: aws.Int64(pending),
+		}
+	}
+	testCases := []struct {
+		name    string
+		service *Service
+		stable  bool
+	}{
+		{"nil service", nil, false},
+		{"no deployment", &Service{}, false},
+		{"stable", &Service{Deployments: []*Deployment{deployment(3, 3, 0)}}, true},
+		{"scaled to zero", &Service{Deployments: []*Deployment{deployment(0, 0, 0)}}, true},
+		{"in progress", &Service{Deployments: []*Deployment{deployment(3, 1, 2), deployment(3, 2, 0)}}, false},
+		{"tasks pending", &Service{Deployments: []*Deployment{deployment(3, 1, 2)}}, false},
+		{"degraded", &Service{Deployments: []*Deployment{deployment(3, 2, 0)}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.stable, tc.service.IsStable())
+		})
+	}
+}
+
+func TestSelectDrainCandidates(t *testing.T) {
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	instance := func(arn, status string, running int64, registeredHoursAgo int) *ContainerInstance {
+		return &ContainerInstance{
+			ContainerInstanceArn: aws.String(arn),
+			Status:               aws.String(status),
+			RunningTasksCount:    aws.Int64(running),
+			RegisteredAt:         aws.Time(epoch.Add(-time.Duration(registeredHoursAgo) * time.Hour)),
+		}
+	}
+	arns := func(instances []*ContainerInstance) []string {
+		var selected []string
+		for _, instance := range instances {
+			selected = append(selected, aws.StringValue(instance.ContainerInstanceArn))
+		}
+		return selected
+	}
+	instances := []*ContainerInstance{
+		instance("busy", ContainerInstanceStatusActive, 5, 10),
+		instance("draining", ContainerInstanceStatusDraining, 0, 10),
+		instance("idle-new", ContainerInstanceStatusActive, 0, 1),
+		instance("idle-old", ContainerInstanceStatusActive, 0, 5),
+		instance("light", ContainerInstanceStatusActive, 1, 1),
+	}
+
+	testCases := []struct {
+		name      string
+		instances []*ContainerInstance
+		n         int
+		expected  []string
+	}{
+		{"empty", nil, 2, nil},
+		{"none requested", instances, 0, nil},
+		{"fewest tasks first", instances, 3, []string{"idle-old", "idle-new", "light"}},
+		{"fewer than n", instances, 10, []string{"idle-old", "idle-new", "light", "busy"}},
+		{"equal task counts", []*ContainerInstance{
+			instance("b", ContainerInstanceStatusActive, 2, 2),
+			instance("c", ContainerInstanceStatusActive, 2, 1),
+			instance("a", ContainerInstanceStatusActive, 2, 3),
+		}, 2, []string{"a", "b"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, arns(SelectDrainCandidates(tc.instances, tc.n)))
+		})
+	}
+}
+
+func TestValidatePlacementStrategies(t *testing.T) {
+	testCases := []struct {
+		strategyType string
+		field        *string
+		valid        bool
+	}{
+		{PlacementStrategyTypeBinpack, aws.String("cpu"), true},
+		{PlacementStrategyTypeBinpack, aws.String("memory"), true},
+		{PlacementStrategyTypeSpread, aws.String("instanceId"), true},
+		{PlacementStrategyTypeSpread, aws.String("host"), true},
+		{PlacementStrategyTypeSpread, aws.String("attribute:ecs.availability-zone"), true},
+		{PlacementStrategyTypeRandom, nil, true},
+		{PlacementStrategyTypeBinpack, nil, false},
+		{PlacementStrategyTypeBinpack, aws.String("instanceId"), false},
+		{PlacementStrategyTypeBinpack, aws.String("attribute:ecs.availability-zone"), false},
+		{PlacementStrategyTypeSpread, nil, false},
+		{PlacementStrategyTypeSpread, aws.String("cpu"), false},
+		{PlacementStrategyTypeSpread, aws.String("attribute:"), false},
+		{PlacementStrategyTypeRandom, aws.String("cpu"), false},
+		{"", aws.String("cpu"), false},
+		{"pack", aws.String("cpu"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s", tc.strategyType, aws.StringValue(tc.field)), func(t *testing.T) {
+			strategy := &PlacementStrategy{Field: tc.field}
+			if tc.strategyType != "" {
+				strategy.Type = aws.String(tc.strategyType)
+			}
+			err := ValidatePlacementStrategies([]*PlacementStrategy{strategy})
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			strategiesErr, ok := err.(*InvalidPlacementStrategiesError)
+			require.True(t, ok)
+			require.Len(t, strategiesErr.Problems, 1)
+			assert.Contains(t, strategiesErr.Problems[0], "[0] ")
+		})
+	}
+}
+
+func TestValidatePlacementStrategiesReportsEach(t *testing.T) {
+	assert.NoError(t, ValidatePlacementStrategies(nil))
+
+	err := ValidatePlacementStrategies([]*PlacementStrategy{
+		{Type: aws.String(PlacementStrategyTypeSpread), Field: aws.String("attribute:ecs.availability-zone")},
+		{Type: aws.String(PlacementStrategyTypeBinpack), Field: aws.String("instanceId")},
+		nil,
+		{Type: aws.String(PlacementStrategyTypeSpread), Field: aws.String("memory")},
+	})
+	require.Error(t, err)
+	assert.Equal(t, &InvalidPlacementStrategiesError{Problems: []string{
+		`[1] binpack field must be cpu or memory, not "instanceId"`,
+		"[2] no placement strategy",
+		`[3] spread field must be instanceId, host or attribute:<name>, not "memory"`,
+	}}, err)
+	assert.Contains(t, err.Error(), "invalid placement strategies: [1] binpack")
+}
+
+// fakeCloudWatch answers GetMetricData with the latest datapoints of the
+// requested metrics, keyed by metric name, and records the input
+type fakeCloudWatch struct {
+	datapoints map[string][]float64
+	input      *cloudwatch.GetMetricDataInput
+	err        error
+}
+
+func (f *fakeCloudWatch) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput, opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
+	f.input = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	output := &cloudwatch.GetMetricDataOutput{}
+	for _, query := range input.MetricDataQueries {
+		output.MetricDataResults = append(output.MetricDataResults, &cloudwatch.MetricDataResult{
+			Id:     query.Id,
+			Values: aws.Float64Slice(f.datapoints[aws.StringValue(query.MetricStat.Metric.MetricName)]),
+		})
+	}
+	return output, nil
+}
+
+func TestGetTaskResourceUsage(t *testing.T) {
+	cw := &fakeCloudWatch{datapoints: map[string][]float64{
+		"CpuUtilized":    {128, 64},
+		"CpuReserved":    {512, 512},
+		"MemoryUtilized": {768},
+		"MemoryReserved": {1024},
+	}}
+
+	usage, err := (&ECS{}).GetTaskResourceUsage(aws.BackgroundContext(),
+		"arn:aws:ecs:us-west-2:123456789012:cluster/default", testTaskArn1, cw, 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, &TaskResourceUsage{CpuPercent: 25, MemoryPercent: 75}, usage)
+
+	require.NotNil(t, cw.input)
+	assert.Equal(t, 5*time.Minute, cw.input.EndTime.Sub(*cw.input.StartTime))
+	require.Len(t, cw.input.MetricDataQueries, 4)
+	for _, query := range cw.input.MetricDataQueries {
+		assert.NoError(t, query.Validate())
+		stat := query.MetricStat
+		assert.Equal(t, "ECS/ContainerInsights", aws.StringValue(stat.Metric.Namespace))
+		assert.Equal(t, []*cloudwatch.Dimension{
+			{Name: aws.String("ClusterName"), Value: aws.String("default")},
+			{Name: aws.String("TaskId"), Value: aws.String("task1")},
+		}, stat.Metric.Dimensions)
+		assert.Equal(t, int64(300), aws.Int64Value(stat.Period))
+		assert.Equal(t, cloudwatch.StatisticAverage, aws.StringValue(stat.Stat))
+	}
+}
+
+func TestGetTaskResourceUsageNames(t *testing.T) {
+	cw := &fakeCloudWatch{datapoints: map[string][]float64{
+		"CpuUtilized":    {256},
+		"CpuReserved":    {1024},
+		"MemoryUtilized": {512},
+		"MemoryReserved": {512},
+	}}
+
+	usage, err := (&ECS{}).GetTaskResourceUsage(aws.BackgroundContext(), "default", "task1", cw, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, &TaskResourceUsage{CpuPercent: 25, MemoryPercent: 100}, usage)
+	assert.Equal(t, "default", aws.StringValue(cw.input.MetricDataQueries[0].MetricStat.Metric.Dimensions[0].Value))
+	assert.Equal(t, "task1", aws.StringValue(cw.input.MetricDataQueries[0].MetricStat.Metric.Dimensions[1].Value))
+}
+
+func TestGetTaskResourceUsageErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cw     *fakeCloudWatch
+		period time.Duration
+	}{
+		{"cloudwatch error", &fakeCloudWatch{err: awserr.New("Throttling", "Rate exceeded", nil)}, time.Minute},
+		{"no datapoints", &fakeCloudWatch{}, time.Minute},
+		{"no memory reserved", &fakeCloudWatch{datapoints: map[string][]float64{
+			"CpuUtilized":    {256},
+			"CpuReserved":    {1024},
+			"MemoryUtilized": {512},
+			"MemoryReserved": {0},
+		}}, time.Minute},
+		{"period too short", &fakeCloudWatch{}, time.Millisecond},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			usage, err := (&ECS{}).GetTaskResourceUsage(aws.BackgroundContext(), "default", testTaskArn1, tc.cw, tc.period)
+			assert.Error(t, err)
+			assert.Nil(t, usage)
+		})
+	}
+}
+
+// testCapacityInstance returns a container instance with 2048 CPU units,
+// 4096 MiB of memory and gpus GPUs registered, of which the given amounts
+// remain
+func testCapacityInstance(status string, gpus int, remainingCPU, remainingMemory int64, remainingGPUs int) *ContainerInstance {
+	gpuIDs := func(n int) []*string {
+		var ids []*string
+		for i := 0; i < n; i++ {
+			ids = append(ids, aws.String(fmt.Sprintf("GPU-%d", i)))
+		}
+		return ids
+	}
+	return &ContainerInstance{
+		Status: aws.String(status),
+		RegisteredResources: []*Resource{
+			{Name: aws.String("CPU"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(2048)},
+			{Name: aws.String("MEMORY"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(4096)},
+			{Name: aws.String("PORTS"), Type: aws.String("STRINGSET"), StringSetValue: aws.StringSlice([]string{"22", "2375"})},
+			{Name: aws.String("GPU"), Type: aws.String("STRINGSET"), StringSetValue: gpuIDs(gpus)},
+		},
+		RemainingResources: []*Resource{
+			{Name: aws.String("CPU"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(remainingCPU)},
+			{Name: aws.String("MEMORY"), Type: aws.String("INTEGER"), IntegerValue: aws.Int64(remainingMemory)},
+			{Name: aws.String("GPU"), Type: aws.String("STRINGSET"), StringSetValue: gpuIDs(remainingGPUs)},
+		},
+	}
+}
+
+func TestBuildCapacityReportEmptyCluster(t *testing.T) {
+	cluster := &Cluster{ClusterArn: aws.String("arn:aws:ecs:us-west-2:123456789012:cluster/default")}
+	assert.Equal(t, &ClusterCapacityReport{
+		ClusterArn: "arn:aws:ecs:us-west-2:123456789012:cluster/default",
+	}, BuildCapacityReport(cluster, nil))
+	assert.Equal(t, &ClusterCapacityReport{}, BuildCapacityReport(nil, nil))
+}
+
+func TestBuildCapacityReportFullyUtilized(t *testing.T) {
+	report := BuildCapacityReport(nil, []*ContainerInstance{
+		testCapacityInstance(ContainerInstanceStatusActive, 4, 0, 0, 0),
+		testCapacityInstance(ContainerInstanceStatusActive, 0, 0, 0, 0),
+		nil,
+	})
+	assert.Equal(t, &ClusterCapacityReport{
+		TotalCPU:    4096,
+		UsedCPU:     4096,
+		TotalMemory: 8192,
+		UsedMemory:  8192,
+		TotalGPUs:   4,
+		UsedGPUs:    4,
+	}, report)
+}
+
+func TestBuildCapacityReportDrainedInstances(t *testing.T) {
+	report := BuildCapacityReport(nil, []*ContainerInstance{
+		testCapacityInstance(ContainerInstanceStatusActive, 2, 1024, 1024, 1),
+		testCapacityInstance(ContainerInstanceStatusDraining, 2, 1536, 3072, 2),
+		testCapacityInstance(ContainerInstanceStatusDraining, 0, 2048, 4096, 0),
+		testCapacityInstance("INACTIVE", 2, 2048, 4096, 2),
+	})
+	assert.Equal(t, &ClusterCapacityReport{
+		TotalCPU:          6144,
+		UsedCPU:           1536,
+		TotalMemory:       12288,
+		UsedMemory:        4096,
+		TotalGPUs:         4,
+		UsedGPUs:          1,
+		DrainedInstances:  2,
+		InactiveInstances: 1,
+	}, report)
+}
+
+func TestAnalyzeTaskFailure(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stoppedReason string
+		containers    []*Container
+		expected      TaskFailureCategory
+	}{
+		{
+			name:          "oom container",
+			stoppedReason: "Essential container in task exited",
+			containers: []*Container{{
+				Reason:   aws.String("OutOfMemoryError: Container killed due to memory usage"),
+				ExitCode: aws.Int64(137),
+			}},
+			expected: TaskFailureCategoryOOMKilled,
+		},
+		{
+			name:          "image pull",
+			stoppedReason: "CannotPullContainerError: pull image manifest has been retried 5 time(s): failed to resolve ref",
+			expected:      TaskFailureCategoryImagePullFailure,
+		},
+		{
+			name:          "registry auth",
+			stoppedReason: "ResourceInitializationError: unable to pull secrets or registry auth: execution resource retrieval failed",
+			expected:      TaskFailureCategoryImagePullFailure,
+		},
+		{
+			name:          "essential container exited",
+			stoppedReason: "Essential container in task exited",
+			containers:    []*Container{{ExitCode: aws.Int64(1)}},
+			expected:      TaskFailureCategoryContainerExited,
+		},
+		{
+			name:       "non-zero exit code",
+			containers: []*Container{{ExitCode: aws.Int64(0)}, {ExitCode: aws.Int64(2)}},
+			expected:   TaskFailureCategoryContainerExited,
+		},
+		{
+			name:          "network interface timeout",
+			stoppedReason: "Timeout waiting for network interface provisioning to complete.",
+			expected:      TaskFailureCategoryTimeoutExceeded,
+		},
+		{
+			name:          "docker timeout",
+			stoppedReason: "DockerTimeoutError: Could not transition to started; timed out after waiting 3m0s",
+			expected:      TaskFailureCategoryTimeoutExceeded,
+		},
+		{
+			name:          "insufficient memory",
+			stoppedReason: "RESOURCE:MEMORY",
+			expected:      TaskFailureCategoryCapacityUnavailable,
+		},
+		{
+			name:          "fargate capacity",
+			stoppedReason: "Capacity is unavailable at this time. Please try again later or in a different availability zone",
+			expected:      TaskFailureCategoryCapacityUnavailable,
+		},
+		{
+			name:          "user stopped",
+			stoppedReason: "Task stopped by user",
+			containers:    []*Container{{ExitCode: aws.Int64(0)}},
+			expected:      TaskFailureCategoryUnknown,
+		},
+		{
+			name:     "no reason",
+			expected: TaskFailureCategoryUnknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &Task{Containers: tc.containers}
+			if tc.stoppedReason != "" {
+				task.StoppedReason = aws.String(tc.stoppedReason)
+			}
+			assert.Equal(t, tc.expected, AnalyzeTaskFailure(task))
+		})
+	}
+	assert.Equal(t, TaskFailureCategoryUnknown, AnalyzeTaskFailure(nil))
+}
+
+func TestContainerInstanceGetAttribute(t *testing.T) {
+	instance := &ContainerInstance{Attributes: []*Attribute{
+		{Name: aws.String("ecs.os-type"), Value: aws.String("linux")},
+		{Name: aws.String("ecs.capability.privileged-container")},
+		{Name: aws.String("ecs.availability-zone"), Value: aws.String("us-west-2a")},
+	}}
+
+	value, ok := instance.GetAttribute("ecs.availability-zone")
+	assert.True(t, ok)
+	assert.Equal(t, "us-west-2a", value)
+
+	value, ok = instance.GetAttribute("ecs.capability.privileged-container")
+	assert.True(t, ok, "attributes without a value are found")
+	assert.Equal(t, "", value)
+
+	value, ok = instance.GetAttribute("ecs.instance-type")
+	assert.False(t, ok)
+	assert.Equal(t, "", value)
+
+	_, ok = (&ContainerInstance{}).GetAttribute("ecs.availability-zone")
+	assert.False(t, ok)
+}
+
+// describedTaskDefinition is a DescribeTaskDefinition response for a task
+// definition registered from testDiffTaskDefinitionsInput, with the defaults
+// that ECS fills in
+const describedTaskDefinition = `{
+  "taskDefinition": {
+    "taskDefinitionArn": "arn:aws:ecs:us-west-2:123456789012:task-definition/web:3",
+    "containerDefinitions": [
+      {
+        "name": "web",
+        "image": "nginx:1.15",
+        "cpu": 0,
+        "memory": 256,
+        "links": ["app"],
+        "portMappings": [
+          {"containerPort": 443, "hostPort": 0, "protocol": "tcp"},
+          {"containerPort": 80, "hostPort": 8080, "protocol": "tcp"}
+        ],
+        "essential": true,
+        "environment": [],
+        "mountPoints": [],
+        "volumesFrom": []
+      },
+      {
+        "name": "app",
+        "image": "123456789012.dkr.ecr.us-west-2.amazonaws.com/app:latest",
+        "cpu": 128,
+        "memoryReservation": 128,
+        "portMappings": [],
+        "essential": false,
+        "environment": [
+          {"name": "PORT", "value": "3000"},
+          {"name": "LOG_LEVEL", "value": "info"}
+        ],
+        "mountPoints": [
+          {"sourceVolume": "data", "containerPath": "/data", "readOnly": false}
+        ],
+        "volumesFrom": [],
+        "logConfiguration": {
+          "logDriver": "awslogs",
+          "options": {"awslogs-group": "app", "awslogs-region": "us-west-2"}
+        }
+      }
+    ],
+    "family": "web",
+    "taskRoleArn": "arn:aws:iam::123456789012:role/web",
+    "networkMode": "bridge",
+    "revision": 3,
+    "volumes": [
+      {"name": "data", "host": {"sourcePath": "/ecs/data"}}
+    ],
+    "status": "ACTIVE",
+    "requiresAttributes": [
+      {"name": "com.amazonaws.ecs.capability.ecr-auth"},
+      {"name": "com.amazonaws.ecs.capability.task-iam-role"},
+      {"name": "com.amazonaws.ecs.capability.logging-driver.awslogs"}
+    ],
+    "placementConstraints": [],
+    "compatibilities": ["EC2"]
+  }
+}`
+
+func testDiffTaskDefinitionsInput() *RegisterTaskDefinitionInput {
+	return &RegisterTaskDefinitionInput{
+		Family:      aws.String("web"),
+		TaskRoleArn: aws.String("arn:aws:iam::123456789012:role/web"),
+		ContainerDefinitions: []*ContainerDefinition{
+			{
+				Name:              aws.String("app"),
+				Image:             aws.String("123456789012.dkr.ecr.us-west-2.amazonaws.com/app:latest"),
+				Cpu:               aws.Int64(128),
+				MemoryReservation: aws.Int64(128),
+				Essential:         aws.Bool(false),
+				Environment: []*KeyValuePair{
+					{Name: aws.String("LOG_LEVEL"), Value: aws.String("info")},
+					{Name: aws.String("PORT"), Value: aws.String("3000")},
+				},
+				MountPoints: []*MountPoint{{
+					SourceVolume:  aws.String("data"),
+					ContainerPath: aws.String("/data"),
+					ReadOnly:      aws.Bool(false),
+				}},
+				LogConfiguration: &LogConfiguration{
+					LogDriver: aws.String(LogDriverAwslogs),
+					Options: map[string]*string{
+						"awslogs-group":  aws.String("app"),
+						"awslogs-region": aws.String("us-west-2"),
+					},
+				},
+			},
+			{
+				Name:   aws.String("web"),
+				Image:  aws.String("nginx:1.15"),
+				Memory: aws.Int64(256),
+				Links:  aws.StringSlice([]string{"app"}),
+				PortMappings: []*PortMapping{
+					{ContainerPort: aws.Int64(80), HostPort: aws.Int64(8080)},
+					{ContainerPort: aws.Int64(443)},
+				},
+			},
+		},
+		Volumes: []*Volume{{
+			Name: aws.String("data"),
+			Host: &HostVolumeProperties{SourcePath: aws.String("/ecs/data")},
+		}},
+		Tags: []*Tag{{Key: aws.String("team"), Value: aws.String("web")}},
+	}
+}
+
+func TestDiffTaskDefinitionsDescribed(t *testing.T) {
+	var output DescribeTaskDefinitionOutput
+	require.NoError(t, jsonutil.UnmarshalJSON(&output, bytes.NewReader([]byte(describedTaskDefinition))))
+	exported, err := ExportTaskDefinition(output.TaskDefinition)
+	require.NoError(t, err)
+
+	assert.Empty(t, DiffTaskDefinitions(exported, testDiffTaskDefinitionsInput()),
+		"the defaults filled in by ECS and the order of lists should not be differences")
+
+	input := testDiffTaskDefinitionsInput()
+	input.ContainerDefinitions[1].Image = aws.String("nginx:1.16")
+	input.ContainerDefinitions[1].PortMappings[1].Protocol = aws.String(TransportProtocolUdp)
+	changes := DiffTaskDefinitions(exported, input)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "ContainerDefinitions[web]", changes[0].Field)
+	assert.Contains(t, changes[0].OldValue, `"nginx:1.15"`)
+	assert.Contains(t, changes[0].NewValue, `"nginx:1.16"`)
+
+	input = testDiffTaskDefinitionsInput()
+	input.ContainerDefinitions = input.ContainerDefinitions[1:]
+	input.TaskRoleArn = nil
+	changes = DiffTaskDefinitions(exported, input)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "TaskRoleArn", changes[0].Field)
+	assert.Equal(t, "ContainerDefinitions[app]", changes[1].Field)
+	assert.Empty(t, changes[1].NewValue)
+}
+
+func TestDiffTaskDefinitionsHostPortDefaults(t *testing.T) {
+	testCases := []struct {
+		networkMode string
+		hostPort    int64
+	}{
+		{NetworkModeBridge, 0},
+		{NetworkModeAwsvpc, 80},
+		{NetworkModeHost, 80},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.networkMode, func(t *testing.T) {
+			described := &RegisterTaskDefinitionInput{
+				Family:      aws.String("web"),
+				NetworkMode: aws.String(tc.networkMode),
+				ContainerDefinitions: []*ContainerDefinition{{
+					Name:         aws.String("web"),
+					Cpu:          aws.Int64(0),
+					Essential:    aws.Bool(true),
+					PortMappings: []*PortMapping{{ContainerPort: aws.Int64(80), HostPort: aws.Int64(tc.hostPort), Protocol: aws.String("tcp")}},
+				}},
+			}
+			input := &RegisterTaskDefinitionInput{
+				Family:      aws.String("web"),
+				NetworkMode: aws.String(tc.networkMode),
+				ContainerDefinitions: []*ContainerDefinition{{
+					Name:         aws.String("web"),
+					PortMappings: []*PortMapping{{ContainerPort: aws.Int64(80)}},
+				}},
+			}
+			assert.Empty(t, DiffTaskDefinitions(described, input))
+		})
+	}
+}
+
+func TestConstraintBuilder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		builder  *ConstraintBuilder
+		expected string
+	}{
+		{"empty", NewConstraintBuilder(), ""},
+		{
+			"single condition",
+			NewConstraintBuilder().AttributeEquals("ecs.instance-type", "t3.micro"),
+			"attribute:ecs.instance-type == t3.micro",
+		},
+		{
+			"conditions",
+			NewConstraintBuilder().AttributeEquals("ecs.instance-type", "t3.micro").AttributeExists("gpu"),
+			"attribute:ecs.instance-type == t3.micro and attribute:gpu exists",
+		},
+		{
+			"and",
+			NewConstraintBuilder().AttributeExists("gpu").And(NewConstraintBuilder().AttributeEquals("env", "prod")),
+			"attribute:gpu exists and (attribute:env == prod)",
+		},
+		{
+			"or",
+			NewConstraintBuilder().AttributeExists("gpu").Or(NewConstraintBuilder().AttributeEquals("env", "prod")),
+			"(attribute:gpu exists) or (attribute:env == prod)",
+		},
+		{
+			"condition after or",
+			NewConstraintBuilder().AttributeExists("gpu").Or(NewConstraintBuilder().AttributeEquals("env", "prod")).AttributeExists("tier"),
+			"((attribute:gpu exists) or (attribute:env == prod)) and attribute:tier exists",
+		},
+		{
+			"or into empty",
+			NewConstraintBuilder().Or(NewConstraintBuilder().AttributeExists("gpu")).And(NewConstraintBuilder()),
+			"attribute:gpu exists",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expression, err := tc.builder.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, expression)
+		})
+	}
+}
+
+func TestConstraintBuilderInvalidWords(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *ConstraintBuilder
+	}{
+		{"value with space", NewConstraintBuilder().AttributeEquals("env", "prod eu")},
+		{"value with parenthesis", NewConstraintBuilder().AttributeEquals("env", "prod)")},
+		{"value with bang", NewConstraintBuilder().AttributeEquals("env", "!prod")},
+		{"empty value", NewConstraintBuilder().AttributeEquals("env", "")},
+		{"name with space", NewConstraintBuilder().AttributeExists("gpu count")},
+		{"in and", NewConstraintBuilder().AttributeExists("gpu").And(NewConstraintBuilder().AttributeEquals("env", "a b"))},
+		{"in or", NewConstraintBuilder().Or(NewConstraintBuilder().AttributeEquals("env", "a|b"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.builder.Build()
+			assert.Error(t, err)
+		})
+	}
+}
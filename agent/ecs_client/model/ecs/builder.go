@@ -0,0 +1,236 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/pkg/errors"
+)
+
+// TaskDefinitionBuilder builds a RegisterTaskDefinitionInput one field at a
+// time:
+//
+//	input, err := NewTaskDefinitionBuilder().
+//		WithFamily("web").
+//		WithContainer(NewContainerDefinitionBuilder().WithName("web").WithImage("nginx")).
+//		Build()
+type TaskDefinitionBuilder struct {
+	input      RegisterTaskDefinitionInput
+	containers []*ContainerDefinitionBuilder
+}
+
+// NewTaskDefinitionBuilder creates an empty TaskDefinitionBuilder
+func NewTaskDefinitionBuilder() *TaskDefinitionBuilder {
+	return &TaskDefinitionBuilder{}
+}
+
+// WithFamily sets the family of the task definition
+func (b *TaskDefinitionBuilder) WithFamily(family string) *TaskDefinitionBuilder {
+	b.input.Family = aws.String(family)
+	return b
+}
+
+// WithNetworkMode sets the network mode, such as awsvpc
+func (b *TaskDefinitionBuilder) WithNetworkMode(networkMode string) *TaskDefinitionBuilder {
+	b.input.NetworkMode = aws.String(networkMode)
+	return b
+}
+
+// WithCPU sets the task CPU, in CPU units, such as "256", or in vCPUs, such
+// as "0.25 vCPU"
+func (b *TaskDefinitionBuilder) WithCPU(cpu string) *TaskDefinitionBuilder {
+	b.input.Cpu = aws.String(cpu)
+	return b
+}
+
+// WithMemory sets the task memory, in MiB, such as "512", or in GB, such as
+// "0.5 GB"
+func (b *TaskDefinitionBuilder) WithMemory(memory string) *TaskDefinitionBuilder {
+	b.input.Memory = aws.String(memory)
+	return b
+}
+
+// WithRequiresCompatibilities sets the launch types the task definition is
+// validated against, such as FARGATE
+func (b *TaskDefinitionBuilder) WithRequiresCompatibilities(compatibilities ...string) *TaskDefinitionBuilder {
+	b.input.RequiresCompatibilities = aws.StringSlice(compatibilities)
+	return b
+}
+
+// WithContainer adds a container, built when the task definition is
+func (b *TaskDefinitionBuilder) WithContainer(container *ContainerDefinitionBuilder) *TaskDefinitionBuilder {
+	b.containers = append(b.containers, container)
+	return b
+}
+
+// WithVolume adds a volume
+func (b *TaskDefinitionBuilder) WithVolume(volume *Volume) *TaskDefinitionBuilder {
+	b.input.Volumes = append(b.input.Volumes, volume)
+	return b
+}
+
+// WithTaskRoleArn sets the IAM role the containers of the task assume
+func (b *TaskDefinitionBuilder) WithTaskRoleArn(roleArn string) *TaskDefinitionBuilder {
+	b.input.TaskRoleArn = aws.String(roleArn)
+	return b
+}
+
+// WithExecutionRoleArn sets the IAM role the agent assumes to pull images and
+// write logs on behalf of the task
+func (b *TaskDefinitionBuilder) WithExecutionRoleArn(roleArn string) *TaskDefinitionBuilder {
+	b.input.ExecutionRoleArn = aws.String(roleArn)
+	return b
+}
+
+// Build builds the containers and returns the input, once validated. The
+// input returned does not share memory with the builder, which can be
+// changed and built again.
+func (b *TaskDefinitionBuilder) Build() (*RegisterTaskDefinitionInput, error) {
+	input := awsutil.CopyOf(&b.input).(*RegisterTaskDefinitionInput)
+	input.ContainerDefinitions = []*ContainerDefinition{}
+	for i, builder := range b.containers {
+		if builder == nil {
+			return nil, fmt.Errorf("build task definition: container %d is nil", i)
+		}
+		container, err := builder.Build()
+		if err != nil {
+			return nil, errors.Wrapf(err, "build task definition: container %d", i)
+		}
+		input.ContainerDefinitions = append(input.ContainerDefinitions, container)
+	}
+	if len(input.ContainerDefinitions) == 0 {
+		return nil, fmt.Errorf("build task definition: no container")
+	}
+	if err := ValidateParams(input); err != nil {
+		return nil, errors.Wrap(err, "build task definition")
+	}
+	return input, nil
+}
+
+// ContainerDefinitionBuilder builds a ContainerDefinition one field at a
+// time, for a TaskDefinitionBuilder
+type ContainerDefinitionBuilder struct {
+	container ContainerDefinition
+}
+
+// NewContainerDefinitionBuilder creates an empty ContainerDefinitionBuilder
+func NewContainerDefinitionBuilder() *ContainerDefinitionBuilder {
+	return &ContainerDefinitionBuilder{}
+}
+
+// WithName sets the name of the container
+func (b *ContainerDefinitionBuilder) WithName(name string) *ContainerDefinitionBuilder {
+	b.container.Name = aws.String(name)
+	return b
+}
+
+// WithImage sets the image the container runs
+func (b *ContainerDefinitionBuilder) WithImage(image string) *ContainerDefinitionBuilder {
+	b.container.Image = aws.String(image)
+	return b
+}
+
+// WithCPU sets the CPU units reserved for the container
+func (b *ContainerDefinitionBuilder) WithCPU(cpu int64) *ContainerDefinitionBuilder {
+	b.container.Cpu = aws.Int64(cpu)
+	return b
+}
+
+// WithMemory sets the hard limit of the memory of the container, in MiB
+func (b *ContainerDefinitionBuilder) WithMemory(memory int64) *ContainerDefinitionBuilder {
+	b.container.Memory = aws.Int64(memory)
+	return b
+}
+
+// WithEssential sets whether the task stops when the container stops
+func (b *ContainerDefinitionBuilder) WithEssential(essential bool) *ContainerDefinitionBuilder {
+	b.container.Essential = aws.Bool(essential)
+	return b
+}
+
+// WithEnvironment adds an environment variable
+func (b *ContainerDefinitionBuilder) WithEnvironment(key, value string) *ContainerDefinitionBuilder {
+	b.container.Environment = append(b.container.Environment, &KeyValuePair{
+		Name:  aws.String(key),
+		Value: aws.String(value),
+	})
+	return b
+}
+
+// WithPortMapping adds a port mapping. The protocol, tcp or udp, defaults to
+// tcp when empty.
+func (b *ContainerDefinitionBuilder) WithPortMapping(containerPort, hostPort int64, protocol string) *ContainerDefinitionBuilder {
+	portMapping := &PortMapping{
+		ContainerPort: aws.Int64(containerPort),
+		HostPort:      aws.Int64(hostPort),
+	}
+	if protocol != "" {
+		portMapping.Protocol = aws.String(protocol)
+	}
+	b.container.PortMappings = append(b.container.PortMappings, portMapping)
+	return b
+}
+
+// WithLogDriver sets the log driver of the container, such as awslogs, and
+// its options
+func (b *ContainerDefinitionBuilder) WithLogDriver(logDriver string, options map[string]string) *ContainerDefinitionBuilder {
+	b.container.LogConfiguration = &LogConfiguration{
+		LogDriver: aws.String(logDriver),
+		Options:   aws.StringMap(options),
+	}
+	return b
+}
+
+// WithCommand sets the command the container runs
+func (b *ContainerDefinitionBuilder) WithCommand(command ...string) *ContainerDefinitionBuilder {
+	b.container.Command = aws.StringSlice(command)
+	return b
+}
+
+// WithHealthCheck sets the health check of the container
+func (b *ContainerDefinitionBuilder) WithHealthCheck(healthCheck *HealthCheck) *ContainerDefinitionBuilder {
+	b.container.HealthCheck = healthCheck
+	return b
+}
+
+// WithSecret adds a secret exposed to the container as the environment
+// variable name, read from the Secrets Manager secret or SSM parameter
+// valueFrom
+func (b *ContainerDefinitionBuilder) WithSecret(name, valueFrom string) *ContainerDefinitionBuilder {
+	b.container.Secrets = append(b.container.Secrets, &Secret{
+		Name:      aws.String(name),
+		ValueFrom: aws.String(valueFrom),
+	})
+	return b
+}
+
+// Build returns the container definition, once validated. A container must
+// have a name and an image. The definition returned does not share memory
+// with the builder.
+func (b *ContainerDefinitionBuilder) Build() (*ContainerDefinition, error) {
+	container := awsutil.CopyOf(&b.container).(*ContainerDefinition)
+	if aws.StringValue(container.Name) == "" {
+		return nil, fmt.Errorf("build container definition: no name")
+	}
+	if aws.StringValue(container.Image) == "" {
+		return nil, fmt.Errorf("build container definition %s: no image", aws.StringValue(container.Name))
+	}
+	if err := ValidateParams(container); err != nil {
+		return nil, errors.Wrapf(err, "build container definition %s", aws.StringValue(container.Name))
+	}
+	return container, nil
+}
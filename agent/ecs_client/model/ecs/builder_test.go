@@ -0,0 +1,194 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskDefinitionBuilderFargate(t *testing.T) {
+	input, err := NewTaskDefinitionBuilder().
+		WithFamily("web").
+		WithRequiresCompatibilities(CompatibilityFargate).
+		WithNetworkMode(NetworkModeAwsvpc).
+		WithCPU("256").
+		WithMemory("512").
+		WithExecutionRoleArn("arn:aws:iam::123456789012:role/ecsTaskExecutionRole").
+		WithContainer(NewContainerDefinitionBuilder().WithName("web").WithImage("nginx")).
+		Build()
+	require.NoError(t, err)
+	assert.NoError(t, ValidateParams(input))
+	assert.Empty(t, input.ValidationWarnings())
+
+	assert.Equal(t, &RegisterTaskDefinitionInput{
+		Family:                  aws.String("web"),
+		RequiresCompatibilities: aws.StringSlice([]string{CompatibilityFargate}),
+		NetworkMode:             aws.String(NetworkModeAwsvpc),
+		Cpu:                     aws.String("256"),
+		Memory:                  aws.String("512"),
+		ExecutionRoleArn:        aws.String("arn:aws:iam::123456789012:role/ecsTaskExecutionRole"),
+		ContainerDefinitions: []*ContainerDefinition{{
+			Name:  aws.String("web"),
+			Image: aws.String("nginx"),
+		}},
+	}, input)
+}
+
+func TestTaskDefinitionBuilderReuse(t *testing.T) {
+	builder := NewTaskDefinitionBuilder().
+		WithFamily("worker").
+		WithTaskRoleArn("arn:aws:iam::123456789012:role/worker").
+		WithVolume(&Volume{Name: aws.String("scratch")}).
+		WithContainer(NewContainerDefinitionBuilder().WithName("worker").WithImage("busybox"))
+	first, err := builder.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/worker", aws.StringValue(first.TaskRoleArn))
+	require.Len(t, first.Volumes, 1)
+
+	// Changing the builder or the input built leaves the other alone
+	first.Volumes[0].Name = aws.String("changed")
+	second, err := builder.WithFamily("worker-v2").Build()
+	require.NoError(t, err)
+	assert.Equal(t, "worker", aws.StringValue(first.Family))
+	assert.Equal(t, "worker-v2", aws.StringValue(second.Family))
+	assert.Equal(t, "scratch", aws.StringValue(second.Volumes[0].Name))
+}
+
+func TestTaskDefinitionBuilderInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		builder *TaskDefinitionBuilder
+	}{
+		{"no container", NewTaskDefinitionBuilder().WithFamily("web")},
+		{"no family", NewTaskDefinitionBuilder().
+			WithContainer(NewContainerDefinitionBuilder().WithName("web").WithImage("nginx"))},
+		{"fargate without cpu", NewTaskDefinitionBuilder().
+			WithFamily("web").
+			WithRequiresCompatibilities(CompatibilityFargate).
+			WithMemory("512").
+			WithContainer(NewContainerDefinitionBuilder().WithName("web").WithImage("nginx"))},
+		{"container without image", NewTaskDefinitionBuilder().
+			WithFamily("web").
+			WithContainer(NewContainerDefinitionBuilder().WithName("web"))},
+		{"container without name", NewTaskDefinitionBuilder().
+			WithFamily("web").
+			WithContainer(NewContainerDefinitionBuilder().WithImage("nginx"))},
+		{"nil container", NewTaskDefinitionBuilder().WithFamily("web").WithContainer(nil)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input, err := tc.builder.Build()
+			assert.Error(t, err)
+			assert.Nil(t, input)
+		})
+	}
+}
+
+func TestContainerDefinitionBuilder(t *testing.T) {
+	container, err := NewContainerDefinitionBuilder().
+		WithName("web").
+		WithImage("nginx:1.15").
+		WithCPU(256).
+		WithMemory(512).
+		WithEssential(true).
+		WithEnvironment("PORT", "80").
+		WithEnvironment("STAGE", "prod").
+		WithPortMapping(80, 8080, "").
+		WithPortMapping(53, 53, TransportProtocolUdp).
+		WithLogDriver(LogDriverAwslogs, map[string]string{"awslogs-group": "web"}).
+		WithCommand("nginx", "-g", "daemon off;").
+		WithHealthCheck(&HealthCheck{Command: aws.StringSlice([]string{"CMD-SHELL", "curl -f http://localhost/"})}).
+		WithSecret("DB_PASSWORD", "arn:aws:ssm:us-west-2:123456789012:parameter/db-password").
+		Build()
+	require.NoError(t, err)
+	assert.Equal(t, &ContainerDefinition{
+		Name:      aws.String("web"),
+		Image:     aws.String("nginx:1.15"),
+		Cpu:       aws.Int64(256),
+		Memory:    aws.Int64(512),
+		Essential: aws.Bool(true),
+		Environment: []*KeyValuePair{
+			{Name: aws.String("PORT"), Value: aws.String("80")},
+			{Name: aws.String("STAGE"), Value: aws.String("prod")},
+		},
+		PortMappings: []*PortMapping{
+			{ContainerPort: aws.Int64(80), HostPort: aws.Int64(8080)},
+			{ContainerPort: aws.Int64(53), HostPort: aws.Int64(53), Protocol: aws.String(TransportProtocolUdp)},
+		},
+		LogConfiguration: &LogConfiguration{
+			LogDriver: aws.String(LogDriverAwslogs),
+			Options:   map[string]*string{"awslogs-group": aws.String("web")},
+		},
+		Command: aws.StringSlice([]string{"nginx", "-g", "daemon off;"}),
+		HealthCheck: &HealthCheck{
+			Command: aws.StringSlice([]string{"CMD-SHELL", "curl -f http://localhost/"}),
+		},
+		Secrets: []*Secret{
+			{Name: aws.String("DB_PASSWORD"), ValueFrom: aws.String("arn:aws:ssm:us-west-2:123456789012:parameter/db-password")},
+		},
+	}, container)
+}
+
+func TestContainerDefinitionBuilderInvalid(t *testing.T) {
+	for name, builder := range map[string]*ContainerDefinitionBuilder{
+		"health check without command": NewContainerDefinitionBuilder().
+			WithName("web").WithImage("nginx").WithHealthCheck(&HealthCheck{}),
+		"interactive without pseudo terminal": NewContainerDefinitionBuilder().
+			WithName("web").WithImage("nginx"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if name == "interactive without pseudo terminal" {
+				builder.container.Interactive = aws.Bool(true)
+			}
+			container, err := builder.Build()
+			assert.Error(t, err)
+			assert.Nil(t, container)
+		})
+	}
+}
+
+func TestTaskDefinitionBuilderTwoContainers(t *testing.T) {
+	input, err := NewTaskDefinitionBuilder().
+		WithFamily("web").
+		WithNetworkMode(NetworkModeBridge).
+		WithContainer(NewContainerDefinitionBuilder().
+			WithName("app").
+			WithImage("example/app:1.0").
+			WithMemory(256).
+			WithEssential(true).
+			WithPortMapping(8080, 0, TransportProtocolTcp).
+			WithSecret("API_KEY", "arn:aws:secretsmanager:us-west-2:123456789012:secret:api-key")).
+		WithContainer(NewContainerDefinitionBuilder().
+			WithName("log-router").
+			WithImage("fluent/fluentd").
+			WithMemory(64).
+			WithEssential(false).
+			WithEnvironment("FLUENTD_CONF", "fluent.conf").
+			WithLogDriver(LogDriverJsonFile, nil)).
+		Build()
+	require.NoError(t, err)
+	assert.NoError(t, ValidateParams(input))
+	require.Len(t, input.ContainerDefinitions, 2)
+	assert.Equal(t, "app", aws.StringValue(input.ContainerDefinitions[0].Name))
+	assert.Equal(t, "log-router", aws.StringValue(input.ContainerDefinitions[1].Name))
+	assert.False(t, aws.BoolValue(input.ContainerDefinitions[1].Essential))
+	assert.Equal(t, LogDriverJsonFile, aws.StringValue(input.ContainerDefinitions[1].LogConfiguration.LogDriver))
+}
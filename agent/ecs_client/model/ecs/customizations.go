@@ -0,0 +1,61 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/corehandlers"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// availabilityZoneAttribute is the attribute the agent sets to the
+// Availability Zone of its container instance
+const availabilityZoneAttribute = "ecs.availability-zone"
+
+func init() {
+	initRequest = func(r *request.Request) {
+		r.Handlers.Validate.Swap(corehandlers.ValidateParametersHandler.Name, validateParamsHandler)
+		switch r.Operation.Name {
+		case opDescribeContainerInstances, opRegisterContainerInstance, opDeregisterContainerInstance,
+			opUpdateContainerAgent, opUpdateContainerInstancesState:
+			r.Handlers.Unmarshal.PushBack(populateAvailabilityZones)
+		}
+	}
+}
+
+// populateAvailabilityZones sets the AvailabilityZone of the container
+// instances of a response from their ecs.availability-zone attribute
+func populateAvailabilityZones(r *request.Request) {
+	var instances []*ContainerInstance
+	switch output := r.Data.(type) {
+	case *DescribeContainerInstancesOutput:
+		instances = output.ContainerInstances
+	case *RegisterContainerInstanceOutput:
+		instances = []*ContainerInstance{output.ContainerInstance}
+	case *DeregisterContainerInstanceOutput:
+		instances = []*ContainerInstance{output.ContainerInstance}
+	case *UpdateContainerAgentOutput:
+		instances = []*ContainerInstance{output.ContainerInstance}
+	case *UpdateContainerInstancesStateOutput:
+		instances = output.ContainerInstances
+	}
+	for _, instance := range instances {
+		if instance == nil || instance.AvailabilityZone != nil {
+			continue
+		}
+		if zone, ok := instance.GetAttribute(availabilityZoneAttribute); ok {
+			instance.AvailabilityZone = aws.String(zone)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCodes(t *testing.T) {
+	errorCodes := map[string]string{
+		"ErrCodeAccessDeniedException":                          ErrCodeAccessDeniedException,
+		"ErrCodeAttributeLimitExceededException":                ErrCodeAttributeLimitExceededException,
+		"ErrCodeBlockedException":                               ErrCodeBlockedException,
+		"ErrCodeClientException":                                ErrCodeClientException,
+		"ErrCodeClusterContainsContainerInstancesException":     ErrCodeClusterContainsContainerInstancesException,
+		"ErrCodeClusterContainsServicesException":               ErrCodeClusterContainsServicesException,
+		"ErrCodeClusterContainsTasksException":                  ErrCodeClusterContainsTasksException,
+		"ErrCodeClusterNotFoundException":                       ErrCodeClusterNotFoundException,
+		"ErrCodeInvalidParameterException":                      ErrCodeInvalidParameterException,
+		"ErrCodeMissingVersionException":                        ErrCodeMissingVersionException,
+		"ErrCodeNoUpdateAvailableException":                     ErrCodeNoUpdateAvailableException,
+		"ErrCodePlatformTaskDefinitionIncompatibilityException": ErrCodePlatformTaskDefinitionIncompatibilityException,
+		"ErrCodePlatformUnknownException":                       ErrCodePlatformUnknownException,
+		"ErrCodeServerException":                                ErrCodeServerException,
+		"ErrCodeServiceNotActiveException":                      ErrCodeServiceNotActiveException,
+		"ErrCodeServiceNotFoundException":                       ErrCodeServiceNotFoundException,
+		"ErrCodeTargetNotFoundException":                        ErrCodeTargetNotFoundException,
+		"ErrCodeUnsupportedFeatureException":                    ErrCodeUnsupportedFeatureException,
+		"ErrCodeUpdateInProgressException":                      ErrCodeUpdateInProgressException,
+	}
+
+	seen := make(map[string]string)
+	for name, code := range errorCodes {
+		assert.NotEmpty(t, code, name)
+		assert.Equal(t, strings.TrimPrefix(name, "ErrCode"), code, "%s should match its name", name)
+		if other, ok := seen[code]; ok {
+			t.Errorf("%s and %s share the error code %s", name, other, code)
+		}
+		seen[code] = name
+	}
+}
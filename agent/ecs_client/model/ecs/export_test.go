@@ -0,0 +1,48 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import "time"
+
+// SetSafeDeregisterPollInterval changes the polling interval of
+// SafeDeregisterContainerInstance and returns a function restoring it
+func SetSafeDeregisterPollInterval(interval time.Duration) func() {
+	previous := safeDeregisterPollInterval
+	safeDeregisterPollInterval = interval
+	return func() {
+		safeDeregisterPollInterval = previous
+	}
+}
+
+// SetWaitForServiceStablePollInterval changes the polling interval of
+// WaitForServiceStable and returns a function restoring it
+func SetWaitForServiceStablePollInterval(interval time.Duration) func() {
+	previous := waitForServiceStablePollInterval
+	waitForServiceStablePollInterval = interval
+	return func() {
+		waitForServiceStablePollInterval = previous
+	}
+}
+
+// SetDrainAndWaitPollInterval changes the polling interval of DrainAndWait
+// and returns a function restoring it
+func SetDrainAndWaitPollInterval(interval time.Duration) func() {
+	previous := drainAndWaitPollInterval
+	drainAndWaitPollInterval = interval
+	return func() {
+		drainAndWaitPollInterval = previous
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// ECSClientInterface is the set of context aware ECS API operations, along
+// with the helpers built on them such as RunTaskBatch, that are implemented
+// by the ECS client, and by the wrappers and fakes built around it. Code that
+// only needs to make API calls should depend on this interface rather than
+// on *ECS, so that the client can be decorated or replaced.
+type ECSClientInterface interface {
+	CreateClusterWithContext(aws.Context, *CreateClusterInput, ...request.Option) (*CreateClusterOutput, error)
+	CreateServiceWithContext(aws.Context, *CreateServiceInput, ...request.Option) (*CreateServiceOutput, error)
+	DeleteAccountSettingWithContext(aws.Context, *DeleteAccountSettingInput, ...request.Option) (*DeleteAccountSettingOutput, error)
+	DeleteAttributesWithContext(aws.Context, *DeleteAttributesInput, ...request.Option) (*DeleteAttributesOutput, error)
+	DeleteClusterWithContext(aws.Context, *DeleteClusterInput, ...request.Option) (*DeleteClusterOutput, error)
+	DeleteServiceWithContext(aws.Context, *DeleteServiceInput, ...request.Option) (*DeleteServiceOutput, error)
+	DeregisterContainerInstanceWithContext(aws.Context, *DeregisterContainerInstanceInput, ...request.Option) (*DeregisterContainerInstanceOutput, error)
+	DeregisterTaskDefinitionWithContext(aws.Context, *DeregisterTaskDefinitionInput, ...request.Option) (*DeregisterTaskDefinitionOutput, error)
+	DescribeClustersWithContext(aws.Context, *DescribeClustersInput, ...request.Option) (*DescribeClustersOutput, error)
+	DescribeContainerInstancesWithContext(aws.Context, *DescribeContainerInstancesInput, ...request.Option) (*DescribeContainerInstancesOutput, error)
+	DescribeServicesWithContext(aws.Context, *DescribeServicesInput, ...request.Option) (*DescribeServicesOutput, error)
+	DescribeTaskDefinitionWithContext(aws.Context, *DescribeTaskDefinitionInput, ...request.Option) (*DescribeTaskDefinitionOutput, error)
+	DescribeTasksWithContext(aws.Context, *DescribeTasksInput, ...request.Option) (*DescribeTasksOutput, error)
+	DiscoverPollEndpointWithContext(aws.Context, *DiscoverPollEndpointInput, ...request.Option) (*DiscoverPollEndpointOutput, error)
+	ListAccountSettingsWithContext(aws.Context, *ListAccountSettingsInput, ...request.Option) (*ListAccountSettingsOutput, error)
+	ListAttributesWithContext(aws.Context, *ListAttributesInput, ...request.Option) (*ListAttributesOutput, error)
+	ListClustersWithContext(aws.Context, *ListClustersInput, ...request.Option) (*ListClustersOutput, error)
+	ListContainerInstancesWithContext(aws.Context, *ListContainerInstancesInput, ...request.Option) (*ListContainerInstancesOutput, error)
+	ListServicesWithContext(aws.Context, *ListServicesInput, ...request.Option) (*ListServicesOutput, error)
+	ListTagsForResourceWithContext(aws.Context, *ListTagsForResourceInput, ...request.Option) (*ListTagsForResourceOutput, error)
+	ListTaskDefinitionFamiliesWithContext(aws.Context, *ListTaskDefinitionFamiliesInput, ...request.Option) (*ListTaskDefinitionFamiliesOutput, error)
+	ListTaskDefinitionsWithContext(aws.Context, *ListTaskDefinitionsInput, ...request.Option) (*ListTaskDefinitionsOutput, error)
+	ListTasksWithContext(aws.Context, *ListTasksInput, ...request.Option) (*ListTasksOutput, error)
+	PutAccountSettingWithContext(aws.Context, *PutAccountSettingInput, ...request.Option) (*PutAccountSettingOutput, error)
+	PutAttributesWithContext(aws.Context, *PutAttributesInput, ...request.Option) (*PutAttributesOutput, error)
+	RegisterContainerInstanceWithContext(aws.Context, *RegisterContainerInstanceInput, ...request.Option) (*RegisterContainerInstanceOutput, error)
+	RegisterTaskDefinitionWithContext(aws.Context, *RegisterTaskDefinitionInput, ...request.Option) (*RegisterTaskDefinitionOutput, error)
+	RunTaskBatch(aws.Context, *RunTaskInput, int) ([]*Task, []*Failure, error)
+	RunTaskWithContext(aws.Context, *RunTaskInput, ...request.Option) (*RunTaskOutput, error)
+	StartTaskWithContext(aws.Context, *StartTaskInput, ...request.Option) (*StartTaskOutput, error)
+	StopTaskWithContext(aws.Context, *StopTaskInput, ...request.Option) (*StopTaskOutput, error)
+	SubmitContainerStateChangeWithContext(aws.Context, *SubmitContainerStateChangeInput, ...request.Option) (*SubmitContainerStateChangeOutput, error)
+	SubmitTaskStateChangeWithContext(aws.Context, *SubmitTaskStateChangeInput, ...request.Option) (*SubmitTaskStateChangeOutput, error)
+	UpdateContainerAgentWithContext(aws.Context, *UpdateContainerAgentInput, ...request.Option) (*UpdateContainerAgentOutput, error)
+	UpdateContainerInstancesStateWithContext(aws.Context, *UpdateContainerInstancesStateInput, ...request.Option) (*UpdateContainerInstancesStateOutput, error)
+	UpdateServiceWithContext(aws.Context, *UpdateServiceInput, ...request.Option) (*UpdateServiceOutput, error)
+}
+
+var _ ECSClientInterface = (*ECS)(nil)
@@ -0,0 +1,98 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// ToDockerRunCommand renders the container definition as an equivalent
+// docker run command, for debugging. It covers the image, command, entry
+// point, environment, port mappings, mount points, CPU, memory and user of
+// the container. Mount points refer to their source volume by name, as a
+// named volume. Arguments that the shell would interpret are single quoted.
+func (cd *ContainerDefinition) ToDockerRunCommand() string {
+	args := []string{"docker", "run"}
+	if cd.Cpu != nil {
+		args = append(args, "--cpu-shares", fmt.Sprint(aws.Int64Value(cd.Cpu)))
+	}
+	if cd.Memory != nil {
+		args = append(args, "--memory", fmt.Sprintf("%dm", aws.Int64Value(cd.Memory)))
+	}
+	if cd.User != nil {
+		args = append(args, "--user", aws.StringValue(cd.User))
+	}
+	for _, variable := range cd.Environment {
+		if variable != nil {
+			args = append(args, "-e", aws.StringValue(variable.Name)+"="+aws.StringValue(variable.Value))
+		}
+	}
+	for _, portMapping := range cd.PortMappings {
+		if portMapping != nil {
+			args = append(args, "-p", dockerPublish(portMapping))
+		}
+	}
+	for _, mountPoint := range cd.MountPoints {
+		if mountPoint == nil {
+			continue
+		}
+		volume := aws.StringValue(mountPoint.SourceVolume) + ":" + aws.StringValue(mountPoint.ContainerPath)
+		if aws.BoolValue(mountPoint.ReadOnly) {
+			volume += ":ro"
+		}
+		args = append(args, "-v", volume)
+	}
+	// docker run takes a single executable as its entry point: the rest of
+	// the entry point goes before the command
+	entryPoint := aws.StringValueSlice(cd.EntryPoint)
+	if len(entryPoint) > 0 {
+		args = append(args, "--entrypoint", entryPoint[0])
+		entryPoint = entryPoint[1:]
+	}
+	args = append(args, aws.StringValue(cd.Image))
+	args = append(args, entryPoint...)
+	args = append(args, aws.StringValueSlice(cd.Command)...)
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// dockerPublish returns the -p value of a port mapping: hostPort:containerPort
+// followed by /udp for UDP. The host port is left to Docker when it is unset
+// or 0.
+func dockerPublish(portMapping *PortMapping) string {
+	publish := fmt.Sprint(aws.Int64Value(portMapping.ContainerPort))
+	if hostPort := aws.Int64Value(portMapping.HostPort); hostPort != 0 {
+		publish = fmt.Sprintf("%d:%s", hostPort, publish)
+	}
+	if aws.StringValue(portMapping.Protocol) == TransportProtocolUdp {
+		publish += "/udp"
+	}
+	return publish
+}
+
+// shellQuote single quotes arg if the shell would otherwise split or
+// interpret it
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=+./:,@%") == "" {
+		return arg
+	}
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
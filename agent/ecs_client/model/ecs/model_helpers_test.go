@@ -0,0 +1,73 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerDefinitionToDockerRunCommand(t *testing.T) {
+	testCases := []struct {
+		name      string
+		container *ContainerDefinition
+		command   string
+	}{
+		{
+			name:      "minimal",
+			container: &ContainerDefinition{Image: aws.String("busybox")},
+			command:   "docker run busybox",
+		},
+		{
+			name: "fully populated",
+			container: &ContainerDefinition{
+				Image:      aws.String("123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.2"),
+				Command:    aws.StringSlice([]string{"--port", "8080", "--greeting", "it's up"}),
+				EntryPoint: aws.StringSlice([]string{"/usr/bin/dumb-init", "--", "/app/server"}),
+				Environment: []*KeyValuePair{
+					{Name: aws.String("STAGE"), Value: aws.String("prod")},
+					{Name: aws.String("OPTS"), Value: aws.String("-Xmx512m -Xms256m")},
+				},
+				PortMappings: []*PortMapping{
+					{ContainerPort: aws.Int64(8080), HostPort: aws.Int64(80), Protocol: aws.String(TransportProtocolTcp)},
+					{ContainerPort: aws.Int64(53), Protocol: aws.String(TransportProtocolUdp)},
+				},
+				MountPoints: []*MountPoint{
+					{SourceVolume: aws.String("data"), ContainerPath: aws.String("/var/lib/data")},
+					{SourceVolume: aws.String("config"), ContainerPath: aws.String("/etc/web"), ReadOnly: aws.Bool(true)},
+				},
+				Cpu:    aws.Int64(256),
+				Memory: aws.Int64(512),
+				User:   aws.String("1000:1000"),
+			},
+			command: "docker run --cpu-shares 256 --memory 512m --user 1000:1000 " +
+				"-e STAGE=prod -e 'OPTS=-Xmx512m -Xms256m' " +
+				"-p 80:8080 -p 53/udp " +
+				"-v data:/var/lib/data -v config:/etc/web:ro " +
+				"--entrypoint /usr/bin/dumb-init " +
+				"123456789012.dkr.ecr.us-west-2.amazonaws.com/web:1.2 " +
+				`-- /app/server --port 8080 --greeting 'it'\''s up'`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.command, tc.container.ToDockerRunCommand())
+		})
+	}
+}
@@ -0,0 +1,751 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// The SDK's request package only provides validation errors for the
+// constraints its code generator understands (required fields and minimums).
+// The errors below cover the additional constraints enforced by the ECS API
+// and satisfy request.ErrInvalidParam, so that they can be added to a
+// request.ErrInvalidParams alongside the generated ones.
+
+const (
+	// ParamMaxLenErrCode is the error code for fields exceeding a maximum length
+	ParamMaxLenErrCode = "ParamMaxLenError"
+	// ParamPatternErrCode is the error code for fields not matching the
+	// pattern of allowed values
+	ParamPatternErrCode = "ParamPatternError"
+	// ParamDependencyErrCode is the error code for fields that can only be
+	// set together with another field
+	ParamDependencyErrCode = "ParamDependencyError"
+	// ParamMaxValueErrCode is the error code for fields exceeding a maximum
+	// value
+	ParamMaxValueErrCode = "ParamMaxValueError"
+	// ParamEnumErrCode is the error code for fields set to a value that is
+	// not one of the allowed values
+	ParamEnumErrCode = "ParamEnumError"
+	// ParamImmutableErrCode is the error code for fields set to change a
+	// value that cannot change once the resource is created
+	ParamImmutableErrCode = "ParamImmutableError"
+	// ParamSelfReferenceErrCode is the error code for fields referring to
+	// the resource they belong to
+	ParamSelfReferenceErrCode = "ParamSelfReferenceError"
+)
+
+// startedByPattern matches the characters allowed in a startedBy value
+var startedByPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]*$`)
+
+// maxStartedByLen is the maximum length of a startedBy value
+const maxStartedByLen = 36
+
+// familyPattern matches the characters allowed in a task definition family
+var familyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]*$`)
+
+// maxFamilyLen is the maximum length of a task definition family
+const maxFamilyLen = 255
+
+// LaunchTypeFargateSpot is the name of the Fargate Spot capacity provider. It
+// is not a value of the LaunchType enum: Fargate Spot is only reachable
+// through a capacity provider strategy. It is accepted as the launch type of
+// a CreateServiceInput by ValidateParams, which leaves the API to reject it,
+// and is reported by CreateServiceInput.ValidationWarnings.
+const LaunchTypeFargateSpot = "FARGATE_SPOT"
+
+// launchTypeValues are the values of the LaunchType enum
+var launchTypeValues = []string{LaunchTypeEc2, LaunchTypeFargate, LaunchTypeExternal}
+
+// propagateTagsValues are the values of the PropagateTags enum
+var propagateTagsValues = []string{PropagateTagsTaskDefinition, PropagateTagsService, PropagateTagsNone}
+
+const (
+	// FilterNameStatus filters container instances by status
+	FilterNameStatus = "status"
+	// FilterNameAgentConnected filters container instances by whether their
+	// agent is connected, with the values "true" and "false"
+	FilterNameAgentConnected = "agentConnected"
+)
+
+// filterNameValues are the names a Filter can have
+var filterNameValues = []string{FilterNameStatus, FilterNameAgentConnected}
+
+// taskDefinitionFamilyStatusValues are the values of the
+// TaskDefinitionFamilyStatus enum
+var taskDefinitionFamilyStatusValues = []string{
+	TaskDefinitionFamilyStatusActive,
+	TaskDefinitionFamilyStatusInactive,
+	TaskDefinitionFamilyStatusAll,
+}
+
+// containerInstanceFieldValues are the values of the ContainerInstanceField
+// enum
+var containerInstanceFieldValues = []string{ContainerInstanceFieldTags, ContainerInstanceFieldContainerInstanceHealth}
+
+type errInvalidParam struct {
+	context       string
+	nestedContext string
+	field         string
+	code          string
+	msg           string
+}
+
+// Code returns the error code for the type of invalid parameter.
+func (e *errInvalidParam) Code() string {
+	return e.code
+}
+
+// Message returns the reason the parameter was invalid, and its context.
+func (e *errInvalidParam) Message() string {
+	return fmt.Sprintf("%s, %s.", e.msg, e.Field())
+}
+
+// Error returns the string version of the invalid parameter error.
+func (e *errInvalidParam) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.Message())
+}
+
+// OrigErr returns nil, Implemented for awserr.Error interface.
+func (e *errInvalidParam) OrigErr() error {
+	return nil
+}
+
+// Field Returns the field and context the error occurred.
+func (e *errInvalidParam) Field() string {
+	field := e.context
+	if len(field) > 0 {
+		field += "."
+	}
+	if len(e.nestedContext) > 0 {
+		field += fmt.Sprintf("%s.", e.nestedContext)
+	}
+	field += e.field
+
+	return field
+}
+
+// SetContext updates the base context of the error.
+func (e *errInvalidParam) SetContext(ctx string) {
+	e.context = ctx
+}
+
+// AddNestedContext prepends a context to the field's path.
+func (e *errInvalidParam) AddNestedContext(ctx string) {
+	if len(e.nestedContext) == 0 {
+		e.nestedContext = ctx
+	} else {
+		e.nestedContext = fmt.Sprintf("%s.%s", ctx, e.nestedContext)
+	}
+}
+
+// An ErrParamMaxLen represents a maximum length parameter error.
+type ErrParamMaxLen struct {
+	errInvalidParam
+	max int
+}
+
+// NewErrParamMaxLen creates a new maximum length parameter error.
+func NewErrParamMaxLen(field string, max int) *ErrParamMaxLen {
+	return &ErrParamMaxLen{
+		errInvalidParam: errInvalidParam{
+			code:  ParamMaxLenErrCode,
+			field: field,
+			msg:   fmt.Sprintf("maximum field size of %v", max),
+		},
+		max: max,
+	}
+}
+
+// MaxLen returns the field's allowed maximum length.
+func (e *ErrParamMaxLen) MaxLen() int {
+	return e.max
+}
+
+// An ErrParamMaxValue represents a maximum value parameter error.
+type ErrParamMaxValue struct {
+	errInvalidParam
+	max float64
+}
+
+// NewErrParamMaxValue creates a new maximum value parameter error.
+func NewErrParamMaxValue(field string, max float64) *ErrParamMaxValue {
+	return &ErrParamMaxValue{
+		errInvalidParam: errInvalidParam{
+			code:  ParamMaxValueErrCode,
+			field: field,
+			msg:   fmt.Sprintf("maximum field value of %v", max),
+		},
+		max: max,
+	}
+}
+
+// MaxValue returns the field's allowed maximum value.
+func (e *ErrParamMaxValue) MaxValue() float64 {
+	return e.max
+}
+
+// An ErrParamPattern represents a parameter not matching its pattern.
+type ErrParamPattern struct {
+	errInvalidParam
+	pattern string
+	invalid string
+}
+
+// NewErrParamPattern creates a new pattern parameter error.
+func NewErrParamPattern(field string, pattern string) *ErrParamPattern {
+	return &ErrParamPattern{
+		errInvalidParam: errInvalidParam{
+			code:  ParamPatternErrCode,
+			field: field,
+			msg:   fmt.Sprintf("field must match pattern %s", pattern),
+		},
+		pattern: pattern,
+	}
+}
+
+// Pattern returns the field's required pattern.
+func (e *ErrParamPattern) Pattern() string {
+	return e.pattern
+}
+
+// newErrParamPatternCharacters creates a new pattern parameter error naming
+// the characters of value that the pattern, a single character class, does
+// not allow
+func newErrParamPatternCharacters(field string, pattern *regexp.Regexp, value string) *ErrParamPattern {
+	var invalid []rune
+	for _, r := range value {
+		if !pattern.MatchString(string(r)) && !strings.ContainsRune(string(invalid), r) {
+			invalid = append(invalid, r)
+		}
+	}
+	err := NewErrParamPattern(field, pattern.String())
+	err.invalid = string(invalid)
+	err.msg += fmt.Sprintf(", invalid characters %q", err.invalid)
+	return err
+}
+
+// InvalidCharacters returns the characters of the field that its pattern
+// does not allow, once each in order of appearance, when they are known.
+func (e *ErrParamPattern) InvalidCharacters() string {
+	return e.invalid
+}
+
+// An ErrParamDependency represents a parameter that was set without the
+// parameter it depends on.
+type ErrParamDependency struct {
+	errInvalidParam
+	dependency string
+}
+
+// NewErrParamDependency creates a new dependency parameter error.
+func NewErrParamDependency(field string, dependency string) *ErrParamDependency {
+	return &ErrParamDependency{
+		errInvalidParam: errInvalidParam{
+			code:  ParamDependencyErrCode,
+			field: field,
+			msg:   fmt.Sprintf("%s must also be set", dependency),
+		},
+		dependency: dependency,
+	}
+}
+
+// Dependency returns the field that must be set along with the field.
+func (e *ErrParamDependency) Dependency() string {
+	return e.dependency
+}
+
+// An ErrParamEnum represents a parameter set to a value outside of its enum.
+type ErrParamEnum struct {
+	errInvalidParam
+	values []string
+}
+
+// NewErrParamEnum creates a new enum parameter error.
+func NewErrParamEnum(field string, values []string) *ErrParamEnum {
+	return &ErrParamEnum{
+		errInvalidParam: errInvalidParam{
+			code:  ParamEnumErrCode,
+			field: field,
+			msg:   fmt.Sprintf("field must be one of %s", strings.Join(values, ", ")),
+		},
+		values: values,
+	}
+}
+
+// Values returns the field's allowed values.
+func (e *ErrParamEnum) Values() []string {
+	return e.values
+}
+
+// An ErrParamImmutable represents a parameter set to change a value that
+// cannot change once the resource is created.
+type ErrParamImmutable struct {
+	errInvalidParam
+	current string
+}
+
+// NewErrParamImmutable creates a new immutable parameter error.
+func NewErrParamImmutable(field string, current string) *ErrParamImmutable {
+	return &ErrParamImmutable{
+		errInvalidParam: errInvalidParam{
+			code:  ParamImmutableErrCode,
+			field: field,
+			msg:   fmt.Sprintf("field cannot change from %s", current),
+		},
+		current: current,
+	}
+}
+
+// Current returns the field's current value.
+func (e *ErrParamImmutable) Current() string {
+	return e.current
+}
+
+// An ErrParamSelfReference represents a parameter referring to the resource
+// it belongs to.
+type ErrParamSelfReference struct {
+	errInvalidParam
+	name string
+}
+
+// NewErrParamSelfReference creates a new self reference parameter error.
+func NewErrParamSelfReference(field string, name string) *ErrParamSelfReference {
+	return &ErrParamSelfReference{
+		errInvalidParam: errInvalidParam{
+			code:  ParamSelfReferenceErrCode,
+			field: field,
+			msg:   fmt.Sprintf("field cannot refer to %s itself", name),
+		},
+		name: name,
+	}
+}
+
+// Name returns the name of the resource the field refers to.
+func (e *ErrParamSelfReference) Name() string {
+	return e.name
+}
+
+// isEnumValue returns true if value is one of values
+func isEnumValue(value string, values []string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// customValidator is implemented by the types with constraints that cannot
+// be expressed in the API model, and so are not checked by their generated
+// Validate. validateCustom adds the violations of these constraints to
+// invalidParams.
+type customValidator interface {
+	validateCustom(invalidParams *request.ErrInvalidParams)
+}
+
+// validateParamsHandler replaces the SDK's core.ValidateParametersHandler on
+// the requests of the client, to check the constraints of customValidator
+// along with the ones of the API model.
+var validateParamsHandler = request.NamedHandler{Name: "ecs.ValidateParamsHandler", Fn: func(r *request.Request) {
+	if !r.ParamsFilled() {
+		return
+	}
+	if err := ValidateParams(r.Params); err != nil {
+		r.Error = err
+	}
+}}
+
+// ValidateParams validates params, the input of an operation or one of its
+// nested types, as the client does before sending a request: against the
+// constraints of the API model, with its generated Validate, and against the
+// constraints that the model cannot express. It returns a
+// request.ErrInvalidParams with all the violations.
+func ValidateParams(params interface{}) error {
+	var custom func(*request.ErrInvalidParams)
+	if v, ok := params.(customValidator); ok {
+		custom = v.validateCustom
+	}
+	return validateParamsWith(params, custom)
+}
+
+// validateParamsWith validates params with its generated Validate, if any,
+// and custom, if not nil
+func validateParamsWith(params interface{}, custom func(*request.ErrInvalidParams)) error {
+	if params == nil {
+		return nil
+	}
+	invalidParams := request.ErrInvalidParams{Context: reflect.Indirect(reflect.ValueOf(params)).Type().Name()}
+	if v, ok := params.(request.Validator); ok {
+		if err := v.Validate(); err != nil {
+			generated, ok := err.(request.ErrInvalidParams)
+			if !ok {
+				return err
+			}
+			invalidParams = generated
+		}
+	}
+	if custom != nil {
+		custom(&invalidParams)
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// addNestedCustom adds the violations of the custom constraints of nested,
+// the value of field, to invalidParams
+func addNestedCustom(invalidParams *request.ErrInvalidParams, field string, nested customValidator) {
+	nestedParams := request.ErrInvalidParams{Context: reflect.Indirect(reflect.ValueOf(nested)).Type().Name()}
+	nested.validateCustom(&nestedParams)
+	if nestedParams.Len() > 0 {
+		invalidParams.AddNested(field, nestedParams)
+	}
+}
+
+// maxTags is the maximum number of tags of a resource
+const maxTags = 50
+
+// validateCustom implements customValidator
+func (s *CreateClusterInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if len(s.Tags) > maxTags {
+		invalidParams.Add(NewErrParamMaxLen("Tags", maxTags))
+	}
+}
+
+// validateCustom implements customValidator
+func (s *DescribeContainerInstancesInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	for i, v := range s.Include {
+		if v != nil && !isEnumValue(*v, containerInstanceFieldValues) {
+			invalidParams.Add(NewErrParamEnum(fmt.Sprintf("%s[%v]", "Include", i), containerInstanceFieldValues))
+		}
+	}
+}
+
+// validateCustom implements customValidator
+func (s *Filter) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.Name == nil {
+		invalidParams.Add(request.NewErrParamRequired("Name"))
+	}
+	if s.Name != nil && !isEnumValue(*s.Name, filterNameValues) {
+		invalidParams.Add(NewErrParamEnum("Name", filterNameValues))
+	}
+}
+
+// validateCustom implements customValidator
+func (s *ListTaskDefinitionFamiliesInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.Status != nil && !isEnumValue(*s.Status, taskDefinitionFamilyStatusValues) {
+		invalidParams.Add(NewErrParamEnum("Status", taskDefinitionFamilyStatusValues))
+	}
+}
+
+// validateCustom implements customValidator. The maximum percent of the
+// deployment configuration cannot be below its minimum healthy percent.
+func (s *DeploymentConfiguration) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.MaximumPercent != nil && s.MinimumHealthyPercent != nil && *s.MaximumPercent < *s.MinimumHealthyPercent {
+		invalidParams.Add(request.NewErrParamMinValue("MaximumPercent", float64(*s.MinimumHealthyPercent)))
+	}
+}
+
+// validateCustom implements customValidator
+func (s *CreateServiceInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.LaunchType != nil && *s.LaunchType != LaunchTypeFargateSpot && !isEnumValue(*s.LaunchType, launchTypeValues) {
+		invalidParams.Add(NewErrParamEnum("LaunchType", launchTypeValues))
+	}
+	if s.PropagateTags != nil && !isEnumValue(*s.PropagateTags, propagateTagsValues) {
+		invalidParams.Add(NewErrParamEnum("PropagateTags", propagateTagsValues))
+	}
+	if s.DeploymentConfiguration != nil {
+		addNestedCustom(invalidParams, "DeploymentConfiguration", s.DeploymentConfiguration)
+	}
+}
+
+// validateCustom implements customValidator
+func (s *UpdateServiceInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.DeploymentConfiguration != nil {
+		addNestedCustom(invalidParams, "DeploymentConfiguration", s.DeploymentConfiguration)
+	}
+}
+
+// ValidationWarnings returns the problems of the input that do not make
+// ValidateParams fail, but are likely mistakes
+func (s *CreateServiceInput) ValidationWarnings() []string {
+	var warnings []string
+	if s.LaunchType != nil && *s.LaunchType == LaunchTypeFargateSpot {
+		warnings = append(warnings, fmt.Sprintf("CreateServiceInput.LaunchType: %s is a capacity provider, not a launch type; "+
+			"use a capacity provider strategy to run the service on Fargate Spot", LaunchTypeFargateSpot))
+	}
+	return warnings
+}
+
+// ValidationWarnings returns the problems of the input that do not make
+// ValidateParams fail, but are likely mistakes
+func (s *UpdateServiceInput) ValidationWarnings() []string {
+	var warnings []string
+	if s.EnableExecuteCommand != nil && !aws.BoolValue(s.ForceNewDeployment) {
+		warnings = append(warnings, "UpdateServiceInput.EnableExecuteCommand: only applies to tasks started by a new deployment; "+
+			"set ForceNewDeployment to apply it to the tasks that are already running")
+	}
+	return warnings
+}
+
+// ValidateAgainstExisting inspects the update against the current service,
+// as described before the update, for what cannot change: schedulingStrategy,
+// the scheduling strategy the service is meant to have, is not a parameter of
+// UpdateService and must match the current one. It is separate from
+// ValidateParams, which runs without the current service.
+func (s *UpdateServiceInput) ValidateAgainstExisting(current *Service, schedulingStrategy *string) error {
+	invalidParams := request.ErrInvalidParams{Context: "UpdateServiceInput"}
+	if current != nil && schedulingStrategy != nil && current.SchedulingStrategy != nil &&
+		*schedulingStrategy != *current.SchedulingStrategy {
+		invalidParams.Add(NewErrParamImmutable("SchedulingStrategy", *current.SchedulingStrategy))
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// maxGroupLen is the maximum length of the group of a task
+const maxGroupLen = 255
+
+// maxReferenceIdLen is the maximum length of the reference ID of a task
+const maxReferenceIdLen = 64
+
+// validateCustom implements customValidator
+func (s *RunTaskInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.Count != nil && *s.Count > maxRunTaskCount {
+		invalidParams.Add(NewErrParamMaxValue("Count", maxRunTaskCount))
+	}
+	if s.Group != nil && len(*s.Group) > maxGroupLen {
+		invalidParams.Add(NewErrParamMaxLen("Group", maxGroupLen))
+	}
+	if s.LaunchType != nil && !isEnumValue(*s.LaunchType, launchTypeValues) {
+		invalidParams.Add(NewErrParamEnum("LaunchType", launchTypeValues))
+	}
+	if s.ReferenceId != nil && len(*s.ReferenceId) > maxReferenceIdLen {
+		invalidParams.Add(NewErrParamMaxLen("ReferenceId", maxReferenceIdLen))
+	}
+	if len(s.Tags) > maxTags {
+		invalidParams.Add(NewErrParamMaxLen("Tags", maxTags))
+	}
+}
+
+// validateCustom implements customValidator
+func (s *StartTaskInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if len(s.Tags) > maxTags {
+		invalidParams.Add(NewErrParamMaxLen("Tags", maxTags))
+	}
+}
+
+// validateCustom implements customValidator. Exit codes are not negative.
+func (s *SubmitContainerStateChangeInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.ExitCode != nil && *s.ExitCode < 0 {
+		invalidParams.Add(request.NewErrParamMinValue("ExitCode", 0))
+	}
+}
+
+// maxReasonLen is the number of characters of a task state change reason
+// that the API keeps
+const maxReasonLen = 255
+
+// ErrReasonTruncated is returned by TruncateReason when the reason of a task
+// state change was longer than the API keeps.
+type ErrReasonTruncated struct {
+	// Reason is the reason as given, before it was truncated
+	Reason string
+}
+
+func (err *ErrReasonTruncated) Error() string {
+	return fmt.Sprintf("SubmitTaskStateChangeInput.Reason: truncated from %d to %d characters",
+		utf8.RuneCountInString(err.Reason), maxReasonLen)
+}
+
+// TruncateReason truncates the Reason of the input to the 255 characters the
+// API keeps, which would otherwise drop the rest silently. It returns an
+// *ErrReasonTruncated if the reason was truncated, as a warning.
+// ValidateParams does not check the length of the reason, as the API keeps
+// the start of a reason that is too long rather than rejecting it.
+func (s *SubmitTaskStateChangeInput) TruncateReason() error {
+	if s.Reason == nil || utf8.RuneCountInString(*s.Reason) <= maxReasonLen {
+		return nil
+	}
+	reason := *s.Reason
+	s.Reason = aws.String(string([]rune(reason)[:maxReasonLen]))
+	return &ErrReasonTruncated{Reason: reason}
+}
+
+// validateCustom implements customValidator
+func (s *ListTasksInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.StartedBy != nil && len(*s.StartedBy) > maxStartedByLen {
+		invalidParams.Add(NewErrParamMaxLen("StartedBy", maxStartedByLen))
+	}
+	if s.StartedBy != nil && !startedByPattern.MatchString(*s.StartedBy) {
+		invalidParams.Add(NewErrParamPattern("StartedBy", startedByPattern.String()))
+	}
+}
+
+// validateCustom implements customValidator. Unlike the reason of a task state
+// change, the reason a task is stopped with is rejected if it is too long.
+func (s *StopTaskInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if s.Reason != nil && len(*s.Reason) > maxReasonLen {
+		invalidParams.Add(NewErrParamMaxLen("Reason", maxReasonLen))
+	}
+}
+
+// validateCustom implements customValidator. An interactive container needs a
+// pseudo terminal.
+func (s *ContainerDefinition) validateCustom(invalidParams *request.ErrInvalidParams) {
+	if aws.BoolValue(s.Interactive) && !aws.BoolValue(s.PseudoTerminal) {
+		invalidParams.Add(NewErrParamDependency("Interactive", "PseudoTerminal"))
+	}
+}
+
+// ValidateAgainstSelf inspects the fields of the container definition that
+// refer to other containers of its task definition, which cannot name the
+// container itself. Only VolumesFrom is checked.
+func (s *ContainerDefinition) ValidateAgainstSelf() error {
+	invalidParams := request.ErrInvalidParams{Context: "ContainerDefinition"}
+	if s.Name != nil {
+		for i, volumesFrom := range s.VolumesFrom {
+			if volumesFrom != nil && aws.StringValue(volumesFrom.SourceContainer) == *s.Name {
+				invalidParams.Add(NewErrParamSelfReference(fmt.Sprintf("VolumesFrom[%v].SourceContainer", i), *s.Name))
+			}
+		}
+	}
+
+	if invalidParams.Len() > 0 {
+		return invalidParams
+	}
+	return nil
+}
+
+// validateCustom implements customValidator. It requires the fields that a
+// dry run, with ValidateTaskDefinition, may leave out but a registration
+// needs.
+func (s *RegisterTaskDefinitionInput) validateCustom(invalidParams *request.ErrInvalidParams) {
+	s.validateDefinition(invalidParams)
+	for _, field := range s.missingFargateFields() {
+		invalidParams.Add(NewErrParamDependency("RequiresCompatibilities", field))
+	}
+}
+
+// validateDefinition adds the violations of the custom constraints that also
+// apply to dry runs to invalidParams
+func (s *RegisterTaskDefinitionInput) validateDefinition(invalidParams *request.ErrInvalidParams) {
+	for i, container := range s.ContainerDefinitions {
+		if container != nil {
+			addNestedCustom(invalidParams, fmt.Sprintf("%s[%v]", "ContainerDefinitions", i), container)
+		}
+	}
+	if s.Family != nil && len(*s.Family) > maxFamilyLen {
+		invalidParams.Add(NewErrParamMaxLen("Family", maxFamilyLen))
+	}
+	if s.Family != nil && !familyPattern.MatchString(*s.Family) {
+		invalidParams.Add(newErrParamPatternCharacters("Family", familyPattern, *s.Family))
+	}
+}
+
+// ValidationWarnings returns the problems of the input that are likely
+// mistakes. Fields that a dry run may leave out but a registration needs are
+// reported here, as ValidateTaskDefinition does not fail on them.
+func (s *RegisterTaskDefinitionInput) ValidationWarnings() []string {
+	var warnings []string
+	for _, field := range s.missingFargateFields() {
+		warnings = append(warnings, fmt.Sprintf("RegisterTaskDefinitionInput.%s: must be set for tasks that require %s; "+
+			"registering this task definition would fail", field, CompatibilityFargate))
+	}
+	return warnings
+}
+
+// missingFargateFields returns the fields the input must set, but does not,
+// because it requires the Fargate launch type
+func (s *RegisterTaskDefinitionInput) missingFargateFields() []string {
+	if !s.requiresFargate() {
+		return nil
+	}
+	var missing []string
+	if s.Cpu == nil {
+		missing = append(missing, "Cpu")
+	}
+	if s.Memory == nil {
+		missing = append(missing, "Memory")
+	}
+	return missing
+}
+
+// requiresFargate returns true if the input requires the Fargate launch type
+func (s *RegisterTaskDefinitionInput) requiresFargate() bool {
+	for _, compatibility := range s.RequiresCompatibilities {
+		if compatibility != nil && *compatibility == CompatibilityFargate {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateCompatibilityFields returns a message for each field of the input
+// that its RequiresCompatibilities rule out, such as
+// "ContainerDefinitions[0].LinuxParameters.Devices is not supported with
+// FARGATE compatibility". The API rejects these task definitions, which
+// ValidateParams does not check.
+func ValidateCompatibilityFields(input *RegisterTaskDefinitionInput) []string {
+	if input == nil || !input.requiresFargate() {
+		return nil
+	}
+	var violations []string
+	unsupported := func(field string) {
+		violations = append(violations, fmt.Sprintf("%s is not supported with %s compatibility", field, CompatibilityFargate))
+	}
+	if input.NetworkMode != nil && *input.NetworkMode != NetworkModeAwsvpc {
+		violations = append(violations, fmt.Sprintf("NetworkMode %s is not supported with %s compatibility, which requires %s",
+			*input.NetworkMode, CompatibilityFargate, NetworkModeAwsvpc))
+	}
+	if input.IpcMode != nil {
+		unsupported("IpcMode")
+	}
+	if len(input.PlacementConstraints) > 0 {
+		unsupported("PlacementConstraints")
+	}
+	for i, volume := range input.Volumes {
+		if volume != nil && volume.Host != nil && volume.Host.SourcePath != nil {
+			unsupported(fmt.Sprintf("Volumes[%d].Host.SourcePath", i))
+		}
+	}
+	for i, container := range input.ContainerDefinitions {
+		if container == nil {
+			continue
+		}
+		if container.Privileged != nil && *container.Privileged {
+			unsupported(fmt.Sprintf("ContainerDefinitions[%d].Privileged", i))
+		}
+		if len(container.Links) > 0 {
+			unsupported(fmt.Sprintf("ContainerDefinitions[%d].Links", i))
+		}
+		if container.LinuxParameters != nil && len(container.LinuxParameters.Devices) > 0 {
+			unsupported(fmt.Sprintf("ContainerDefinitions[%d].LinuxParameters.Devices", i))
+		}
+	}
+	return violations
+}
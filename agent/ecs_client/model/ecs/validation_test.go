@@ -0,0 +1,910 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTasksInputValidateStartedBy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		startedBy *string
+		errCodes  []string
+	}{
+		{"unset", nil, nil},
+		{"uuid", aws.String("8d3c2c0e-3d9a-4c5b-9f3e-0123456789ab"), nil},
+		{"underscore", aws.String("batch_job-1"), nil},
+		{"36 characters", aws.String(strings.Repeat("a", 36)), nil},
+		{"empty", aws.String(""), []string{request.ParamMinLenErrCode}},
+		{"37 characters", aws.String(strings.Repeat("a", 37)), []string{ParamMaxLenErrCode}},
+		{"invalid characters", aws.String("ecs-svc/1234"), []string{ParamPatternErrCode}},
+		{"too long and invalid", aws.String(strings.Repeat("a", 36) + " "), []string{ParamMaxLenErrCode, ParamPatternErrCode}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&ListTasksInput{StartedBy: tc.startedBy})
+			if len(tc.errCodes) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, len(tc.errCodes), invalidParams.Len())
+			for i, code := range tc.errCodes {
+				assert.Equal(t, code, invalidParams.OrigErrs()[i].(awserr.Error).Code())
+			}
+		})
+	}
+}
+
+func TestListTasksInputValidateStartedByMessage(t *testing.T) {
+	err := ValidateParams(&ListTasksInput{StartedBy: aws.String("a b")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ListTasksInput.StartedBy")
+}
+
+func TestUpdateServiceInputValidateEnableExecuteCommand(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		enableExecuteCommand *bool
+		forceNewDeployment   *bool
+		warned               bool
+	}{
+		{"unset", nil, nil, false},
+		{"enabled with new deployment", aws.Bool(true), aws.Bool(true), false},
+		{"disabled with new deployment", aws.Bool(false), aws.Bool(true), false},
+		{"enabled without new deployment", aws.Bool(true), nil, true},
+		{"disabled without new deployment", aws.Bool(false), aws.Bool(false), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &UpdateServiceInput{
+				Service:              aws.String("service"),
+				EnableExecuteCommand: tc.enableExecuteCommand,
+				ForceNewDeployment:   tc.forceNewDeployment,
+			}
+			assert.NoError(t, input.Validate(), "changing ECS Exec without a new deployment is allowed")
+			warnings := input.ValidationWarnings()
+			if !tc.warned {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Contains(t, warnings[0], "UpdateServiceInput.EnableExecuteCommand")
+			assert.Contains(t, warnings[0], "ForceNewDeployment")
+		})
+	}
+}
+
+func TestSubmitContainerStateChangeInputValidateExitCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		exitCode *int64
+		errCode  string
+	}{
+		{"unset", nil, ""},
+		{"0", aws.Int64(0), ""},
+		{"255", aws.Int64(255), ""},
+		{"256", aws.Int64(256), ""},
+		{"windows service error", aws.Int64(1067), ""},
+		{"windows ctrl-c", aws.Int64(0xC000013A), ""},
+		{"-1", aws.Int64(-1), request.ParamMinValueErrCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&SubmitContainerStateChangeInput{ExitCode: tc.exitCode})
+			if tc.errCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			assert.Equal(t, tc.errCode, invalidParams.OrigErrs()[0].(awserr.Error).Code())
+		})
+	}
+}
+
+func TestSubmitTaskStateChangeInputValidateAttachments(t *testing.T) {
+	input := &SubmitTaskStateChangeInput{
+		Attachments: []*AttachmentStateChange{
+			{AttachmentArn: aws.String("attachment0"), Status: aws.String("ATTACHED")},
+			{Status: aws.String("ATTACHED")},
+		},
+	}
+
+	err := input.Validate()
+	require.Error(t, err)
+	invalidParams, ok := err.(request.ErrInvalidParams)
+	require.True(t, ok)
+	require.Equal(t, 1, invalidParams.Len())
+	paramErr := invalidParams.OrigErrs()[0].(request.ErrInvalidParam)
+	assert.Equal(t, request.ParamRequiredErrCode, paramErr.Code())
+	assert.Equal(t, "SubmitTaskStateChangeInput.Attachments[1].AttachmentArn", paramErr.Field())
+
+	input.Attachments[1].AttachmentArn = aws.String("attachment1")
+	assert.NoError(t, input.Validate())
+}
+
+func TestSubmitTaskStateChangeInputValidateManagedAgents(t *testing.T) {
+	testCases := []struct {
+		name  string
+		agent *ManagedAgentStateChange
+		field string
+	}{
+		{
+			name: "missing container name",
+			agent: &ManagedAgentStateChange{
+				ManagedAgentName: aws.String(ManagedAgentNameExecuteCommandAgent),
+				Status:           aws.String("RUNNING"),
+			},
+			field: "SubmitTaskStateChangeInput.ManagedAgents[1].ContainerName",
+		},
+		{
+			name: "missing status",
+			agent: &ManagedAgentStateChange{
+				ContainerName:    aws.String("web"),
+				ManagedAgentName: aws.String(ManagedAgentNameExecuteCommandAgent),
+				Reason:           aws.String("agent exited"),
+			},
+			field: "SubmitTaskStateChangeInput.ManagedAgents[1].Status",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &SubmitTaskStateChangeInput{
+				ManagedAgents: []*ManagedAgentStateChange{
+					{
+						ContainerName:    aws.String("sidecar"),
+						ManagedAgentName: aws.String(ManagedAgentNameExecuteCommandAgent),
+						Status:           aws.String("STOPPED"),
+					},
+					tc.agent,
+				},
+			}
+
+			err := input.Validate()
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr := invalidParams.OrigErrs()[0].(request.ErrInvalidParam)
+			assert.Equal(t, request.ParamRequiredErrCode, paramErr.Code())
+			assert.Equal(t, tc.field, paramErr.Field())
+		})
+	}
+
+	assert.NoError(t, (&SubmitTaskStateChangeInput{
+		ManagedAgents: []*ManagedAgentStateChange{{
+			ContainerName:    aws.String("web"),
+			ManagedAgentName: aws.String(ManagedAgentNameExecuteCommandAgent),
+			Status:           aws.String("RUNNING"),
+		}},
+	}).Validate())
+}
+
+func TestRunTaskInputValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   *RunTaskInput
+		field   string
+		errCode string
+	}{
+		{
+			name:  "valid",
+			input: &RunTaskInput{TaskDefinition: aws.String("family"), Count: aws.Int64(10), LaunchType: aws.String(LaunchTypeExternal)},
+		},
+		{
+			name:    "missing task definition",
+			input:   &RunTaskInput{},
+			field:   "TaskDefinition",
+			errCode: request.ParamRequiredErrCode,
+		},
+		{
+			name:    "empty task definition",
+			input:   &RunTaskInput{TaskDefinition: aws.String("")},
+			field:   "TaskDefinition",
+			errCode: request.ParamMinLenErrCode,
+		},
+		{
+			name:    "unknown launch type",
+			input:   &RunTaskInput{TaskDefinition: aws.String("family"), LaunchType: aws.String("LAMBDA")},
+			field:   "LaunchType",
+			errCode: ParamEnumErrCode,
+		},
+		{
+			name:    "count too low",
+			input:   &RunTaskInput{TaskDefinition: aws.String("family"), Count: aws.Int64(0)},
+			field:   "Count",
+			errCode: request.ParamMinValueErrCode,
+		},
+		{
+			name:    "count too high",
+			input:   &RunTaskInput{TaskDefinition: aws.String("family"), Count: aws.Int64(11)},
+			field:   "Count",
+			errCode: ParamMaxValueErrCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(tc.input)
+			if tc.errCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr := invalidParams.OrigErrs()[0].(request.ErrInvalidParam)
+			assert.Equal(t, tc.errCode, paramErr.Code())
+			assert.Equal(t, "RunTaskInput."+tc.field, paramErr.Field())
+		})
+	}
+}
+
+func TestRunTaskInputValidateGroup(t *testing.T) {
+	testCases := []struct {
+		name  string
+		group *string
+		valid bool
+	}{
+		{"unset", nil, true},
+		{"empty", aws.String(""), true},
+		{"255 characters", aws.String(strings.Repeat("g", 255)), true},
+		{"256 characters", aws.String(strings.Repeat("g", 256)), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&RunTaskInput{TaskDefinition: aws.String("family"), Group: tc.group})
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamMaxLen)
+			require.True(t, ok)
+			assert.Equal(t, "RunTaskInput.Group", paramErr.Field())
+			assert.Equal(t, 255, paramErr.MaxLen())
+		})
+	}
+}
+
+func TestRunTaskInputValidateReferenceId(t *testing.T) {
+	testCases := []struct {
+		name        string
+		referenceId *string
+		valid       bool
+	}{
+		{"unset", nil, true},
+		{"64 characters", aws.String(strings.Repeat("r", 64)), true},
+		{"65 characters", aws.String(strings.Repeat("r", 65)), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &RunTaskInput{TaskDefinition: aws.String("family")}
+			if tc.referenceId != nil {
+				input.SetReferenceId(*tc.referenceId)
+			}
+			err := ValidateParams(input)
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamMaxLen)
+			require.True(t, ok)
+			assert.Equal(t, "RunTaskInput.ReferenceId", paramErr.Field())
+			assert.Equal(t, 64, paramErr.MaxLen())
+		})
+	}
+}
+
+func TestRunTaskInputValidateLaunchTypeMessage(t *testing.T) {
+	err := ValidateParams(&RunTaskInput{TaskDefinition: aws.String("family"), LaunchType: aws.String("LAMBDA")})
+	require.Error(t, err)
+	paramErr, ok := err.(request.ErrInvalidParams).OrigErrs()[0].(*ErrParamEnum)
+	require.True(t, ok)
+	assert.Equal(t, []string{LaunchTypeEc2, LaunchTypeFargate, LaunchTypeExternal}, paramErr.Values())
+	assert.Contains(t, err.Error(), "field must be one of EC2, FARGATE, EXTERNAL, RunTaskInput.LaunchType")
+}
+
+func TestListTaskDefinitionFamiliesInputValidateStatus(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status *string
+		valid  bool
+	}{
+		{"unset", nil, true},
+		{"ACTIVE", aws.String(TaskDefinitionFamilyStatusActive), true},
+		{"INACTIVE", aws.String(TaskDefinitionFamilyStatusInactive), true},
+		{"ALL", aws.String(TaskDefinitionFamilyStatusAll), true},
+		{"unknown", aws.String("UNKNOWN"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&ListTaskDefinitionFamiliesInput{Status: tc.status})
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamEnum)
+			require.True(t, ok)
+			assert.Equal(t, "ListTaskDefinitionFamiliesInput.Status", paramErr.Field())
+			assert.Equal(t, []string{"ACTIVE", "INACTIVE", "ALL"}, paramErr.Values())
+		})
+	}
+}
+
+func TestCreateServiceInputValidatePropagateTags(t *testing.T) {
+	testCases := []struct {
+		name          string
+		propagateTags *string
+		errCode       string
+	}{
+		{"unset", nil, ""},
+		{"TASK_DEFINITION", aws.String(PropagateTagsTaskDefinition), ""},
+		{"SERVICE", aws.String(PropagateTagsService), ""},
+		{"NONE", aws.String(PropagateTagsNone), ""},
+		{"unknown", aws.String("CLUSTER"), ParamEnumErrCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&CreateServiceInput{
+				ServiceName:    aws.String("service"),
+				TaskDefinition: aws.String("family"),
+				PropagateTags:  tc.propagateTags,
+			})
+			if tc.errCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			assert.Equal(t, tc.errCode, invalidParams.OrigErrs()[0].(awserr.Error).Code())
+			assert.Contains(t, err.Error(), "CreateServiceInput.PropagateTags")
+		})
+	}
+}
+
+func TestCreateServiceInputValidateLaunchType(t *testing.T) {
+	testCases := []struct {
+		name       string
+		launchType *string
+		errCode    string
+		warned     bool
+	}{
+		{"unset", nil, "", false},
+		{"EC2", aws.String(LaunchTypeEc2), "", false},
+		{"FARGATE", aws.String(LaunchTypeFargate), "", false},
+		{"EXTERNAL", aws.String(LaunchTypeExternal), "", false},
+		{"FARGATE_SPOT", aws.String(LaunchTypeFargateSpot), "", true},
+		{"unknown", aws.String("LAMBDA"), ParamEnumErrCode, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &CreateServiceInput{
+				ServiceName:    aws.String("service"),
+				TaskDefinition: aws.String("family"),
+				LaunchType:     tc.launchType,
+			}
+			err := ValidateParams(input)
+			if tc.errCode == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				invalidParams, ok := err.(request.ErrInvalidParams)
+				require.True(t, ok)
+				require.Equal(t, 1, invalidParams.Len())
+				assert.Equal(t, tc.errCode, invalidParams.OrigErrs()[0].(awserr.Error).Code())
+			}
+
+			warnings := input.ValidationWarnings()
+			if !tc.warned {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Contains(t, warnings[0], "capacity provider strategy")
+		})
+	}
+}
+
+func TestDescribeContainerInstancesInputValidateInclude(t *testing.T) {
+	input := &DescribeContainerInstancesInput{
+		ContainerInstances: aws.StringSlice([]string{"instance"}),
+		Include:            aws.StringSlice([]string{ContainerInstanceFieldTags, ContainerInstanceFieldContainerInstanceHealth}),
+	}
+	assert.NoError(t, ValidateParams(input))
+
+	input.Include = aws.StringSlice([]string{ContainerInstanceFieldTags, "ATTRIBUTES"})
+	err := ValidateParams(input)
+	require.Error(t, err)
+	invalidParams, ok := err.(request.ErrInvalidParams)
+	require.True(t, ok)
+	require.Equal(t, 1, invalidParams.Len())
+	paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamEnum)
+	require.True(t, ok)
+	assert.Equal(t, "DescribeContainerInstancesInput.Include[1]", paramErr.Field())
+}
+
+func TestDeploymentConfigurationValidate(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		maximumPercent        *int64
+		minimumHealthyPercent *int64
+		valid                 bool
+	}{
+		{"100/50", aws.Int64(100), aws.Int64(50), true},
+		{"50/50", aws.Int64(50), aws.Int64(50), true},
+		{"49/50", aws.Int64(49), aws.Int64(50), false},
+		{"maximum only", aws.Int64(49), nil, true},
+		{"minimum only", nil, aws.Int64(50), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deploymentConfiguration := &DeploymentConfiguration{
+				MaximumPercent:        tc.maximumPercent,
+				MinimumHealthyPercent: tc.minimumHealthyPercent,
+			}
+			err := ValidateParams(deploymentConfiguration)
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			assert.Equal(t, request.InvalidParameterErrCode, invalidParams.Code())
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*request.ErrParamMinValue)
+			require.True(t, ok)
+			assert.Equal(t, "DeploymentConfiguration.MaximumPercent", paramErr.Field())
+			assert.Equal(t, float64(50), paramErr.MinValue())
+		})
+	}
+}
+
+func TestUpdateServiceInputValidateDeploymentConfiguration(t *testing.T) {
+	input := &UpdateServiceInput{
+		Service: aws.String("service"),
+		DeploymentConfiguration: &DeploymentConfiguration{
+			MaximumPercent:        aws.Int64(49),
+			MinimumHealthyPercent: aws.Int64(50),
+		},
+	}
+	err := ValidateParams(input)
+	require.Error(t, err)
+	paramErr := err.(request.ErrInvalidParams).OrigErrs()[0].(request.ErrInvalidParam)
+	assert.Equal(t, "UpdateServiceInput.DeploymentConfiguration.MaximumPercent", paramErr.Field())
+
+	input.DeploymentConfiguration.MaximumPercent = aws.Int64(200)
+	assert.NoError(t, ValidateParams(input))
+}
+
+func TestStopTaskInputValidateReason(t *testing.T) {
+	testCases := []struct {
+		name   string
+		reason *string
+		valid  bool
+	}{
+		{"unset", nil, true},
+		{"255 characters", aws.String(strings.Repeat("r", 255)), true},
+		{"256 characters", aws.String(strings.Repeat("r", 256)), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&StopTaskInput{Task: aws.String("task"), Reason: tc.reason})
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			assert.Equal(t, request.InvalidParameterErrCode, invalidParams.Code())
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamMaxLen)
+			require.True(t, ok)
+			assert.Equal(t, "StopTaskInput.Reason", paramErr.Field())
+			assert.Equal(t, 255, paramErr.MaxLen())
+		})
+	}
+}
+
+func TestRegisterTaskDefinitionInputValidateFargate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cpu     *string
+		memory  *string
+		missing []string
+	}{
+		{"complete", aws.String("256"), aws.String("512"), nil},
+		{"missing cpu", nil, aws.String("512"), []string{"Cpu"}},
+		{"missing both", nil, nil, []string{"Cpu", "Memory"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &RegisterTaskDefinitionInput{
+				Family:                  aws.String("family"),
+				ContainerDefinitions:    []*ContainerDefinition{},
+				RequiresCompatibilities: aws.StringSlice([]string{CompatibilityEc2, CompatibilityFargate}),
+				Cpu:                     tc.cpu,
+				Memory:                  tc.memory,
+			}
+			assert.Len(t, input.ValidationWarnings(), len(tc.missing))
+			assert.NoError(t, input.Validate(), "the generated validation does not know about Fargate")
+			err := ValidateParams(input)
+			if len(tc.missing) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, len(tc.missing), invalidParams.Len())
+			for i, field := range tc.missing {
+				paramErr, ok := invalidParams.OrigErrs()[i].(*ErrParamDependency)
+				require.True(t, ok)
+				assert.Equal(t, "RegisterTaskDefinitionInput.RequiresCompatibilities", paramErr.Field())
+				assert.Equal(t, field, paramErr.Dependency())
+			}
+		})
+	}
+}
+
+func TestRegisterTaskDefinitionInputValidateEc2(t *testing.T) {
+	input := &RegisterTaskDefinitionInput{
+		Family:               aws.String("family"),
+		ContainerDefinitions: []*ContainerDefinition{},
+	}
+	assert.NoError(t, ValidateParams(input), "cpu and memory are optional for the EC2 launch type")
+	assert.Empty(t, input.ValidationWarnings())
+}
+
+func TestRegisterTaskDefinitionInputValidateInferenceAccelerators(t *testing.T) {
+	testCases := []struct {
+		name        string
+		accelerator *InferenceAccelerator
+		field       string
+		code        string
+	}{
+		{"valid", &InferenceAccelerator{DeviceName: aws.String("device_1"), DeviceType: aws.String("eia1.medium")}, "", ""},
+		{"missing device name", &InferenceAccelerator{DeviceType: aws.String("eia1.medium")}, "DeviceName", request.ParamRequiredErrCode},
+		{"empty device name", &InferenceAccelerator{DeviceName: aws.String(""), DeviceType: aws.String("eia1.medium")}, "DeviceName", request.ParamMinLenErrCode},
+		{"missing device type", &InferenceAccelerator{DeviceName: aws.String("device_1")}, "DeviceType", request.ParamRequiredErrCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&RegisterTaskDefinitionInput{
+				Family:                aws.String("family"),
+				ContainerDefinitions:  []*ContainerDefinition{},
+				InferenceAccelerators: []*InferenceAccelerator{tc.accelerator},
+			}).Validate()
+			if tc.code == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(request.ErrInvalidParam)
+			require.True(t, ok)
+			assert.Equal(t, tc.code, paramErr.Code())
+			assert.Equal(t, "RegisterTaskDefinitionInput.InferenceAccelerators[0]."+tc.field, paramErr.Field())
+		})
+	}
+}
+
+func TestContainerDefinitionValidateInteractive(t *testing.T) {
+	testCases := []struct {
+		name           string
+		interactive    *bool
+		pseudoTerminal *bool
+		valid          bool
+	}{
+		{"both unset", nil, nil, true},
+		{"interactive unset, pseudo terminal", nil, aws.Bool(true), true},
+		{"interactive false, pseudo terminal false", aws.Bool(false), aws.Bool(false), true},
+		{"interactive false, pseudo terminal", aws.Bool(false), aws.Bool(true), true},
+		{"interactive, pseudo terminal", aws.Bool(true), aws.Bool(true), true},
+		{"interactive, pseudo terminal unset", aws.Bool(true), nil, false},
+		{"interactive, pseudo terminal false", aws.Bool(true), aws.Bool(false), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&ContainerDefinition{
+				Interactive:    tc.interactive,
+				PseudoTerminal: tc.pseudoTerminal,
+			})
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			assert.Equal(t, request.InvalidParameterErrCode, invalidParams.Code())
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamDependency)
+			require.True(t, ok)
+			assert.Equal(t, "ContainerDefinition.Interactive", paramErr.Field())
+			assert.Equal(t, "PseudoTerminal", paramErr.Dependency())
+		})
+	}
+
+	err := ValidateParams(&RegisterTaskDefinitionInput{
+		Family: aws.String("family"),
+		ContainerDefinitions: []*ContainerDefinition{
+			{Name: aws.String("web")},
+			{Name: aws.String("shell"), Interactive: aws.Bool(true)},
+		},
+	})
+	require.Error(t, err)
+	paramErr := err.(request.ErrInvalidParams).OrigErrs()[0].(request.ErrInvalidParam)
+	assert.Equal(t, "RegisterTaskDefinitionInput.ContainerDefinitions[1].Interactive", paramErr.Field())
+}
+
+func TestRegisterTaskDefinitionInputValidateFamily(t *testing.T) {
+	testCases := []struct {
+		name    string
+		family  string
+		errCode string
+		invalid string
+	}{
+		{"letters and digits", "web2", "", ""},
+		{"dashes and underscores", "my-web_app", "", ""},
+		{"255 characters", strings.Repeat("f", 255), "", ""},
+		{"slash", "team/web", ParamPatternErrCode, "/"},
+		{"several invalid characters", "web app:v1 (prod)", ParamPatternErrCode, " :()"},
+		{"256 characters", strings.Repeat("f", 256), ParamMaxLenErrCode, ""},
+		{"empty", "", request.ParamMinLenErrCode, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParams(&RegisterTaskDefinitionInput{
+				Family: aws.String(tc.family),
+				ContainerDefinitions: []*ContainerDefinition{{
+					Name:  aws.String("container"),
+					Image: aws.String("busybox"),
+				}},
+			})
+			if tc.errCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			assert.Equal(t, request.InvalidParameterErrCode, invalidParams.Code())
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr := invalidParams.OrigErrs()[0].(request.ErrInvalidParam)
+			assert.Equal(t, tc.errCode, paramErr.Code())
+			assert.Equal(t, "RegisterTaskDefinitionInput.Family", paramErr.Field())
+			if tc.invalid != "" {
+				assert.Equal(t, tc.invalid, paramErr.(*ErrParamPattern).InvalidCharacters())
+				assert.Contains(t, paramErr.Error(), fmt.Sprintf("invalid characters %q", tc.invalid))
+			}
+		})
+	}
+}
+
+func TestValidateCompatibilityFields(t *testing.T) {
+	container := func() *ContainerDefinition {
+		return &ContainerDefinition{Name: aws.String("web"), Image: aws.String("nginx")}
+	}
+	withDevices := container()
+	withDevices.LinuxParameters = &LinuxParameters{
+		Devices: []*Device{{HostPath: aws.String("/dev/fuse")}},
+	}
+	privileged := container()
+	privileged.Privileged = aws.Bool(true)
+
+	testCases := []struct {
+		name       string
+		input      *RegisterTaskDefinitionInput
+		violations []string
+	}{
+		{
+			name: "fargate with devices",
+			input: &RegisterTaskDefinitionInput{
+				RequiresCompatibilities: aws.StringSlice([]string{CompatibilityFargate}),
+				NetworkMode:             aws.String(NetworkModeAwsvpc),
+				ContainerDefinitions:    []*ContainerDefinition{container(), withDevices},
+			},
+			violations: []string{"ContainerDefinitions[1].LinuxParameters.Devices is not supported with FARGATE compatibility"},
+		},
+		{
+			name: "ec2 and fargate with bridge network and privileged container",
+			input: &RegisterTaskDefinitionInput{
+				RequiresCompatibilities: aws.StringSlice([]string{CompatibilityEc2, CompatibilityFargate}),
+				NetworkMode:             aws.String(NetworkModeBridge),
+				ContainerDefinitions:    []*ContainerDefinition{privileged},
+			},
+			violations: []string{
+				"NetworkMode bridge is not supported with FARGATE compatibility, which requires awsvpc",
+				"ContainerDefinitions[0].Privileged is not supported with FARGATE compatibility",
+			},
+		},
+		{
+			name: "ec2 with awsvpc",
+			input: &RegisterTaskDefinitionInput{
+				RequiresCompatibilities: aws.StringSlice([]string{CompatibilityEc2}),
+				NetworkMode:             aws.String(NetworkModeAwsvpc),
+				ContainerDefinitions:    []*ContainerDefinition{withDevices, privileged},
+			},
+		},
+		{
+			name: "fargate with awsvpc",
+			input: &RegisterTaskDefinitionInput{
+				RequiresCompatibilities: aws.StringSlice([]string{CompatibilityFargate}),
+				NetworkMode:             aws.String(NetworkModeAwsvpc),
+				ContainerDefinitions:    []*ContainerDefinition{container()},
+			},
+		},
+		{
+			name: "nil input",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.violations, ValidateCompatibilityFields(tc.input))
+		})
+	}
+}
+
+func TestUpdateServiceInputValidateAgainstExisting(t *testing.T) {
+	current := &Service{
+		ServiceName:        aws.String("web"),
+		SchedulingStrategy: aws.String(SchedulingStrategyReplica),
+	}
+
+	testCases := []struct {
+		name               string
+		schedulingStrategy *string
+		current            *Service
+		valid              bool
+	}{
+		{"unset", nil, current, true},
+		{"unchanged", aws.String(SchedulingStrategyReplica), current, true},
+		{"changed", aws.String(SchedulingStrategyDaemon), current, false},
+		{"no current service", aws.String(SchedulingStrategyDaemon), nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			input := &UpdateServiceInput{Service: aws.String("web")}
+			assert.NoError(t, ValidateParams(input), "ValidateParams does not need the current service")
+			err := input.ValidateAgainstExisting(tc.current, tc.schedulingStrategy)
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamImmutable)
+			require.True(t, ok)
+			assert.Equal(t, ParamImmutableErrCode, paramErr.Code())
+			assert.Equal(t, "UpdateServiceInput.SchedulingStrategy", paramErr.Field())
+			assert.Equal(t, SchedulingStrategyReplica, paramErr.Current())
+		})
+	}
+}
+
+func TestContainerDefinitionValidateAgainstSelf(t *testing.T) {
+	testCases := []struct {
+		name        string
+		volumesFrom []*VolumeFrom
+		valid       bool
+	}{
+		{"no volumes from", nil, true},
+		{"other container", []*VolumeFrom{{SourceContainer: aws.String("data")}}, true},
+		{"self", []*VolumeFrom{{SourceContainer: aws.String("data")}, {SourceContainer: aws.String("web")}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			container := &ContainerDefinition{
+				Name:        aws.String("web"),
+				Image:       aws.String("nginx"),
+				VolumesFrom: tc.volumesFrom,
+			}
+			err := container.ValidateAgainstSelf()
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			invalidParams, ok := err.(request.ErrInvalidParams)
+			require.True(t, ok)
+			require.Equal(t, 1, invalidParams.Len())
+			paramErr, ok := invalidParams.OrigErrs()[0].(*ErrParamSelfReference)
+			require.True(t, ok)
+			assert.Equal(t, ParamSelfReferenceErrCode, paramErr.Code())
+			assert.Equal(t, "ContainerDefinition.VolumesFrom[1].SourceContainer", paramErr.Field())
+			assert.Equal(t, "web", paramErr.Name())
+		})
+	}
+}
+
+func TestSubmitTaskStateChangeInputTruncateReason(t *testing.T) {
+	reason := strings.Repeat("é", 250) + strings.Repeat("x", 10)
+	input := &SubmitTaskStateChangeInput{Reason: aws.String(reason)}
+
+	err := input.TruncateReason()
+	require.Error(t, err)
+	truncated, ok := err.(*ErrReasonTruncated)
+	require.True(t, ok)
+	assert.Equal(t, reason, truncated.Reason)
+	assert.Contains(t, err.Error(), "truncated from 260 to 255 characters")
+	assert.Equal(t, strings.Repeat("é", 250)+"xxxxx", aws.StringValue(input.Reason))
+
+	assert.NoError(t, input.TruncateReason(), "a reason of 255 characters is kept")
+	assert.NoError(t, (&SubmitTaskStateChangeInput{}).TruncateReason())
+}
+
+func TestSubmitTaskStateChangeInputValidateLongReason(t *testing.T) {
+	reason := strings.Repeat("x", 300)
+	input := &SubmitTaskStateChangeInput{
+		Task:   aws.String("task"),
+		Reason: aws.String(reason),
+	}
+	assert.NoError(t, input.Validate(), "a long reason should not be rejected")
+	assert.Equal(t, reason, aws.StringValue(input.Reason), "Validate should not modify the input")
+}
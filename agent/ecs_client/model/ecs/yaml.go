@@ -0,0 +1,154 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// plainYAMLPattern matches the strings that can be written as YAML plain
+// scalars without being mistaken for another type
+var plainYAMLPattern = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./-]*$`)
+
+// reservedYAMLWords are the plain scalars that YAML reads as booleans or null
+var reservedYAMLWords = map[string]struct{}{
+	"true": {}, "false": {}, "yes": {}, "no": {}, "on": {}, "off": {},
+	"y": {}, "n": {}, "null": {}, "~": {},
+}
+
+// jsonToYAML converts a JSON document to block style YAML. Objects become
+// mappings with sorted keys and arrays become sequences.
+func jsonToYAML(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeYAML(&buf, value, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeYAML writes value to buf as a block of lines indented by indent
+// spaces. Scalars and empty collections are written on a line of their own.
+func writeYAML(buf *bytes.Buffer, value interface{}, indent int) error {
+	prefix := strings.Repeat(" ", indent)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString(prefix + "{}\n")
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			buf.WriteString(prefix + yamlString(key) + ":")
+			if isYAMLBlock(v[key]) {
+				buf.WriteString("\n")
+				if err := writeYAML(buf, v[key], indent+2); err != nil {
+					return err
+				}
+				continue
+			}
+			scalar, err := yamlScalar(v[key])
+			if err != nil {
+				return err
+			}
+			buf.WriteString(" " + scalar + "\n")
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(prefix + "[]\n")
+			return nil
+		}
+		for _, item := range v {
+			if !isYAMLBlock(item) {
+				scalar, err := yamlScalar(item)
+				if err != nil {
+					return err
+				}
+				buf.WriteString(prefix + "- " + scalar + "\n")
+				continue
+			}
+			// The item is written indented under the dash, then its
+			// first line is moved up next to the dash
+			var nested bytes.Buffer
+			if err := writeYAML(&nested, item, indent+2); err != nil {
+				return err
+			}
+			buf.WriteString(prefix + "- ")
+			buf.Write(nested.Bytes()[indent+2:])
+		}
+	default:
+		scalar, err := yamlScalar(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(prefix + scalar + "\n")
+	}
+	return nil
+}
+
+// isYAMLBlock returns true for the values written as nested blocks, which
+// are the non empty objects and arrays
+func isYAMLBlock(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// yamlScalar returns the YAML representation of a scalar or empty collection
+// decoded from JSON
+func yamlScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return yamlString(v), nil
+	case map[string]interface{}:
+		return "{}", nil
+	case []interface{}:
+		return "[]", nil
+	}
+	return "", fmt.Errorf("yaml: unsupported value of type %T", value)
+}
+
+// yamlString returns s as a plain scalar if it cannot be misread, or as a
+// double quoted scalar otherwise
+func yamlString(s string) string {
+	if _, reserved := reservedYAMLWords[strings.ToLower(s)]; !reserved && plainYAMLPattern.MatchString(s) {
+		return s
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,53 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONToYAML(t *testing.T) {
+	testCases := []struct {
+		name string
+		json string
+		yaml string
+	}{
+		{"scalar", `"web"`, "web\n"},
+		{"reserved words are quoted", `{"a":"yes","b":"null","c":"True"}`, "a: \"yes\"\nb: \"null\"\nc: \"True\"\n"},
+		{"numbers as strings are quoted", `{"cpu":"256"}`, "cpu: \"256\"\n"},
+		{"special characters are escaped", `{"key with space":"a: b\n#c"}`, "\"key with space\": \"a: b\\n#c\"\n"},
+		{"numbers, booleans and null", `{"a":1.5,"b":false,"c":null}`, "a: 1.5\nb: false\nc: null\n"},
+		{"empty collections", `{"a":{},"b":[]}`, "a: {}\nb: []\n"},
+		{"nested lists", `[[1,2],[]]`, "- - 1\n  - 2\n- []\n"},
+		{"sorted keys", `{"b":{"d":1,"c":2},"a":0}`, "a: 0\nb:\n  c: 2\n  d: 1\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			yaml, err := jsonToYAML([]byte(tc.json))
+			require.NoError(t, err)
+			assert.Equal(t, tc.yaml, string(yaml))
+		})
+	}
+}
+
+func TestJSONToYAMLInvalidJSON(t *testing.T) {
+	_, err := jsonToYAML([]byte(`{"a":`))
+	assert.Error(t, err)
+}
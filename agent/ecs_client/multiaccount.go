@@ -0,0 +1,556 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const (
+	// AssumedRoleCredentialsTTL is how long MultiAccountECS reuses the
+	// credentials of an assumed role before assuming it again
+	AssumedRoleCredentialsTTL = 5 * time.Minute
+
+	assumeRoleSessionName = "amazon-ecs-agent"
+	// assumeRoleDuration is the lifetime requested for assumed role
+	// credentials, which is the minimum allowed by STS
+	assumeRoleDuration = 15 * time.Minute
+
+	defaultCluster = "default"
+)
+
+// STSAssumeRoleAPI is the subset of the STS API used by MultiAccountECS
+type STSAssumeRoleAPI interface {
+	AssumeRoleWithContext(aws.Context, *sts.AssumeRoleInput, ...request.Option) (*sts.AssumeRoleOutput, error)
+}
+
+// assumedRole holds the credentials of an assumed role until they expire
+type assumedRole struct {
+	credentials *credentials.Credentials
+	expiresAt   time.Time
+}
+
+// MultiAccountECS implements ecs.ECSClientInterface for clusters owned by
+// different accounts. Each call is made with the credentials of the role
+// configured for the cluster named in its input, assumed through STS. Calls
+// that do not name a cluster, such as ListClusters, and calls to clusters
+// without a role, are made with the credentials of the base configuration.
+// Inputs that leave the cluster unset target the default cluster.
+type MultiAccountECS struct {
+	roleArnByCluster map[string]string
+	session          *session.Session
+	sts              STSAssumeRoleAPI
+
+	lock sync.Mutex
+	// assumedRoles are keyed by role ARN
+	assumedRoles map[string]*assumedRole
+	// now returns the current time, it is replaced in tests
+	now func() time.Time
+}
+
+var _ ecs.ECSClientInterface = (*MultiAccountECS)(nil)
+
+// NewMultiAccountECS creates a MultiAccountECS that assumes the roles of
+// roleArnByCluster, keyed by cluster name, with stsSvc. The clients it creates
+// are configured with baseConfig.
+func NewMultiAccountECS(roleArnByCluster map[string]string, baseConfig *aws.Config, stsSvc STSAssumeRoleAPI) (*MultiAccountECS, error) {
+	if len(roleArnByCluster) > 0 && stsSvc == nil {
+		return nil, fmt.Errorf("ecs client: an STS client is needed to assume the roles of %d clusters", len(roleArnByCluster))
+	}
+	if baseConfig == nil {
+		baseConfig = aws.NewConfig()
+	}
+	sess, err := session.NewSession(baseConfig)
+	if err != nil {
+		return nil, err
+	}
+	roles := make(map[string]string, len(roleArnByCluster))
+	for cluster, roleArn := range roleArnByCluster {
+		roles[cluster] = roleArn
+	}
+	return &MultiAccountECS{
+		roleArnByCluster: roles,
+		session:          sess,
+		sts:              stsSvc,
+		assumedRoles:     make(map[string]*assumedRole),
+		now:              time.Now,
+	}, nil
+}
+
+// client returns a client for the call made with input, using the
+// credentials of the role of its cluster, if any
+func (m *MultiAccountECS) client(ctx aws.Context, input interface{}) (*ecs.ECS, error) {
+	cluster, ok := clusterOf(input)
+	if !ok {
+		return ecs.New(m.session), nil
+	}
+	roleArn, ok := m.roleArnByCluster[cluster]
+	if !ok {
+		return ecs.New(m.session), nil
+	}
+	creds, err := m.assumeRole(ctx, roleArn)
+	if err != nil {
+		return nil, err
+	}
+	return ecs.New(m.session.Copy(&aws.Config{Credentials: creds})), nil
+}
+
+// assumeRole returns the credentials of roleArn, assuming it again if the
+// cached credentials are older than AssumedRoleCredentialsTTL or expired. The
+// lock is not held while STS is called, so that calls for other roles are not
+// held up by it.
+func (m *MultiAccountECS) assumeRole(ctx aws.Context, roleArn string) (*credentials.Credentials, error) {
+	if creds, ok := m.cachedRole(roleArn); ok {
+		return creds, nil
+	}
+
+	now := m.now()
+	output, err := m.sts.AssumeRoleWithContext(ctx, &sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleArn),
+		RoleSessionName: aws.String(assumeRoleSessionName),
+		DurationSeconds: aws.Int64(int64(assumeRoleDuration / time.Second)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if output.Credentials == nil {
+		return nil, fmt.Errorf("ecs client: assuming role %s returned no credentials", roleArn)
+	}
+	role := &assumedRole{
+		credentials: credentials.NewStaticCredentials(
+			aws.StringValue(output.Credentials.AccessKeyId),
+			aws.StringValue(output.Credentials.SecretAccessKey),
+			aws.StringValue(output.Credentials.SessionToken)),
+		expiresAt: now.Add(AssumedRoleCredentialsTTL),
+	}
+	if expiration := output.Credentials.Expiration; expiration != nil && expiration.Before(role.expiresAt) {
+		role.expiresAt = *expiration
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	// Another call may have assumed the role while STS was called, in which
+	// case its credentials are kept
+	if cached, ok := m.assumedRoles[roleArn]; ok && m.now().Before(cached.expiresAt) {
+		return cached.credentials, nil
+	}
+	m.assumedRoles[roleArn] = role
+	return role.credentials, nil
+}
+
+// cachedRole returns the cached credentials of roleArn, if they have not
+// expired
+func (m *MultiAccountECS) cachedRole(roleArn string) (*credentials.Credentials, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	role, ok := m.assumedRoles[roleArn]
+	if !ok || !m.now().Before(role.expiresAt) {
+		return nil, false
+	}
+	return role.credentials, true
+}
+
+// clusterOf returns the name of the cluster targeted by an input, from its
+// Cluster or ClusterName field. False is returned for inputs that have no
+// such field.
+func clusterOf(input interface{}) (string, bool) {
+	value := reflect.ValueOf(input)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return "", false
+	}
+	for _, name := range []string{"Cluster", "ClusterName"} {
+		field := value.Elem().FieldByName(name)
+		if !field.IsValid() {
+			continue
+		}
+		cluster, ok := field.Interface().(*string)
+		if !ok {
+			continue
+		}
+		identifier := aws.StringValue(cluster)
+		if identifier == "" {
+			return defaultCluster, true
+		}
+		// Clusters can also be given by ARN
+		return identifier[strings.LastIndex(identifier, "/")+1:], true
+	}
+	return "", false
+}
+
+// CreateClusterWithContext calls CreateClusterWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) CreateClusterWithContext(ctx aws.Context, input *ecs.CreateClusterInput, opts ...request.Option) (*ecs.CreateClusterOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateClusterWithContext(ctx, input, opts...)
+}
+
+// CreateServiceWithContext calls CreateServiceWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) CreateServiceWithContext(ctx aws.Context, input *ecs.CreateServiceInput, opts ...request.Option) (*ecs.CreateServiceOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateServiceWithContext(ctx, input, opts...)
+}
+
+// DeleteAccountSettingWithContext calls DeleteAccountSettingWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DeleteAccountSettingWithContext(ctx aws.Context, input *ecs.DeleteAccountSettingInput, opts ...request.Option) (*ecs.DeleteAccountSettingOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteAccountSettingWithContext(ctx, input, opts...)
+}
+
+// DeleteAttributesWithContext calls DeleteAttributesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DeleteAttributesWithContext(ctx aws.Context, input *ecs.DeleteAttributesInput, opts ...request.Option) (*ecs.DeleteAttributesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteAttributesWithContext(ctx, input, opts...)
+}
+
+// DeleteClusterWithContext calls DeleteClusterWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DeleteClusterWithContext(ctx aws.Context, input *ecs.DeleteClusterInput, opts ...request.Option) (*ecs.DeleteClusterOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteClusterWithContext(ctx, input, opts...)
+}
+
+// DeleteServiceWithContext calls DeleteServiceWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DeleteServiceWithContext(ctx aws.Context, input *ecs.DeleteServiceInput, opts ...request.Option) (*ecs.DeleteServiceOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteServiceWithContext(ctx, input, opts...)
+}
+
+// DeregisterContainerInstanceWithContext calls DeregisterContainerInstanceWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DeregisterContainerInstanceWithContext(ctx aws.Context, input *ecs.DeregisterContainerInstanceInput, opts ...request.Option) (*ecs.DeregisterContainerInstanceOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeregisterContainerInstanceWithContext(ctx, input, opts...)
+}
+
+// DeregisterTaskDefinitionWithContext calls DeregisterTaskDefinitionWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DeregisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.DeregisterTaskDefinitionInput, opts ...request.Option) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeregisterTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// DescribeClustersWithContext calls DescribeClustersWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput, opts ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeClustersWithContext(ctx, input, opts...)
+}
+
+// DescribeContainerInstancesWithContext calls DescribeContainerInstancesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DescribeContainerInstancesWithContext(ctx aws.Context, input *ecs.DescribeContainerInstancesInput, opts ...request.Option) (*ecs.DescribeContainerInstancesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeContainerInstancesWithContext(ctx, input, opts...)
+}
+
+// DescribeServicesWithContext calls DescribeServicesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeServicesWithContext(ctx, input, opts...)
+}
+
+// DescribeTaskDefinitionWithContext calls DescribeTaskDefinitionWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// DescribeTasksWithContext calls DescribeTasksWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeTasksWithContext(ctx, input, opts...)
+}
+
+// DiscoverPollEndpointWithContext calls DiscoverPollEndpointWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) DiscoverPollEndpointWithContext(ctx aws.Context, input *ecs.DiscoverPollEndpointInput, opts ...request.Option) (*ecs.DiscoverPollEndpointOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.DiscoverPollEndpointWithContext(ctx, input, opts...)
+}
+
+// ListAccountSettingsWithContext calls ListAccountSettingsWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListAccountSettingsWithContext(ctx aws.Context, input *ecs.ListAccountSettingsInput, opts ...request.Option) (*ecs.ListAccountSettingsOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListAccountSettingsWithContext(ctx, input, opts...)
+}
+
+// ListAttributesWithContext calls ListAttributesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListAttributesWithContext(ctx aws.Context, input *ecs.ListAttributesInput, opts ...request.Option) (*ecs.ListAttributesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListAttributesWithContext(ctx, input, opts...)
+}
+
+// ListClustersWithContext calls ListClustersWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListClustersWithContext(ctx, input, opts...)
+}
+
+// ListContainerInstancesWithContext calls ListContainerInstancesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListContainerInstancesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput, opts ...request.Option) (*ecs.ListContainerInstancesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListContainerInstancesWithContext(ctx, input, opts...)
+}
+
+// ListServicesWithContext calls ListServicesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput, opts ...request.Option) (*ecs.ListServicesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListServicesWithContext(ctx, input, opts...)
+}
+
+// ListTagsForResourceWithContext calls ListTagsForResourceWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListTagsForResourceWithContext(ctx aws.Context, input *ecs.ListTagsForResourceInput, opts ...request.Option) (*ecs.ListTagsForResourceOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTagsForResourceWithContext(ctx, input, opts...)
+}
+
+// ListTaskDefinitionFamiliesWithContext calls ListTaskDefinitionFamiliesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListTaskDefinitionFamiliesWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionFamiliesInput, opts ...request.Option) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTaskDefinitionFamiliesWithContext(ctx, input, opts...)
+}
+
+// ListTaskDefinitionsWithContext calls ListTaskDefinitionsWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListTaskDefinitionsWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput, opts ...request.Option) (*ecs.ListTaskDefinitionsOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTaskDefinitionsWithContext(ctx, input, opts...)
+}
+
+// ListTasksWithContext calls ListTasksWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) ListTasksWithContext(ctx aws.Context, input *ecs.ListTasksInput, opts ...request.Option) (*ecs.ListTasksOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTasksWithContext(ctx, input, opts...)
+}
+
+// PutAccountSettingWithContext calls PutAccountSettingWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) PutAccountSettingWithContext(ctx aws.Context, input *ecs.PutAccountSettingInput, opts ...request.Option) (*ecs.PutAccountSettingOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.PutAccountSettingWithContext(ctx, input, opts...)
+}
+
+// PutAttributesWithContext calls PutAttributesWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) PutAttributesWithContext(ctx aws.Context, input *ecs.PutAttributesInput, opts ...request.Option) (*ecs.PutAttributesOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.PutAttributesWithContext(ctx, input, opts...)
+}
+
+// RegisterContainerInstanceWithContext calls RegisterContainerInstanceWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) RegisterContainerInstanceWithContext(ctx aws.Context, input *ecs.RegisterContainerInstanceInput, opts ...request.Option) (*ecs.RegisterContainerInstanceOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.RegisterContainerInstanceWithContext(ctx, input, opts...)
+}
+
+// RegisterTaskDefinitionWithContext calls RegisterTaskDefinitionWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) RegisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.RegisterTaskDefinitionInput, opts ...request.Option) (*ecs.RegisterTaskDefinitionOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.RegisterTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// RunTaskBatch makes the RunTask calls of the batch through the
+// MultiAccountECS
+func (m *MultiAccountECS) RunTaskBatch(ctx aws.Context, input *ecs.RunTaskInput, count int) ([]*ecs.Task, []*ecs.Failure, error) {
+	return ecs.RunTaskInChunks(ctx, m, input, count)
+}
+
+// RunTaskWithContext calls RunTaskWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) RunTaskWithContext(ctx aws.Context, input *ecs.RunTaskInput, opts ...request.Option) (*ecs.RunTaskOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.RunTaskWithContext(ctx, input, opts...)
+}
+
+// StartTaskWithContext calls StartTaskWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) StartTaskWithContext(ctx aws.Context, input *ecs.StartTaskInput, opts ...request.Option) (*ecs.StartTaskOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.StartTaskWithContext(ctx, input, opts...)
+}
+
+// StopTaskWithContext calls StopTaskWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) StopTaskWithContext(ctx aws.Context, input *ecs.StopTaskInput, opts ...request.Option) (*ecs.StopTaskOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.StopTaskWithContext(ctx, input, opts...)
+}
+
+// SubmitContainerStateChangeWithContext calls SubmitContainerStateChangeWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) SubmitContainerStateChangeWithContext(ctx aws.Context, input *ecs.SubmitContainerStateChangeInput, opts ...request.Option) (*ecs.SubmitContainerStateChangeOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.SubmitContainerStateChangeWithContext(ctx, input, opts...)
+}
+
+// SubmitTaskStateChangeWithContext calls SubmitTaskStateChangeWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.SubmitTaskStateChangeWithContext(ctx, input, opts...)
+}
+
+// UpdateContainerAgentWithContext calls UpdateContainerAgentWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) UpdateContainerAgentWithContext(ctx aws.Context, input *ecs.UpdateContainerAgentInput, opts ...request.Option) (*ecs.UpdateContainerAgentOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateContainerAgentWithContext(ctx, input, opts...)
+}
+
+// UpdateContainerInstancesStateWithContext calls UpdateContainerInstancesStateWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) UpdateContainerInstancesStateWithContext(ctx aws.Context, input *ecs.UpdateContainerInstancesStateInput, opts ...request.Option) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateContainerInstancesStateWithContext(ctx, input, opts...)
+}
+
+// UpdateServiceWithContext calls UpdateServiceWithContext with the credentials of the role
+// of the cluster of input
+func (m *MultiAccountECS) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput, opts ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	client, err := m.client(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateServiceWithContext(ctx, input, opts...)
+}
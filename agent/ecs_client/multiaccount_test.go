@@ -0,0 +1,275 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProdRole    = "arn:aws:iam::111111111111:role/prod"
+	testStagingRole = "arn:aws:iam::222222222222:role/staging"
+	testBaseKey     = "AKIDBASE"
+)
+
+// fakeSTS hands out credentials whose access key is derived from the role
+// ARN and the number of times roles were assumed
+type fakeSTS struct {
+	lock  sync.Mutex
+	calls []string
+	err   error
+}
+
+func (f *fakeSTS) AssumeRoleWithContext(ctx aws.Context, input *sts.AssumeRoleInput, opts ...request.Option) (*sts.AssumeRoleOutput, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.calls = append(f.calls, aws.StringValue(input.RoleArn))
+	account := strings.Split(aws.StringValue(input.RoleArn), ":")[4]
+	return &sts.AssumeRoleOutput{
+		Credentials: &sts.Credentials{
+			AccessKeyId:     aws.String("AKID" + account),
+			SecretAccessKey: aws.String("secret"),
+			SessionToken:    aws.String("token"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+// blockingSTS blocks the calls assuming role until release is closed,
+// closing blocked once the first such call is made
+type blockingSTS struct {
+	fakeSTS
+	role    string
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func newBlockingSTS(role string) *blockingSTS {
+	return &blockingSTS{
+		role:    role,
+		blocked: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blockingSTS) AssumeRoleWithContext(ctx aws.Context, input *sts.AssumeRoleInput, opts ...request.Option) (*sts.AssumeRoleOutput, error) {
+	if aws.StringValue(input.RoleArn) == b.role {
+		close(b.blocked)
+		<-b.release
+	}
+	return b.fakeSTS.AssumeRoleWithContext(ctx, input, opts...)
+}
+
+// accessKeyRecorder answers every ECS call with an empty response and
+// records the access key each call was signed with
+type accessKeyRecorder struct {
+	lock sync.Mutex
+	keys []string
+}
+
+func (r *accessKeyRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	authorization := req.Header.Get("Authorization")
+	credential := authorization[strings.Index(authorization, "Credential=")+len("Credential="):]
+	r.keys = append(r.keys, credential[:strings.Index(credential, "/")])
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	w.Write([]byte("{}"))
+}
+
+func (r *accessKeyRecorder) lastKey() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.keys[len(r.keys)-1]
+}
+
+// newTestMultiAccountECS creates a MultiAccountECS sending its calls to an
+// accessKeyRecorder. The returned server must be closed.
+func newTestMultiAccountECS(t *testing.T, stsSvc STSAssumeRoleAPI) (*MultiAccountECS, *accessKeyRecorder, *httptest.Server) {
+	recorder := &accessKeyRecorder{}
+	server := httptest.NewServer(recorder)
+
+	client, err := NewMultiAccountECS(map[string]string{
+		"prod":    testProdRole,
+		"staging": testStagingRole,
+	}, &aws.Config{
+		Region:      aws.String("us-west-2"),
+		Endpoint:    aws.String(server.URL),
+		Credentials: credentials.NewStaticCredentials(testBaseKey, "secret", ""),
+		MaxRetries:  aws.Int(0),
+	}, stsSvc)
+	require.NoError(t, err)
+	return client, recorder, server
+}
+
+func TestMultiAccountECSAssumesClusterRole(t *testing.T) {
+	stsSvc := &fakeSTS{}
+	client, recorder, server := newTestMultiAccountECS(t, stsSvc)
+	defer server.Close()
+	ctx := aws.BackgroundContext()
+
+	_, err := client.ListContainerInstancesWithContext(ctx, &ecs.ListContainerInstancesInput{Cluster: aws.String("prod")})
+	require.NoError(t, err)
+	assert.Equal(t, "AKID111111111111", recorder.lastKey())
+
+	_, err = client.ListTasksWithContext(ctx, &ecs.ListTasksInput{
+		Cluster: aws.String("arn:aws:ecs:us-west-2:222222222222:cluster/staging"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "AKID222222222222", recorder.lastKey())
+
+	_, err = client.CreateClusterWithContext(ctx, &ecs.CreateClusterInput{ClusterName: aws.String("prod")})
+	require.NoError(t, err)
+	assert.Equal(t, "AKID111111111111", recorder.lastKey())
+
+	assert.Equal(t, []string{testProdRole, testStagingRole}, stsSvc.calls)
+}
+
+func TestMultiAccountECSUsesBaseCredentials(t *testing.T) {
+	stsSvc := &fakeSTS{}
+	client, recorder, server := newTestMultiAccountECS(t, stsSvc)
+	defer server.Close()
+	ctx := aws.BackgroundContext()
+
+	// Without a cluster in the input
+	_, err := client.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+	require.NoError(t, err)
+	assert.Equal(t, testBaseKey, recorder.lastKey())
+
+	// With a cluster that has no role, including the default cluster
+	_, err = client.ListServicesWithContext(ctx, &ecs.ListServicesInput{Cluster: aws.String("dev")})
+	require.NoError(t, err)
+	assert.Equal(t, testBaseKey, recorder.lastKey())
+	_, err = client.ListServicesWithContext(ctx, &ecs.ListServicesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, testBaseKey, recorder.lastKey())
+
+	assert.Empty(t, stsSvc.calls)
+}
+
+func TestMultiAccountECSCachesCredentials(t *testing.T) {
+	stsSvc := &fakeSTS{}
+	client, _, server := newTestMultiAccountECS(t, stsSvc)
+	defer server.Close()
+	now := time.Now()
+	client.now = func() time.Time { return now }
+	ctx := aws.BackgroundContext()
+	input := &ecs.ListServicesInput{Cluster: aws.String("prod")}
+
+	_, err := client.ListServicesWithContext(ctx, input)
+	require.NoError(t, err)
+	now = now.Add(AssumedRoleCredentialsTTL - time.Second)
+	_, err = client.ListServicesWithContext(ctx, input)
+	require.NoError(t, err)
+	assert.Len(t, stsSvc.calls, 1, "the credentials should be reused within the TTL")
+
+	now = now.Add(time.Second)
+	_, err = client.ListServicesWithContext(ctx, input)
+	require.NoError(t, err)
+	assert.Len(t, stsSvc.calls, 2, "the role should be assumed again after the TTL")
+}
+
+func TestMultiAccountECSAssumeRoleDoesNotBlockOtherRoles(t *testing.T) {
+	stsSvc := newBlockingSTS(testProdRole)
+	client, _, server := newTestMultiAccountECS(t, stsSvc)
+	defer server.Close()
+	ctx := aws.BackgroundContext()
+
+	prodDone := make(chan error)
+	go func() {
+		_, err := client.ListServicesWithContext(ctx, &ecs.ListServicesInput{Cluster: aws.String("prod")})
+		prodDone <- err
+	}()
+	<-stsSvc.blocked
+
+	stagingDone := make(chan error)
+	go func() {
+		_, err := client.ListServicesWithContext(ctx, &ecs.ListServicesInput{Cluster: aws.String("staging")})
+		stagingDone <- err
+	}()
+	select {
+	case err := <-stagingDone:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "assuming the staging role was blocked by the prod role")
+	}
+
+	close(stsSvc.release)
+	assert.NoError(t, <-prodDone)
+}
+
+func TestMultiAccountECSAssumeRoleKeepsConcurrentCredentials(t *testing.T) {
+	stsSvc := newBlockingSTS(testProdRole)
+	client, _, server := newTestMultiAccountECS(t, stsSvc)
+	defer server.Close()
+
+	done := make(chan *credentials.Credentials)
+	go func() {
+		creds, err := client.assumeRole(aws.BackgroundContext(), testProdRole)
+		assert.NoError(t, err)
+		done <- creds
+	}()
+	<-stsSvc.blocked
+
+	// The role is assumed by another call while the first one waits on STS
+	concurrent := credentials.NewStaticCredentials("AKIDCONCURRENT", "secret", "token")
+	client.lock.Lock()
+	client.assumedRoles[testProdRole] = &assumedRole{
+		credentials: concurrent,
+		expiresAt:   time.Now().Add(AssumedRoleCredentialsTTL),
+	}
+	client.lock.Unlock()
+
+	close(stsSvc.release)
+	creds := <-done
+	assert.True(t, creds == concurrent, "the credentials cached first should be kept")
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	assert.True(t, client.assumedRoles[testProdRole].credentials == concurrent)
+}
+
+func TestMultiAccountECSAssumeRoleError(t *testing.T) {
+	client, _, server := newTestMultiAccountECS(t, &fakeSTS{err: errors.New("access denied")})
+	defer server.Close()
+
+	_, err := client.ListServicesWithContext(aws.BackgroundContext(), &ecs.ListServicesInput{Cluster: aws.String("prod")})
+	assert.EqualError(t, err, "access denied")
+}
+
+func TestNewMultiAccountECSWithoutSTS(t *testing.T) {
+	_, err := NewMultiAccountECS(map[string]string{"prod": testProdRole}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewMultiAccountECS(nil, nil, nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,400 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// regionContextKey is the key of the region set by WithRegion in a context
+type regionContextKey struct{}
+
+// WithRegion returns a copy of ctx that makes MultiRegionECS route the calls
+// made with it to the client of region
+func WithRegion(ctx aws.Context, region string) aws.Context {
+	return context.WithValue(ctx, regionContextKey{}, region)
+}
+
+// MultiRegionECS implements ecs.ECSClientInterface on top of one client per
+// region. Each call is routed to the client of the region set in its context
+// with WithRegion, or to the client of DefaultRegion when the context does
+// not carry a region.
+type MultiRegionECS struct {
+	// DefaultRegion is the region of the calls whose context does not carry
+	// one
+	DefaultRegion string
+
+	clients map[string]ecs.ECSClientInterface
+}
+
+var _ ecs.ECSClientInterface = (*MultiRegionECS)(nil)
+
+// NewMultiRegionECS creates a MultiRegionECS from clients, keyed by region
+// name. An error is returned if there is no client for defaultRegion.
+func NewMultiRegionECS(clients map[string]ecs.ECSClientInterface, defaultRegion string) (*MultiRegionECS, error) {
+	if _, ok := clients[defaultRegion]; !ok {
+		return nil, fmt.Errorf("ecs client: no client for default region %q", defaultRegion)
+	}
+	regionClients := make(map[string]ecs.ECSClientInterface, len(clients))
+	for region, client := range clients {
+		regionClients[region] = client
+	}
+	return &MultiRegionECS{
+		DefaultRegion: defaultRegion,
+		clients:       regionClients,
+	}, nil
+}
+
+// client returns the client of the region the call made with ctx is routed
+// to
+func (m *MultiRegionECS) client(ctx aws.Context) (ecs.ECSClientInterface, error) {
+	region, ok := ctx.Value(regionContextKey{}).(string)
+	if !ok || region == "" {
+		region = m.DefaultRegion
+	}
+	client, ok := m.clients[region]
+	if !ok {
+		return nil, fmt.Errorf("ecs client: no client for region %q", region)
+	}
+	return client, nil
+}
+
+// CreateClusterWithContext calls CreateClusterWithContext on the client of the region of ctx
+func (m *MultiRegionECS) CreateClusterWithContext(ctx aws.Context, input *ecs.CreateClusterInput, opts ...request.Option) (*ecs.CreateClusterOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateClusterWithContext(ctx, input, opts...)
+}
+
+// CreateServiceWithContext calls CreateServiceWithContext on the client of the region of ctx
+func (m *MultiRegionECS) CreateServiceWithContext(ctx aws.Context, input *ecs.CreateServiceInput, opts ...request.Option) (*ecs.CreateServiceOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateServiceWithContext(ctx, input, opts...)
+}
+
+// DeleteAccountSettingWithContext calls DeleteAccountSettingWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DeleteAccountSettingWithContext(ctx aws.Context, input *ecs.DeleteAccountSettingInput, opts ...request.Option) (*ecs.DeleteAccountSettingOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteAccountSettingWithContext(ctx, input, opts...)
+}
+
+// DeleteAttributesWithContext calls DeleteAttributesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DeleteAttributesWithContext(ctx aws.Context, input *ecs.DeleteAttributesInput, opts ...request.Option) (*ecs.DeleteAttributesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteAttributesWithContext(ctx, input, opts...)
+}
+
+// DeleteClusterWithContext calls DeleteClusterWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DeleteClusterWithContext(ctx aws.Context, input *ecs.DeleteClusterInput, opts ...request.Option) (*ecs.DeleteClusterOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteClusterWithContext(ctx, input, opts...)
+}
+
+// DeleteServiceWithContext calls DeleteServiceWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DeleteServiceWithContext(ctx aws.Context, input *ecs.DeleteServiceInput, opts ...request.Option) (*ecs.DeleteServiceOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeleteServiceWithContext(ctx, input, opts...)
+}
+
+// DeregisterContainerInstanceWithContext calls DeregisterContainerInstanceWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DeregisterContainerInstanceWithContext(ctx aws.Context, input *ecs.DeregisterContainerInstanceInput, opts ...request.Option) (*ecs.DeregisterContainerInstanceOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeregisterContainerInstanceWithContext(ctx, input, opts...)
+}
+
+// DeregisterTaskDefinitionWithContext calls DeregisterTaskDefinitionWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DeregisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.DeregisterTaskDefinitionInput, opts ...request.Option) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DeregisterTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// DescribeClustersWithContext calls DescribeClustersWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput, opts ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeClustersWithContext(ctx, input, opts...)
+}
+
+// DescribeContainerInstancesWithContext calls DescribeContainerInstancesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DescribeContainerInstancesWithContext(ctx aws.Context, input *ecs.DescribeContainerInstancesInput, opts ...request.Option) (*ecs.DescribeContainerInstancesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeContainerInstancesWithContext(ctx, input, opts...)
+}
+
+// DescribeServicesWithContext calls DescribeServicesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeServicesWithContext(ctx, input, opts...)
+}
+
+// DescribeTaskDefinitionWithContext calls DescribeTaskDefinitionWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// DescribeTasksWithContext calls DescribeTasksWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DescribeTasksWithContext(ctx, input, opts...)
+}
+
+// DiscoverPollEndpointWithContext calls DiscoverPollEndpointWithContext on the client of the region of ctx
+func (m *MultiRegionECS) DiscoverPollEndpointWithContext(ctx aws.Context, input *ecs.DiscoverPollEndpointInput, opts ...request.Option) (*ecs.DiscoverPollEndpointOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.DiscoverPollEndpointWithContext(ctx, input, opts...)
+}
+
+// ListAccountSettingsWithContext calls ListAccountSettingsWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListAccountSettingsWithContext(ctx aws.Context, input *ecs.ListAccountSettingsInput, opts ...request.Option) (*ecs.ListAccountSettingsOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListAccountSettingsWithContext(ctx, input, opts...)
+}
+
+// ListAttributesWithContext calls ListAttributesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListAttributesWithContext(ctx aws.Context, input *ecs.ListAttributesInput, opts ...request.Option) (*ecs.ListAttributesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListAttributesWithContext(ctx, input, opts...)
+}
+
+// ListClustersWithContext calls ListClustersWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListClustersWithContext(ctx, input, opts...)
+}
+
+// ListContainerInstancesWithContext calls ListContainerInstancesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListContainerInstancesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput, opts ...request.Option) (*ecs.ListContainerInstancesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListContainerInstancesWithContext(ctx, input, opts...)
+}
+
+// ListServicesWithContext calls ListServicesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput, opts ...request.Option) (*ecs.ListServicesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListServicesWithContext(ctx, input, opts...)
+}
+
+// ListTagsForResourceWithContext calls ListTagsForResourceWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListTagsForResourceWithContext(ctx aws.Context, input *ecs.ListTagsForResourceInput, opts ...request.Option) (*ecs.ListTagsForResourceOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTagsForResourceWithContext(ctx, input, opts...)
+}
+
+// ListTaskDefinitionFamiliesWithContext calls ListTaskDefinitionFamiliesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListTaskDefinitionFamiliesWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionFamiliesInput, opts ...request.Option) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTaskDefinitionFamiliesWithContext(ctx, input, opts...)
+}
+
+// ListTaskDefinitionsWithContext calls ListTaskDefinitionsWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListTaskDefinitionsWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput, opts ...request.Option) (*ecs.ListTaskDefinitionsOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTaskDefinitionsWithContext(ctx, input, opts...)
+}
+
+// ListTasksWithContext calls ListTasksWithContext on the client of the region of ctx
+func (m *MultiRegionECS) ListTasksWithContext(ctx aws.Context, input *ecs.ListTasksInput, opts ...request.Option) (*ecs.ListTasksOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.ListTasksWithContext(ctx, input, opts...)
+}
+
+// PutAccountSettingWithContext calls PutAccountSettingWithContext on the client of the region of ctx
+func (m *MultiRegionECS) PutAccountSettingWithContext(ctx aws.Context, input *ecs.PutAccountSettingInput, opts ...request.Option) (*ecs.PutAccountSettingOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.PutAccountSettingWithContext(ctx, input, opts...)
+}
+
+// PutAttributesWithContext calls PutAttributesWithContext on the client of the region of ctx
+func (m *MultiRegionECS) PutAttributesWithContext(ctx aws.Context, input *ecs.PutAttributesInput, opts ...request.Option) (*ecs.PutAttributesOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.PutAttributesWithContext(ctx, input, opts...)
+}
+
+// RegisterContainerInstanceWithContext calls RegisterContainerInstanceWithContext on the client of the region of ctx
+func (m *MultiRegionECS) RegisterContainerInstanceWithContext(ctx aws.Context, input *ecs.RegisterContainerInstanceInput, opts ...request.Option) (*ecs.RegisterContainerInstanceOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.RegisterContainerInstanceWithContext(ctx, input, opts...)
+}
+
+// RegisterTaskDefinitionWithContext calls RegisterTaskDefinitionWithContext on the client of the region of ctx
+func (m *MultiRegionECS) RegisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.RegisterTaskDefinitionInput, opts ...request.Option) (*ecs.RegisterTaskDefinitionOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.RegisterTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// RunTaskBatch calls RunTaskBatch on the client of the region of ctx
+func (m *MultiRegionECS) RunTaskBatch(ctx aws.Context, input *ecs.RunTaskInput, count int) ([]*ecs.Task, []*ecs.Failure, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.RunTaskBatch(ctx, input, count)
+}
+
+// RunTaskWithContext calls RunTaskWithContext on the client of the region of ctx
+func (m *MultiRegionECS) RunTaskWithContext(ctx aws.Context, input *ecs.RunTaskInput, opts ...request.Option) (*ecs.RunTaskOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.RunTaskWithContext(ctx, input, opts...)
+}
+
+// StartTaskWithContext calls StartTaskWithContext on the client of the region of ctx
+func (m *MultiRegionECS) StartTaskWithContext(ctx aws.Context, input *ecs.StartTaskInput, opts ...request.Option) (*ecs.StartTaskOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.StartTaskWithContext(ctx, input, opts...)
+}
+
+// StopTaskWithContext calls StopTaskWithContext on the client of the region of ctx
+func (m *MultiRegionECS) StopTaskWithContext(ctx aws.Context, input *ecs.StopTaskInput, opts ...request.Option) (*ecs.StopTaskOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.StopTaskWithContext(ctx, input, opts...)
+}
+
+// SubmitContainerStateChangeWithContext calls SubmitContainerStateChangeWithContext on the client of the region of ctx
+func (m *MultiRegionECS) SubmitContainerStateChangeWithContext(ctx aws.Context, input *ecs.SubmitContainerStateChangeInput, opts ...request.Option) (*ecs.SubmitContainerStateChangeOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.SubmitContainerStateChangeWithContext(ctx, input, opts...)
+}
+
+// SubmitTaskStateChangeWithContext calls SubmitTaskStateChangeWithContext on the client of the region of ctx
+func (m *MultiRegionECS) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.SubmitTaskStateChangeWithContext(ctx, input, opts...)
+}
+
+// UpdateContainerAgentWithContext calls UpdateContainerAgentWithContext on the client of the region of ctx
+func (m *MultiRegionECS) UpdateContainerAgentWithContext(ctx aws.Context, input *ecs.UpdateContainerAgentInput, opts ...request.Option) (*ecs.UpdateContainerAgentOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateContainerAgentWithContext(ctx, input, opts...)
+}
+
+// UpdateContainerInstancesStateWithContext calls UpdateContainerInstancesStateWithContext on the client of the region of ctx
+func (m *MultiRegionECS) UpdateContainerInstancesStateWithContext(ctx aws.Context, input *ecs.UpdateContainerInstancesStateInput, opts ...request.Option) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateContainerInstancesStateWithContext(ctx, input, opts...)
+}
+
+// UpdateServiceWithContext calls UpdateServiceWithContext on the client of the region of ctx
+func (m *MultiRegionECS) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput, opts ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	client, err := m.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return client.UpdateServiceWithContext(ctx, input, opts...)
+}
@@ -0,0 +1,97 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/ecsfake"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDefaultRegion = "us-west-2"
+	testOtherRegion   = "us-east-1"
+)
+
+func newTestMultiRegionECS(t *testing.T) (*MultiRegionECS, map[string]*ecsfake.Server) {
+	servers := map[string]*ecsfake.Server{
+		testDefaultRegion: ecsfake.NewServer(),
+		testOtherRegion:   ecsfake.NewServer(),
+	}
+	clients := make(map[string]ecs.ECSClientInterface)
+	for region, server := range servers {
+		clients[region] = server
+	}
+	client, err := NewMultiRegionECS(clients, testDefaultRegion)
+	require.NoError(t, err)
+	return client, servers
+}
+
+func listClusterArns(t *testing.T, client ecs.ECSClientInterface) []string {
+	output, err := client.ListClustersWithContext(aws.BackgroundContext(), &ecs.ListClustersInput{})
+	require.NoError(t, err)
+	return aws.StringValueSlice(output.ClusterArns)
+}
+
+func TestMultiRegionECSRoutesToContextRegion(t *testing.T) {
+	client, servers := newTestMultiRegionECS(t)
+
+	_, err := client.CreateClusterWithContext(WithRegion(aws.BackgroundContext(), testOtherRegion),
+		&ecs.CreateClusterInput{ClusterName: aws.String("east")})
+	require.NoError(t, err)
+
+	assert.Len(t, listClusterArns(t, servers[testOtherRegion]), 1)
+	assert.Empty(t, listClusterArns(t, servers[testDefaultRegion]))
+}
+
+func TestMultiRegionECSFallsBackToDefaultRegion(t *testing.T) {
+	client, servers := newTestMultiRegionECS(t)
+
+	_, err := client.CreateClusterWithContext(aws.BackgroundContext(),
+		&ecs.CreateClusterInput{ClusterName: aws.String("west")})
+	require.NoError(t, err)
+	_, err = client.CreateClusterWithContext(WithRegion(aws.BackgroundContext(), ""),
+		&ecs.CreateClusterInput{ClusterName: aws.String("west2")})
+	require.NoError(t, err)
+
+	assert.Len(t, listClusterArns(t, servers[testDefaultRegion]), 2)
+	assert.Empty(t, listClusterArns(t, servers[testOtherRegion]))
+}
+
+func TestMultiRegionECSUnknownRegion(t *testing.T) {
+	client, _ := newTestMultiRegionECS(t)
+
+	output, err := client.ListClustersWithContext(WithRegion(aws.BackgroundContext(), "eu-west-1"), &ecs.ListClustersInput{})
+	require.Error(t, err)
+	assert.Nil(t, output)
+	assert.Contains(t, err.Error(), "eu-west-1")
+}
+
+func TestNewMultiRegionECSMissingDefaultRegion(t *testing.T) {
+	_, err := NewMultiRegionECS(map[string]ecs.ECSClientInterface{
+		testOtherRegion: ecsfake.NewServer(),
+	}, testDefaultRegion)
+	assert.Error(t, err)
+}
+
+func TestMultiRegionECSInterfaceCompliance(t *testing.T) {
+	client, _ := newTestMultiRegionECS(t)
+	ecsfake.TestInterfaceCompliance(t, client)
+}
@@ -0,0 +1,272 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// attributeSubjectPrefix prefixes the subjects of the expressions that
+// refer to container instance attributes
+const attributeSubjectPrefix = "attribute:"
+
+// PlacementConstraintEvaluator evaluates the memberOf expressions of
+// placement constraints against container instances, in the subset of the
+// cluster query language that refers to attributes:
+//
+//	attribute:ecs.instance-type == t3.micro
+//	attribute:ecs.instance-type != t3.micro
+//	attribute:ecs.instance-type =~ t3.*
+//	attribute:ecs.os-type exists
+//	attribute:ecs.os-type !exists
+//
+// Comparisons are combined with && and ||, negated with !, and grouped with
+// parentheses; and, or and not may be spelled out. ! binds tighter than &&,
+// which binds tighter than ||. The =~ operator matches a value in which * stands for any run of
+// characters. The zero value is ready to use.
+type PlacementConstraintEvaluator struct{}
+
+// Evaluate returns true if instance satisfies the expression. An error is
+// returned if the expression is not valid.
+func (PlacementConstraintEvaluator) Evaluate(expr string, instance *ecs.ContainerInstance) (bool, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return false, fmt.Errorf("placement constraint %q: %v", expr, err)
+	}
+	p := &expressionParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return false, fmt.Errorf("placement constraint %q: %v", expr, err)
+	}
+	return node.evaluate(instanceAttributes(instance)), nil
+}
+
+// instanceAttributes returns the values of the attributes of an instance,
+// keyed by name
+func instanceAttributes(instance *ecs.ContainerInstance) map[string]string {
+	attributes := make(map[string]string)
+	if instance == nil {
+		return attributes
+	}
+	for _, attribute := range instance.Attributes {
+		if attribute != nil {
+			attributes[aws.StringValue(attribute.Name)] = aws.StringValue(attribute.Value)
+		}
+	}
+	return attributes
+}
+
+// tokenizeExpression splits an expression into parentheses, operators and
+// words, such as subjects and values
+func tokenizeExpression(expr string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, expr[i:i+1])
+			i++
+		case strings.HasPrefix(expr[i:], "!exists"):
+			tokens = append(tokens, "!exists")
+			i += len("!exists")
+		case hasOperatorPrefix(expr[i:]):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case c == '!':
+			tokens = append(tokens, "!")
+			i++
+		case c == '=' || c == '&' || c == '|':
+			return nil, fmt.Errorf("unknown operator at %q", expr[i:])
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t()=!&|", rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, expr[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+// hasOperatorPrefix returns true if s starts with a two character operator
+func hasOperatorPrefix(s string) bool {
+	for _, operator := range []string{"==", "!=", "=~", "&&", "||"} {
+		if strings.HasPrefix(s, operator) {
+			return true
+		}
+	}
+	return false
+}
+
+// expressionNode is a node of a parsed expression
+type expressionNode interface {
+	evaluate(attributes map[string]string) bool
+}
+
+type orNode struct{ left, right expressionNode }
+
+func (n orNode) evaluate(attributes map[string]string) bool {
+	return n.left.evaluate(attributes) || n.right.evaluate(attributes)
+}
+
+type andNode struct{ left, right expressionNode }
+
+func (n andNode) evaluate(attributes map[string]string) bool {
+	return n.left.evaluate(attributes) && n.right.evaluate(attributes)
+}
+
+type notNode struct{ operand expressionNode }
+
+func (n notNode) evaluate(attributes map[string]string) bool {
+	return !n.operand.evaluate(attributes)
+}
+
+// comparisonNode compares an attribute with a value. An attribute that is
+// not set is only equal to nothing.
+type comparisonNode struct {
+	attribute string
+	operator  string
+	value     string
+	pattern   *regexp.Regexp
+}
+
+func (n comparisonNode) evaluate(attributes map[string]string) bool {
+	value, ok := attributes[n.attribute]
+	switch n.operator {
+	case "exists":
+		return ok
+	case "!exists":
+		return !ok
+	case "==":
+		return ok && value == n.value
+	case "!=":
+		return !ok || value != n.value
+	case "=~":
+		return ok && n.pattern.MatchString(value)
+	}
+	return false
+}
+
+// expressionParser parses tokens by recursive descent
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+// next returns the current token, or "" at the end of the tokens
+func (p *expressionParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *expressionParser) parseOr() (expressionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.next() == "||" || p.next() == "or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseAnd() (expressionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.next() == "&&" || p.next() == "and" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary() (expressionNode, error) {
+	switch p.next() {
+	case "!", "not":
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	case "(":
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *expressionParser) parseComparison() (expressionNode, error) {
+	subject := p.next()
+	if !strings.HasPrefix(subject, attributeSubjectPrefix) || len(subject) == len(attributeSubjectPrefix) {
+		if subject == "" {
+			return nil, fmt.Errorf("unexpected end of expression")
+		}
+		return nil, fmt.Errorf("unsupported subject %q, expected %s<name>", subject, attributeSubjectPrefix)
+	}
+	p.pos++
+	node := comparisonNode{attribute: strings.TrimPrefix(subject, attributeSubjectPrefix), operator: p.next()}
+	switch node.operator {
+	case "exists", "!exists":
+		p.pos++
+		return node, nil
+	case "==", "!=", "=~":
+		p.pos++
+	case "":
+		return nil, fmt.Errorf("missing operator after %s", subject)
+	default:
+		return nil, fmt.Errorf("unknown operator %q", node.operator)
+	}
+
+	node.value = p.next()
+	if node.value == "" || strings.ContainsAny(node.value, "()!") || hasOperatorPrefix(node.value) {
+		return nil, fmt.Errorf("missing value after %s %s", subject, node.operator)
+	}
+	p.pos++
+	if node.operator == "=~" {
+		pattern := strings.Replace(regexp.QuoteMeta(node.value), `\*`, ".*", -1)
+		node.pattern = regexp.MustCompile("^" + pattern + "$")
+	}
+	return node, nil
+}
@@ -0,0 +1,156 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPlacementInstance() *ecs.ContainerInstance {
+	return &ecs.ContainerInstance{
+		Attributes: []*ecs.Attribute{
+			{Name: aws.String("ecs.instance-type"), Value: aws.String("t3.micro")},
+			{Name: aws.String("ecs.availability-zone"), Value: aws.String("us-west-2a")},
+			{Name: aws.String("ecs.capability.task-eni")},
+			nil,
+		},
+	}
+}
+
+func TestPlacementConstraintEvaluator(t *testing.T) {
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{"attribute:ecs.instance-type == t3.micro", true},
+		{"attribute:ecs.instance-type == t3.small", false},
+		{"attribute:ecs.instance-type==t3.micro", true},
+		{"attribute:ecs.instance-type != t3.small", true},
+		{"attribute:ecs.instance-type != t3.micro", false},
+		{"attribute:ecs.os-type != linux", true},
+		{"attribute:ecs.instance-type =~ t3.*", true},
+		{"attribute:ecs.instance-type =~ *.micro", true},
+		{"attribute:ecs.instance-type =~ t2.*", false},
+		{"attribute:ecs.instance-type =~ t3", false},
+		{"attribute:ecs.instance-type =~ t3?micro", false},
+		{"attribute:ecs.os-type =~ *", false},
+		{"attribute:ecs.capability.task-eni exists", true},
+		{"attribute:ecs.os-type exists", false},
+		{"attribute:ecs.os-type !exists", true},
+		{"attribute:ecs.instance-type !exists", false},
+		{"!attribute:ecs.os-type exists", true},
+		{"not attribute:ecs.instance-type =~ t3.*", false},
+		{"!(attribute:ecs.instance-type == t3.micro)", false},
+		{"attribute:ecs.instance-type =~ t3.* && attribute:ecs.availability-zone == us-west-2a", true},
+		{"attribute:ecs.instance-type =~ t3.* and attribute:ecs.availability-zone == us-west-2b", false},
+		{"attribute:ecs.instance-type == t2.micro || attribute:ecs.availability-zone == us-west-2a", true},
+		{"attribute:ecs.instance-type == t2.micro or attribute:ecs.availability-zone == us-west-2b", false},
+		// && binds tighter than ||
+		{"attribute:ecs.os-type exists && attribute:ecs.instance-type == t2.micro || attribute:ecs.capability.task-eni exists", true},
+		{"attribute:ecs.os-type exists && (attribute:ecs.instance-type == t2.micro || attribute:ecs.capability.task-eni exists)", false},
+		{"(attribute:ecs.instance-type =~ t3.* || attribute:ecs.instance-type =~ m5.*) && !attribute:ecs.availability-zone == us-west-2c", true},
+	}
+
+	evaluator := PlacementConstraintEvaluator{}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			matches, err := evaluator.Evaluate(tc.expr, testPlacementInstance())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, matches)
+		})
+	}
+}
+
+func TestPlacementConstraintEvaluatorNilInstance(t *testing.T) {
+	evaluator := PlacementConstraintEvaluator{}
+	matches, err := evaluator.Evaluate("attribute:ecs.instance-type !exists", nil)
+	require.NoError(t, err)
+	assert.True(t, matches)
+}
+
+func TestPlacementConstraintEvaluatorInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"attribute:ecs.instance-type",
+		"attribute:ecs.instance-type ==",
+		"attribute:ecs.instance-type == (t3.micro)",
+		"attribute:ecs.instance-type > 1",
+		"attribute:ecs.instance-type = t3.micro",
+		"attribute: exists",
+		"ec2InstanceId == i-12345678",
+		"attribute:ecs.os-type exists &&",
+		"attribute:ecs.os-type exists & attribute:ecs.instance-type exists",
+		"(attribute:ecs.os-type exists",
+		"attribute:ecs.os-type exists)",
+		"attribute:ecs.os-type exists attribute:ecs.instance-type exists",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := PlacementConstraintEvaluator{}.Evaluate(expr, testPlacementInstance())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPlacementConstraintEvaluatorAttributeFilter(t *testing.T) {
+	instance := &ecs.ContainerInstance{Attributes: []*ecs.Attribute{
+		{Name: aws.String("env"), Value: aws.String("prod")},
+		{Name: aws.String("tier"), Value: aws.String("backend")},
+		{Name: aws.String("gpu")},
+	}}
+	evaluator := PlacementConstraintEvaluator{}
+
+	matches, err := evaluator.Evaluate(ecs.AttributeFilter(map[string]string{"env": "prod", "tier": "backend", "gpu": ""}), instance)
+	require.NoError(t, err)
+	assert.True(t, matches)
+	matches, err = evaluator.Evaluate(ecs.AttributeFilter(map[string]string{"env": "prod", "tier": "frontend"}), instance)
+	require.NoError(t, err)
+	assert.False(t, matches)
+}
+
+func TestPlacementConstraintEvaluatorConstraintBuilder(t *testing.T) {
+	gpu := func() *ecs.ConstraintBuilder { return ecs.NewConstraintBuilder().AttributeExists("gpu") }
+	microInstance := func() *ecs.ConstraintBuilder {
+		return ecs.NewConstraintBuilder().AttributeEquals("ecs.instance-type", "t3.micro")
+	}
+	testCases := []struct {
+		name     string
+		builder  *ecs.ConstraintBuilder
+		expected bool
+	}{
+		{"equals", microInstance(), true},
+		{"exists", ecs.NewConstraintBuilder().AttributeExists("ecs.capability.task-eni"), true},
+		{"and", microInstance().And(gpu()), false},
+		{"or", gpu().Or(microInstance()), true},
+		{"condition after or", gpu().Or(microInstance()).AttributeEquals("ecs.availability-zone", "us-west-2b"), false},
+		{"nested", gpu().Or(microInstance().And(ecs.NewConstraintBuilder().AttributeExists("ecs.capability.task-eni"))), true},
+	}
+
+	evaluator := PlacementConstraintEvaluator{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expression, err := tc.builder.Build()
+			require.NoError(t, err)
+			matches, err := evaluator.Evaluate(expression, testPlacementInstance())
+			require.NoError(t, err, expression)
+			assert.Equal(t, tc.expected, matches, expression)
+		})
+	}
+}
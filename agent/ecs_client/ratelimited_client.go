@@ -0,0 +1,416 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"sync"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"golang.org/x/time/rate"
+)
+
+// OperationLimit is the token bucket of an API operation: Rate tokens are
+// added per second, up to Burst, and each call takes one
+type OperationLimit struct {
+	Rate  rate.Limit
+	Burst int
+}
+
+// RateLimitedECS wraps an ecs.ECSClientInterface and holds calls back, rather
+// than letting them be throttled by ECS, once an operation's rate limit is
+// reached. Each operation has its own token bucket, and calls wait for a
+// token until their context is done. Operations without a limit are not
+// held back.
+type RateLimitedECS struct {
+	client ecs.ECSClientInterface
+
+	lock     sync.RWMutex
+	limiters map[string]*rate.Limiter
+}
+
+var _ ecs.ECSClientInterface = (*RateLimitedECS)(nil)
+
+// NewRateLimitedECS creates a RateLimitedECS that limits the calls made to
+// client with the given limits, keyed by operation name, such as
+// "DescribeTasks"
+func NewRateLimitedECS(client ecs.ECSClientInterface, limits map[string]OperationLimit) *RateLimitedECS {
+	r := &RateLimitedECS{
+		client:   client,
+		limiters: make(map[string]*rate.Limiter),
+	}
+	for operation, limit := range limits {
+		r.limiters[operation] = rate.NewLimiter(limit.Rate, limit.Burst)
+	}
+	return r
+}
+
+// SetLimit changes the limit of an operation, taking effect for the calls
+// that have not started waiting yet. A limit of rate.Inf lifts it.
+func (r *RateLimitedECS) SetLimit(operation string, limit OperationLimit) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	limiter, ok := r.limiters[operation]
+	if ok && limiter.Burst() == limit.Burst {
+		limiter.SetLimit(limit.Rate)
+		return
+	}
+	r.limiters[operation] = rate.NewLimiter(limit.Rate, limit.Burst)
+}
+
+// Limit returns the limit of an operation, and whether it has one
+func (r *RateLimitedECS) Limit(operation string) (OperationLimit, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	limiter, ok := r.limiters[operation]
+	if !ok {
+		return OperationLimit{}, false
+	}
+	return OperationLimit{Rate: limiter.Limit(), Burst: limiter.Burst()}, true
+}
+
+// wait blocks until a call to operation is allowed, or ctx is done
+func (r *RateLimitedECS) wait(ctx aws.Context, operation string) error {
+	r.lock.RLock()
+	limiter, ok := r.limiters[operation]
+	r.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// CreateClusterWithContext calls CreateClusterWithContext on the wrapped client once the
+// CreateCluster limit allows it
+func (r *RateLimitedECS) CreateClusterWithContext(ctx aws.Context, input *ecs.CreateClusterInput, opts ...request.Option) (*ecs.CreateClusterOutput, error) {
+	if err := r.wait(ctx, "CreateCluster"); err != nil {
+		return nil, err
+	}
+	return r.client.CreateClusterWithContext(ctx, input, opts...)
+}
+
+// CreateServiceWithContext calls CreateServiceWithContext on the wrapped client once the
+// CreateService limit allows it
+func (r *RateLimitedECS) CreateServiceWithContext(ctx aws.Context, input *ecs.CreateServiceInput, opts ...request.Option) (*ecs.CreateServiceOutput, error) {
+	if err := r.wait(ctx, "CreateService"); err != nil {
+		return nil, err
+	}
+	return r.client.CreateServiceWithContext(ctx, input, opts...)
+}
+
+// DeleteAccountSettingWithContext calls DeleteAccountSettingWithContext on the wrapped client once the
+// DeleteAccountSetting limit allows it
+func (r *RateLimitedECS) DeleteAccountSettingWithContext(ctx aws.Context, input *ecs.DeleteAccountSettingInput, opts ...request.Option) (*ecs.DeleteAccountSettingOutput, error) {
+	if err := r.wait(ctx, "DeleteAccountSetting"); err != nil {
+		return nil, err
+	}
+	return r.client.DeleteAccountSettingWithContext(ctx, input, opts...)
+}
+
+// DeleteAttributesWithContext calls DeleteAttributesWithContext on the wrapped client once the
+// DeleteAttributes limit allows it
+func (r *RateLimitedECS) DeleteAttributesWithContext(ctx aws.Context, input *ecs.DeleteAttributesInput, opts ...request.Option) (*ecs.DeleteAttributesOutput, error) {
+	if err := r.wait(ctx, "DeleteAttributes"); err != nil {
+		return nil, err
+	}
+	return r.client.DeleteAttributesWithContext(ctx, input, opts...)
+}
+
+// DeleteClusterWithContext calls DeleteClusterWithContext on the wrapped client once the
+// DeleteCluster limit allows it
+func (r *RateLimitedECS) DeleteClusterWithContext(ctx aws.Context, input *ecs.DeleteClusterInput, opts ...request.Option) (*ecs.DeleteClusterOutput, error) {
+	if err := r.wait(ctx, "DeleteCluster"); err != nil {
+		return nil, err
+	}
+	return r.client.DeleteClusterWithContext(ctx, input, opts...)
+}
+
+// DeleteServiceWithContext calls DeleteServiceWithContext on the wrapped client once the
+// DeleteService limit allows it
+func (r *RateLimitedECS) DeleteServiceWithContext(ctx aws.Context, input *ecs.DeleteServiceInput, opts ...request.Option) (*ecs.DeleteServiceOutput, error) {
+	if err := r.wait(ctx, "DeleteService"); err != nil {
+		return nil, err
+	}
+	return r.client.DeleteServiceWithContext(ctx, input, opts...)
+}
+
+// DeregisterContainerInstanceWithContext calls DeregisterContainerInstanceWithContext on the wrapped client once the
+// DeregisterContainerInstance limit allows it
+func (r *RateLimitedECS) DeregisterContainerInstanceWithContext(ctx aws.Context, input *ecs.DeregisterContainerInstanceInput, opts ...request.Option) (*ecs.DeregisterContainerInstanceOutput, error) {
+	if err := r.wait(ctx, "DeregisterContainerInstance"); err != nil {
+		return nil, err
+	}
+	return r.client.DeregisterContainerInstanceWithContext(ctx, input, opts...)
+}
+
+// DeregisterTaskDefinitionWithContext calls DeregisterTaskDefinitionWithContext on the wrapped client once the
+// DeregisterTaskDefinition limit allows it
+func (r *RateLimitedECS) DeregisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.DeregisterTaskDefinitionInput, opts ...request.Option) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	if err := r.wait(ctx, "DeregisterTaskDefinition"); err != nil {
+		return nil, err
+	}
+	return r.client.DeregisterTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// DescribeClustersWithContext calls DescribeClustersWithContext on the wrapped client once the
+// DescribeClusters limit allows it
+func (r *RateLimitedECS) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput, opts ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	if err := r.wait(ctx, "DescribeClusters"); err != nil {
+		return nil, err
+	}
+	return r.client.DescribeClustersWithContext(ctx, input, opts...)
+}
+
+// DescribeContainerInstancesWithContext calls DescribeContainerInstancesWithContext on the wrapped client once the
+// DescribeContainerInstances limit allows it
+func (r *RateLimitedECS) DescribeContainerInstancesWithContext(ctx aws.Context, input *ecs.DescribeContainerInstancesInput, opts ...request.Option) (*ecs.DescribeContainerInstancesOutput, error) {
+	if err := r.wait(ctx, "DescribeContainerInstances"); err != nil {
+		return nil, err
+	}
+	return r.client.DescribeContainerInstancesWithContext(ctx, input, opts...)
+}
+
+// DescribeServicesWithContext calls DescribeServicesWithContext on the wrapped client once the
+// DescribeServices limit allows it
+func (r *RateLimitedECS) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	if err := r.wait(ctx, "DescribeServices"); err != nil {
+		return nil, err
+	}
+	return r.client.DescribeServicesWithContext(ctx, input, opts...)
+}
+
+// DescribeTaskDefinitionWithContext calls DescribeTaskDefinitionWithContext on the wrapped client once the
+// DescribeTaskDefinition limit allows it
+func (r *RateLimitedECS) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	if err := r.wait(ctx, "DescribeTaskDefinition"); err != nil {
+		return nil, err
+	}
+	return r.client.DescribeTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// DescribeTasksWithContext calls DescribeTasksWithContext on the wrapped client once the
+// DescribeTasks limit allows it
+func (r *RateLimitedECS) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	if err := r.wait(ctx, "DescribeTasks"); err != nil {
+		return nil, err
+	}
+	return r.client.DescribeTasksWithContext(ctx, input, opts...)
+}
+
+// DiscoverPollEndpointWithContext calls DiscoverPollEndpointWithContext on the wrapped client once the
+// DiscoverPollEndpoint limit allows it
+func (r *RateLimitedECS) DiscoverPollEndpointWithContext(ctx aws.Context, input *ecs.DiscoverPollEndpointInput, opts ...request.Option) (*ecs.DiscoverPollEndpointOutput, error) {
+	if err := r.wait(ctx, "DiscoverPollEndpoint"); err != nil {
+		return nil, err
+	}
+	return r.client.DiscoverPollEndpointWithContext(ctx, input, opts...)
+}
+
+// ListAccountSettingsWithContext calls ListAccountSettingsWithContext on the wrapped client once the
+// ListAccountSettings limit allows it
+func (r *RateLimitedECS) ListAccountSettingsWithContext(ctx aws.Context, input *ecs.ListAccountSettingsInput, opts ...request.Option) (*ecs.ListAccountSettingsOutput, error) {
+	if err := r.wait(ctx, "ListAccountSettings"); err != nil {
+		return nil, err
+	}
+	return r.client.ListAccountSettingsWithContext(ctx, input, opts...)
+}
+
+// ListAttributesWithContext calls ListAttributesWithContext on the wrapped client once the
+// ListAttributes limit allows it
+func (r *RateLimitedECS) ListAttributesWithContext(ctx aws.Context, input *ecs.ListAttributesInput, opts ...request.Option) (*ecs.ListAttributesOutput, error) {
+	if err := r.wait(ctx, "ListAttributes"); err != nil {
+		return nil, err
+	}
+	return r.client.ListAttributesWithContext(ctx, input, opts...)
+}
+
+// ListClustersWithContext calls ListClustersWithContext on the wrapped client once the
+// ListClusters limit allows it
+func (r *RateLimitedECS) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	if err := r.wait(ctx, "ListClusters"); err != nil {
+		return nil, err
+	}
+	return r.client.ListClustersWithContext(ctx, input, opts...)
+}
+
+// ListContainerInstancesWithContext calls ListContainerInstancesWithContext on the wrapped client once the
+// ListContainerInstances limit allows it
+func (r *RateLimitedECS) ListContainerInstancesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput, opts ...request.Option) (*ecs.ListContainerInstancesOutput, error) {
+	if err := r.wait(ctx, "ListContainerInstances"); err != nil {
+		return nil, err
+	}
+	return r.client.ListContainerInstancesWithContext(ctx, input, opts...)
+}
+
+// ListServicesWithContext calls ListServicesWithContext on the wrapped client once the
+// ListServices limit allows it
+func (r *RateLimitedECS) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput, opts ...request.Option) (*ecs.ListServicesOutput, error) {
+	if err := r.wait(ctx, "ListServices"); err != nil {
+		return nil, err
+	}
+	return r.client.ListServicesWithContext(ctx, input, opts...)
+}
+
+// ListTagsForResourceWithContext calls ListTagsForResourceWithContext on the wrapped client once the
+// ListTagsForResource limit allows it
+func (r *RateLimitedECS) ListTagsForResourceWithContext(ctx aws.Context, input *ecs.ListTagsForResourceInput, opts ...request.Option) (*ecs.ListTagsForResourceOutput, error) {
+	if err := r.wait(ctx, "ListTagsForResource"); err != nil {
+		return nil, err
+	}
+	return r.client.ListTagsForResourceWithContext(ctx, input, opts...)
+}
+
+// ListTaskDefinitionFamiliesWithContext calls ListTaskDefinitionFamiliesWithContext on the wrapped client once the
+// ListTaskDefinitionFamilies limit allows it
+func (r *RateLimitedECS) ListTaskDefinitionFamiliesWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionFamiliesInput, opts ...request.Option) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	if err := r.wait(ctx, "ListTaskDefinitionFamilies"); err != nil {
+		return nil, err
+	}
+	return r.client.ListTaskDefinitionFamiliesWithContext(ctx, input, opts...)
+}
+
+// ListTaskDefinitionsWithContext calls ListTaskDefinitionsWithContext on the wrapped client once the
+// ListTaskDefinitions limit allows it
+func (r *RateLimitedECS) ListTaskDefinitionsWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput, opts ...request.Option) (*ecs.ListTaskDefinitionsOutput, error) {
+	if err := r.wait(ctx, "ListTaskDefinitions"); err != nil {
+		return nil, err
+	}
+	return r.client.ListTaskDefinitionsWithContext(ctx, input, opts...)
+}
+
+// ListTasksWithContext calls ListTasksWithContext on the wrapped client once the
+// ListTasks limit allows it
+func (r *RateLimitedECS) ListTasksWithContext(ctx aws.Context, input *ecs.ListTasksInput, opts ...request.Option) (*ecs.ListTasksOutput, error) {
+	if err := r.wait(ctx, "ListTasks"); err != nil {
+		return nil, err
+	}
+	return r.client.ListTasksWithContext(ctx, input, opts...)
+}
+
+// PutAccountSettingWithContext calls PutAccountSettingWithContext on the wrapped client once the
+// PutAccountSetting limit allows it
+func (r *RateLimitedECS) PutAccountSettingWithContext(ctx aws.Context, input *ecs.PutAccountSettingInput, opts ...request.Option) (*ecs.PutAccountSettingOutput, error) {
+	if err := r.wait(ctx, "PutAccountSetting"); err != nil {
+		return nil, err
+	}
+	return r.client.PutAccountSettingWithContext(ctx, input, opts...)
+}
+
+// PutAttributesWithContext calls PutAttributesWithContext on the wrapped client once the
+// PutAttributes limit allows it
+func (r *RateLimitedECS) PutAttributesWithContext(ctx aws.Context, input *ecs.PutAttributesInput, opts ...request.Option) (*ecs.PutAttributesOutput, error) {
+	if err := r.wait(ctx, "PutAttributes"); err != nil {
+		return nil, err
+	}
+	return r.client.PutAttributesWithContext(ctx, input, opts...)
+}
+
+// RegisterContainerInstanceWithContext calls RegisterContainerInstanceWithContext on the wrapped client once the
+// RegisterContainerInstance limit allows it
+func (r *RateLimitedECS) RegisterContainerInstanceWithContext(ctx aws.Context, input *ecs.RegisterContainerInstanceInput, opts ...request.Option) (*ecs.RegisterContainerInstanceOutput, error) {
+	if err := r.wait(ctx, "RegisterContainerInstance"); err != nil {
+		return nil, err
+	}
+	return r.client.RegisterContainerInstanceWithContext(ctx, input, opts...)
+}
+
+// RegisterTaskDefinitionWithContext calls RegisterTaskDefinitionWithContext on the wrapped client once the
+// RegisterTaskDefinition limit allows it
+func (r *RateLimitedECS) RegisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.RegisterTaskDefinitionInput, opts ...request.Option) (*ecs.RegisterTaskDefinitionOutput, error) {
+	if err := r.wait(ctx, "RegisterTaskDefinition"); err != nil {
+		return nil, err
+	}
+	return r.client.RegisterTaskDefinitionWithContext(ctx, input, opts...)
+}
+
+// RunTaskBatch makes the RunTask calls of the batch through the
+// RateLimitedECS, so that each of them takes a RunTask token
+func (r *RateLimitedECS) RunTaskBatch(ctx aws.Context, input *ecs.RunTaskInput, count int) ([]*ecs.Task, []*ecs.Failure, error) {
+	return ecs.RunTaskInChunks(ctx, r, input, count)
+}
+
+// RunTaskWithContext calls RunTaskWithContext on the wrapped client once the
+// RunTask limit allows it
+func (r *RateLimitedECS) RunTaskWithContext(ctx aws.Context, input *ecs.RunTaskInput, opts ...request.Option) (*ecs.RunTaskOutput, error) {
+	if err := r.wait(ctx, "RunTask"); err != nil {
+		return nil, err
+	}
+	return r.client.RunTaskWithContext(ctx, input, opts...)
+}
+
+// StartTaskWithContext calls StartTaskWithContext on the wrapped client once the
+// StartTask limit allows it
+func (r *RateLimitedECS) StartTaskWithContext(ctx aws.Context, input *ecs.StartTaskInput, opts ...request.Option) (*ecs.StartTaskOutput, error) {
+	if err := r.wait(ctx, "StartTask"); err != nil {
+		return nil, err
+	}
+	return r.client.StartTaskWithContext(ctx, input, opts...)
+}
+
+// StopTaskWithContext calls StopTaskWithContext on the wrapped client once the
+// StopTask limit allows it
+func (r *RateLimitedECS) StopTaskWithContext(ctx aws.Context, input *ecs.StopTaskInput, opts ...request.Option) (*ecs.StopTaskOutput, error) {
+	if err := r.wait(ctx, "StopTask"); err != nil {
+		return nil, err
+	}
+	return r.client.StopTaskWithContext(ctx, input, opts...)
+}
+
+// SubmitContainerStateChangeWithContext calls SubmitContainerStateChangeWithContext on the wrapped client once the
+// SubmitContainerStateChange limit allows it
+func (r *RateLimitedECS) SubmitContainerStateChangeWithContext(ctx aws.Context, input *ecs.SubmitContainerStateChangeInput, opts ...request.Option) (*ecs.SubmitContainerStateChangeOutput, error) {
+	if err := r.wait(ctx, "SubmitContainerStateChange"); err != nil {
+		return nil, err
+	}
+	return r.client.SubmitContainerStateChangeWithContext(ctx, input, opts...)
+}
+
+// SubmitTaskStateChangeWithContext calls SubmitTaskStateChangeWithContext on the wrapped client once the
+// SubmitTaskStateChange limit allows it
+func (r *RateLimitedECS) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	if err := r.wait(ctx, "SubmitTaskStateChange"); err != nil {
+		return nil, err
+	}
+	return r.client.SubmitTaskStateChangeWithContext(ctx, input, opts...)
+}
+
+// UpdateContainerAgentWithContext calls UpdateContainerAgentWithContext on the wrapped client once the
+// UpdateContainerAgent limit allows it
+func (r *RateLimitedECS) UpdateContainerAgentWithContext(ctx aws.Context, input *ecs.UpdateContainerAgentInput, opts ...request.Option) (*ecs.UpdateContainerAgentOutput, error) {
+	if err := r.wait(ctx, "UpdateContainerAgent"); err != nil {
+		return nil, err
+	}
+	return r.client.UpdateContainerAgentWithContext(ctx, input, opts...)
+}
+
+// UpdateContainerInstancesStateWithContext calls UpdateContainerInstancesStateWithContext on the wrapped client once the
+// UpdateContainerInstancesState limit allows it
+func (r *RateLimitedECS) UpdateContainerInstancesStateWithContext(ctx aws.Context, input *ecs.UpdateContainerInstancesStateInput, opts ...request.Option) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	if err := r.wait(ctx, "UpdateContainerInstancesState"); err != nil {
+		return nil, err
+	}
+	return r.client.UpdateContainerInstancesStateWithContext(ctx, input, opts...)
+}
+
+// UpdateServiceWithContext calls UpdateServiceWithContext on the wrapped client once the
+// UpdateService limit allows it
+func (r *RateLimitedECS) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput, opts ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	if err := r.wait(ctx, "UpdateService"); err != nil {
+		return nil, err
+	}
+	return r.client.UpdateServiceWithContext(ctx, input, opts...)
+}
@@ -0,0 +1,105 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/ecsfake"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitedECSUnlimitedOperation(t *testing.T) {
+	client := NewRateLimitedECS(ecsfake.NewServer(), map[string]OperationLimit{
+		"DescribeTasks": {Rate: rate.Every(time.Hour), Burst: 1},
+	})
+
+	// ListClusters has no limit, so calls are never held back
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 10; i++ {
+		_, err := client.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+		require.NoError(t, err)
+	}
+	_, ok := client.Limit("ListClusters")
+	assert.False(t, ok)
+}
+
+func TestRateLimitedECSBlocksWhenExhausted(t *testing.T) {
+	client := NewRateLimitedECS(ecsfake.NewServer(), map[string]OperationLimit{
+		"ListClusters": {Rate: rate.Every(time.Hour), Burst: 2},
+	})
+
+	// The burst lets the first calls through
+	for i := 0; i < 2; i++ {
+		_, err := client.ListClustersWithContext(context.Background(), &ecs.ListClustersInput{})
+		require.NoError(t, err)
+	}
+
+	// The next call would wait for an hour, which outlives its context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+	assert.Error(t, err)
+}
+
+func TestRateLimitedECSWaitsForToken(t *testing.T) {
+	client := NewRateLimitedECS(ecsfake.NewServer(), map[string]OperationLimit{
+		"ListClusters": {Rate: rate.Every(50 * time.Millisecond), Burst: 1},
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.ListClustersWithContext(context.Background(), &ecs.ListClustersInput{})
+		require.NoError(t, err)
+	}
+	assert.True(t, time.Since(start) >= 90*time.Millisecond,
+		"the second and third calls should each wait for a token, took %s", time.Since(start))
+}
+
+func TestRateLimitedECSSetLimit(t *testing.T) {
+	client := NewRateLimitedECS(ecsfake.NewServer(), map[string]OperationLimit{
+		"ListClusters": {Rate: rate.Every(time.Hour), Burst: 1},
+	})
+	_, err := client.ListClustersWithContext(context.Background(), &ecs.ListClustersInput{})
+	require.NoError(t, err)
+
+	// Lifting the limit unblocks the operation
+	client.SetLimit("ListClusters", OperationLimit{Rate: rate.Inf, Burst: 1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = client.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+	require.NoError(t, err)
+
+	// Limits can be added to operations that had none, and changed in place
+	client.SetLimit("DescribeTasks", OperationLimit{Rate: 100, Burst: 10})
+	client.SetLimit("DescribeTasks", OperationLimit{Rate: 50, Burst: 10})
+	limit, ok := client.Limit("DescribeTasks")
+	require.True(t, ok)
+	assert.Equal(t, OperationLimit{Rate: 50, Burst: 10}, limit)
+}
+
+func TestRateLimitedECSInterfaceCompliance(t *testing.T) {
+	ecsfake.TestInterfaceCompliance(t, NewRateLimitedECS(ecsfake.NewServer(), map[string]OperationLimit{
+		"DescribeTasks": {Rate: 100, Burst: 10},
+		"RunTask":       {Rate: 100, Burst: 10},
+	}))
+}
@@ -0,0 +1,528 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package ecsclient provides wrappers around ecs.ECSClientInterface that add
+// behaviour, such as retries, to the calls made to the ECS API.
+package ecsclient
+
+import (
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times RetryingECS makes a call,
+	// including the first attempt, when no other value is given
+	DefaultMaxAttempts = 5
+
+	defaultBackoffBase = 100 * time.Millisecond
+	defaultBackoffMax  = 5 * time.Second
+)
+
+// DefaultBackoff is the delay curve used by RetryingECS when no BackoffFunc
+// is given
+var DefaultBackoff = NewExponentialBackoff(defaultBackoffBase, defaultBackoffMax)
+
+// RetryingECS wraps an ecs.ECSClientInterface and retries calls that fail with
+// a throttling, server side or connection error. The delay between attempts
+// is given by a BackoffFunc. Retries stop early when the context of the call
+// is done.
+//
+// When wrapping an *ecs.ECS, its own retries should be turned off with
+// MaxRetries, so that the two do not compound.
+type RetryingECS struct {
+	client      ecs.ECSClientInterface
+	maxAttempts int
+	backoff     BackoffFunc
+}
+
+var _ ecs.ECSClientInterface = (*RetryingECS)(nil)
+
+// NewRetryingECS creates a RetryingECS that makes up to maxAttempts attempts
+// of each call, waiting between them as given by backoff. DefaultMaxAttempts
+// and DefaultBackoff are used when maxAttempts is not positive or backoff is
+// nil.
+func NewRetryingECS(client ecs.ECSClientInterface, maxAttempts int, backoff BackoffFunc) *RetryingECS {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+	return &RetryingECS{
+		client:      client,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// retry calls fn until it succeeds, fails with an error that cannot be
+// retried, or maxAttempts is reached. The last error is returned.
+func (r *RetryingECS) retry(ctx aws.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < r.maxAttempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(r.backoff(attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+		err = fn()
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// isRetryable returns true for the errors that are worth retrying: throttling
+// errors, server errors and failures to send the request
+func isRetryable(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ecs.ErrCodeServerException {
+		return true
+	}
+	return request.IsErrorThrottle(err) || request.IsErrorRetryable(err)
+}
+
+// CreateClusterWithContext calls CreateClusterWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) CreateClusterWithContext(ctx aws.Context, input *ecs.CreateClusterInput, opts ...request.Option) (*ecs.CreateClusterOutput, error) {
+	var output *ecs.CreateClusterOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.CreateClusterWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// CreateServiceWithContext calls CreateServiceWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) CreateServiceWithContext(ctx aws.Context, input *ecs.CreateServiceInput, opts ...request.Option) (*ecs.CreateServiceOutput, error) {
+	var output *ecs.CreateServiceOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.CreateServiceWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DeleteAccountSettingWithContext calls DeleteAccountSettingWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DeleteAccountSettingWithContext(ctx aws.Context, input *ecs.DeleteAccountSettingInput, opts ...request.Option) (*ecs.DeleteAccountSettingOutput, error) {
+	var output *ecs.DeleteAccountSettingOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DeleteAccountSettingWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DeleteAttributesWithContext calls DeleteAttributesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DeleteAttributesWithContext(ctx aws.Context, input *ecs.DeleteAttributesInput, opts ...request.Option) (*ecs.DeleteAttributesOutput, error) {
+	var output *ecs.DeleteAttributesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DeleteAttributesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DeleteClusterWithContext calls DeleteClusterWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DeleteClusterWithContext(ctx aws.Context, input *ecs.DeleteClusterInput, opts ...request.Option) (*ecs.DeleteClusterOutput, error) {
+	var output *ecs.DeleteClusterOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DeleteClusterWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DeleteServiceWithContext calls DeleteServiceWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DeleteServiceWithContext(ctx aws.Context, input *ecs.DeleteServiceInput, opts ...request.Option) (*ecs.DeleteServiceOutput, error) {
+	var output *ecs.DeleteServiceOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DeleteServiceWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DeregisterContainerInstanceWithContext calls DeregisterContainerInstanceWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DeregisterContainerInstanceWithContext(ctx aws.Context, input *ecs.DeregisterContainerInstanceInput, opts ...request.Option) (*ecs.DeregisterContainerInstanceOutput, error) {
+	var output *ecs.DeregisterContainerInstanceOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DeregisterContainerInstanceWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DeregisterTaskDefinitionWithContext calls DeregisterTaskDefinitionWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DeregisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.DeregisterTaskDefinitionInput, opts ...request.Option) (*ecs.DeregisterTaskDefinitionOutput, error) {
+	var output *ecs.DeregisterTaskDefinitionOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DeregisterTaskDefinitionWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DescribeClustersWithContext calls DescribeClustersWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DescribeClustersWithContext(ctx aws.Context, input *ecs.DescribeClustersInput, opts ...request.Option) (*ecs.DescribeClustersOutput, error) {
+	var output *ecs.DescribeClustersOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DescribeClustersWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DescribeContainerInstancesWithContext calls DescribeContainerInstancesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DescribeContainerInstancesWithContext(ctx aws.Context, input *ecs.DescribeContainerInstancesInput, opts ...request.Option) (*ecs.DescribeContainerInstancesOutput, error) {
+	var output *ecs.DescribeContainerInstancesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DescribeContainerInstancesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DescribeServicesWithContext calls DescribeServicesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DescribeServicesWithContext(ctx aws.Context, input *ecs.DescribeServicesInput, opts ...request.Option) (*ecs.DescribeServicesOutput, error) {
+	var output *ecs.DescribeServicesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DescribeServicesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DescribeTaskDefinitionWithContext calls DescribeTaskDefinitionWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DescribeTaskDefinitionWithContext(ctx aws.Context, input *ecs.DescribeTaskDefinitionInput, opts ...request.Option) (*ecs.DescribeTaskDefinitionOutput, error) {
+	var output *ecs.DescribeTaskDefinitionOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DescribeTaskDefinitionWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DescribeTasksWithContext calls DescribeTasksWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DescribeTasksWithContext(ctx aws.Context, input *ecs.DescribeTasksInput, opts ...request.Option) (*ecs.DescribeTasksOutput, error) {
+	var output *ecs.DescribeTasksOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DescribeTasksWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// DiscoverPollEndpointWithContext calls DiscoverPollEndpointWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) DiscoverPollEndpointWithContext(ctx aws.Context, input *ecs.DiscoverPollEndpointInput, opts ...request.Option) (*ecs.DiscoverPollEndpointOutput, error) {
+	var output *ecs.DiscoverPollEndpointOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.DiscoverPollEndpointWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListAccountSettingsWithContext calls ListAccountSettingsWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListAccountSettingsWithContext(ctx aws.Context, input *ecs.ListAccountSettingsInput, opts ...request.Option) (*ecs.ListAccountSettingsOutput, error) {
+	var output *ecs.ListAccountSettingsOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListAccountSettingsWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListAttributesWithContext calls ListAttributesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListAttributesWithContext(ctx aws.Context, input *ecs.ListAttributesInput, opts ...request.Option) (*ecs.ListAttributesOutput, error) {
+	var output *ecs.ListAttributesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListAttributesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListClustersWithContext calls ListClustersWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	var output *ecs.ListClustersOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListClustersWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListContainerInstancesWithContext calls ListContainerInstancesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListContainerInstancesWithContext(ctx aws.Context, input *ecs.ListContainerInstancesInput, opts ...request.Option) (*ecs.ListContainerInstancesOutput, error) {
+	var output *ecs.ListContainerInstancesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListContainerInstancesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListServicesWithContext calls ListServicesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListServicesWithContext(ctx aws.Context, input *ecs.ListServicesInput, opts ...request.Option) (*ecs.ListServicesOutput, error) {
+	var output *ecs.ListServicesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListServicesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListTagsForResourceWithContext calls ListTagsForResourceWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListTagsForResourceWithContext(ctx aws.Context, input *ecs.ListTagsForResourceInput, opts ...request.Option) (*ecs.ListTagsForResourceOutput, error) {
+	var output *ecs.ListTagsForResourceOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListTagsForResourceWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListTaskDefinitionFamiliesWithContext calls ListTaskDefinitionFamiliesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListTaskDefinitionFamiliesWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionFamiliesInput, opts ...request.Option) (*ecs.ListTaskDefinitionFamiliesOutput, error) {
+	var output *ecs.ListTaskDefinitionFamiliesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListTaskDefinitionFamiliesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListTaskDefinitionsWithContext calls ListTaskDefinitionsWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListTaskDefinitionsWithContext(ctx aws.Context, input *ecs.ListTaskDefinitionsInput, opts ...request.Option) (*ecs.ListTaskDefinitionsOutput, error) {
+	var output *ecs.ListTaskDefinitionsOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListTaskDefinitionsWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// ListTasksWithContext calls ListTasksWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) ListTasksWithContext(ctx aws.Context, input *ecs.ListTasksInput, opts ...request.Option) (*ecs.ListTasksOutput, error) {
+	var output *ecs.ListTasksOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.ListTasksWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// PutAccountSettingWithContext calls PutAccountSettingWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) PutAccountSettingWithContext(ctx aws.Context, input *ecs.PutAccountSettingInput, opts ...request.Option) (*ecs.PutAccountSettingOutput, error) {
+	var output *ecs.PutAccountSettingOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.PutAccountSettingWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// PutAttributesWithContext calls PutAttributesWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) PutAttributesWithContext(ctx aws.Context, input *ecs.PutAttributesInput, opts ...request.Option) (*ecs.PutAttributesOutput, error) {
+	var output *ecs.PutAttributesOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.PutAttributesWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// RegisterContainerInstanceWithContext calls RegisterContainerInstanceWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) RegisterContainerInstanceWithContext(ctx aws.Context, input *ecs.RegisterContainerInstanceInput, opts ...request.Option) (*ecs.RegisterContainerInstanceOutput, error) {
+	var output *ecs.RegisterContainerInstanceOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.RegisterContainerInstanceWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// RegisterTaskDefinitionWithContext calls RegisterTaskDefinitionWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) RegisterTaskDefinitionWithContext(ctx aws.Context, input *ecs.RegisterTaskDefinitionInput, opts ...request.Option) (*ecs.RegisterTaskDefinitionOutput, error) {
+	var output *ecs.RegisterTaskDefinitionOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.RegisterTaskDefinitionWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// RunTaskBatch makes the RunTask calls of the batch through the RetryingECS,
+// so that each of them is retried on its own
+func (r *RetryingECS) RunTaskBatch(ctx aws.Context, input *ecs.RunTaskInput, count int) ([]*ecs.Task, []*ecs.Failure, error) {
+	return ecs.RunTaskInChunks(ctx, r, input, count)
+}
+
+// RunTaskWithContext calls RunTaskWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) RunTaskWithContext(ctx aws.Context, input *ecs.RunTaskInput, opts ...request.Option) (*ecs.RunTaskOutput, error) {
+	var output *ecs.RunTaskOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.RunTaskWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// StartTaskWithContext calls StartTaskWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) StartTaskWithContext(ctx aws.Context, input *ecs.StartTaskInput, opts ...request.Option) (*ecs.StartTaskOutput, error) {
+	var output *ecs.StartTaskOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.StartTaskWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// StopTaskWithContext calls StopTaskWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) StopTaskWithContext(ctx aws.Context, input *ecs.StopTaskInput, opts ...request.Option) (*ecs.StopTaskOutput, error) {
+	var output *ecs.StopTaskOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.StopTaskWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// SubmitContainerStateChangeWithContext calls SubmitContainerStateChangeWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) SubmitContainerStateChangeWithContext(ctx aws.Context, input *ecs.SubmitContainerStateChangeInput, opts ...request.Option) (*ecs.SubmitContainerStateChangeOutput, error) {
+	var output *ecs.SubmitContainerStateChangeOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.SubmitContainerStateChangeWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// SubmitTaskStateChangeWithContext calls SubmitTaskStateChangeWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	var output *ecs.SubmitTaskStateChangeOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.SubmitTaskStateChangeWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// UpdateContainerAgentWithContext calls UpdateContainerAgentWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) UpdateContainerAgentWithContext(ctx aws.Context, input *ecs.UpdateContainerAgentInput, opts ...request.Option) (*ecs.UpdateContainerAgentOutput, error) {
+	var output *ecs.UpdateContainerAgentOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.UpdateContainerAgentWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// UpdateContainerInstancesStateWithContext calls UpdateContainerInstancesStateWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) UpdateContainerInstancesStateWithContext(ctx aws.Context, input *ecs.UpdateContainerInstancesStateInput, opts ...request.Option) (*ecs.UpdateContainerInstancesStateOutput, error) {
+	var output *ecs.UpdateContainerInstancesStateOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.UpdateContainerInstancesStateWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
+
+// UpdateServiceWithContext calls UpdateServiceWithContext on the wrapped client, retrying on
+// retryable errors
+func (r *RetryingECS) UpdateServiceWithContext(ctx aws.Context, input *ecs.UpdateServiceInput, opts ...request.Option) (*ecs.UpdateServiceOutput, error) {
+	var output *ecs.UpdateServiceOutput
+	err := r.retry(ctx, func() error {
+		var err error
+		output, err = r.client.UpdateServiceWithContext(ctx, input, opts...)
+		return err
+	})
+	return output, err
+}
@@ -0,0 +1,195 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/ecsfake"
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyListClusters fails ListClusters with the given errors, in order, before
+// succeeding
+type flakyListClusters struct {
+	ecs.ECSClientInterface
+	errs  []error
+	calls int
+}
+
+func (c *flakyListClusters) ListClustersWithContext(ctx aws.Context, input *ecs.ListClustersInput, opts ...request.Option) (*ecs.ListClustersOutput, error) {
+	c.calls++
+	if c.calls <= len(c.errs) {
+		return nil, c.errs[c.calls-1]
+	}
+	return &ecs.ListClustersOutput{ClusterArns: aws.StringSlice([]string{"arn"})}, nil
+}
+
+func noBackoff(int) time.Duration { return 0 }
+
+func TestRetryingECSRetriesRetryableErrors(t *testing.T) {
+	flaky := &flakyListClusters{errs: []error{
+		awserr.New(ecs.ErrCodeServerException, "server error", nil),
+		awserr.New("ThrottlingException", "slow down", nil),
+	}}
+	var attempts []int
+	client := NewRetryingECS(flaky, 3, func(attempt int) time.Duration {
+		attempts = append(attempts, attempt)
+		return 0
+	})
+
+	output, err := client.ListClustersWithContext(aws.BackgroundContext(), &ecs.ListClustersInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"arn"}, aws.StringValueSlice(output.ClusterArns))
+	assert.Equal(t, 3, flaky.calls)
+	assert.Equal(t, []int{0, 1}, attempts, "the backoff should be asked for each retry")
+}
+
+func TestRetryingECSGivesUpAfterMaxAttempts(t *testing.T) {
+	serverErr := awserr.New(ecs.ErrCodeServerException, "server error", nil)
+	flaky := &flakyListClusters{errs: []error{serverErr, serverErr, serverErr}}
+	client := NewRetryingECS(flaky, 2, noBackoff)
+
+	_, err := client.ListClustersWithContext(aws.BackgroundContext(), &ecs.ListClustersInput{})
+	assert.Equal(t, serverErr, err)
+	assert.Equal(t, 2, flaky.calls)
+}
+
+func TestRetryingECSDoesNotRetryClientErrors(t *testing.T) {
+	for _, clientErr := range []error{
+		awserr.New(ecs.ErrCodeClientException, "client error", nil),
+		errors.New("not an aws error"),
+	} {
+		flaky := &flakyListClusters{errs: []error{clientErr}}
+		client := NewRetryingECS(flaky, 3, noBackoff)
+
+		_, err := client.ListClustersWithContext(aws.BackgroundContext(), &ecs.ListClustersInput{})
+		assert.Equal(t, clientErr, err)
+		assert.Equal(t, 1, flaky.calls)
+	}
+}
+
+func TestRetryingECSStopsWhenContextIsDone(t *testing.T) {
+	serverErr := awserr.New(ecs.ErrCodeServerException, "server error", nil)
+	flaky := &flakyListClusters{errs: []error{serverErr, serverErr}}
+	client := NewRetryingECS(flaky, 3, NewLinearBackoff(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.ListClustersWithContext(ctx, &ecs.ListClustersInput{})
+	assert.Equal(t, serverErr, err)
+	assert.Equal(t, 1, flaky.calls)
+}
+
+func TestRetryingECSDefaults(t *testing.T) {
+	client := NewRetryingECS(ecsfake.NewServer(), 0, nil)
+
+	assert.Equal(t, DefaultMaxAttempts, client.maxAttempts)
+	assert.NotNil(t, client.backoff)
+}
+
+func TestRetryingECSInterfaceCompliance(t *testing.T) {
+	ecsfake.TestInterfaceCompliance(t, NewRetryingECS(ecsfake.NewServer(), 1, noBackoff))
+}
+
+func TestRetryingECSRecoversFromInjectedFailures(t *testing.T) {
+	var options []ecsfake.Option
+	for _, operation := range []string{"CreateCluster", "RegisterTaskDefinition", "CreateService", "DescribeServices"} {
+		options = append(options, ecsfake.DeterministicFailures(operation, 3))
+	}
+	server := ecsfake.NewServer(options...)
+	defer server.Close()
+	client := NewRetryingECS(server.Client(), 2, noBackoff)
+	ctx := aws.BackgroundContext()
+
+	for i := 0; i < 3; i++ {
+		_, err := client.CreateClusterWithContext(ctx, &ecs.CreateClusterInput{
+			ClusterName: aws.String(fmt.Sprintf("cluster%d", i)),
+		})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 3; i++ {
+		_, err := client.RegisterTaskDefinitionWithContext(ctx, &ecs.RegisterTaskDefinitionInput{
+			Family: aws.String("family"),
+			ContainerDefinitions: []*ecs.ContainerDefinition{{
+				Name:  aws.String("container"),
+				Image: aws.String("busybox"),
+			}},
+		})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 3; i++ {
+		_, err := client.CreateServiceWithContext(ctx, &ecs.CreateServiceInput{
+			Cluster:        aws.String("cluster0"),
+			ServiceName:    aws.String(fmt.Sprintf("service%d", i)),
+			TaskDefinition: aws.String("family"),
+		})
+		require.NoError(t, err)
+	}
+	for i := 0; i < 6; i++ {
+		output, err := client.DescribeServicesWithContext(ctx, &ecs.DescribeServicesInput{
+			Cluster:  aws.String("cluster0"),
+			Services: aws.StringSlice([]string{"service0", "service1", "service2"}),
+		})
+		require.NoError(t, err)
+		assert.Len(t, output.Services, 3)
+	}
+
+	// Injected failures happen before the call has any effect, so the retries
+	// did not register extra revisions
+	output, err := client.ListTaskDefinitionsWithContext(ctx, &ecs.ListTaskDefinitionsInput{})
+	require.NoError(t, err)
+	assert.Len(t, output.TaskDefinitionArns, 3)
+}
+
+func TestRetryingECSRunTaskBatchRetriesEachCall(t *testing.T) {
+	server := ecsfake.NewServer(ecsfake.DeterministicFailures("RunTask", 2))
+	defer server.Close()
+	// Every other call fails, so the 3 concurrent calls of the batch fail at
+	// most 3 times in total and 4 attempts are always enough
+	client := NewRetryingECS(server.Client(), 4, noBackoff)
+	ctx := aws.BackgroundContext()
+
+	_, err := client.CreateClusterWithContext(ctx, &ecs.CreateClusterInput{ClusterName: aws.String("cluster")})
+	require.NoError(t, err)
+	_, err = client.RegisterContainerInstanceWithContext(ctx, &ecs.RegisterContainerInstanceInput{Cluster: aws.String("cluster")})
+	require.NoError(t, err)
+	_, err = client.RegisterTaskDefinitionWithContext(ctx, &ecs.RegisterTaskDefinitionInput{
+		Family: aws.String("family"),
+		ContainerDefinitions: []*ecs.ContainerDefinition{{
+			Name:  aws.String("container"),
+			Image: aws.String("busybox"),
+		}},
+	})
+	require.NoError(t, err)
+
+	tasks, failures, err := client.RunTaskBatch(ctx, &ecs.RunTaskInput{
+		Cluster:        aws.String("cluster"),
+		TaskDefinition: aws.String("family"),
+	}, 25)
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.Len(t, tasks, 25)
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// taskStateChangeRecord is the JSON line written by
+// SubmitTaskStateChangeLogger for each call
+type taskStateChangeRecord struct {
+	Timestamp  time.Time                    `json:"timestamp"`
+	Cluster    string                       `json:"cluster"`
+	Task       string                       `json:"task"`
+	Status     string                       `json:"status"`
+	Reason     string                       `json:"reason,omitempty"`
+	Containers []containerStateChangeRecord `json:"containers"`
+}
+
+// containerStateChangeRecord is a container of a taskStateChangeRecord
+type containerStateChangeRecord struct {
+	ContainerName string `json:"containerName"`
+	Status        string `json:"status"`
+	ExitCode      *int64 `json:"exitCode,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// SubmitTaskStateChangeLogger wraps an ecs.ECSClientInterface and writes each
+// SubmitTaskStateChange call made through it to a writer as a line of JSON,
+// with the time of the call, the cluster, the task, its status and the
+// state of its containers, before passing the call on. A line that cannot be
+// written is dropped without failing the call. Other calls go straight to the
+// wrapped client. It is safe for concurrent use if the wrapped client is.
+type SubmitTaskStateChangeLogger struct {
+	ecs.ECSClientInterface
+	// now returns the current time, and is replaced in tests
+	now func() time.Time
+
+	lock   sync.Mutex
+	writer io.Writer
+}
+
+var _ ecs.ECSClientInterface = (*SubmitTaskStateChangeLogger)(nil)
+
+// NewSubmitTaskStateChangeLogger creates a SubmitTaskStateChangeLogger
+// writing the SubmitTaskStateChange calls made to client to writer
+func NewSubmitTaskStateChangeLogger(client ecs.ECSClientInterface, writer io.Writer) *SubmitTaskStateChangeLogger {
+	return &SubmitTaskStateChangeLogger{
+		ECSClientInterface: client,
+		now:                time.Now,
+		writer:             writer,
+	}
+}
+
+// SubmitTaskStateChangeWithContext writes the call, then calls
+// SubmitTaskStateChangeWithContext on the wrapped client
+func (l *SubmitTaskStateChangeLogger) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	l.write(input)
+	return l.ECSClientInterface.SubmitTaskStateChangeWithContext(ctx, input, opts...)
+}
+
+// write writes the JSON line of a call, ignoring errors
+func (l *SubmitTaskStateChangeLogger) write(input *ecs.SubmitTaskStateChangeInput) {
+	if input == nil {
+		return
+	}
+	record := taskStateChangeRecord{
+		Timestamp:  l.now().UTC(),
+		Cluster:    aws.StringValue(input.Cluster),
+		Task:       aws.StringValue(input.Task),
+		Status:     aws.StringValue(input.Status),
+		Reason:     aws.StringValue(input.Reason),
+		Containers: []containerStateChangeRecord{},
+	}
+	for _, container := range input.Containers {
+		if container == nil {
+			continue
+		}
+		record.Containers = append(record.Containers, containerStateChangeRecord{
+			ContainerName: aws.StringValue(container.ContainerName),
+			Status:        aws.StringValue(container.Status),
+			ExitCode:      container.ExitCode,
+			Reason:        aws.StringValue(container.Reason),
+		})
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.writer.Write(append(line, '\n'))
+}
@@ -0,0 +1,112 @@
+// +build unit
+
+// Copyright 2018 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//    http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecsclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-ecs-agent/agent/ecs_client/model/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taskStateChangeRecorder records the SubmitTaskStateChange calls made to it
+type taskStateChangeRecorder struct {
+	ecs.ECSClientInterface
+	inputs []*ecs.SubmitTaskStateChangeInput
+}
+
+func (c *taskStateChangeRecorder) SubmitTaskStateChangeWithContext(ctx aws.Context, input *ecs.SubmitTaskStateChangeInput, opts ...request.Option) (*ecs.SubmitTaskStateChangeOutput, error) {
+	c.inputs = append(c.inputs, input)
+	return &ecs.SubmitTaskStateChangeOutput{Acknowledgment: aws.String("ACK")}, nil
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func testTaskStateChange() *ecs.SubmitTaskStateChangeInput {
+	return &ecs.SubmitTaskStateChangeInput{
+		Cluster: aws.String("cluster"),
+		Task:    aws.String("arn:aws:ecs:us-west-2:123456789012:task/cluster/1234"),
+		Status:  aws.String(ecs.DesiredStatusStopped),
+		Reason:  aws.String("Essential container in task exited"),
+		Containers: []*ecs.ContainerStateChange{
+			{ContainerName: aws.String("web"), Status: aws.String(ecs.DesiredStatusStopped), ExitCode: aws.Int64(137), Reason: aws.String("OOM")},
+			{ContainerName: aws.String("sidecar"), Status: aws.String(ecs.DesiredStatusStopped), ExitCode: aws.Int64(0)},
+		},
+	}
+}
+
+func TestSubmitTaskStateChangeLogger(t *testing.T) {
+	client := &taskStateChangeRecorder{}
+	var buffer bytes.Buffer
+	logger := NewSubmitTaskStateChangeLogger(client, &buffer)
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	logger.now = func() time.Time { return now }
+
+	input := testTaskStateChange()
+	output, err := logger.SubmitTaskStateChangeWithContext(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "ACK", aws.StringValue(output.Acknowledgment))
+	assert.Equal(t, []*ecs.SubmitTaskStateChangeInput{input}, client.inputs)
+
+	_, err = logger.SubmitTaskStateChangeWithContext(context.Background(), &ecs.SubmitTaskStateChangeInput{
+		Task:   aws.String("task"),
+		Status: aws.String(ecs.DesiredStatusRunning),
+	})
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 2, "each call should be written on its own line")
+	assert.JSONEq(t, `{
+		"timestamp": "2018-06-01T12:00:00Z",
+		"cluster": "cluster",
+		"task": "arn:aws:ecs:us-west-2:123456789012:task/cluster/1234",
+		"status": "STOPPED",
+		"reason": "Essential container in task exited",
+		"containers": [
+			{"containerName": "web", "status": "STOPPED", "exitCode": 137, "reason": "OOM"},
+			{"containerName": "sidecar", "status": "STOPPED", "exitCode": 0}
+		]
+	}`, string(lines[0]))
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[1], &record))
+	assert.Equal(t, "RUNNING", record["status"])
+	assert.Equal(t, "", record["cluster"])
+	assert.Equal(t, []interface{}{}, record["containers"])
+}
+
+func TestSubmitTaskStateChangeLoggerWriteError(t *testing.T) {
+	client := &taskStateChangeRecorder{}
+	logger := NewSubmitTaskStateChangeLogger(client, failingWriter{})
+
+	output, err := logger.SubmitTaskStateChangeWithContext(context.Background(), testTaskStateChange())
+	require.NoError(t, err)
+	assert.Equal(t, "ACK", aws.StringValue(output.Acknowledgment))
+	assert.Len(t, client.inputs, 1, "the call should be made even if it cannot be written")
+}
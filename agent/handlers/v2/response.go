@@ -41,6 +41,7 @@ type TaskResponse struct {
 	PullStoppedAt      *time.Time          `json:"PullStoppedAt,omitempty"`
 	ExecutionStoppedAt *time.Time          `json:"ExecutionStoppedAt,omitempty"`
 	AvailabilityZone   string              `json:"AvailabilityZone,omitempty"`
+	LaunchType         string              `json:"LaunchType,omitempty"`
 }
 
 // ContainerResponse defines the schema for the container response
@@ -64,6 +65,7 @@ type ContainerResponse struct {
 	Networks      []containermetadata.Network `json:"Networks,omitempty"`
 	Health        *apicontainer.HealthStatus  `json:"Health,omitempty"`
 	Volumes       []v1.VolumeResponse         `json:"Volumes,omitempty"`
+	ContainerARN  string                      `json:"ContainerARN,omitempty"`
 }
 
 // LimitsResponse defines the schema for task/cpu limits response